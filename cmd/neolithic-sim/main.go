@@ -0,0 +1,248 @@
+// Command neolithic-sim runs the simulation headless, without any rendering. It loads a scenario or resumes a saved
+// snapshot, advances the engine a fixed number of ticks at a fixed delta time, and prints a summary of the final world
+// state.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"sort"
+
+	"Neolithic/internal/agent"
+	"Neolithic/internal/attributes"
+	"Neolithic/internal/core"
+	"Neolithic/internal/logging"
+	"Neolithic/internal/profiling"
+	"Neolithic/internal/replay"
+	"Neolithic/internal/scenario"
+	"Neolithic/internal/snapshot"
+	"Neolithic/internal/world"
+)
+
+func main() {
+	ticks := flag.Int("ticks", 3600, "number of ticks to run the simulation for")
+	deltaTime := flag.Float64("delta", 1.0/60, "simulated seconds that pass each tick")
+	logLevel := flag.String("log-level", "warn", "log level (debug, info, warn, error)")
+	scenarioPath := flag.String("scenario", "", "path to a scenario file; the built-in default scenario is used if empty")
+	loadPath := flag.String("load", "", "path to a snapshot to resume from instead of loading a scenario")
+	savePath := flag.String("save", "", "path to write a snapshot of the final world state to")
+	seed := flag.Int64("seed", world.DefaultSeed, "seed of the simulation, from which maps are generated; overrides the seed of the scenario if given")
+	recordPath := flag.String("record", "", "path to write a replay log of the run to")
+	replayPath := flag.String("replay", "", "path to a replay log to replay and verify instead of running a new simulation")
+	listAttributes := flag.Bool("list-attributes", false, "list the attribute types that can be used in scenario files and exit")
+	planStats := flag.Bool("plan-stats", false, "print statistics about the planner's searches")
+	var profileConfig profiling.Config
+	profileConfig.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *listAttributes {
+		writeAttributeSchemas(os.Stdout, attributes.NewRegistry())
+		return
+	}
+
+	if *ticks < 0 {
+		log.Fatal("ticks must not be negative")
+	}
+	if *deltaTime <= 0 {
+		log.Fatal("delta must be positive")
+	}
+	if *replayPath == "" && *loadPath != "" && *scenarioPath != "" {
+		log.Fatal("only one of -load and -scenario may be given")
+	}
+	if *replayPath == "" && *loadPath != "" && flagGiven("seed") {
+		log.Fatal("-seed can't be given with -load, since a snapshot keeps the seed it was taken with")
+	}
+
+	logger := logging.NewLogger(*logLevel)
+
+	profiler, err := profiling.Start(profileConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *replayPath != "" {
+		engine, replayed, simulatedTime, err := runReplay(*replayPath, logger)
+		if err != nil {
+			stopAndFatal(profiler, err)
+		}
+		if err = profiler.Stop(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(os.Stdout, "replay matched %d ticks\n", replayed)
+		writeSummary(os.Stdout, engine, replayed, simulatedTime)
+		return
+	}
+
+	var scenarioOpts []scenario.Option
+	if flagGiven("seed") {
+		scenarioOpts = append(scenarioOpts, scenario.WithSeed(*seed))
+	}
+	engine, err := loadEngine(*scenarioPath, *loadPath, logger, scenarioOpts...)
+	if err != nil {
+		stopAndFatal(profiler, err)
+	}
+
+	var stats *profiling.PlannerStats
+	if *planStats {
+		stats = profiling.NewPlannerStats()
+		stats.Attach(engine.World)
+	}
+
+	tick := engine.Tick
+	var recorder *replay.Recorder
+	if *recordPath != "" {
+		recorder, err = replay.NewRecorder(engine, attributes.NewRegistry())
+		if err != nil {
+			stopAndFatal(profiler, err)
+		}
+		tick = recorder.Tick
+	}
+
+	for i := 0; i < *ticks; i++ {
+		if err = tick(*deltaTime); err != nil {
+			stopAndFatal(profiler, fmt.Errorf("tick %d: %w", i, err))
+		}
+	}
+	if err = profiler.Stop(); err != nil {
+		log.Fatal(err)
+	}
+
+	if recorder != nil {
+		if err = replay.SaveFile(*recordPath, recorder.Log()); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *savePath != "" {
+		if err = snapshot.SaveFile(*savePath, engine); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	writeSummary(os.Stdout, engine, *ticks, float64(*ticks)*(*deltaTime))
+	if stats != nil {
+		stats.WriteSummary(os.Stdout)
+	}
+}
+
+// stopAndFatal stops the profiler, so that the profiles taken so far are written, then logs err and exits.
+func stopAndFatal(profiler *profiling.Session, err error) {
+	if stopErr := profiler.Stop(); stopErr != nil {
+		log.Print(stopErr)
+	}
+	log.Fatal(err)
+}
+
+// runReplay replays the log at path, returning the resulting engine, the number of ticks replayed and the simulated
+// time they covered.
+func runReplay(path string, logger *slog.Logger) (*world.Engine, int, float64, error) {
+	replayLog, err := replay.LoadFile(path)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	engine, err := replay.Replay(replayLog, attributes.NewRegistry(), logger)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	simulatedTime := 0.0
+	for _, tick := range replayLog.Ticks {
+		simulatedTime += tick.DeltaTime
+	}
+	return engine, len(replayLog.Ticks), simulatedTime, nil
+}
+
+// flagGiven reports whether the named flag was set on the command line.
+func flagGiven(name string) bool {
+	given := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	})
+	return given
+}
+
+// loadEngine restores the snapshot at snapshotPath if given. Otherwise, it loads the scenario at scenarioPath, or the
+// default scenario if that is empty too, built with the options.
+func loadEngine(scenarioPath, snapshotPath string, logger *slog.Logger, opts ...scenario.Option) (*world.Engine, error) {
+	if snapshotPath != "" {
+		return snapshot.LoadFile(snapshotPath, logger)
+	}
+	if scenarioPath == "" {
+		return scenario.Default(logger, opts...)
+	}
+	return scenario.Load(scenarioPath, logger, opts...)
+}
+
+// writeSummary writes a human-readable summary of the engine's world state to w.
+func writeSummary(w io.Writer, engine *world.Engine, ticks int, simulatedTime float64) {
+	fmt.Fprintf(w, "ticks: %d\n", ticks)
+	fmt.Fprintf(w, "seed: %d\n", engine.Seed())
+	fmt.Fprintf(w, "simulated time: %.2fs\n", simulatedTime)
+
+	locNames := make([]string, 0, len(engine.World.Locations))
+	for name := range engine.World.Locations {
+		locNames = append(locNames, name)
+	}
+	sort.Strings(locNames)
+
+	fmt.Fprintln(w, "locations:")
+	for _, name := range locNames {
+		loc := engine.World.Locations[name]
+		fmt.Fprintf(w, "  %s %s inventory:%s\n", loc.Name, loc.Coord, loc.Inventory)
+	}
+
+	agentNames := make([]string, 0, len(engine.World.Agents))
+	for name := range engine.World.Agents {
+		agentNames = append(agentNames, name)
+	}
+	sort.Strings(agentNames)
+
+	fmt.Fprintln(w, "agents:")
+	for _, name := range agentNames {
+		a := engine.World.Agents[name].(*agent.Agent)
+		fmt.Fprintf(w, "  %s %s state:%s inventory:%s\n", a.Name(), a.Position, stateName(a.Behavior.CurState), a.Inventory())
+		for _, need := range a.Behavior.Needs {
+			resting := ""
+			if need.Resting {
+				resting = " (resting)"
+			}
+			fmt.Fprintf(w, "    %s: %.2f%s\n", need.Name, need.Level, resting)
+		}
+	}
+}
+
+// writeAttributeSchemas writes every attribute type in the registry along with its parameters to w.
+func writeAttributeSchemas(w io.Writer, registry *core.AttributeRegistry) {
+	for _, schema := range registry.Schemas() {
+		fmt.Fprintf(w, "%s: %s\n", schema.Type, schema.Description)
+		for _, param := range schema.Params {
+			requirement := "optional"
+			if param.Required {
+				requirement = "required"
+			} else if param.Default != nil {
+				requirement = fmt.Sprintf("default %v", param.Default)
+			}
+			fmt.Fprintf(w, "  %s (%s, %s): %s\n", param.Name, param.Kind, requirement, param.Description)
+		}
+	}
+}
+
+// stateName returns a short name for an agent's current state.
+func stateName(state agent.State) string {
+	switch state.(type) {
+	case *agent.Idle:
+		return "idle"
+	case *agent.Moving:
+		return "moving"
+	case *agent.Performing:
+		return "performing"
+	default:
+		return fmt.Sprintf("%T", state)
+	}
+}
@@ -18,10 +18,21 @@ type Agent struct {
 	inventory core.Inventory
 	// Position represents the Agent's current location in the world using coordinates
 	Position core.Coord
+	// MaxCarry is the most weight of resources the Agent can carry, or 0 if there is no limit
+	MaxCarry float64
+	// Speed is how many tiles the Agent moves in a second over open ground, or 0 for DefaultSpeed
+	Speed float64
 }
 
-// Ensure Agent implements core.Agent interface
-var _ core.Agent = (*Agent)(nil)
+// DefaultSpeed is the speed, in tiles per second, of agents that aren't given one
+const DefaultSpeed = 4.0
+
+// Ensure Agent implements core.Agent, core.Carrier and core.Occupant interfaces
+var (
+	_ core.Agent    = (*Agent)(nil)
+	_ core.Carrier  = (*Agent)(nil)
+	_ core.Occupant = (*Agent)(nil)
+)
 
 // Name returns the name of the Agent
 func (a *Agent) Name() string {
@@ -33,6 +44,60 @@ func (a *Agent) Inventory() core.Inventory {
 	return a.inventory
 }
 
+// CarryCapacity implements core.Carrier, and returns the most weight the Agent can carry, or 0 if there is no limit
+func (a *Agent) CarryCapacity() float64 {
+	return a.MaxCarry
+}
+
+// speed returns the Agent's Speed, or DefaultSpeed if it isn't set.
+func (a *Agent) speed() float64 {
+	if a.Speed <= 0 {
+		return DefaultSpeed
+	}
+	return a.Speed
+}
+
+// OccupiedCoords implements core.Occupant. The Agent takes up its Position and, once it has started moving onto it, the
+// next coordinate of its path.
+func (a *Agent) OccupiedCoords() []core.Coord {
+	coords := []core.Coord{a.Position}
+	if a.Behavior == nil {
+		return coords
+	}
+	if moving, ok := a.Behavior.CurState.(*Moving); ok && moving.next != nil && moving.progress > 0 {
+		coords = append(coords, *moving.next)
+	}
+	return coords
+}
+
+// heading returns the coordinate the Agent is moving onto, or waiting to move onto, or false if it isn't moving.
+func (a *Agent) heading() (core.Coord, bool) {
+	if a.Behavior == nil {
+		return core.Coord{}, false
+	}
+	moving, ok := a.Behavior.CurState.(*Moving)
+	if !ok || moving.next == nil {
+		return core.Coord{}, false
+	}
+	return *moving.next, true
+}
+
+// InterpolatedPosition returns where the Agent is, in tiles: its Position, or a point on the way from its Position to
+// the next coordinate while it is moving between them. It is meant for drawing the Agent smoothly.
+func (a *Agent) InterpolatedPosition() (x, y float64) {
+	x, y = float64(a.Position.X), float64(a.Position.Y)
+	if a.Behavior == nil {
+		return x, y
+	}
+	moving, ok := a.Behavior.CurState.(*Moving)
+	if !ok || moving.next == nil {
+		return x, y
+	}
+	x += (float64(moving.next.X) - x) * moving.progress
+	y += (float64(moving.next.Y) - y) * moving.progress
+	return x, y
+}
+
 // DeepCopy creates a deep copy of the Agent and returns it
 func (a *Agent) DeepCopy() core.Agent {
 	newAgent := &Agent{}
@@ -43,12 +108,17 @@ func (a *Agent) DeepCopy() core.Agent {
 			CurPlan:         a.Behavior.CurPlan,
 			CurState:        a.Behavior.CurState,
 			GoalEngine:      a.Behavior.GoalEngine,
+			Planning:        a.Behavior.Planning,
+			PlanStats:       a.Behavior.PlanStats,
+			Needs:           a.Behavior.Needs,
 		}
 	}
 	if a.inventory != nil {
 		newAgent.inventory = a.inventory.DeepCopy()
 	}
 	newAgent.Position = a.Position
+	newAgent.MaxCarry = a.MaxCarry
+	newAgent.Speed = a.Speed
 	return newAgent
 }
 
@@ -65,10 +135,23 @@ func (a *Agent) String() string {
 	return sb.String()
 }
 
+// Tick updates the Agent's needs and runs its current State for a single unit of discrete time. If the Agent consumes
+// something to satisfy a need, that takes up the tick and the State is not run. The State is not run either while the
+// Agent is resting.
 func (a *Agent) Tick(worldState *core.WorldState, deltaTime float64) (*core.WorldState, error) {
+	if binder, ok := a.Behavior.CurState.(agentBinder); ok {
+		binder.bindAgent(a)
+	}
+	if consumed := a.tickNeeds(worldState, deltaTime); consumed != nil {
+		return consumed, nil
+	}
+	if a.resting() {
+		return nil, nil
+	}
 	return a.Behavior.CurState.Execute(worldState, deltaTime)
 }
 
+// NewAgent creates a new Agent with an empty inventory, in the Idle state.
 func NewAgent(name string, logger *slog.Logger) *Agent {
 	newAgent := &Agent{
 		Behavior:  &Behavior{},
@@ -1,6 +1,8 @@
 package agent
 
 import (
+	"time"
+
 	"Neolithic/internal/core"
 	"Neolithic/internal/goalengine"
 )
@@ -13,6 +15,13 @@ type State interface {
 	Execute(world *core.WorldState, deltaTime float64) (*core.WorldState, error)
 }
 
+// agentBinder is implemented by States that act on behalf of an Agent. The Agent is copied every time it changes the
+// world, so a State is bound to the current copy of its Agent before each Execute.
+type agentBinder interface {
+	// bindAgent sets the Agent the State acts on behalf of
+	bindAgent(agent *Agent)
+}
+
 // Behavior encapsulates the parts of the Agent that are not in the physical WorldState
 type Behavior struct {
 	// PossibleActions represents all possible actions the Agent can do. This is NOT the same as the actions in the
@@ -25,4 +34,38 @@ type Behavior struct {
 	CurState State
 	// GoalEngine is used to determine the agent's current and future goals
 	GoalEngine *goalengine.GoalEngine
+	// Planning limits how much work the GOAP planner does for the Agent
+	Planning PlanningBudget
+	// PlanStats, if set, receives statistics about every search the planner finishes for the Agent
+	PlanStats PlanStatsRecorder
+	// Needs are the Agent's needs, which decay over time and add goals to the GoalEngine when they are unmet
+	Needs []*Need
+}
+
+// PlanningBudget limits how much work the GOAP planner does for an Agent. A search that doesn't finish within a tick is
+// resumed on the next one, so a large search is spread over several frames instead of stalling one. Zero values use
+// the defaults.
+type PlanningBudget struct {
+	// IterationsPerTick is the most iterations the planner runs in a single tick. Defaults to defaultNumIterations.
+	IterationsPerTick int `json:"iterations_per_tick,omitempty"`
+	// TimePerTick, if set, stops the planner for the tick once it has run this long. The number of iterations run then
+	// depends on the speed of the machine, so a simulation using it can't be replayed exactly.
+	TimePerTick time.Duration `json:"time_per_tick,omitempty"`
+	// MaxIterations is the most iterations spent planning for a single goal, across all ticks. Once it is reached, the
+	// best plan found so far is used, or the goal is given up on if there is none. Defaults to defaultMaxIterations.
+	MaxIterations int `json:"max_iterations,omitempty"`
+	// AcceptAfter, if set, accepts the best plan found so far once the planner has run this many iterations for the
+	// goal, rather than waiting until the plan is known to be the best one.
+	AcceptAfter int `json:"accept_after,omitempty"`
+}
+
+// withDefaults returns the budget with its zero values replaced by the defaults.
+func (b PlanningBudget) withDefaults() PlanningBudget {
+	if b.IterationsPerTick <= 0 {
+		b.IterationsPerTick = defaultNumIterations
+	}
+	if b.MaxIterations <= 0 {
+		b.MaxIterations = defaultMaxIterations
+	}
+	return b
 }
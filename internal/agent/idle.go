@@ -2,23 +2,26 @@ package agent
 
 import (
 	"errors"
-	"log"
 	"log/slog"
-	"os"
-	"runtime"
-	"runtime/pprof"
+	"time"
 
 	"Neolithic/internal/astar"
 	"Neolithic/internal/core"
 	"Neolithic/internal/planner"
 )
 
-const defaultNumIterations = 100000
+const (
+	// defaultNumIterations is the default number of iterations the planner runs in a single tick
+	defaultNumIterations = 100000
+	// defaultMaxIterations is the default number of iterations the planner runs for a single goal
+	defaultMaxIterations = 4 * defaultNumIterations
+)
 
 // Idle is the state the Agent enters in when it has no working plan. It attempts to create a plan and will proceed
 // to a different state once successful.
 type Idle struct {
-	// IterationsPerCall is the number of iterations to run the goap planner for in a given call.
+	// IterationsPerCall is the number of iterations to run the goap planner for in a given call. If zero, the Agent's
+	// PlanningBudget is used.
 	IterationsPerCall int
 	// planner is the GOAP planner that creates the agent's plan
 	planner *astar.SearchState
@@ -26,22 +29,23 @@ type Idle struct {
 	agent *Agent
 	// logger is used for logging state events
 	logger *slog.Logger
-	// numRetries is the number of times Idle has attempted to get a goal
-	numRetries int
 	// curGoal is the current goal for the agent
 	curGoal *core.WorldState
+	// planTicks is the number of ticks the current search has run for
+	planTicks int
+	// planTime is the time spent running the current search
+	planTime time.Duration
 }
 
 // Execute implements State.Exeucte. Using a defined goal, it creates a plan using the GOAP planner. It runs
-// the planner a given number of iterations per call. Once a plan is found, it is set on the Agent and
-// the Agent proceeds to a Moving state.
+// the planner within the Agent's PlanningBudget each call, resuming the same search on the next call if it hasn't
+// finished. Once a plan is found, it is set on the Agent and the Agent proceeds to a Moving state.
+//
+// The search is made against the world as it was when the search started; if the world changes before the plan is
+// carried out, the plan is repaired when one of its actions fails.
 func (i *Idle) Execute(world *core.WorldState, _ float64) (*core.WorldState, error) {
 	i.logger.Debug("idle state execute", "agent", i.agent.Name())
 
-	if i.IterationsPerCall == 0 {
-		i.IterationsPerCall = defaultNumIterations
-	}
-
 	if i.agent.Behavior.GoalEngine == nil {
 		// no goal, do nothing
 		i.logger.Debug("no goal set, staying idle", "agent", i.agent.Name())
@@ -50,104 +54,219 @@ func (i *Idle) Execute(world *core.WorldState, _ float64) (*core.WorldState, err
 
 	if i.curGoal == nil {
 		i.logger.Info("creating new search state", "agent", i.agent.Name())
-		i.curGoal = i.agent.Behavior.GoalEngine.GetNextGoal(world, i.numRetries)
+		// plan against what is left once other agents' reservations are taken out
+		available := world.AvailableTo(i.agent.Name())
+		i.curGoal = i.agent.Behavior.GoalEngine.GetNextGoal(available)
 		if i.curGoal == nil {
 			i.logger.Info("goal engine unable to provide goal")
 			return nil, nil
 		}
-		search, err := i.createSearchState(world)
+		i.logger.Debug("planning for goal", "agent", i.agent.Name(), "goal", i.agent.Behavior.GoalEngine.Current().Name)
+		search, err := i.createSearchState(available)
 		if err != nil {
 			i.logger.Error("failed to create search state", "agent", i.agent.Name(), "error", err)
 			return nil, err
 		}
 		i.planner = search
+		i.planTicks = 0
+		i.planTime = 0
+	}
 
-		if err = search.RunIterations(i.IterationsPerCall); err != nil {
-			if errors.Is(err, astar.ErrNoPath) {
-				i.logger.Debug("no path found to goal")
-				i.curGoal = nil
-				i.numRetries++
-				return nil, nil
-			}
-			i.logger.Error("planner iteration error", "agent", i.agent.Name(), "error", err)
-			return nil, err
-		}
-		// --- Take the memory snapshot immediately after ---
-		log.Println("Taking memory profile snapshot...")
-		memProfileFile, err := os.Create("mem.pprof")
-		if err != nil {
-			log.Fatal("could not create memory profile: ", err)
-		}
-		defer memProfileFile.Close()
-
-		runtime.GC() // Force GC to see what's really still in use by the searchState
-		if err := pprof.WriteHeapProfile(memProfileFile); err != nil {
-			log.Fatal("could not write memory profile: ", err)
+	budget := i.budget()
+	started := time.Now()
+	err := i.runPlanner(budget)
+	i.planTicks++
+	i.planTime += time.Since(started)
+	if err != nil {
+		if errors.Is(err, astar.ErrNoPath) {
+			i.logger.Debug("no path found to goal")
+			i.abandonGoal()
+			return nil, nil
 		}
-		log.Println("Memory profile created.")
-
-		// if we were unable to find a path, reset values and try again next tick
-		if !i.planner.FoundBest {
+		i.logger.Error("planner iteration error", "agent", i.agent.Name(), "error", err)
+		return nil, err
+	}
+	if !i.planner.FoundBest && !i.acceptBestPlan(budget) {
+		if i.planner.Iterations >= budget.MaxIterations {
+			// out of budget without a plan, so try a different goal next tick
 			i.logger.Debug("unable to produce plan with this goal", "agent", i.agent.Name())
-			i.curGoal = nil
-			i.numRetries++
-			return nil, nil // we'll continue the search next Execute call
+			i.abandonGoal()
+			return nil, nil
 		}
+		i.logger.Debug("plan not finished, continuing next tick", "agent", i.agent.Name(), "iterations", i.planner.Iterations)
+		return nil, nil
 	}
 
-	i.logger.Info("plan found, creating action list", "agent", i.agent.Name())
+	i.logger.Info("plan found, creating action list", "agent", i.agent.Name(), "best", i.planner.FoundBest)
 	actionList, err := i.createActionListFromSearchState()
 	if err != nil {
 		i.logger.Error("failed to create action list", "agent", i.agent.Name(), "error", err)
 		return nil, err
 	}
 
-	i.agent.Behavior.CurPlan = &plan{Actions: actionList, curLocation: 1} // 1 because index of zero is null
+	// the search may have run over several ticks, in which time another agent may have reserved what the plan takes
+	reserved, ok := reservePlan(world, i.agent.Name(), actionList)
+	if !ok {
+		i.logger.Info("plan no longer fits the world, replanning", "agent", i.agent.Name())
+		i.recordStats(false, 0)
+		i.curGoal = nil
+		return nil, nil
+	}
+
+	// the first entry of the action list is the start node, which has no action
+	i.recordStats(true, len(actionList)-1)
+	i.agent.Behavior.GoalEngine.GoalPlanned()
+	i.agent.Behavior.CurPlan = &plan{Actions: actionList, curLocation: 1, goal: i.curGoal} // 1 because index of zero is null
 	i.agent.Behavior.CurState = &Moving{agent: i.agent, logger: i.logger}
 	i.logger.Info("transitioning to moving state", "agent", i.agent.Name(), "planLength", len(actionList))
-	return nil, nil
+	return reserved, nil
+}
+
+// budget returns the PlanningBudget of the Agent, with IterationsPerCall taking precedence if it is set.
+func (i *Idle) budget() PlanningBudget {
+	budget := i.agent.Behavior.Planning.withDefaults()
+	if i.IterationsPerCall > 0 {
+		budget.IterationsPerTick = i.IterationsPerCall
+	}
+	return budget
+}
+
+// runPlanner runs the planner for a single tick's worth of the budget, without going over the budget for the goal.
+func (i *Idle) runPlanner(budget PlanningBudget) error {
+	iterations := min(budget.IterationsPerTick, budget.MaxIterations-i.planner.Iterations)
+	if budget.TimePerTick > 0 {
+		return i.planner.RunFor(budget.TimePerTick, iterations)
+	}
+	return i.planner.RunIterations(iterations)
+}
+
+// acceptBestPlan reports whether the planner's best plan should be used before it is known to be the best, either
+// because the budget allows accepting it early or because the budget has run out.
+func (i *Idle) acceptBestPlan(budget PlanningBudget) bool {
+	if !i.planner.HasSolution() {
+		return false
+	}
+	if budget.AcceptAfter > 0 && i.planner.Iterations >= budget.AcceptAfter {
+		return true
+	}
+	return i.planner.Iterations >= budget.MaxIterations
+}
+
+// abandonGoal drops the current goal and reports the failure to the GoalEngine, so that a different goal or a less
+// ambitious chunk is requested on the next call.
+func (i *Idle) abandonGoal() {
+	i.recordStats(false, 0)
+	i.curGoal = nil
+	i.agent.Behavior.GoalEngine.GoalFailed()
+}
+
+// recordStats logs the statistics of the finished search and passes them to the Agent's PlanStatsRecorder, if any.
+// planLength is the number of actions in the plan found.
+func (i *Idle) recordStats(found bool, planLength int) {
+	stats := PlanStats{
+		Agent:      i.agent.Name(),
+		Found:      found,
+		Best:       found && i.planner.FoundBest,
+		PlanLength: planLength,
+		Iterations: i.planner.Iterations,
+		Expanded:   i.planner.Expanded,
+		OpenSet:    i.planner.OpenSetSize(),
+		ClosedSet:  i.planner.ClosedSetSize(),
+		Ticks:      i.planTicks,
+		WallTime:   i.planTime,
+	}
+	i.logger.Debug("planner finished", "agent", stats.Agent, "found", stats.Found, "iterations", stats.Iterations,
+		"expanded", stats.Expanded, "openSet", stats.OpenSet, "closedSet", stats.ClosedSet, "ticks", stats.Ticks,
+		"wallTime", stats.WallTime)
+	if i.agent.Behavior.PlanStats != nil {
+		i.agent.Behavior.PlanStats.RecordPlan(stats)
+	}
+}
+
+// reservePlan returns a new WorldState in which the agent has reserved the resources taken by the actions in its plan,
+// or nil if the plan takes nothing. Each reservation is checked against what is available to the agent in world; if
+// any no longer fits, nothing is reserved and the returned bool is false.
+func reservePlan(world *core.WorldState, agentName string, actions []core.Action) (*core.WorldState, bool) {
+	var reserved *core.WorldState
+	for _, action := range actions {
+		reserving, ok := action.(core.Reserving)
+		if !ok || reserving.Location() == nil {
+			continue
+		}
+		if reserved == nil {
+			reserved = world.ShallowCopy()
+		}
+		locName := reserving.Location().Name
+		loc, ok := reserved.GetLocation(locName)
+		if !ok {
+			continue
+		}
+		if loc == world.Locations[locName] { // copy on first write
+			loc = loc.DeepCopy()
+			reserved.Locations[locName] = loc
+		}
+		res := reserving.Resource()
+		loc.Reserve(agentName, res, reserving.ReservedAmount())
+		if loc.ReservedBy(agentName, res) > loc.Available(agentName, res) {
+			return nil, false
+		}
+	}
+	return reserved, true
 }
 
 // createSearchState creates the search state for the planner.
 func (i *Idle) createSearchState(world *core.WorldState) (*astar.SearchState, error) {
-	behavior := i.agent.Behavior
+	return newPlanSearch(world, i.curGoal, i.agent, i.logger)
+}
+
+// createActionListFromSearchState creates a list of actions for the Agent to follow.
+func (i *Idle) createActionListFromSearchState() ([]core.Action, error) {
+	if i.planner == nil {
+		return nil, errors.New("no planner")
+	}
+	return actionListFromSearch(i.planner), nil
+}
+
+// newPlanSearch creates a GOAP search from the world to the goal, using the agent's possible actions.
+func newPlanSearch(world, goal *core.WorldState, agent *Agent, logger *slog.Logger) (*astar.SearchState, error) {
 	runInfo := &planner.GoapRunInfo{
-		Agent:               i.agent,
-		PossibleNextActions: behavior.PossibleActions,
+		Agent:               agent,
+		PossibleNextActions: agent.Behavior.PossibleActions,
+		Goal:                goal,
 	}
 	start := &planner.GoapNode{
 		State:       world,
 		GoapRunInfo: runInfo,
 	}
 
-	goal := &planner.GoapNode{
-		State:       i.curGoal,
+	goalNode := &planner.GoapNode{
+		State:       goal,
 		GoapRunInfo: runInfo,
 	}
 
-	return astar.NewSearch(start, goal, astar.WithLogger(i.logger), astar.WithBias(astar.DoubleBias))
+	return astar.NewSearch(start, goalNode, astar.WithLogger(logger), astar.WithBias(astar.DoubleBias))
 }
 
-// createActionListFromSearchState creates a list of actions for the Agent to follow.
-func (i *Idle) createActionListFromSearchState() ([]core.Action, error) {
-	if i.planner == nil {
-		return nil, errors.New("no planner")
-	}
-
-	nodePlan := i.planner.CurrentBestPath()
+// actionListFromSearch returns the actions along the best path of a GOAP search. The first entry is the start node,
+// which has no action, so it is always nil.
+func actionListFromSearch(search *astar.SearchState) []core.Action {
 	var actionList []core.Action
-	for _, node := range nodePlan {
+	for _, node := range search.CurrentBestPath() {
 		action := node.(*planner.GoapNode).Action
 		actionList = append(actionList, action)
 	}
-	return actionList, nil
+	return actionList
+}
+
+// bindAgent implements agentBinder.
+func (i *Idle) bindAgent(agent *Agent) {
+	i.agent = agent
 }
 
-// NewIdle creates a new Idle state
+// NewIdle creates a new Idle state, which plans within the Agent's PlanningBudget
 func NewIdle(agent *Agent, logger *slog.Logger) *Idle {
 	return &Idle{
-		IterationsPerCall: defaultNumIterations,
-		agent:             agent,
-		logger:            logger,
+		agent:  agent,
+		logger: logger,
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"Neolithic/internal/logging"
 	"Neolithic/internal/planner"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -29,9 +30,11 @@ var testChunkerFunc goalengine.ChunkerFunc = func(location *core.Location, resou
 func TestIdle_Execute(t *testing.T) {
 	type testCase struct {
 		iterationsPerCall  int
+		planning           PlanningBudget
+		numCalls           int
 		planner            *astar.SearchState
 		startLocation      core.Location
-		goalEngine         *goalengine.GoalEngine
+		goal               goalengine.Goal
 		possibleActions    []core.Action
 		expectedIterations int
 		expectedPlan       Plan
@@ -39,19 +42,17 @@ func TestIdle_Execute(t *testing.T) {
 		expectedError      error
 	}
 
-	defaultGoalEngine := &goalengine.GoalEngine{
-		Goal: goalengine.Goal{
-			Name: "testGoal",
-			Logic: goalengine.GoalLogic{
-				Chunker:      testChunkerFunc,
-				Fallback:     goalengine.FallbackChunkFunc,
-				ShouldGiveUp: goalengine.GiveUpIfLessThanFive,
-			},
-			Location: &core.Location{
-				Name: "testLocation",
-			},
-			Resource: testResource,
+	defaultGoal := goalengine.Goal{
+		Name: "testGoal",
+		Logic: goalengine.GoalLogic{
+			Chunker:      testChunkerFunc,
+			Fallback:     goalengine.FallbackChunkFunc,
+			ShouldGiveUp: goalengine.GiveUpIfLessThanFive,
 		},
+		Location: &core.Location{
+			Name: "testLocation",
+		},
+		Resource: testResource,
 	}
 
 	// Create common inventories
@@ -66,7 +67,7 @@ func TestIdle_Execute(t *testing.T) {
 				Name:      "testLocation",
 				Inventory: emptyInventory,
 			},
-			goalEngine:         defaultGoalEngine,
+			goal:               defaultGoal,
 			possibleActions:    []core.Action{&mockAction{}},
 			expectedIterations: 2,
 			expectedRetries:    0,
@@ -77,7 +78,7 @@ func TestIdle_Execute(t *testing.T) {
 				Name:      "testLocation",
 				Inventory: emptyInventory,
 			},
-			goalEngine:         defaultGoalEngine,
+			goal:               defaultGoal,
 			possibleActions:    []core.Action{&mockAction{}},
 			expectedIterations: 4,
 			expectedRetries:    0,
@@ -95,48 +96,76 @@ func TestIdle_Execute(t *testing.T) {
 				Name:      "testLocation",
 				Inventory: emptyInventory,
 			},
-			goalEngine: &goalengine.GoalEngine{
-				Goal: goalengine.Goal{
-					Name: "testGoal",
-					Logic: goalengine.GoalLogic{
-						Chunker:      testChunkerFunc,
-						Fallback:     goalengine.FallbackChunkFunc,
-						ShouldGiveUp: goalengine.GiveUpIfLessThanFive,
-					},
-					Location: &core.Location{
-						Name: "testLocation",
-					},
-					Resource: testResource,
-				},
-			},
+			goal:               defaultGoal,
 			possibleActions:    []core.Action{&mockNilAction{}},
 			expectedIterations: 1,
 			expectedRetries:    1,
 		},
 		"increments retries when iterations run out": {
 			iterationsPerCall: 10,
+			planning:          PlanningBudget{MaxIterations: 10},
 			startLocation: core.Location{
 				Name:      "testLocation",
 				Inventory: emptyInventory,
 			},
-			goalEngine: &goalengine.GoalEngine{
-				Goal: goalengine.Goal{
-					Name: "testGoal",
-					Logic: goalengine.GoalLogic{
-						Chunker:      goalengine.AddToLocation,
-						Fallback:     goalengine.FallbackChunkFunc,
-						ShouldGiveUp: goalengine.GiveUpIfLessThanFive,
-					},
-					Location: &core.Location{
-						Name: "testLocation",
-					},
-					Resource: testResource,
+			goal: goalengine.Goal{
+				Name: "testGoal",
+				Logic: goalengine.GoalLogic{
+					Chunker:      goalengine.AddToLocation,
+					Fallback:     goalengine.FallbackChunkFunc,
+					ShouldGiveUp: goalengine.GiveUpIfLessThanFive,
+				},
+				Location: &core.Location{
+					Name: "testLocation",
 				},
+				Resource: testResource,
 			},
 			possibleActions:    []core.Action{&mockAction{}},
 			expectedIterations: 10,
 			expectedRetries:    1,
 		},
+		"resumes unfinished search on the next call": {
+			iterationsPerCall: 2,
+			numCalls:          2,
+			startLocation: core.Location{
+				Name:      "testLocation",
+				Inventory: emptyInventory,
+			},
+			goal:               defaultGoal,
+			possibleActions:    []core.Action{&mockAction{}},
+			expectedIterations: 4,
+			expectedRetries:    0,
+			expectedPlan: &plan{
+				Actions: []core.Action{
+					&mockAction{},
+					&mockAction{},
+					&mockAction{},
+				},
+			},
+		},
+		"keeps searching within the budget for the goal": {
+			planning: PlanningBudget{IterationsPerTick: 3, MaxIterations: 5},
+			numCalls: 2,
+			startLocation: core.Location{
+				Name:      "testLocation",
+				Inventory: emptyInventory,
+			},
+			goal: goalengine.Goal{
+				Name: "testGoal",
+				Logic: goalengine.GoalLogic{
+					Chunker:      goalengine.AddToLocation,
+					Fallback:     goalengine.FallbackChunkFunc,
+					ShouldGiveUp: goalengine.GiveUpIfLessThanFive,
+				},
+				Location: &core.Location{
+					Name: "testLocation",
+				},
+				Resource: testResource,
+			},
+			possibleActions:    []core.Action{&mockAction{}},
+			expectedIterations: 5,
+			expectedRetries:    1,
+		},
 	}
 
 	for name, tc := range tests {
@@ -152,7 +181,8 @@ func TestIdle_Execute(t *testing.T) {
 			// Create agent behavior for this specific test
 			agentBehavior := &Behavior{
 				PossibleActions: tc.possibleActions,
-				GoalEngine:      tc.goalEngine,
+				GoalEngine:      goalengine.NewGoalEngine(tc.goal),
+				Planning:        tc.planning,
 			}
 
 			testAgent := &Agent{
@@ -174,18 +204,256 @@ func TestIdle_Execute(t *testing.T) {
 				logger:            logging.NewLogger("info"),
 			}
 
-			_, err := testIdle.Execute(testStart, 0)
+			var err error
+			for call := 0; call < max(tc.numCalls, 1) && err == nil; call++ {
+				_, err = testIdle.Execute(testStart, 0)
+			}
 
 			if tc.expectedError != nil {
 				require.ErrorIs(t, err, tc.expectedError)
-				require.Equal(t, tc.expectedRetries, testIdle.numRetries)
+				require.Equal(t, tc.expectedRetries, testAgent.Behavior.GoalEngine.Goals[0].Failures)
 			} else {
 				require.NoError(t, err)
 				require.Equal(t, tc.expectedIterations, testIdle.planner.Iterations)
+				require.Equal(t, tc.expectedRetries, testAgent.Behavior.GoalEngine.Goals[0].Failures)
 				require.Equal(t, expectedStart.Action, testIdle.planner.Start.(*planner.GoapNode).Action)
+				expectedStart.GoapRunInfo.Goal = testIdle.planner.Goal.(*planner.GoapNode).State
 				require.Equal(t, expectedStart.GoapRunInfo, testIdle.planner.Start.(*planner.GoapNode).GoapRunInfo)
 				require.Equal(t, testAgent.Behavior.CurPlan, testIdle.agent.Behavior.CurPlan)
 			}
 		})
 	}
 }
+
+func TestReservePlan(t *testing.T) {
+	bush := &core.Location{Name: "bush", Inventory: core.NewInventory()}
+	bush.Inventory.AdjustAmount(testResource, 10)
+	pond := &core.Location{Name: "pond", Inventory: core.NewInventory()}
+	pond.Inventory.AdjustAmount(testResource, 1)
+
+	type testCase struct {
+		actions          []core.Action
+		claimedByOthers  int
+		expectNil        bool
+		expectNoFit      bool
+		expectedReserved map[string]int
+	}
+
+	tests := map[string]testCase{
+		"plan without reserving actions": {
+			actions:   []core.Action{&mockAction{}, &mockAction{}},
+			expectNil: true,
+		},
+		"reserves each gather": {
+			actions: []core.Action{
+				&mockReservingAction{location: bush, amount: 2},
+				&mockAction{},
+				&mockReservingAction{location: bush, amount: 3},
+				&mockReservingAction{location: pond, amount: 1},
+			},
+			expectedReserved: map[string]int{"bush": 5, "pond": 1},
+		},
+		"reserves what is left after other claimants": {
+			actions:          []core.Action{&mockReservingAction{location: bush, amount: 4}},
+			claimedByOthers:  6,
+			expectedReserved: map[string]int{"bush": 4},
+		},
+		"fails when another claimant has reserved the resource": {
+			actions: []core.Action{
+				&mockReservingAction{location: bush, amount: 2},
+				&mockReservingAction{location: bush, amount: 3},
+			},
+			claimedByOthers: 6,
+			expectNoFit:     true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			world := &core.WorldState{
+				Locations: map[string]*core.Location{"bush": bush.DeepCopy(), "pond": pond},
+				Agents:    map[string]core.Agent{},
+			}
+			world.Locations["bush"].Reserve("neighbour", testResource, tc.claimedByOthers)
+
+			reserved, ok := reservePlan(world, "villager", tc.actions)
+			if tc.expectNoFit {
+				require.False(t, ok)
+				require.Nil(t, reserved)
+				require.Zero(t, world.Locations["bush"].ReservedBy("villager", testResource))
+				return
+			}
+			require.True(t, ok)
+			if tc.expectNil {
+				require.Nil(t, reserved)
+				return
+			}
+			require.NotNil(t, reserved)
+			for locName, amount := range tc.expectedReserved {
+				loc, ok := reserved.GetLocation(locName)
+				require.True(t, ok)
+				require.Equal(t, amount, loc.ReservedBy("villager", testResource), "reserved at %s", locName)
+				require.Zero(t, world.Locations[locName].ReservedBy("villager", testResource), "original world should be unchanged")
+			}
+		})
+	}
+}
+
+func TestIdle_OverlappingSearches(t *testing.T) {
+	goal := goalengine.Goal{
+		Name: "fill store",
+		Logic: goalengine.GoalLogic{
+			Chunker:      testChunkerFunc,
+			Fallback:     goalengine.FallbackChunkFunc,
+			ShouldGiveUp: goalengine.GiveUpIfLessThanFive,
+		},
+		Location: &core.Location{Name: "store"},
+		Resource: testResource,
+	}
+
+	bush := &core.Location{Name: "bush", Inventory: core.NewInventory()}
+	bush.Inventory.AdjustAmount(testResource, 4)
+	world := &core.WorldState{
+		Locations: map[string]*core.Location{
+			"bush":  bush,
+			"store": {Name: "store", Inventory: core.NewInventory()},
+		},
+		Agents: map[string]core.Agent{},
+	}
+
+	newIdle := func(name string) *Idle {
+		a := &Agent{
+			name:      name,
+			inventory: core.NewInventory(),
+			Behavior: &Behavior{
+				PossibleActions: []core.Action{&mockTakeAction{from: "bush", to: "store"}},
+				GoalEngine:      goalengine.NewGoalEngine(goal),
+			},
+		}
+		world.Agents[name] = a
+		return &Idle{IterationsPerCall: 2, agent: a, logger: logging.NewLogger("error")}
+	}
+	first, second := newIdle("first"), newIdle("second")
+
+	// both searches start against the same world, and neither finishes on the first tick
+	for _, idle := range []*Idle{first, second} {
+		newWorld, err := idle.Execute(world, 0)
+		require.NoError(t, err)
+		require.Nil(t, newWorld)
+		require.NotNil(t, idle.curGoal, "search should still be running")
+	}
+
+	// the first agent finishes and reserves three of the four berries
+	newWorld, err := first.Execute(world, 0)
+	require.NoError(t, err)
+	require.NotNil(t, first.agent.Behavior.CurPlan)
+	require.NotNil(t, newWorld)
+	world = newWorld
+	newBush, _ := world.GetLocation("bush")
+	require.Equal(t, 3, newBush.ReservedBy("first", testResource))
+
+	// the second agent's search finishes against the stale world, so its plan is thrown away rather than reserved
+	newWorld, err = second.Execute(world, 0)
+	require.NoError(t, err)
+	assert.Nil(t, newWorld)
+	assert.Nil(t, second.agent.Behavior.CurPlan)
+	assert.Nil(t, second.curGoal, "the second agent should replan")
+	assert.Zero(t, second.agent.Behavior.GoalEngine.Goals[0].Failures, "a stale plan isn't a failure of the goal")
+	assert.Zero(t, newBush.ReservedBy("second", testResource))
+}
+
+func TestNewIdle_Budget(t *testing.T) {
+	a := &Agent{name: "planner", Behavior: &Behavior{Planning: PlanningBudget{IterationsPerTick: 50}}}
+
+	idle := NewIdle(a, logging.NewLogger("error"))
+	assert.Equal(t, 50, idle.budget().IterationsPerTick, "the agent's planning budget should apply")
+
+	idle.IterationsPerCall = 20
+	assert.Equal(t, 20, idle.budget().IterationsPerTick)
+}
+
+func TestIdle_RecordsPlanStats(t *testing.T) {
+	goal := goalengine.Goal{
+		Name: "testGoal",
+		Logic: goalengine.GoalLogic{
+			Chunker:      testChunkerFunc,
+			Fallback:     goalengine.FallbackChunkFunc,
+			ShouldGiveUp: goalengine.GiveUpIfLessThanFive,
+		},
+		Location: &core.Location{Name: "testLocation"},
+		Resource: testResource,
+	}
+
+	type testCase struct {
+		possibleActions []core.Action
+		iterations      int
+		numCalls        int
+		expectedStats   PlanStats
+	}
+
+	tests := map[string]testCase{
+		"plan found over several ticks": {
+			possibleActions: []core.Action{&mockAction{}},
+			iterations:      2,
+			numCalls:        2,
+			expectedStats: PlanStats{
+				Agent:      "villager",
+				Found:      true,
+				Best:       true,
+				PlanLength: 3,
+				Iterations: 4,
+				Expanded:   3,
+				ClosedSet:  3,
+				Ticks:      2,
+			},
+		},
+		"no path to goal": {
+			possibleActions: []core.Action{&mockNilAction{}},
+			iterations:      100,
+			numCalls:        1,
+			expectedStats: PlanStats{
+				Agent:      "villager",
+				Iterations: 1,
+				Expanded:   1,
+				ClosedSet:  1,
+				Ticks:      1,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := &mockPlanStatsRecorder{}
+			testAgent := &Agent{
+				name: "villager",
+				Behavior: &Behavior{
+					PossibleActions: tc.possibleActions,
+					GoalEngine:      goalengine.NewGoalEngine(goal),
+					PlanStats:       recorder,
+				},
+			}
+			world := &core.WorldState{
+				Locations: map[string]*core.Location{
+					"testLocation": {Name: "testLocation", Inventory: core.NewInventory()},
+				},
+				Agents: map[string]core.Agent{},
+			}
+			testIdle := &Idle{
+				IterationsPerCall: tc.iterations,
+				agent:             testAgent,
+				logger:            logging.NewLogger("error"),
+			}
+
+			for call := 0; call < tc.numCalls; call++ {
+				_, err := testIdle.Execute(world, 0)
+				require.NoError(t, err)
+			}
+
+			require.Len(t, recorder.plans, 1)
+			stats := recorder.plans[0]
+			require.Positive(t, stats.WallTime)
+			stats.WallTime = 0
+			require.Equal(t, tc.expectedStats, stats)
+		})
+	}
+}
@@ -2,10 +2,13 @@ package agent
 
 import (
 	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
 
 	"Neolithic/internal/astar"
 	"Neolithic/internal/core"
+	"Neolithic/internal/pathfind"
 )
 
 // Moving represents the state of an agent as it navigates along a Path toward a Target location.
@@ -16,6 +19,12 @@ type Moving struct {
 	Target *core.Coord
 	// Path is the sequence of coordinates to get to Target
 	Path Path
+	// next is the coordinate of Path the agent is moving onto, or nil if it is standing on its Position
+	next *core.Coord
+	// progress is how far the agent has moved from its Position onto next, where 1 is all the way
+	progress float64
+	// waiting is how long, in seconds, the agent has been waiting for another agent to clear next
+	waiting float64
 	// logger is the logger
 	logger *slog.Logger
 }
@@ -23,8 +32,12 @@ type Moving struct {
 const (
 	// maxPathfindingIterations represents the maximum number of iterations the planner will run in a single Execute call
 	maxPathfindingIterations = 10000
-	// targetProximityThreshold represents the distance an agent can be from the target to execute an action
-	targetProximityThreshold = 1
+	// encumbranceSlowdown is how much slower a fully encumbered agent moves: it takes 1+encumbranceSlowdown times as
+	// long to move a single coordinate
+	encumbranceSlowdown = 1
+	// blockedPatience is how long, in seconds, an agent waits for another agent to move off the coordinate it is about
+	// to move onto before it goes around
+	blockedPatience = 1.0
 )
 
 var ErrNoPathFound = errors.New("no Path found")
@@ -32,7 +45,9 @@ var ErrNoPathFound = errors.New("no Path found")
 var _ State = (*Moving)(nil)
 
 // Execute progresses the Moving state, handling path creation and movement, and updates the agent's state as needed.
-func (m *Moving) Execute(world *core.WorldState, _ float64) (*core.WorldState, error) {
+// The agent moves along its Path at its speed for deltaTime seconds, which may take it over several coordinates or
+// only part of the way to the next one.
+func (m *Moving) Execute(world *core.WorldState, deltaTime float64) (*core.WorldState, error) {
 	m.logger.Debug("moving state execute", "agent", m.agent.Name())
 
 	behavior := m.agent.Behavior
@@ -54,7 +69,7 @@ func (m *Moving) Execute(world *core.WorldState, _ float64) (*core.WorldState, e
 		m.logger.Debug("Target set", "agent", m.agent.Name(), "Target", m.Target)
 	}
 
-	if m.agent.Position.IsWithin(*m.Target, targetProximityThreshold) {
+	if m.atAccess(m.agent.Position) {
 		m.logger.Info("reached Target, transitioning to performing", "agent", m.agent.Name(), "position", m.agent.Position, "Target", m.Target)
 		behavior.CurState = &Performing{agent: m.agent, logger: m.logger}
 		return nil, nil
@@ -62,7 +77,10 @@ func (m *Moving) Execute(world *core.WorldState, _ float64) (*core.WorldState, e
 
 	if m.Path == nil {
 		m.logger.Debug("creating Path to Target", "agent", m.agent.Name(), "start", m.agent.Position, "Target", m.Target)
-		path, err := m.createPathToTarget(world)
+		path, err := m.createPath(world, m.agent.Position, nil)
+		if errors.Is(err, ErrNoPathFound) {
+			return m.fail(world, err), nil
+		}
 		if err != nil {
 			m.logger.Error("failed to create Path", "agent", m.agent.Name(), "error", err)
 			return nil, err
@@ -75,19 +93,173 @@ func (m *Moving) Execute(world *core.WorldState, _ float64) (*core.WorldState, e
 	newAgent := oldAgent.DeepCopy()
 	newState.Agents[newAgent.Name()] = newAgent
 
-	if m.Path.IsComplete() {
+	if m.next == nil && m.Path.IsComplete() {
 		m.logger.Info("Path complete, transitioning to performing", "agent", m.agent.Name())
 		newAgent.(*Agent).Behavior.CurState = &Performing{agent: newAgent.(*Agent), logger: m.logger}
 		return newState, nil
 	}
 
-	nextCoord := m.Path.NextCoord()
-	m.logger.Debug("moving to next coordinate", "agent", m.agent.Name(), "from", newAgent.(*Agent).Position, "to", nextCoord)
-	newAgent.(*Agent).Position = nextCoord
-
+	if !m.advance(world, newAgent.(*Agent), deltaTime) {
+		m.logger.Debug("still moving to next coordinate", "agent", m.agent.Name(), "progress", m.progress)
+		return nil, nil
+	}
 	return newState, nil
 }
 
+// fail abandons the plan when the Target can't be reached, as an action of the plan failing would. The goal of the plan
+// counts as failed, so that the agent tries something less ambitious or another goal, and the agent goes back to Idle
+// to replan. It returns the WorldState with the plan's reservations released, or nil if it held none.
+func (m *Moving) fail(world *core.WorldState, err error) *core.WorldState {
+	m.logger.Error("unable to reach Target, transitioning to idle", "agent", m.agent.Name(), "Target", m.Target, "error", err)
+	if m.agent.Behavior.GoalEngine != nil {
+		m.agent.Behavior.GoalEngine.GoalFailed()
+	}
+	m.agent.Behavior.CurState = &Idle{agent: m.agent, logger: m.logger}
+	return world.ReleaseReservations(m.agent.Name())
+}
+
+// advance moves the agent along the Path for deltaTime seconds, and returns whether it reached a new coordinate. The
+// time it takes to move onto a coordinate is the cost of the move, 1.4 times longer for diagonal moves and scaled by
+// the terrain, divided by the agent's speed. The agent stops early once it is on a tile it can use the Target from, and
+// doesn't start moving onto a coordinate another agent takes up; see avoid.
+func (m *Moving) advance(world *core.WorldState, agent *Agent, deltaTime float64) bool {
+	remaining := deltaTime * moveSpeed(agent)
+	moved := false
+	var occupancy map[core.Coord][]string
+	for remaining > 0 {
+		if m.next == nil {
+			if m.Path.IsComplete() || m.atAccess(agent.Position) {
+				break
+			}
+			next := m.Path.NextCoord()
+			m.next = &next
+		}
+
+		if m.progress == 0 {
+			if occupancy == nil {
+				occupancy = world.Occupancy()
+			}
+			if blocker, blocked := occupantOtherThan(occupancy, *m.next, agent.Name()); blocked {
+				m.avoid(world, occupancy, agent, blocker, deltaTime)
+				break
+			}
+			m.waiting = 0
+		}
+
+		cost := stepCost(world, agent.Position, *m.next)
+		needed := (1 - m.progress) * cost
+		if remaining < needed {
+			m.progress += remaining / cost
+			break
+		}
+		remaining -= needed
+
+		m.logger.Debug("moving to next coordinate", "agent", m.agent.Name(), "from", agent.Position, "to", *m.next)
+		agent.Position = *m.next
+		m.next, m.progress = nil, 0
+		moved = true
+	}
+	return moved
+}
+
+// avoid handles the coordinate the agent is about to move onto being taken up by blocker. The agent waits for the
+// blocker to move on, for up to blockedPatience seconds, and then goes around it. When the two agents are heading
+// onto each other's coordinates, neither can move on, so the one whose name sorts last gives way at once.
+func (m *Moving) avoid(world *core.WorldState, occupancy map[core.Coord][]string, agent *Agent, blocker string, deltaTime float64) {
+	m.waiting += deltaTime
+	headOn := isHeadOn(world, agent, blocker)
+	giveWay := headOn && blocker < agent.Name()
+	if !giveWay && m.waiting < blockedPatience {
+		m.logger.Debug("waiting for coordinate to clear", "agent", agent.Name(), "coord", *m.next, "blocker", blocker, "headOn", headOn)
+		return
+	}
+	if m.detour(world, occupancy, agent) {
+		m.logger.Debug("going around blocked coordinate", "agent", agent.Name(), "blocker", blocker, "headOn", headOn)
+		return
+	}
+	m.logger.Debug("no way around blocked coordinate, waiting", "agent", agent.Name(), "coord", *m.next, "blocker", blocker)
+	m.waiting = 0 // wait out another spell before looking for a way around again
+}
+
+// detour replaces the Path with one that goes around the coordinates other agents take up. If there is none, such as
+// in a corridor, the agent steps aside onto a free coordinate beside it, as far from the blocked coordinate as it can,
+// and carries on to the Target from there. It returns false if the agent can do neither.
+func (m *Moving) detour(world *core.WorldState, occupancy map[core.Coord][]string, agent *Agent) bool {
+	occupied := func(coord core.Coord) bool {
+		_, taken := occupantOtherThan(occupancy, coord, agent.Name())
+		return taken
+	}
+	blocked := *m.next
+
+	if path, err := m.createPath(world, agent.Position, occupied); err == nil {
+		m.Path, m.next, m.waiting = path, nil, 0
+		return true
+	}
+
+	successors, err := world.Grid.CellAt(agent.Position).GetSuccessors()
+	if err != nil {
+		return false
+	}
+	var aside *core.Coord
+	farthest := 0
+	for _, successor := range successors {
+		coord := successor.(core.Cell).Coord()
+		if occupied(coord) {
+			continue
+		}
+		dx, dy := coord.X-blocked.X, coord.Y-blocked.Y
+		if distance := dx*dx + dy*dy; distance > farthest {
+			aside, farthest = &coord, distance
+		}
+	}
+	if aside == nil {
+		return false
+	}
+	onward, err := m.createPath(world, *aside, nil)
+	if err != nil {
+		return false
+	}
+	m.Path = NewCoordPath(append([]core.Coord{agent.Position, *aside}, onward.Remaining()...))
+	m.next, m.waiting = nil, 0
+	return true
+}
+
+// isHeadOn reports whether the agent and blocker are heading onto each other's coordinates.
+func isHeadOn(world *core.WorldState, agent *Agent, blocker string) bool {
+	other, ok := world.GetAgent(blocker)
+	if !ok {
+		return false
+	}
+	otherAgent, ok := other.(*Agent)
+	if !ok {
+		return false
+	}
+	heading, moving := otherAgent.heading()
+	return moving && heading == agent.Position
+}
+
+// occupantOtherThan returns the first agent, other than the named one, taking up the coordinate, or false if there is
+// none.
+func occupantOtherThan(occupancy map[core.Coord][]string, coord core.Coord, name string) (string, bool) {
+	for _, occupant := range occupancy[coord] {
+		if occupant != name {
+			return occupant, true
+		}
+	}
+	return "", false
+}
+
+// stepCost returns the cost of moving between two adjacent coordinates, as given by the cells of the grid.
+func stepCost(world *core.WorldState, from, to core.Coord) float64 {
+	return world.Grid.CellAt(to).Cost(world.Grid.CellAt(from))
+}
+
+// moveSpeed returns how many tiles of cost 1 the agent moves in a second: its Speed when it carries nothing, and less
+// the more encumbered it is.
+func moveSpeed(agent *Agent) float64 {
+	return agent.speed() / (1 + encumbranceSlowdown*core.Encumbrance(agent))
+}
+
 // getTarget determines the target coordinate for the agent's next action and returns it, or nil if no location is needed.
 func (m *Moving) getTarget() *core.Coord {
 	nextAction := m.agent.Behavior.CurPlan.PeekAction()
@@ -99,33 +271,130 @@ func (m *Moving) getTarget() *core.Coord {
 	return &targetCoord
 }
 
-// createPathToTarget generates a path from the agent's current position to the target using the A* algorithm.
-// Returns the computed path or an error if no valid path is found or an issue occurs during pathfinding.
-func (m *Moving) createPathToTarget(world *core.WorldState) (Path, error) {
-	start := world.Grid.CellAt(m.agent.Position)
-	end := world.Grid.CellAt(*m.Target)
+// createPath generates a path from the given coordinate to the nearest free tile the target can be used from, never
+// passing through a coordinate for which avoid returns true. If no free tile can be reached, the path leads to the
+// nearest tile that can, for the agent to wait its turn. Avoid may be nil, in which case a grid that is a
+// core.FlowFinder shares a flow field to the free tiles between the agents heading to the same target. Returns the
+// computed path or an error if no valid path is found or an issue occurs during pathfinding.
+func (m *Moving) createPath(world *core.WorldState, from core.Coord, avoid func(core.Coord) bool) (Path, error) {
+	access := m.accessCoords()
+	free := m.freeCoords(world, access)
 
-	search, err := astar.NewSearch(start, end, astar.WithLogger(m.logger))
-	if err != nil {
-		return nil, err
+	find := func(goals []core.Coord) (Path, error) {
+		return m.findPath(world, from, goals, avoid)
+	}
+	if finder, ok := world.Grid.(core.FlowFinder); ok && avoid == nil && m.sharesTarget(world) {
+		find = func(goals []core.Coord) (Path, error) {
+			return followField(finder, from, goals)
+		}
 	}
 
-	if err = search.RunIterations(maxPathfindingIterations); err != nil {
-		return nil, err
+	path, err := find(free)
+	if errors.Is(err, ErrNoPathFound) && len(free) < len(access) {
+		return find(access)
 	}
+	return path, err
+}
 
-	if !search.FoundBest {
+// followField returns a path from the given coordinate that follows the flow field to the nearest of the goals. The
+// finder caches the field, so agents heading to the same goals share it.
+func followField(finder core.FlowFinder, from core.Coord, goals []core.Coord) (Path, error) {
+	field, err := finder.FlowField(goals...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoPathFound, err)
+	}
+	if _, ok := field.Next(from); !ok {
 		return nil, ErrNoPathFound
 	}
+	return NewFlowPath(field, from), nil
+}
+
+// findPath finds a path from the given coordinate to the nearest of the goals, never passing through a coordinate for
+// which avoid returns true. Avoid may be nil, in which case a grid that is a core.PathFinder finds the path itself.
+// Otherwise, the grid is searched using the A* algorithm.
+func (m *Moving) findPath(world *core.WorldState, from core.Coord, goals []core.Coord, avoid func(core.Coord) bool) (Path, error) {
+	if finder, ok := world.Grid.(core.PathFinder); ok && avoid == nil {
+		coords, err := finder.FindPath(from, goals...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNoPathFound, err)
+		}
+		return NewCoordPath(coords), nil
+	}
 
-	var coords []core.Coord
-	for _, node := range search.CurrentBestPath() {
-		nodeCoord := node.(core.Cell).Coord()
-		coords = append(coords, nodeCoord)
+	opts := []astar.Option{astar.WithLogger(m.logger)}
+	if avoid != nil {
+		opts = append(opts, astar.WithSkip(func(node astar.Node) bool {
+			return avoid(node.(core.Cell).Coord())
+		}))
+	}
+	coords, err := pathfind.Search(world.Grid, from, goals, maxPathfindingIterations, opts...)
+	if err != nil {
+		if errors.Is(err, pathfind.ErrNoPath) {
+			return nil, fmt.Errorf("%w: %w", ErrNoPathFound, err)
+		}
+		return nil, err
 	}
 	return NewCoordPath(coords), nil
 }
 
+// accessCoords returns the coordinates the agent can use the Target from, as declared by the location of the next
+// action in the plan. If the plan has moved on to another location, the Target can be used from around it.
+func (m *Moving) accessCoords() []core.Coord {
+	var loc *core.Location
+	if m.agent.Behavior.CurPlan != nil && !m.agent.Behavior.CurPlan.IsComplete() {
+		if locatable, ok := m.agent.Behavior.CurPlan.PeekAction().(core.Locatable); ok {
+			loc = locatable.Location()
+		}
+	}
+	if loc == nil || loc.Coord != *m.Target {
+		loc = &core.Location{Coord: *m.Target}
+	}
+	return loc.AccessCoords()
+}
+
+// atAccess reports whether the agent can use the Target from the coordinate.
+func (m *Moving) atAccess(coord core.Coord) bool {
+	return slices.Contains(m.accessCoords(), coord)
+}
+
+// freeCoords returns the coordinates no other agent takes up, so that agents heading to the same location spread out
+// over the tiles it can be used from, or all of them if every one is taken.
+func (m *Moving) freeCoords(world *core.WorldState, coords []core.Coord) []core.Coord {
+	occupancy := world.Occupancy()
+	free := make([]core.Coord, 0, len(coords))
+	for _, coord := range coords {
+		if _, taken := occupantOtherThan(occupancy, coord, m.agent.Name()); !taken {
+			free = append(free, coord)
+		}
+	}
+	if len(free) == 0 {
+		return coords
+	}
+	return free
+}
+
+// sharesTarget reports whether another agent is moving to the same target.
+func (m *Moving) sharesTarget(world *core.WorldState) bool {
+	for name, other := range world.Agents {
+		if name == m.agent.Name() {
+			continue
+		}
+		otherAgent, ok := other.(*Agent)
+		if !ok {
+			continue
+		}
+		if moving, ok := otherAgent.Behavior.CurState.(*Moving); ok && moving.Target != nil && *moving.Target == *m.Target {
+			return true
+		}
+	}
+	return false
+}
+
+// bindAgent implements agentBinder.
+func (m *Moving) bindAgent(agent *Agent) {
+	m.agent = agent
+}
+
 // NewMoving creates a new Moving state
 func NewMoving(agent *Agent, logger *slog.Logger) *Moving {
 	return &Moving{
@@ -3,8 +3,11 @@ package agent
 import (
 	"testing"
 
+	"Neolithic/internal/astar"
 	"Neolithic/internal/core"
+	"Neolithic/internal/goalengine"
 	"Neolithic/internal/logging"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,6 +24,13 @@ func (p *mockPath) IsComplete() bool {
 	return p.isComplete
 }
 
+func (p *mockPath) Remaining() []core.Coord {
+	if p.isComplete {
+		return nil
+	}
+	return []core.Coord{p.nextCoord}
+}
+
 func TestMoving_Execute(t *testing.T) {
 	type testCase struct {
 		agentPosition   core.Coord
@@ -29,6 +39,7 @@ func TestMoving_Execute(t *testing.T) {
 		isComplete      bool
 		plan            Plan
 		target          *core.Coord
+		deltaTime       float64
 		expectedErr     error
 		newAgentPositon core.Coord
 		expectedState   State
@@ -78,9 +89,10 @@ func TestMoving_Execute(t *testing.T) {
 					},
 				},
 			},
+			deltaTime:     1.4 / DefaultSpeed,
 			expectedState: &Moving{},
 			expectedPath: &CoordPath{
-				coords: []core.Coord{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 3}, {X: 4, Y: 4}, {X: 5, Y: 5}},
+				coords: []core.Coord{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 3}, {X: 4, Y: 4}},
 				index:  2,
 			},
 		},
@@ -96,6 +108,8 @@ func TestMoving_Execute(t *testing.T) {
 					},
 				},
 			},
+			target:          &core.Coord{X: 2, Y: 2},
+			deltaTime:       1.4 / DefaultSpeed,
 			newAgentPositon: core.Coord{X: 1, Y: 1},
 		},
 		"maintains Path when not complete": {
@@ -152,7 +166,7 @@ func TestMoving_Execute(t *testing.T) {
 				},
 			}
 
-			newState, err := testMoving.Execute(startWorld, 0)
+			newState, err := testMoving.Execute(startWorld, tc.deltaTime)
 			if expectedErr := tc.expectedErr; expectedErr != nil {
 				require.ErrorIs(t, err, expectedErr)
 			} else {
@@ -172,3 +186,541 @@ func TestMoving_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestMoving_Encumbered(t *testing.T) {
+	stone := core.NewResource("stone", core.WithResourceAttributes(&mockWeightAttribute{weight: 2}))
+	testAgent := &Agent{
+		name:      "testAgent",
+		inventory: core.NewInventory(),
+		MaxCarry:  10,
+		Behavior: &Behavior{
+			CurPlan: &MockPlan{
+				NextAction: &mockLocationAction{location: &core.Location{Coord: core.Coord{X: 3, Y: 3}}},
+			},
+		},
+	}
+	testAgent.Inventory().AdjustAmount(stone, 5)
+	testMoving := &Moving{
+		agent:  testAgent,
+		Path:   NewCoordPath([]core.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}}),
+		Target: &core.Coord{X: 3, Y: 3},
+		logger: logging.NewLogger("info"),
+	}
+	testAgent.Behavior.CurState = testMoving
+	startWorld := &core.WorldState{
+		Grid:   &mockGrid{},
+		Agents: map[string]core.Agent{testAgent.Name(): testAgent},
+	}
+
+	newState, err := testMoving.Execute(startWorld, 1/DefaultSpeed)
+	require.NoError(t, err)
+	require.Nil(t, newState, "a fully encumbered agent should take twice as long to move")
+	require.InDelta(t, 0.5, testMoving.progress, 1e-9)
+
+	newState, err = testMoving.Execute(startWorld, 1/DefaultSpeed)
+	require.NoError(t, err)
+	require.NotNil(t, newState)
+	newAgent, _ := newState.GetAgent(testAgent.Name())
+	require.Equal(t, core.Coord{X: 1, Y: 0}, newAgent.(*Agent).Position)
+	require.InDelta(t, 0.0, testMoving.progress, 1e-9)
+}
+
+func TestMoving_Speed(t *testing.T) {
+	type testCase struct {
+		speed            float64
+		path             []core.Coord
+		deltaTime        float64
+		ticks            int
+		expectedPosition core.Coord
+		expectedProgress float64
+	}
+
+	tests := map[string]testCase{
+		"moves a tile in the time its speed allows": {
+			speed:            2,
+			path:             []core.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}},
+			deltaTime:        0.5,
+			ticks:            1,
+			expectedPosition: core.Coord{X: 1, Y: 0},
+		},
+		"accumulates time over several ticks": {
+			speed:            2,
+			path:             []core.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}},
+			deltaTime:        0.1,
+			ticks:            3,
+			expectedPosition: core.Coord{X: 0, Y: 0},
+			expectedProgress: 0.6,
+		},
+		"moves several tiles in a long tick": {
+			speed:            2,
+			path:             []core.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}, {X: 4, Y: 0}, {X: 5, Y: 0}},
+			deltaTime:        1.25,
+			ticks:            1,
+			expectedPosition: core.Coord{X: 2, Y: 0},
+			expectedProgress: 0.5,
+		},
+		"takes longer to move diagonally": {
+			speed:            1,
+			path:             []core.Coord{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}},
+			deltaTime:        1,
+			ticks:            1,
+			expectedPosition: core.Coord{X: 0, Y: 0},
+			expectedProgress: 1 / 1.4,
+		},
+		"uses the default speed": {
+			path:             []core.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}},
+			deltaTime:        1 / DefaultSpeed,
+			ticks:            1,
+			expectedPosition: core.Coord{X: 1, Y: 0},
+		},
+		"stops once close enough to the target": {
+			speed:            1,
+			path:             []core.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}, {X: 4, Y: 0}},
+			deltaTime:        10,
+			ticks:            1,
+			expectedPosition: core.Coord{X: 3, Y: 0},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			target := tc.path[len(tc.path)-1]
+			testAgent := &Agent{
+				name:      "testAgent",
+				inventory: core.NewInventory(),
+				Speed:     tc.speed,
+				Behavior: &Behavior{
+					CurPlan: &MockPlan{
+						NextAction: &mockLocationAction{location: &core.Location{Coord: target}},
+					},
+				},
+			}
+			testMoving := &Moving{
+				agent:  testAgent,
+				Path:   NewCoordPath(tc.path),
+				Target: &target,
+				logger: logging.NewLogger("info"),
+			}
+			testAgent.Behavior.CurState = testMoving
+			world := &core.WorldState{
+				Grid:   &mockGrid{},
+				Agents: map[string]core.Agent{testAgent.Name(): testAgent},
+			}
+
+			for i := 0; i < tc.ticks; i++ {
+				newState, err := testMoving.Execute(world, tc.deltaTime)
+				require.NoError(t, err)
+				if newState != nil {
+					world = newState
+					newAgent, _ := world.GetAgent(testAgent.Name())
+					testMoving.bindAgent(newAgent.(*Agent))
+				}
+			}
+
+			movedAgent := testMoving.agent
+			assert.Equal(t, tc.expectedPosition, movedAgent.Position)
+			assert.InDelta(t, tc.expectedProgress, testMoving.progress, 1e-9)
+		})
+	}
+}
+
+func TestAgent_InterpolatedPosition(t *testing.T) {
+	testAgent := &Agent{name: "testAgent", Position: core.Coord{X: 2, Y: 3}, Behavior: &Behavior{}}
+	testAgent.Behavior.CurState = &Idle{agent: testAgent}
+
+	x, y := testAgent.InterpolatedPosition()
+	assert.Equal(t, 2.0, x)
+	assert.Equal(t, 3.0, y)
+
+	testAgent.Behavior.CurState = &Moving{agent: testAgent, next: &core.Coord{X: 3, Y: 2}, progress: 0.25}
+	x, y = testAgent.InterpolatedPosition()
+	assert.InDelta(t, 2.25, x, 1e-9)
+	assert.InDelta(t, 2.75, y, 1e-9)
+}
+
+// corridorGrid is a grid whose only passable coordinates are the open ones.
+type corridorGrid struct {
+	open map[core.Coord]bool
+}
+
+func newCorridorGrid(coords ...core.Coord) corridorGrid {
+	open := map[core.Coord]bool{}
+	for _, coord := range coords {
+		open[coord] = true
+	}
+	return corridorGrid{open: open}
+}
+
+func (g corridorGrid) CellAt(coord core.Coord) core.Cell {
+	return &corridorTile{coord: coord, grid: g}
+}
+
+type corridorTile struct {
+	coord core.Coord
+	grid  corridorGrid
+}
+
+func (c *corridorTile) Heuristic(goal astar.Node) (float64, error) {
+	goalCoord := goal.(*corridorTile).coord
+	return float64(max(abs(goalCoord.X-c.coord.X), abs(goalCoord.Y-c.coord.Y))), nil
+}
+
+func (c *corridorTile) ID() (string, error) {
+	return c.coord.String(), nil
+}
+
+func (c *corridorTile) Cost(prev astar.Node) float64 {
+	prevCoord := prev.(*corridorTile).coord
+	if prevCoord.X != c.coord.X && prevCoord.Y != c.coord.Y {
+		return 1.4
+	}
+	return 1
+}
+
+func (c *corridorTile) GetSuccessors() ([]astar.Node, error) {
+	var successors []astar.Node
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			coord := core.Coord{X: c.coord.X + dx, Y: c.coord.Y + dy}
+			if (dx != 0 || dy != 0) && c.grid.open[coord] {
+				successors = append(successors, &corridorTile{coord: coord, grid: c.grid})
+			}
+		}
+	}
+	return successors, nil
+}
+
+func (c *corridorTile) Coord() core.Coord {
+	return c.coord
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// newMovingAgent creates an agent at the position, moving along the path to the target.
+func newMovingAgent(name string, position core.Coord, path []core.Coord, target core.Coord) *Agent {
+	a := &Agent{
+		name:      name,
+		inventory: core.NewInventory(),
+		Position:  position,
+		Speed:     1,
+		Behavior: &Behavior{
+			CurPlan: &MockPlan{NextAction: &mockLocationAction{location: &core.Location{Coord: target}}},
+		},
+	}
+	moving := &Moving{agent: a, Target: &target, logger: logging.NewLogger("error")}
+	if path != nil {
+		moving.Path = NewCoordPath(path)
+	}
+	a.Behavior.CurState = moving
+	return a
+}
+
+// tickMoving runs every moving agent of the world once, in order of name, as the engine would.
+func tickMoving(t *testing.T, world *core.WorldState, deltaTime float64) *core.WorldState {
+	t.Helper()
+	for _, name := range world.AgentNames() {
+		a := world.Agents[name].(*Agent)
+		moving, ok := a.Behavior.CurState.(*Moving)
+		if !ok {
+			continue
+		}
+		moving.bindAgent(a)
+		newState, err := moving.Execute(world, deltaTime)
+		require.NoError(t, err)
+		if newState != nil {
+			world = newState
+		}
+	}
+	return world
+}
+
+func TestMoving_Avoidance(t *testing.T) {
+	type testCase struct {
+		grid             core.Grid
+		agents           []*Agent
+		ticks            int
+		expectedPosition map[string]core.Coord
+		expectedWaiting  map[string]bool
+	}
+
+	straight := []core.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}, {X: 4, Y: 0}}
+	corridor := newCorridorGrid(straight...)
+
+	tests := map[string]testCase{
+		"waits for a blocked coordinate to clear": {
+			grid: &mockGrid{},
+			agents: []*Agent{
+				newMovingAgent("mover", core.Coord{X: 0, Y: 0}, straight, core.Coord{X: 4, Y: 0}),
+				newMovingAgent("stander", core.Coord{X: 1, Y: 0}, nil, core.Coord{X: 1, Y: 0}),
+			},
+			ticks:            3,
+			expectedPosition: map[string]core.Coord{"mover": {X: 0, Y: 0}},
+			expectedWaiting:  map[string]bool{"mover": true},
+		},
+		"goes around a coordinate that stays blocked": {
+			grid: &mockGrid{},
+			agents: []*Agent{
+				newMovingAgent("mover", core.Coord{X: 0, Y: 0}, straight, core.Coord{X: 4, Y: 0}),
+				newMovingAgent("stander", core.Coord{X: 1, Y: 0}, nil, core.Coord{X: 1, Y: 0}),
+			},
+			ticks:            12,
+			expectedPosition: map[string]core.Coord{"mover": {X: 1, Y: -1}},
+		},
+		"gives way at once when heading onto each other": {
+			grid: &mockGrid{},
+			agents: []*Agent{
+				newMovingAgent("a", core.Coord{X: 1, Y: 0}, straight[1:], core.Coord{X: 4, Y: 0}),
+				newMovingAgent("b", core.Coord{X: 2, Y: 0}, []core.Coord{{X: 2, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 0}, {X: -1, Y: 0}}, core.Coord{X: -1, Y: 0}),
+			},
+			ticks:            3,
+			expectedPosition: map[string]core.Coord{"a": {X: 1, Y: 0}},
+			expectedWaiting:  map[string]bool{"a": true, "b": false},
+		},
+		"steps back out of a corridor": {
+			grid: corridor,
+			agents: []*Agent{
+				newMovingAgent("a", core.Coord{X: 1, Y: 0}, straight[1:], core.Coord{X: 4, Y: 0}),
+				newMovingAgent("b", core.Coord{X: 2, Y: 0}, []core.Coord{{X: 2, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 0}}, core.Coord{X: 0, Y: 0}),
+			},
+			ticks:            5,
+			expectedPosition: map[string]core.Coord{"b": {X: 3, Y: 0}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			world := &core.WorldState{Grid: tc.grid, Agents: map[string]core.Agent{}}
+			for _, a := range tc.agents {
+				world.Agents[a.Name()] = a
+			}
+			for i := 0; i < tc.ticks; i++ {
+				world = tickMoving(t, world, 0.25)
+				for coord, occupants := range world.Occupancy() {
+					require.Len(t, occupants, 1, "agents share %v", coord)
+				}
+			}
+
+			for agentName, expected := range tc.expectedPosition {
+				a, _ := world.GetAgent(agentName)
+				assert.Equal(t, expected, a.(*Agent).Position, agentName)
+			}
+			for agentName, expected := range tc.expectedWaiting {
+				a, _ := world.GetAgent(agentName)
+				moving := a.(*Agent).Behavior.CurState.(*Moving)
+				assert.Equal(t, expected, moving.waiting > 0, agentName)
+			}
+		})
+	}
+}
+
+func TestMoving_UnreachableTarget(t *testing.T) {
+	// the target stands on an island, cut off from the agent by a ring of water two tiles out
+	target := core.Coord{X: 7, Y: 3}
+	var open []core.Coord
+	for x := 0; x < 11; x++ {
+		for y := 0; y < 7; y++ {
+			coord := core.Coord{X: x, Y: y}
+			dx, dy := abs(coord.X-target.X), abs(coord.Y-target.Y)
+			if max(dx, dy) != 2 {
+				open = append(open, coord)
+			}
+		}
+	}
+
+	goal := goalengine.Goal{
+		Name: "fill store",
+		Logic: goalengine.GoalLogic{
+			Chunker:      testChunkerFunc,
+			Fallback:     goalengine.FallbackChunkFunc,
+			ShouldGiveUp: goalengine.GiveUpIfLessThanFive,
+		},
+		Location: &core.Location{Name: "store"},
+		Resource: testResource,
+	}
+	store := &core.Location{Name: "store", Coord: target, Inventory: core.NewInventory()}
+	store.Inventory.AdjustAmount(testResource, 5)
+	store.Reserve("mover", testResource, 2)
+
+	mover := newMovingAgent("mover", core.Coord{X: 0, Y: 3}, nil, target)
+	mover.Behavior.GoalEngine = goalengine.NewGoalEngine(goal)
+	world := &core.WorldState{
+		Grid:      newCorridorGrid(open...),
+		Locations: map[string]*core.Location{"store": store},
+		Agents:    map[string]core.Agent{mover.Name(): mover},
+	}
+	require.NotNil(t, mover.Behavior.GoalEngine.GetNextGoal(world))
+	mover.Behavior.GoalEngine.GoalPlanned()
+
+	newWorld, err := mover.Behavior.CurState.Execute(world, 0.25)
+	require.NoError(t, err, "an unreachable target should fail the plan, not the simulation")
+
+	assert.IsType(t, &Idle{}, mover.Behavior.CurState, "the agent should replan")
+	assert.Equal(t, 1, mover.Behavior.GoalEngine.Goals[0].Failures, "the goal should count a failure")
+	require.NotNil(t, newWorld)
+	newStore, _ := newWorld.GetLocation("store")
+	assert.Empty(t, newStore.Claimants(), "the plan's reservations should be released")
+}
+
+func TestMoving_PassingInCorridor(t *testing.T) {
+	// a corridor with an alcove in the middle, where one agent can wait for the other to pass
+	grid := newCorridorGrid(
+		core.Coord{X: 0, Y: 0}, core.Coord{X: 1, Y: 0}, core.Coord{X: 2, Y: 0}, core.Coord{X: 3, Y: 0},
+		core.Coord{X: 4, Y: 0}, core.Coord{X: 5, Y: 0}, core.Coord{X: 6, Y: 0}, core.Coord{X: 7, Y: 0},
+		core.Coord{X: 8, Y: 0}, core.Coord{X: 4, Y: 1},
+	)
+	east := newMovingAgent("east", core.Coord{X: 0, Y: 0}, nil, core.Coord{X: 8, Y: 0})
+	west := newMovingAgent("west", core.Coord{X: 8, Y: 0}, nil, core.Coord{X: 0, Y: 0})
+	world := &core.WorldState{Grid: grid, Agents: map[string]core.Agent{"east": east, "west": west}}
+
+	for i := 0; i < 200; i++ {
+		world = tickMoving(t, world, 0.25)
+		for coord, occupants := range world.Occupancy() {
+			require.Len(t, occupants, 1, "agents share %v", coord)
+		}
+	}
+
+	eastAgent, _ := world.GetAgent("east")
+	westAgent, _ := world.GetAgent("west")
+	assert.IsType(t, &Performing{}, eastAgent.(*Agent).Behavior.CurState, "east should have got past")
+	assert.IsType(t, &Performing{}, westAgent.(*Agent).Behavior.CurState, "west should have got past")
+	assert.True(t, eastAgent.(*Agent).Position.IsWithin(core.Coord{X: 8, Y: 0}, core.DefaultAccessRadius))
+	assert.True(t, westAgent.(*Agent).Position.IsWithin(core.Coord{X: 0, Y: 0}, core.DefaultAccessRadius))
+}
+
+func TestAgent_OccupiedCoords(t *testing.T) {
+	a := newMovingAgent("a", core.Coord{X: 1, Y: 1}, nil, core.Coord{X: 5, Y: 5})
+	assert.Equal(t, []core.Coord{{X: 1, Y: 1}}, a.OccupiedCoords())
+
+	moving := a.Behavior.CurState.(*Moving)
+	moving.next = &core.Coord{X: 2, Y: 2}
+	assert.Equal(t, []core.Coord{{X: 1, Y: 1}}, a.OccupiedCoords(), "waiting to move onto a coordinate doesn't take it up")
+
+	moving.progress = 0.1
+	assert.Equal(t, []core.Coord{{X: 1, Y: 1}, {X: 2, Y: 2}}, a.OccupiedCoords())
+}
+
+// flowGrid is a mockGrid that builds flow fields.
+type flowGrid struct {
+	mockGrid
+}
+
+func (g *flowGrid) FlowField(targets ...core.Coord) (core.FlowField, error) {
+	return &mockFlowField{target: targets[0]}, nil
+}
+
+func TestMoving_SharedTarget(t *testing.T) {
+	type testCase struct {
+		grid         core.Grid
+		others       []*Agent
+		expectedPath Path
+	}
+
+	target := core.Coord{X: 4, Y: 0}
+
+	tests := map[string]testCase{
+		"searches alone": {
+			grid:         &flowGrid{},
+			expectedPath: &CoordPath{},
+		},
+		"follows a flow field to a shared target": {
+			grid:         &flowGrid{},
+			others:       []*Agent{newMovingAgent("other", core.Coord{X: 0, Y: 2}, nil, target)},
+			expectedPath: &FlowPath{},
+		},
+		"searches when others head elsewhere": {
+			grid:         &flowGrid{},
+			others:       []*Agent{newMovingAgent("other", core.Coord{X: 0, Y: 2}, nil, core.Coord{X: 0, Y: 4})},
+			expectedPath: &CoordPath{},
+		},
+		"searches when the grid has no flow fields": {
+			grid:         &mockGrid{},
+			others:       []*Agent{newMovingAgent("other", core.Coord{X: 0, Y: 2}, nil, target)},
+			expectedPath: &CoordPath{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mover := newMovingAgent("mover", core.Coord{X: 0, Y: 0}, nil, target)
+			// used only from the west, so that the field and the search lead the same way
+			mover.Behavior.CurPlan.(*MockPlan).NextAction.(*mockLocationAction).location.AccessTiles = []core.Coord{{X: -1, Y: 0}}
+			world := &core.WorldState{Grid: tc.grid, Agents: map[string]core.Agent{mover.Name(): mover}}
+			for _, other := range tc.others {
+				world.Agents[other.Name()] = other
+			}
+
+			moving := mover.Behavior.CurState.(*Moving)
+			_, err := moving.Execute(world, 0)
+			require.NoError(t, err)
+
+			assert.IsType(t, tc.expectedPath, moving.Path)
+			assert.Equal(t, []core.Coord{{X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}}, moving.Path.Remaining())
+		})
+	}
+}
+
+func TestMoving_SharedTargetFreeTiles(t *testing.T) {
+	target := core.Coord{X: 4, Y: 0}
+	mover := newMovingAgent("mover", core.Coord{X: 0, Y: 0}, nil, target)
+	mover.Behavior.CurPlan.(*MockPlan).NextAction.(*mockLocationAction).location.AccessTiles = []core.Coord{{X: -1, Y: 0}, {X: -2, Y: 0}}
+	// the other agent heads for the same target, and already stands on its nearest access tile
+	stander := newMovingAgent("stander", core.Coord{X: 3, Y: 0}, nil, target)
+	world := &core.WorldState{Grid: &flowGrid{}, Agents: map[string]core.Agent{mover.Name(): mover, stander.Name(): stander}}
+
+	moving := mover.Behavior.CurState.(*Moving)
+	_, err := moving.Execute(world, 0)
+	require.NoError(t, err)
+
+	assert.IsType(t, &FlowPath{}, moving.Path)
+	assert.Equal(t, []core.Coord{{X: 1, Y: 0}, {X: 2, Y: 0}}, moving.Path.Remaining(), "the field should lead to the free access tile")
+}
+
+func TestMoving_AccessTiles(t *testing.T) {
+	type testCase struct {
+		others      []*Agent
+		expectedEnd core.Coord
+	}
+
+	target := core.Coord{X: 4, Y: 0}
+
+	tests := map[string]testCase{
+		"heads for the nearest access tile": {
+			expectedEnd: core.Coord{X: 3, Y: 0},
+		},
+		"heads for a free access tile": {
+			others:      []*Agent{newMovingAgent("stander", core.Coord{X: 3, Y: 0}, nil, core.Coord{X: 3, Y: 0})},
+			expectedEnd: core.Coord{X: 4, Y: 1},
+		},
+		"heads for the nearest access tile when none is free": {
+			others: []*Agent{
+				newMovingAgent("stander", core.Coord{X: 3, Y: 0}, nil, core.Coord{X: 3, Y: 0}),
+				newMovingAgent("sitter", core.Coord{X: 4, Y: 1}, nil, core.Coord{X: 4, Y: 1}),
+			},
+			expectedEnd: core.Coord{X: 3, Y: 0},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mover := newMovingAgent("mover", core.Coord{X: 0, Y: 0}, nil, target)
+			mover.Behavior.CurPlan.(*MockPlan).NextAction.(*mockLocationAction).location.AccessTiles = []core.Coord{{X: -1, Y: 0}, {X: 0, Y: 1}}
+			world := &core.WorldState{Grid: &mockGrid{}, Agents: map[string]core.Agent{mover.Name(): mover}}
+			for _, other := range tc.others {
+				world.Agents[other.Name()] = other
+			}
+
+			moving := mover.Behavior.CurState.(*Moving)
+			_, err := moving.Execute(world, 0)
+			require.NoError(t, err)
+
+			remaining := moving.Path.Remaining()
+			require.NotEmpty(t, remaining)
+			assert.Equal(t, tc.expectedEnd, remaining[len(remaining)-1])
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package agent
+
+import (
+	"math"
+
+	"Neolithic/internal/core"
+	"Neolithic/internal/goalengine"
+)
+
+// Need is something the Agent must keep up, such as hunger, fatigue or warmth. Its Level decays over time and is
+// restored either by consuming a Resource from the Agent's inventory or by resting. While the Level is below the
+// Threshold, the need drives the Agent's goals: a need satisfied by a Resource adds a goal to the Agent's GoalEngine
+// to fetch some, with a priority that rises with the urgency of the need, and a need satisfied by resting keeps the
+// Agent from starting a new plan until it has rested.
+type Need struct {
+	// Name is the name of the need. It is also the name of the goal the need adds to the GoalEngine.
+	Name string
+	// Level is how well the need is met, from 0 (critical) to 1 (fully satisfied)
+	Level float64
+	// DecayPerSecond is how much the Level drops every second
+	DecayPerSecond float64
+	// Threshold is the Level below which the Agent acts to satisfy the need
+	Threshold float64
+	// Weight scales the priority of the goal the need adds to the GoalEngine
+	Weight float64
+	// Resource, if set, is consumed from the Agent's inventory to satisfy the need, using the Agent's Consuming action
+	// for it if there is one
+	Resource *core.Resource
+	// RestorePerUnit is how much the Level rises for every unit of Resource consumed. If it is 0, the nutrition of the
+	// Resource is used instead.
+	RestorePerUnit float64
+	// RestPerSecond, if set, is how much the Level rises every second the Agent rests. The Agent rests while it is Idle
+	// and the need has dropped below its Threshold, until the need is fully satisfied.
+	RestPerSecond float64
+	// Resting indicates that the Agent is resting to satisfy the need
+	Resting bool
+}
+
+// Urgency returns how pressing the need is: its Weight scaled by how far the Level is from fully satisfied.
+func (n *Need) Urgency() float64 {
+	return n.Weight * (1 - n.Level)
+}
+
+// Unmet reports whether the Level of the need is below its Threshold.
+func (n *Need) Unmet() bool {
+	return n.Level < n.Threshold
+}
+
+// decay lowers the Level of the need for deltaTime seconds, or raises it if the Agent is resting.
+func (n *Need) decay(deltaTime float64) {
+	if n.Resting {
+		n.adjust(n.RestPerSecond * deltaTime)
+		if n.Level >= 1 {
+			n.Resting = false
+		}
+		return
+	}
+	n.adjust(-n.DecayPerSecond * deltaTime)
+}
+
+// restorePerUnit returns how much the Level rises for every unit of the Resource consumed: RestorePerUnit if it is set,
+// or else the nutrition of the Resource.
+func (n *Need) restorePerUnit() float64 {
+	if n.RestorePerUnit != 0 || n.Resource == nil {
+		return n.RestorePerUnit
+	}
+	return n.Resource.UnitNutrition()
+}
+
+// adjust changes the Level of the need by amount, keeping it between 0 and 1.
+func (n *Need) adjust(amount float64) {
+	n.Level = math.Min(1, math.Max(0, n.Level+amount))
+}
+
+// tickNeeds updates the Agent's needs for deltaTime seconds. An unmet need is satisfied from the Agent's inventory if
+// it can be; consuming changes the world, so the new WorldState is returned. Otherwise, it returns nil. The goals of
+// the needs are brought up to date with the world as it is after the tick.
+func (a *Agent) tickNeeds(world *core.WorldState, deltaTime float64) *core.WorldState {
+	if len(a.Behavior.Needs) == 0 {
+		return nil
+	}
+	if a.Behavior.GoalEngine == nil {
+		a.Behavior.GoalEngine = goalengine.NewGoalEngine()
+	}
+	_, idle := a.Behavior.CurState.(*Idle)
+
+	state := world
+	current := a
+	for _, need := range a.Behavior.Needs {
+		if need.RestPerSecond > 0 && need.Unmet() && idle {
+			need.Resting = true
+		}
+		need.decay(deltaTime)
+
+		if need.Resource == nil || !need.Unmet() {
+			continue
+		}
+		consumed, amount := current.consume(state, need.Resource)
+		if consumed == nil {
+			continue
+		}
+		state = consumed
+		current = consumed.Agents[a.Name()].(*Agent)
+		need.adjust(need.restorePerUnit() * float64(amount))
+	}
+
+	a.syncNeedGoals(current)
+	if state == world {
+		return nil
+	}
+	return state
+}
+
+// consume uses up some of the resource the Agent carries. It performs the Agent's Consuming action for the resource if
+// it has one; a resource without one is used up a unit at a time. It returns the new WorldState and the amount used up,
+// or nil if the Agent has none of the resource to consume.
+func (a *Agent) consume(world *core.WorldState, res *core.Resource) (*core.WorldState, int) {
+	for _, action := range a.Behavior.PossibleActions {
+		consuming, ok := action.(core.Consuming)
+		if !ok || consuming.Resource() != res {
+			continue
+		}
+		end := action.Perform(world, a)
+		if end == nil {
+			return nil, 0
+		}
+		return end, consuming.ConsumedAmount()
+	}
+
+	if a.inventory.GetAmount(res) <= 0 {
+		return nil, 0
+	}
+	end := world.ShallowCopy()
+	endAgent := a.DeepCopy().(*Agent)
+	endAgent.inventory.AdjustAmount(res, -1)
+	end.Agents[endAgent.Name()] = endAgent
+	return end, 1
+}
+
+// resting reports whether the Agent is resting to satisfy one of its needs.
+func (a *Agent) resting() bool {
+	for _, need := range a.Behavior.Needs {
+		if need.Resting {
+			return true
+		}
+	}
+	return false
+}
+
+// syncNeedGoals keeps a goal in the Agent's GoalEngine for every need that is satisfied by a Resource. The goal of an
+// unmet need asks for the Agent to carry a unit of the Resource, with the urgency of the need as its priority; it is
+// retired once the need is met again. current is the Agent as of the end of the tick.
+func (a *Agent) syncNeedGoals(current *Agent) {
+	for _, need := range a.Behavior.Needs {
+		if need.Resource == nil {
+			continue
+		}
+		goal := a.needGoal(need)
+		if goal == nil {
+			if !need.Unmet() {
+				continue
+			}
+			a.Behavior.GoalEngine.Goals = append(a.Behavior.GoalEngine.Goals, newNeedGoal(a.Name(), need))
+			goal = a.Behavior.GoalEngine.Goals[len(a.Behavior.GoalEngine.Goals)-1]
+		}
+
+		goal.Priority = need.Urgency()
+		goal.Target = current.inventory.GetAmount(need.Resource) + 1
+		if goal.Retired && need.Unmet() {
+			goal.Failures = 0
+		}
+		goal.Retired = !need.Unmet()
+	}
+}
+
+// needGoal returns the goal of the need in the Agent's GoalEngine, or nil if there is none.
+func (a *Agent) needGoal(need *Need) *goalengine.Goal {
+	if a.Behavior.GoalEngine == nil {
+		return nil
+	}
+	for _, goal := range a.Behavior.GoalEngine.Goals {
+		if goal.Agent == a.Name() && goal.Name == need.Name {
+			return goal
+		}
+	}
+	return nil
+}
+
+// newNeedGoal creates the goal of a need of the named agent.
+func newNeedGoal(agentName string, need *Need) *goalengine.Goal {
+	return &goalengine.Goal{
+		Name:     need.Name,
+		Agent:    agentName,
+		Resource: need.Resource,
+		Logic: goalengine.GoalLogic{
+			ID:           need.Name,
+			Chunker:      goalengine.AddOne,
+			Fallback:     goalengine.FallbackChunkFunc,
+			ShouldGiveUp: goalengine.GiveUpIfNoChange,
+		},
+	}
+}
@@ -0,0 +1,203 @@
+package agent
+
+import (
+	"testing"
+
+	"Neolithic/internal/core"
+	"Neolithic/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_TickNeeds(t *testing.T) {
+	berries := core.NewResource("berries", core.WithResourceAttributes(&mockNutritionAttribute{nutrition: 0.25}))
+
+	type testCase struct {
+		need              Need
+		actions           []core.Action
+		carried           int
+		moving            bool
+		expectedLevel     float64
+		expectedCarried   int
+		expectConsumed    bool
+		expectedResting   bool
+		expectGoal        bool
+		expectGoalRetired bool
+	}
+
+	tests := map[string]testCase{
+		"decays": {
+			need:          Need{Name: "hunger", Level: 0.8, DecayPerSecond: 0.1, Threshold: 0.5, Resource: testResource},
+			expectedLevel: 0.7,
+		},
+		"does not decay below zero": {
+			need:          Need{Name: "hunger", Level: 0.05, DecayPerSecond: 0.1, Threshold: 0.5, Resource: testResource},
+			expectedLevel: 0,
+			expectGoal:    true,
+		},
+		"unmet need adds goal": {
+			need:          Need{Name: "hunger", Level: 0.3, Threshold: 0.5, Weight: 2, Resource: testResource},
+			expectedLevel: 0.3,
+			expectGoal:    true,
+		},
+		"unmet need consumes carried resource": {
+			need:            Need{Name: "hunger", Level: 0.3, Threshold: 0.5, Resource: testResource, RestorePerUnit: 0.4},
+			carried:         2,
+			expectedLevel:   0.7,
+			expectedCarried: 1,
+			expectConsumed:  true,
+		},
+		"unmet need restores by the nutrition of the resource": {
+			need:            Need{Name: "hunger", Level: 0.3, Threshold: 0.5, Resource: berries},
+			carried:         2,
+			expectedLevel:   0.55,
+			expectedCarried: 1,
+			expectConsumed:  true,
+		},
+		"restore per unit overrides the nutrition of the resource": {
+			need:            Need{Name: "hunger", Level: 0.3, Threshold: 0.5, Resource: berries, RestorePerUnit: 0.4},
+			carried:         2,
+			expectedLevel:   0.7,
+			expectedCarried: 1,
+			expectConsumed:  true,
+		},
+		"decaying below threshold adds goal": {
+			need:          Need{Name: "hunger", Level: 0.45, DecayPerSecond: 0.1, Threshold: 0.4, Resource: testResource},
+			expectedLevel: 0.35,
+			expectGoal:    true,
+		},
+		"unmet need consumes with consuming action": {
+			need:            Need{Name: "hunger", Level: 0.1, Threshold: 0.5, Resource: testResource, RestorePerUnit: 0.2},
+			actions:         []core.Action{&mockAction{}, &mockConsumeAction{amount: 2}},
+			carried:         3,
+			expectedLevel:   0.5,
+			expectedCarried: 1,
+			expectConsumed:  true,
+		},
+		"unmet need can't consume less than consuming action needs": {
+			need:          Need{Name: "hunger", Level: 0.1, Threshold: 0.5, Resource: testResource, RestorePerUnit: 0.2},
+			actions:       []core.Action{&mockConsumeAction{amount: 2}},
+			carried:       1,
+			expectedLevel: 0.1,
+			expectGoal:    true,
+		},
+		"unmet need rests while idle": {
+			need:            Need{Name: "fatigue", Level: 0.1, Threshold: 0.2, RestPerSecond: 0.5},
+			expectedLevel:   0.6,
+			expectedResting: true,
+		},
+		"unmet need waits for plan to finish before resting": {
+			need:          Need{Name: "fatigue", Level: 0.1, DecayPerSecond: 0.1, Threshold: 0.2, RestPerSecond: 0.5},
+			moving:        true,
+			expectedLevel: 0,
+		},
+		"stops resting once fully rested": {
+			need:          Need{Name: "fatigue", Level: 0.8, Threshold: 0.2, RestPerSecond: 0.5, Resting: true},
+			expectedLevel: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := NewAgent("test", logging.NewLogger("error"))
+			if tc.moving {
+				a.Behavior.CurState = &Moving{agent: a}
+			}
+			a.Behavior.PossibleActions = tc.actions
+			need := tc.need
+			res := testResource
+			if need.Resource != nil {
+				res = need.Resource
+			}
+			a.Inventory().AdjustAmount(res, tc.carried)
+			a.Behavior.Needs = []*Need{&need}
+			world := &core.WorldState{
+				Locations: map[string]*core.Location{},
+				Agents:    map[string]core.Agent{a.Name(): a},
+			}
+
+			consumed := a.tickNeeds(world, 1)
+
+			assert.InDelta(t, tc.expectedLevel, need.Level, 1e-9)
+			assert.Equal(t, tc.expectedResting, need.Resting)
+			assert.Equal(t, tc.expectedResting, a.resting())
+			if tc.expectConsumed {
+				require.NotNil(t, consumed)
+				consumedAgent, _ := consumed.GetAgent(a.Name())
+				assert.Equal(t, tc.expectedCarried, consumedAgent.Inventory().GetAmount(res))
+				assert.Equal(t, tc.carried, a.Inventory().GetAmount(res), "original agent should be unchanged")
+			} else {
+				assert.Nil(t, consumed)
+			}
+
+			goal := a.needGoal(&need)
+			if !tc.expectGoal {
+				assert.Nil(t, goal)
+				return
+			}
+			require.NotNil(t, goal)
+			assert.Equal(t, a.Name(), goal.Agent)
+			assert.Equal(t, testResource, goal.Resource)
+			assert.Equal(t, tc.expectGoalRetired, goal.Retired)
+			assert.InDelta(t, need.Urgency(), goal.Priority, 1e-9)
+		})
+	}
+}
+
+func TestAgent_TickRests(t *testing.T) {
+	a := NewAgent("test", logging.NewLogger("error"))
+	fatigue := &Need{Name: "fatigue", Level: 0.1, Threshold: 0.2, RestPerSecond: 0.25}
+	hunger := &Need{Name: "hunger", Level: 0.1, Threshold: 0.2, Resource: testResource}
+	a.Behavior.Needs = []*Need{fatigue, hunger}
+	counter := &mockPlanStatsRecorder{}
+	a.Behavior.PlanStats = counter
+	world := &core.WorldState{
+		Locations: map[string]*core.Location{},
+		Agents:    map[string]core.Agent{a.Name(): a},
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := a.Tick(world, 1)
+		require.NoError(t, err)
+		assert.True(t, a.resting())
+		assert.Empty(t, counter.plans, "agent should not plan while resting")
+	}
+
+	_, err := a.Tick(world, 1)
+	require.NoError(t, err)
+	assert.False(t, a.resting())
+	assert.Equal(t, 1.0, fatigue.Level)
+	assert.Len(t, counter.plans, 1, "agent should plan for its hunger once rested")
+}
+
+func TestAgent_NeedGoalLifecycle(t *testing.T) {
+	a := NewAgent("test", logging.NewLogger("error"))
+	hunger := &Need{Name: "hunger", Level: 0.3, Threshold: 0.5, Weight: 2, Resource: testResource, RestorePerUnit: 0.5}
+	a.Behavior.Needs = []*Need{hunger}
+	world := &core.WorldState{
+		Locations: map[string]*core.Location{},
+		Agents:    map[string]core.Agent{a.Name(): a},
+	}
+
+	assert.Nil(t, a.tickNeeds(world, 1))
+	goal := a.needGoal(hunger)
+	require.NotNil(t, goal)
+	assert.False(t, goal.Retired)
+	assert.Equal(t, 1, goal.Target, "goal should be to carry one more unit")
+	assert.InDelta(t, 1.4, goal.Priority, 1e-9)
+	goal.Failures = 2
+
+	a.Inventory().AdjustAmount(testResource, 1)
+	consumed := a.tickNeeds(world, 1)
+	require.NotNil(t, consumed)
+	assert.True(t, goal.Retired, "goal should be retired once the need is met")
+
+	consumedAgent, _ := consumed.GetAgent(a.Name())
+	a = consumedAgent.(*Agent)
+	hunger.Level = 0.1
+	assert.Nil(t, a.tickNeeds(consumed, 1))
+	assert.Same(t, goal, a.needGoal(hunger), "goal should be reused")
+	assert.False(t, goal.Retired)
+	assert.Zero(t, goal.Failures, "failures should be reset when the goal is pursued again")
+	assert.Len(t, a.Behavior.GoalEngine.Goals, 1)
+}
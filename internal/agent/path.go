@@ -8,6 +8,8 @@ type Path interface {
 	NextCoord() core.Coord
 	// IsComplete returns true if the Path is complete
 	IsComplete() bool
+	// Remaining returns the coordinates left to move onto, in order
+	Remaining() []core.Coord
 }
 
 // CoordPath implements Path
@@ -40,3 +42,55 @@ func (p *CoordPath) NextCoord() core.Coord {
 func (p *CoordPath) IsComplete() bool {
 	return p.index >= len(p.coords)
 }
+
+// Remaining returns the coordinates left in the Path
+func (p *CoordPath) Remaining() []core.Coord {
+	remaining := make([]core.Coord, len(p.coords)-p.index)
+	copy(remaining, p.coords[p.index:])
+	return remaining
+}
+
+// FlowPath implements Path by following a core.FlowField to its target, so that agents heading to the same place share
+// a single search.
+type FlowPath struct {
+	// field is the flow field followed
+	field core.FlowField
+	// current is the coordinate the Path has been followed to
+	current core.Coord
+}
+
+// NewFlowPath creates a new FlowPath that follows the field from the given coordinate, which the agent is on
+func NewFlowPath(field core.FlowField, from core.Coord) *FlowPath {
+	return &FlowPath{
+		field:   field,
+		current: from,
+	}
+}
+
+// NextCoord returns the next coordinate in the Path
+func (p *FlowPath) NextCoord() core.Coord {
+	if p.IsComplete() {
+		panic("attempting to get next coordinate from completed Path")
+	}
+	p.current, _ = p.field.Next(p.current)
+	return p.current
+}
+
+// IsComplete returns true if the Path has reached the target of the field, or can't reach it
+func (p *FlowPath) IsComplete() bool {
+	next, ok := p.field.Next(p.current)
+	return !ok || next == p.current
+}
+
+// Remaining returns the coordinates the field leads through from where the Path has been followed to
+func (p *FlowPath) Remaining() []core.Coord {
+	remaining := []core.Coord{}
+	for coord := p.current; ; {
+		next, ok := p.field.Next(coord)
+		if !ok || next == coord {
+			return remaining
+		}
+		remaining = append(remaining, next)
+		coord = next
+	}
+}
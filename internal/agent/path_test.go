@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"Neolithic/internal/core"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNewCoordPath(t *testing.T) {
@@ -166,3 +167,44 @@ func TestCoordPath_IsComplete(t *testing.T) {
 		})
 	}
 }
+
+// mockFlowField leads along the x axis to its target, and can't be reached from negative x.
+type mockFlowField struct {
+	target core.Coord
+}
+
+func (f *mockFlowField) Next(from core.Coord) (core.Coord, bool) {
+	switch {
+	case from.X < 0:
+		return core.Coord{}, false
+	case from.X < f.target.X:
+		return core.Coord{X: from.X + 1, Y: from.Y}, true
+	case from.X > f.target.X:
+		return core.Coord{X: from.X - 1, Y: from.Y}, true
+	default:
+		return from, true
+	}
+}
+
+func TestFlowPath(t *testing.T) {
+	field := &mockFlowField{target: core.Coord{X: 3, Y: 0}}
+
+	t.Run("follows the field to the target", func(t *testing.T) {
+		path := NewFlowPath(field, core.Coord{X: 0, Y: 0})
+		assert.Equal(t, []core.Coord{{X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}}, path.Remaining())
+
+		var followed []core.Coord
+		for !path.IsComplete() {
+			followed = append(followed, path.NextCoord())
+		}
+		assert.Equal(t, []core.Coord{{X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}}, followed)
+		assert.Empty(t, path.Remaining())
+		assert.Panics(t, func() { path.NextCoord() })
+	})
+
+	t.Run("complete when the target can't be reached", func(t *testing.T) {
+		path := NewFlowPath(field, core.Coord{X: -1, Y: 0})
+		assert.True(t, path.IsComplete())
+		assert.Empty(t, path.Remaining())
+	})
+}
@@ -35,7 +35,7 @@ func (p *Performing) Execute(world *core.WorldState, deltaTime float64) (*core.W
 		if p.action == nil { // still nil, plan complete
 			p.logger.Info("plan complete, transitioning to idle", "agent", p.agent.Name())
 			behavior.CurState = &Idle{agent: p.agent, logger: p.logger}
-			return (*core.WorldState)(nil), nil
+			return world.ReleaseReservations(p.agent.Name()), nil
 		}
 
 		p.logger.Debug("starting new action", "agent", p.agent.Name(), "action", p.action)
@@ -56,9 +56,25 @@ func (p *Performing) Execute(world *core.WorldState, deltaTime float64) (*core.W
 	p.logger.Info("performing action", "agent", p.agent.Name(), "action", p.action)
 	newWorldState := p.action.Perform(world, p.agent)
 	if newWorldState == nil { // action failed
-		p.logger.Error("action failed", "agent", p.agent.Name(), "action", p.action)
+		if explaining, ok := p.action.(core.Explaining); ok {
+			p.logger.Error("action failed", "agent", p.agent.Name(), "action", p.action,
+				"reason", explaining.FailureReason(world, p.agent))
+		} else {
+			p.logger.Error("action failed", "agent", p.agent.Name(), "action", p.action)
+		}
+		repairedWorld, repaired, err := repairPlan(world, p.agent, p.logger)
+		if err != nil {
+			p.logger.Error("plan repair error", "agent", p.agent.Name(), "error", err)
+			return nil, err
+		}
+		if repaired {
+			p.logger.Info("plan repaired, transitioning to moving", "agent", p.agent.Name())
+			behavior.CurState = &Moving{agent: p.agent, logger: p.logger}
+			return repairedWorld, nil
+		}
+		p.logger.Info("unable to repair plan, transitioning to idle", "agent", p.agent.Name())
 		behavior.CurState = &Idle{agent: p.agent, logger: p.logger}
-		return (*core.WorldState)(nil), nil
+		return world.ReleaseReservations(p.agent.Name()), nil // the rest of the plan is abandoned
 	}
 
 	newAgentInterface, exists := newWorldState.GetAgent(p.agent.Name())
@@ -74,6 +90,9 @@ func (p *Performing) Execute(world *core.WorldState, deltaTime float64) (*core.W
 	if curPlan.IsComplete() {
 		p.logger.Info("plan complete after action, transitioning to idle", "agent", p.agent.Name())
 		behavior.CurState = &Idle{agent: newAgent, logger: p.logger}
+		if released := newWorldState.ReleaseReservations(p.agent.Name()); released != nil {
+			newWorldState = released
+		}
 	} else {
 		p.logger.Info("action complete, transitioning to moving", "agent", p.agent.Name())
 		behavior.CurState = &Moving{agent: newAgent, logger: p.logger}
@@ -82,6 +101,11 @@ func (p *Performing) Execute(world *core.WorldState, deltaTime float64) (*core.W
 	return newWorldState, nil
 }
 
+// bindAgent implements agentBinder.
+func (p *Performing) bindAgent(agent *Agent) {
+	p.agent = agent
+}
+
 // NewPerforming creates a new Performing state
 func NewPerforming(agent *Agent, logger *slog.Logger) *Performing {
 	return &Performing{
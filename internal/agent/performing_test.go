@@ -189,3 +189,44 @@ func TestPerforming_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestPerforming_ReleasesReservations(t *testing.T) {
+	type testCase struct {
+		plan Plan
+	}
+
+	tests := map[string]testCase{
+		"action fails": {
+			plan: &MockPlan{NextAction: &mockNullAction{}},
+		},
+		"plan completes": {
+			plan: &MockPlan{NextAction: &mockAction{}, Complete: true},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			testAgent := &Agent{
+				name:     "villager",
+				Behavior: &Behavior{CurPlan: tc.plan},
+			}
+			loc := &core.Location{Name: "testLocation", Inventory: core.NewInventory()}
+			loc.Reserve(testAgent.Name(), testResource, 4)
+			loc.Reserve("other", testResource, 2)
+			world := &core.WorldState{
+				Locations: map[string]*core.Location{loc.Name: loc},
+				Agents:    map[string]core.Agent{testAgent.Name(): testAgent},
+			}
+
+			testPerforming := NewPerforming(testAgent, logging.NewLogger("error"))
+			output, err := testPerforming.Execute(world, deltaTime)
+			require.NoError(t, err)
+			require.NotNil(t, output)
+
+			outputLocation, exists := output.GetLocation("testLocation")
+			require.True(t, exists)
+			require.Equal(t, 0, outputLocation.ReservedBy(testAgent.Name(), testResource))
+			require.Equal(t, 2, outputLocation.ReservedBy("other", testResource))
+		})
+	}
+}
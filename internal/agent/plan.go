@@ -20,6 +20,8 @@ type plan struct {
 	Actions []core.Action
 	// curLocation is used to determine the current step in the plan.
 	curLocation int
+	// goal is the world state the plan was made to reach. It is used to repair the plan if an action fails.
+	goal *core.WorldState
 }
 
 // Ensure plan implements the Plan interface
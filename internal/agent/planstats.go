@@ -0,0 +1,34 @@
+package agent
+
+import "time"
+
+// PlanStats are statistics about a single search of the GOAP planner, from creating the search until a plan is found or
+// the goal is given up on.
+type PlanStats struct {
+	// Agent is the name of the Agent the search was for
+	Agent string
+	// Found indicates that the search produced a plan
+	Found bool
+	// Best indicates that the plan is known to be the best one; a plan accepted early is not
+	Best bool
+	// PlanLength is the number of actions in the plan, or zero if none was found
+	PlanLength int
+	// Iterations is the number of iterations the search ran
+	Iterations int
+	// Expanded is the number of nodes whose successors were generated
+	Expanded int
+	// OpenSet is the number of nodes still waiting to be visited when the search ended
+	OpenSet int
+	// ClosedSet is the number of nodes visited
+	ClosedSet int
+	// Ticks is the number of ticks the search was spread over
+	Ticks int
+	// WallTime is the time spent running the search, summed over all of its ticks
+	WallTime time.Duration
+}
+
+// PlanStatsRecorder receives the PlanStats of every search the planner finishes.
+type PlanStatsRecorder interface {
+	// RecordPlan records the statistics of a finished search
+	RecordPlan(stats PlanStats)
+}
@@ -0,0 +1,140 @@
+package agent
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+
+	"Neolithic/internal/astar"
+	"Neolithic/internal/core"
+	"Neolithic/internal/planner"
+)
+
+// defaultRepairIterations is the number of iterations the GOAP planner may run when repairing a plan. It is kept well
+// below defaultNumIterations, since a repair only needs to bridge from the current state to the plan's goal.
+const defaultRepairIterations = 5000
+
+// repairPlan attempts to fix the Agent's plan after its next action has failed, without planning from scratch. The
+// actions already performed are kept. First, the failed action is swapped for an equivalent action elsewhere, such as
+// gathering the same resource at a different location, as long as the rest of the plan still reaches the goal. If no
+// swap works, the planner searches from the current state to the plan's goal with a small iteration budget.
+//
+// On success, the Agent's plan is replaced by the repaired one and its reservations are updated; the returned bool is
+// true and the WorldState, if not nil, holds the new reservations.
+func repairPlan(world *core.WorldState, agent *Agent, logger *slog.Logger) (*core.WorldState, bool, error) {
+	goal := PlanGoal(agent.Behavior.CurPlan)
+	if goal == nil {
+		return nil, false, nil
+	}
+	remaining, err := RemainingActions(agent.Behavior.CurPlan)
+	if err != nil || len(remaining) == 0 {
+		return nil, false, err
+	}
+
+	available := world.AvailableTo(agent.Name())
+
+	actions := swapFailedAction(available, goal, agent, remaining)
+	if actions == nil {
+		actions, err = searchForRepair(available, goal, agent, logger)
+		if err != nil || actions == nil {
+			return nil, false, err
+		}
+	}
+
+	newWorld := world
+	if released := world.ReleaseReservations(agent.Name()); released != nil {
+		newWorld = released
+	}
+	reserved, ok := reservePlan(newWorld, agent.Name(), actions)
+	if !ok {
+		return nil, false, nil
+	}
+	if reserved != nil {
+		newWorld = reserved
+	}
+
+	agent.Behavior.CurPlan = &plan{Actions: actions, goal: goal}
+	if newWorld == world {
+		return nil, true, nil
+	}
+	return newWorld, true, nil
+}
+
+// swapFailedAction looks for an alternative to the first of the remaining actions that lets the rest of the plan reach
+// the goal. It returns the repaired list of remaining actions, or nil if there is none.
+func swapFailedAction(world, goal *core.WorldState, agent *Agent, remaining []core.Action) []core.Action {
+	failed := remaining[0]
+	for _, alternative := range agent.Behavior.PossibleActions {
+		if !isAlternative(failed, alternative) {
+			continue
+		}
+		// the alternative is a template, so it must be taken with the amount the failed action was planned with
+		if batched, ok := failed.(core.Batched); ok {
+			alternative = alternative.(core.Batched).WithAmount(batched.BatchAmount())
+		}
+		candidate := make([]core.Action, len(remaining))
+		candidate[0] = alternative
+		copy(candidate[1:], remaining[1:])
+		if simulatePlan(world, goal, agent, candidate) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// isAlternative reports whether alternative does the same thing as action, with the same resource, but at a different
+// location.
+func isAlternative(action, alternative core.Action) bool {
+	if action == alternative || reflect.TypeOf(action) != reflect.TypeOf(alternative) {
+		return false
+	}
+
+	actionLoc, ok := action.(core.Locatable)
+	if !ok || actionLoc.Location() == nil {
+		return false
+	}
+	alternativeLoc := alternative.(core.Locatable)
+	if alternativeLoc.Location() == nil || alternativeLoc.Location().Name == actionLoc.Location().Name {
+		return false
+	}
+
+	if actionRes, ok := action.(core.NeedsResource); ok {
+		if alternative.(core.NeedsResource).Resource() != actionRes.Resource() {
+			return false
+		}
+	}
+	return true
+}
+
+// simulatePlan performs the actions in order, the way the planner does, and reports whether they all succeed and
+// reach the goal.
+func simulatePlan(world, goal *core.WorldState, agent *Agent, actions []core.Action) bool {
+	state := world
+	for _, action := range actions {
+		state = action.Perform(state, agent)
+		if state == nil {
+			return false
+		}
+	}
+	return planner.GoalReached(state, goal)
+}
+
+// searchForRepair runs a short GOAP search from the world to the goal. It returns the actions of the path found, or nil
+// if none was found within defaultRepairIterations.
+func searchForRepair(world, goal *core.WorldState, agent *Agent, logger *slog.Logger) ([]core.Action, error) {
+	search, err := newPlanSearch(world, goal, agent, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err = search.RunIterations(defaultRepairIterations); err != nil {
+		if errors.Is(err, astar.ErrNoPath) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !search.FoundBest {
+		return nil, nil
+	}
+	// drop the start node, which has no action
+	return actionListFromSearch(search)[1:], nil
+}
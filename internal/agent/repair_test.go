@@ -0,0 +1,196 @@
+package agent
+
+import (
+	"testing"
+
+	"Neolithic/internal/core"
+	"Neolithic/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairPlan(t *testing.T) {
+	fromBush1 := &mockTakeAction{from: "bush1", to: "depo"}
+	fromBush2 := &mockTakeAction{from: "bush2", to: "depo"}
+	possibleActions := []core.Action{fromBush1, fromBush2}
+
+	newGoal := func(amount int) *core.WorldState {
+		depo := &core.Location{Name: "depo", Inventory: core.NewInventory()}
+		depo.Inventory.AdjustAmount(testResource, amount)
+		return &core.WorldState{Locations: map[string]*core.Location{"depo": depo}}
+	}
+
+	type testCase struct {
+		bush2Amount      int
+		plan             Plan
+		expectRepaired   bool
+		expectedActions  []core.Action
+		expectedReserved int
+	}
+
+	tests := map[string]testCase{
+		"swaps failed action for another location": {
+			bush2Amount:      5,
+			plan:             NewPlan([]core.Action{fromBush1}, newGoal(1)),
+			expectRepaired:   true,
+			expectedActions:  []core.Action{fromBush2},
+			expectedReserved: 1,
+		},
+		"searches when no single swap works": {
+			bush2Amount:      5,
+			plan:             NewPlan([]core.Action{fromBush1, fromBush1}, newGoal(2)),
+			expectRepaired:   true,
+			expectedActions:  []core.Action{fromBush2, fromBush2},
+			expectedReserved: 2,
+		},
+		"fails when the goal can't be reached": {
+			bush2Amount:    0,
+			plan:           NewPlan([]core.Action{fromBush1}, newGoal(1)),
+			expectRepaired: false,
+		},
+		"fails when the plan has no goal": {
+			bush2Amount:    5,
+			plan:           NewPlan([]core.Action{fromBush1}, nil),
+			expectRepaired: false,
+		},
+		"fails for plans of other types": {
+			bush2Amount:    5,
+			plan:           &MockPlan{NextAction: fromBush1},
+			expectRepaired: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			testAgent := &Agent{
+				name:      "villager",
+				inventory: core.NewInventory(),
+				Behavior: &Behavior{
+					PossibleActions: possibleActions,
+					CurPlan:         tc.plan,
+				},
+			}
+
+			bush2 := &core.Location{Name: "bush2", Inventory: core.NewInventory()}
+			bush2.Inventory.AdjustAmount(testResource, tc.bush2Amount)
+			bush1 := &core.Location{Name: "bush1", Inventory: core.NewInventory()}
+			bush1.Reserve(testAgent.Name(), testResource, 1)
+			world := &core.WorldState{
+				Locations: map[string]*core.Location{
+					"bush1": bush1,
+					"bush2": bush2,
+					"depo":  {Name: "depo", Inventory: core.NewInventory()},
+				},
+				Agents: map[string]core.Agent{testAgent.Name(): testAgent},
+			}
+
+			newWorld, repaired, err := repairPlan(world, testAgent, logging.NewLogger("error"))
+			require.NoError(t, err)
+			require.Equal(t, tc.expectRepaired, repaired)
+			if !tc.expectRepaired {
+				assert.Nil(t, newWorld)
+				assert.Equal(t, tc.plan, testAgent.Behavior.CurPlan)
+				return
+			}
+
+			remaining, err := RemainingActions(testAgent.Behavior.CurPlan)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedActions, remaining)
+			assert.Equal(t, PlanGoal(tc.plan), PlanGoal(testAgent.Behavior.CurPlan))
+
+			require.NotNil(t, newWorld)
+			newBush1, _ := newWorld.GetLocation("bush1")
+			newBush2, _ := newWorld.GetLocation("bush2")
+			assert.Equal(t, 0, newBush1.ReservedBy(testAgent.Name(), testResource), "old reservations should be released")
+			assert.Equal(t, tc.expectedReserved, newBush2.ReservedBy(testAgent.Name(), testResource))
+		})
+	}
+}
+
+func TestRepairPlan_Batched(t *testing.T) {
+	fromBush1 := newMockBatchedTakeAction("bush1", "depo", 10)
+	fromBush2 := newMockBatchedTakeAction("bush2", "depo", 10)
+
+	goal := &core.WorldState{Locations: map[string]*core.Location{
+		"depo": {Name: "depo", Inventory: core.NewInventory()},
+	}}
+	goal.Locations["depo"].Inventory.AdjustAmount(testResource, 3)
+
+	testAgent := &Agent{
+		name:      "villager",
+		inventory: core.NewInventory(),
+		Behavior: &Behavior{
+			PossibleActions: []core.Action{fromBush1, fromBush2},
+			CurPlan:         NewPlan([]core.Action{fromBush1.WithAmount(3)}, goal),
+		},
+	}
+	bush1 := &core.Location{Name: "bush1", Inventory: core.NewInventory()}
+	bush1.Reserve(testAgent.Name(), testResource, 3)
+	bush2 := &core.Location{Name: "bush2", Inventory: core.NewInventory()}
+	bush2.Inventory.AdjustAmount(testResource, 20)
+	world := &core.WorldState{
+		Locations: map[string]*core.Location{
+			"bush1": bush1,
+			"bush2": bush2,
+			"depo":  {Name: "depo", Inventory: core.NewInventory()},
+		},
+		Agents: map[string]core.Agent{testAgent.Name(): testAgent},
+	}
+
+	planned, err := RemainingActions(testAgent.Behavior.CurPlan)
+	require.NoError(t, err)
+	require.NotNil(t, swapFailedAction(world.AvailableTo(testAgent.Name()), goal, testAgent, planned),
+		"a batch of the planned amount from the other bush should reach the goal without searching")
+
+	newWorld, repaired, err := repairPlan(world, testAgent, logging.NewLogger("error"))
+	require.NoError(t, err)
+	require.True(t, repaired)
+
+	remaining, err := RemainingActions(testAgent.Behavior.CurPlan)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	swapped, ok := remaining[0].(*mockBatchedTakeAction)
+	require.True(t, ok)
+	assert.Equal(t, "bush2", swapped.from)
+	assert.Equal(t, 3, swapped.BatchAmount(), "the swapped action should take the amount that was planned")
+
+	require.NotNil(t, newWorld)
+	newBush2, _ := newWorld.GetLocation("bush2")
+	assert.Equal(t, 3, newBush2.ReservedBy(testAgent.Name(), testResource))
+}
+
+func TestPerforming_RepairsFailedAction(t *testing.T) {
+	fromBush1 := &mockTakeAction{from: "bush1", to: "depo"}
+	fromBush2 := &mockTakeAction{from: "bush2", to: "depo"}
+
+	goal := &core.WorldState{Locations: map[string]*core.Location{
+		"depo": {Name: "depo", Inventory: core.NewInventory()},
+	}}
+	goal.Locations["depo"].Inventory.AdjustAmount(testResource, 1)
+
+	testAgent := &Agent{
+		name:      "villager",
+		inventory: core.NewInventory(),
+		Behavior: &Behavior{
+			PossibleActions: []core.Action{fromBush1, fromBush2},
+			CurPlan:         NewPlan([]core.Action{fromBush1}, goal),
+		},
+	}
+	bush2 := &core.Location{Name: "bush2", Inventory: core.NewInventory()}
+	bush2.Inventory.AdjustAmount(testResource, 1)
+	world := &core.WorldState{
+		Locations: map[string]*core.Location{
+			"bush1": {Name: "bush1", Inventory: core.NewInventory()},
+			"bush2": bush2,
+			"depo":  {Name: "depo", Inventory: core.NewInventory()},
+		},
+		Agents: map[string]core.Agent{testAgent.Name(): testAgent},
+	}
+
+	testPerforming := NewPerforming(testAgent, logging.NewLogger("error"))
+	_, err := testPerforming.Execute(world, deltaTime)
+	require.NoError(t, err)
+
+	assert.IsType(t, &Moving{}, testAgent.Behavior.CurState)
+	assert.Equal(t, fromBush2, testAgent.Behavior.CurPlan.PeekAction())
+}
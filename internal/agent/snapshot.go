@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"fmt"
+	"log/slog"
+
+	"Neolithic/internal/core"
+)
+
+const (
+	// IdleStateKind identifies the Idle state in a StateSnapshot
+	IdleStateKind = "idle"
+	// MovingStateKind identifies the Moving state in a StateSnapshot
+	MovingStateKind = "moving"
+	// PerformingStateKind identifies the Performing state in a StateSnapshot
+	PerformingStateKind = "performing"
+)
+
+// StateSnapshot is a serializable representation of an Agent's State. Only the fields relevant to Kind are set.
+type StateSnapshot struct {
+	// Kind is the kind of State the Agent is in
+	Kind string `json:"kind"`
+	// IterationsPerCall is Idle.IterationsPerCall
+	IterationsPerCall int `json:"iterations_per_call,omitempty"`
+	// Target is Moving.Target
+	Target *core.Coord `json:"target,omitempty"`
+	// Path is the remainder of Moving.Path, or nil if no path has been created yet
+	Path *PathSnapshot `json:"path,omitempty"`
+	// Next is the coordinate Moving is moving onto, or nil if the Agent is standing on its position
+	Next *core.Coord `json:"next,omitempty"`
+	// MoveProgress is how far Moving has moved onto Next
+	MoveProgress float64 `json:"move_progress,omitempty"`
+	// Waiting is how long Moving has been waiting for another agent to clear Next
+	Waiting float64 `json:"waiting,omitempty"`
+	// ActionStarted indicates that Performing has started the next action in the plan
+	ActionStarted bool `json:"action_started,omitempty"`
+	// TimeLeft is the time left before Performing completes its action
+	TimeLeft float64 `json:"time_left,omitempty"`
+}
+
+// PathSnapshot is a serializable representation of the coordinates left in a Path.
+type PathSnapshot struct {
+	// Remaining are the coordinates the Agent has yet to move to
+	Remaining []core.Coord `json:"remaining"`
+}
+
+// SnapshotState returns a serializable representation of the Agent's current State. A search the Idle state has not
+// finished is not included; the restored Idle state starts a new one.
+func (a *Agent) SnapshotState() (StateSnapshot, error) {
+	switch state := a.Behavior.CurState.(type) {
+	case *Idle:
+		return StateSnapshot{
+			Kind:              IdleStateKind,
+			IterationsPerCall: state.IterationsPerCall,
+		}, nil
+	case *Moving:
+		snapshot := StateSnapshot{
+			Kind:         MovingStateKind,
+			Target:       state.Target,
+			Next:         state.next,
+			MoveProgress: state.progress,
+			Waiting:      state.waiting,
+		}
+		if state.Path != nil {
+			snapshot.Path = &PathSnapshot{Remaining: state.Path.Remaining()}
+		}
+		return snapshot, nil
+	case *Performing:
+		return StateSnapshot{
+			Kind:          PerformingStateKind,
+			ActionStarted: state.action != nil,
+			TimeLeft:      state.timeLeft,
+		}, nil
+	default:
+		return StateSnapshot{}, fmt.Errorf("cannot snapshot state of type %T", a.Behavior.CurState)
+	}
+}
+
+// RestoreState sets the Agent's State from a snapshot. The Agent's plan must be restored first, as a Performing state
+// that has started its action resumes the next action in the plan.
+func (a *Agent) RestoreState(snapshot StateSnapshot, logger *slog.Logger) error {
+	switch snapshot.Kind {
+	case IdleStateKind:
+		a.Behavior.CurState = &Idle{
+			IterationsPerCall: snapshot.IterationsPerCall,
+			agent:             a,
+			logger:            logger,
+		}
+	case MovingStateKind:
+		moving := &Moving{
+			agent:    a,
+			Target:   snapshot.Target,
+			next:     snapshot.Next,
+			progress: snapshot.MoveProgress,
+			waiting:  snapshot.Waiting,
+			logger:   logger,
+		}
+		if snapshot.Path != nil {
+			moving.Path = &CoordPath{coords: snapshot.Path.Remaining}
+		}
+		a.Behavior.CurState = moving
+	case PerformingStateKind:
+		performing := &Performing{
+			timeLeft: snapshot.TimeLeft,
+			agent:    a,
+			logger:   logger,
+		}
+		if snapshot.ActionStarted {
+			if a.Behavior.CurPlan == nil {
+				return fmt.Errorf("agent %s is performing an action but has no plan", a.name)
+			}
+			performing.action = a.Behavior.CurPlan.PeekAction()
+		}
+		a.Behavior.CurState = performing
+	default:
+		return fmt.Errorf("unknown state kind %q", snapshot.Kind)
+	}
+	return nil
+}
+
+// RemainingActions returns the actions of a Plan that have yet to be completed.
+func RemainingActions(p Plan) ([]core.Action, error) {
+	curPlan, ok := p.(*plan)
+	if !ok {
+		return nil, fmt.Errorf("cannot get remaining actions of plan type %T", p)
+	}
+	if curPlan.IsComplete() {
+		return []core.Action{}, nil
+	}
+	remaining := make([]core.Action, len(curPlan.Actions)-curPlan.curLocation)
+	copy(remaining, curPlan.Actions[curPlan.curLocation:])
+	return remaining, nil
+}
+
+// PlanGoal returns the world state a Plan was made to reach, or nil if it is unknown.
+func PlanGoal(p Plan) *core.WorldState {
+	curPlan, ok := p.(*plan)
+	if !ok {
+		return nil
+	}
+	return curPlan.goal
+}
+
+// NewPlan creates a Plan that performs the given actions in order to reach goal. Goal may be nil, in which case the
+// plan can't be repaired if an action fails.
+func NewPlan(actions []core.Action, goal *core.WorldState) Plan {
+	return &plan{Actions: actions, goal: goal}
+}
@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"log/slog"
+	"testing"
+
+	"Neolithic/internal/core"
+	"Neolithic/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_SnapshotState(t *testing.T) {
+	logger := logging.NewLogger("error")
+	action := &mockAction{}
+
+	type testCase struct {
+		state     func(a *Agent) State
+		plan      Plan
+		want      StateSnapshot
+		expectErr bool
+	}
+
+	tests := map[string]testCase{
+		"idle": {
+			state: func(a *Agent) State {
+				return NewIdle(a, logger)
+			},
+			want: StateSnapshot{Kind: IdleStateKind},
+		},
+		"moving without path": {
+			state: func(a *Agent) State {
+				return newMovingTo(a, &core.Coord{X: 4, Y: 5}, logger)
+			},
+			want: StateSnapshot{Kind: MovingStateKind, Target: &core.Coord{X: 4, Y: 5}},
+		},
+		"moving with partially followed path": {
+			state: func(a *Agent) State {
+				moving := newMovingTo(a, &core.Coord{X: 2, Y: 0}, logger)
+				moving.Path = &CoordPath{
+					coords: []core.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}},
+					index:  1,
+				}
+				return moving
+			},
+			want: StateSnapshot{
+				Kind:   MovingStateKind,
+				Target: &core.Coord{X: 2, Y: 0},
+				Path:   &PathSnapshot{Remaining: []core.Coord{{X: 1, Y: 0}, {X: 2, Y: 0}}},
+			},
+		},
+		"moving between coordinates": {
+			state: func(a *Agent) State {
+				moving := newMovingTo(a, &core.Coord{X: 2, Y: 0}, logger)
+				moving.Path = &CoordPath{
+					coords: []core.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}},
+					index:  2,
+				}
+				moving.next = &core.Coord{X: 1, Y: 0}
+				moving.progress = 0.75
+				return moving
+			},
+			want: StateSnapshot{
+				Kind:         MovingStateKind,
+				Target:       &core.Coord{X: 2, Y: 0},
+				Path:         &PathSnapshot{Remaining: []core.Coord{{X: 2, Y: 0}}},
+				Next:         &core.Coord{X: 1, Y: 0},
+				MoveProgress: 0.75,
+			},
+		},
+		"performing an action": {
+			state: func(a *Agent) State {
+				performing := NewPerforming(a, logger)
+				performing.action = action
+				performing.timeLeft = 1.5
+				return performing
+			},
+			plan: NewPlan([]core.Action{action}, nil),
+			want: StateSnapshot{Kind: PerformingStateKind, ActionStarted: true, TimeLeft: 1.5},
+		},
+		"unknown state": {
+			state: func(a *Agent) State {
+				return nil
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := NewAgent("test", logger)
+			a.Behavior.CurState = tc.state(a)
+			a.Behavior.CurPlan = tc.plan
+
+			got, err := a.SnapshotState()
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+
+			restored := NewAgent("test", logger)
+			restored.Behavior.CurPlan = tc.plan
+			require.NoError(t, restored.RestoreState(got, logger))
+
+			roundTrip, err := restored.SnapshotState()
+			require.NoError(t, err)
+			assert.Equal(t, got, roundTrip)
+		})
+	}
+}
+
+func TestAgent_RestoreState(t *testing.T) {
+	logger := logging.NewLogger("error")
+
+	t.Run("performing without a plan", func(t *testing.T) {
+		a := NewAgent("test", logger)
+		err := a.RestoreState(StateSnapshot{Kind: PerformingStateKind, ActionStarted: true}, logger)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		a := NewAgent("test", logger)
+		err := a.RestoreState(StateSnapshot{Kind: "sleeping"}, logger)
+		assert.Error(t, err)
+	})
+
+	t.Run("performing resumes next action", func(t *testing.T) {
+		action := &mockAction{}
+		a := NewAgent("test", logger)
+		a.Behavior.CurPlan = NewPlan([]core.Action{action}, nil)
+		require.NoError(t, a.RestoreState(StateSnapshot{Kind: PerformingStateKind, ActionStarted: true, TimeLeft: 2}, logger))
+
+		performing, ok := a.Behavior.CurState.(*Performing)
+		require.True(t, ok)
+		assert.Equal(t, action, performing.action)
+		assert.Equal(t, 2.0, performing.timeLeft)
+		assert.Equal(t, a, performing.agent)
+	})
+}
+
+func TestRemainingActions(t *testing.T) {
+	first, second := &mockAction{}, &mockNilAction{}
+	p := NewPlan([]core.Action{first, second}, nil)
+
+	remaining, err := RemainingActions(p)
+	require.NoError(t, err)
+	assert.Equal(t, []core.Action{first, second}, remaining)
+
+	p.PopAction()
+	remaining, err = RemainingActions(p)
+	require.NoError(t, err)
+	assert.Equal(t, []core.Action{second}, remaining)
+
+	p.PopAction()
+	remaining, err = RemainingActions(p)
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+}
+
+// newMovingTo creates a Moving state headed for target.
+func newMovingTo(a *Agent, target *core.Coord, logger *slog.Logger) *Moving {
+	moving := NewMoving(a, logger)
+	moving.Target = target
+	return moving
+}
@@ -57,6 +57,9 @@ type mockLocationAction struct {
 }
 
 func (m *mockLocationAction) Location() *core.Location {
+	if m.location != nil {
+		return m.location
+	}
 	return &core.Location{
 		Name: "testLocation",
 		Coord: core.Coord{
@@ -136,6 +139,10 @@ func (m *mockTile) ID() (string, error) {
 }
 
 func (m *mockTile) Cost(prev astar.Node) float64 {
+	prevTile := prev.(*mockTile)
+	if prevTile.X != m.X && prevTile.Y != m.Y {
+		return 1.4
+	}
 	return 1
 }
 
@@ -159,3 +166,268 @@ func (m *mockTile) GetSuccessors() ([]astar.Node, error) {
 func (m *mockTile) Coord() core.Coord {
 	return core.Coord{X: m.X, Y: m.Y}
 }
+
+// mockReservingAction is a mockAction that reserves resources at a location.
+type mockReservingAction struct {
+	mockAction
+	location *core.Location
+	amount   int
+}
+
+var _ core.Reserving = (*mockReservingAction)(nil)
+
+func (m *mockReservingAction) Location() *core.Location {
+	return m.location
+}
+
+func (m *mockReservingAction) Resource() *core.Resource {
+	return testResource
+}
+
+func (m *mockReservingAction) ReservedAmount() int {
+	return m.amount
+}
+
+// mockTakeAction implements Action and Reserving, and moves one testResource from one location to another.
+type mockTakeAction struct {
+	from string
+	to   string
+}
+
+var _ core.Reserving = (*mockTakeAction)(nil)
+
+func (m *mockTakeAction) Perform(start *core.WorldState, agent core.Agent) *core.WorldState {
+	from, ok := start.GetLocation(m.from)
+	if !ok || from.Available(agent.Name(), testResource) <= 0 {
+		return nil
+	}
+	to, ok := start.GetLocation(m.to)
+	if !ok {
+		return nil
+	}
+	end := start.ShallowCopy()
+	newFrom, newTo := from.DeepCopy(), to.DeepCopy()
+	newFrom.Inventory.AdjustAmount(testResource, -1)
+	newFrom.Release(agent.Name(), testResource, 1)
+	newTo.Inventory.AdjustAmount(testResource, 1)
+	end.Locations[m.from] = newFrom
+	end.Locations[m.to] = newTo
+	return end
+}
+
+func (m *mockTakeAction) Cost(_ core.Agent) float64 {
+	return 1.0
+}
+
+func (m *mockTakeAction) Description() string {
+	return fmt.Sprintf("take from %s to %s", m.from, m.to)
+}
+
+func (m *mockTakeAction) GetChanges(_ core.Agent) []core.StateChange {
+	return []core.StateChange{
+		{EntityType: core.LocationEntity, Entity: m.from, Resource: testResource, Amount: -1},
+		{EntityType: core.LocationEntity, Entity: m.to, Resource: testResource, Amount: 1},
+	}
+}
+
+func (m *mockTakeAction) Location() *core.Location {
+	return &core.Location{Name: m.from}
+}
+
+func (m *mockTakeAction) Resource() *core.Resource {
+	return testResource
+}
+
+func (m *mockTakeAction) ReservedAmount() int {
+	return 1
+}
+
+// mockBatchedTakeAction is a mockTakeAction that takes a batch of amount at once, up to max.
+type mockBatchedTakeAction struct {
+	mockTakeAction
+	amount   int
+	max      int
+	template *mockBatchedTakeAction
+}
+
+var _ core.Batched = (*mockBatchedTakeAction)(nil)
+
+func newMockBatchedTakeAction(from, to string, max int) *mockBatchedTakeAction {
+	return &mockBatchedTakeAction{mockTakeAction: mockTakeAction{from: from, to: to}, amount: max, max: max}
+}
+
+func (m *mockBatchedTakeAction) Perform(start *core.WorldState, agent core.Agent) *core.WorldState {
+	from, ok := start.GetLocation(m.from)
+	if !ok || from.Available(agent.Name(), testResource) < m.amount {
+		return nil
+	}
+	to, ok := start.GetLocation(m.to)
+	if !ok {
+		return nil
+	}
+	end := start.ShallowCopy()
+	newFrom, newTo := from.DeepCopy(), to.DeepCopy()
+	newFrom.Inventory.AdjustAmount(testResource, -m.amount)
+	newFrom.Release(agent.Name(), testResource, m.amount)
+	newTo.Inventory.AdjustAmount(testResource, m.amount)
+	end.Locations[m.from] = newFrom
+	end.Locations[m.to] = newTo
+	return end
+}
+
+func (m *mockBatchedTakeAction) GetChanges(_ core.Agent) []core.StateChange {
+	return []core.StateChange{
+		{EntityType: core.LocationEntity, Entity: m.from, Resource: testResource, Amount: -m.amount},
+		{EntityType: core.LocationEntity, Entity: m.to, Resource: testResource, Amount: m.amount},
+	}
+}
+
+func (m *mockBatchedTakeAction) ReservedAmount() int {
+	return m.amount
+}
+
+func (m *mockBatchedTakeAction) MaxAmount(state *core.WorldState, agent core.Agent) int {
+	from, ok := state.GetLocation(m.from)
+	if !ok {
+		return 0
+	}
+	return min(m.max, from.Available(agent.Name(), testResource))
+}
+
+func (m *mockBatchedTakeAction) WithAmount(amount int) core.Batched {
+	batch := *m.Template().(*mockBatchedTakeAction)
+	batch.amount = amount
+	batch.template = m.Template().(*mockBatchedTakeAction)
+	return &batch
+}
+
+func (m *mockBatchedTakeAction) Template() core.Batched {
+	if m.template != nil {
+		return m.template
+	}
+	return m
+}
+
+func (m *mockBatchedTakeAction) BatchAmount() int {
+	return m.amount
+}
+
+// mockPlanStatsRecorder implements PlanStatsRecorder, keeping every PlanStats it receives.
+type mockPlanStatsRecorder struct {
+	plans []PlanStats
+}
+
+func (m *mockPlanStatsRecorder) RecordPlan(stats PlanStats) {
+	m.plans = append(m.plans, stats)
+}
+
+// mockConsumeAction implements Action and Consuming, and uses up an amount of testResource the agent carries.
+type mockConsumeAction struct {
+	amount int
+}
+
+var _ core.Consuming = (*mockConsumeAction)(nil)
+
+func (m *mockConsumeAction) Perform(start *core.WorldState, agent core.Agent) *core.WorldState {
+	startAgent, ok := start.GetAgent(agent.Name())
+	if !ok || startAgent.Inventory().GetAmount(testResource) < m.amount {
+		return nil
+	}
+	end := start.ShallowCopy()
+	endAgent := startAgent.DeepCopy()
+	endAgent.Inventory().AdjustAmount(testResource, -m.amount)
+	end.Agents[endAgent.Name()] = endAgent
+	return end
+}
+
+func (m *mockConsumeAction) Cost(_ core.Agent) float64 {
+	return 1.0
+}
+
+func (m *mockConsumeAction) Description() string {
+	return fmt.Sprintf("consume %d", m.amount)
+}
+
+func (m *mockConsumeAction) GetChanges(agent core.Agent) []core.StateChange {
+	return []core.StateChange{
+		{EntityType: core.AgentEntity, Entity: agent.Name(), Resource: testResource, Amount: -m.amount},
+	}
+}
+
+func (m *mockConsumeAction) Resource() *core.Resource {
+	return testResource
+}
+
+func (m *mockConsumeAction) ConsumedAmount() int {
+	return m.amount
+}
+
+// mockWeightAttribute implements core.WeightedAttribute, and gives a resource a weight.
+type mockWeightAttribute struct {
+	weight float64
+}
+
+var _ core.WeightedAttribute = (*mockWeightAttribute)(nil)
+
+func (m *mockWeightAttribute) String() string {
+	return fmt.Sprintf("weight %v", m.weight)
+}
+
+func (m *mockWeightAttribute) Type() core.AttributeType {
+	return "weight"
+}
+
+func (m *mockWeightAttribute) CreateAction(_ core.AttributeHolder, _ core.CreateActionParams) (core.Action, error) {
+	return nil, nil
+}
+
+func (m *mockWeightAttribute) NeedsLocation() bool {
+	return false
+}
+
+func (m *mockWeightAttribute) NeedsResource() bool {
+	return false
+}
+
+func (m *mockWeightAttribute) Copy() core.Attribute {
+	return &mockWeightAttribute{weight: m.weight}
+}
+
+func (m *mockWeightAttribute) UnitWeight() float64 {
+	return m.weight
+}
+
+// mockNutritionAttribute implements core.NourishingAttribute, and gives a resource a nutrition.
+type mockNutritionAttribute struct {
+	nutrition float64
+}
+
+var _ core.NourishingAttribute = (*mockNutritionAttribute)(nil)
+
+func (m *mockNutritionAttribute) String() string {
+	return fmt.Sprintf("nutrition %v", m.nutrition)
+}
+
+func (m *mockNutritionAttribute) Type() core.AttributeType {
+	return "edible"
+}
+
+func (m *mockNutritionAttribute) CreateAction(_ core.AttributeHolder, _ core.CreateActionParams) (core.Action, error) {
+	return nil, nil
+}
+
+func (m *mockNutritionAttribute) NeedsLocation() bool {
+	return false
+}
+
+func (m *mockNutritionAttribute) NeedsResource() bool {
+	return false
+}
+
+func (m *mockNutritionAttribute) Copy() core.Attribute {
+	return &mockNutritionAttribute{nutrition: m.nutrition}
+}
+
+func (m *mockNutritionAttribute) UnitNutrition() float64 {
+	return m.nutrition
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"time"
 
 	"Neolithic/internal/logging"
 )
@@ -15,6 +16,8 @@ const (
 	DoubleBias = 2.0
 	// NoBias represents the default level of heuristic bias (none)
 	NoBias = 1.0
+	// clockCheckInterval is the number of iterations RunFor runs between checks of the clock
+	clockCheckInterval = 64
 )
 
 // ErrNoPath is thrown when the Run Planner is unable to find a path to the goal state
@@ -63,6 +66,8 @@ type SearchState struct {
 	BestCost float64
 	// Iterations is the number of times the AStar algorithm has run through its main looop
 	Iterations int
+	// Expanded is the number of nodes whose successors have been generated
+	Expanded int
 	// FoundBest indicates if the algorithm has found the optimal path to the end.
 	FoundBest bool
 	// HeuristicBias determines the amount of bias to give the heuristic; higher values will result in a shorter search
@@ -77,6 +82,8 @@ type SearchState struct {
 	// bestSolution is the head node of the current best solution. Not meant to be accessed directly,
 	// instead use CurrentBestPath
 	bestSolution *searchNode
+	// skip reports whether a node must not be visited, or is nil if every node may be visited
+	skip func(Node) bool
 	// logger is used to log information about the search
 	logger *slog.Logger
 }
@@ -98,6 +105,14 @@ func WithBias(b float64) Option {
 	}
 }
 
+// WithSkip allows the creator to rule out nodes the search must not visit, such as tiles that are blocked for now. The
+// start and goal nodes are never skipped.
+func WithSkip(skip func(Node) bool) Option {
+	return func(s *SearchState) {
+		s.skip = skip
+	}
+}
+
 // NewSearch initializes a new SearchState with a start and finish Node
 func NewSearch(start, goal Node, opts ...Option) (*SearchState, error) {
 	search := &SearchState{
@@ -197,6 +212,7 @@ func (s *SearchState) RunIterations(numIterations int) error {
 		if err != nil {
 			return err
 		}
+		s.Expanded++
 
 		for _, successor := range successors {
 			sucId, err := successor.ID()
@@ -205,6 +221,15 @@ func (s *SearchState) RunIterations(numIterations int) error {
 			}
 
 			s.logger.Debug(fmt.Sprintf("Checking successor %s", sucId))
+			if s.skip != nil && s.skip(successor) {
+				goalID, err := s.Goal.ID()
+				if err != nil {
+					return err
+				}
+				if sucId != goalID {
+					continue
+				}
+			}
 
 			stepCost := successor.Cost(currentNode.nodeState)
 			newGCost := currentNode.gCost + stepCost
@@ -228,6 +253,8 @@ func (s *SearchState) RunIterations(numIterations int) error {
 					existing.gCost = newGCost
 					existing.hCost = newHCost
 					existing.parent = currentNode
+					// the successor reaches the same state a different way, and nodes may hold how they were reached
+					existing.nodeState = successor
 					heap.Fix(s.openSet, existing.index)
 				}
 			} else {
@@ -254,6 +281,39 @@ func (s *SearchState) RunIterations(numIterations int) error {
 	return nil
 }
 
+// RunFor runs the SearchState for up to the given duration, never exceeding maxIterations, or until an optimal path is
+// found. The clock is only checked every few iterations, so the duration may be slightly exceeded. Since the number of
+// iterations run depends on the speed of the machine, searches run this way are not reproducible.
+func (s *SearchState) RunFor(duration time.Duration, maxIterations int) error {
+	deadline := time.Now().Add(duration)
+	for maxIterations > 0 && !s.FoundBest {
+		chunk := min(clockCheckInterval, maxIterations)
+		if err := s.RunIterations(chunk); err != nil {
+			return err
+		}
+		maxIterations -= chunk
+		if !time.Now().Before(deadline) {
+			break
+		}
+	}
+	return nil
+}
+
+// OpenSetSize returns the number of nodes waiting to be visited.
+func (s *SearchState) OpenSetSize() int {
+	return len(s.openSetMap)
+}
+
+// ClosedSetSize returns the number of nodes that have been visited.
+func (s *SearchState) ClosedSetSize() int {
+	return len(s.closedSet)
+}
+
+// HasSolution reports whether the search has found any path to the goal, even if it is not yet known to be optimal.
+func (s *SearchState) HasSolution() bool {
+	return s.bestSolution != nil
+}
+
 // CurrentBestPath returns an array of nodes as the current best path to the goal.
 func (s *SearchState) CurrentBestPath() []Node {
 	return reconstructPath(s.bestSolution)
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"math"
 	"testing"
+	"time"
 
 	"Neolithic/internal/logging"
 	"github.com/stretchr/testify/assert"
@@ -14,6 +15,7 @@ var testError = errors.New("test error")
 
 type dummyNode struct {
 	name           string
+	cost           float64
 	neighbors      []*dummyNode
 	heuristicError error
 	idError        error
@@ -44,6 +46,9 @@ func (d *dummyNode) ID() (string, error) {
 }
 
 func (d *dummyNode) Cost(prev Node) float64 {
+	if d.cost != 0 {
+		return d.cost
+	}
 	return 1
 }
 
@@ -259,6 +264,124 @@ func TestNewSearch(t *testing.T) {
 	}
 }
 
+func TestSearchState_Resume(t *testing.T) {
+	A := &dummyNode{name: "A"}
+	B := &dummyNode{name: "B"}
+	C := &dummyNode{name: "C"}
+	A.neighbors = []*dummyNode{B, C}
+
+	search, err := NewSearch(A, C, WithLogger(logging.NewLogger("error")))
+	assert.NoError(t, err)
+
+	assert.NoError(t, search.RunIterations(1))
+	assert.False(t, search.HasSolution())
+	assert.False(t, search.FoundBest)
+
+	assert.NoError(t, search.RunIterations(1))
+	assert.True(t, search.HasSolution(), "a path is found before the search is finished")
+	assert.False(t, search.FoundBest)
+	assert.Equal(t, []Node{A, C}, search.CurrentBestPath())
+	assert.Equal(t, 1, search.Expanded, "the goal node is not expanded")
+	assert.Equal(t, 1, search.OpenSetSize())
+	assert.Equal(t, 1, search.ClosedSetSize())
+
+	assert.NoError(t, search.RunIterations(10))
+	assert.True(t, search.FoundBest)
+	assert.Equal(t, 3, search.Iterations)
+	assert.Equal(t, []Node{A, C}, search.CurrentBestPath())
+}
+
+func TestSearchState_RunFor(t *testing.T) {
+	type testCase struct {
+		duration           time.Duration
+		maxIterations      int
+		expectedIterations int
+		expectedIsBest     bool
+	}
+
+	testCases := map[string]testCase{
+		"limited by iterations": {
+			duration:           time.Hour,
+			maxIterations:      2,
+			expectedIterations: 2,
+			expectedIsBest:     false,
+		},
+		"runs until finished": {
+			duration:           time.Hour,
+			maxIterations:      1000,
+			expectedIterations: 4,
+			expectedIsBest:     true,
+		},
+		"runs at least one chunk when out of time": {
+			duration:           0,
+			maxIterations:      1000,
+			expectedIterations: 4,
+			expectedIsBest:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			A := &dummyNode{name: "A"}
+			B := &dummyNode{name: "B"}
+			C := &dummyNode{name: "C"}
+			D := &dummyNode{name: "D"}
+			A.neighbors = []*dummyNode{B, C}
+			B.neighbors = []*dummyNode{D}
+			C.neighbors = []*dummyNode{D}
+
+			search, err := NewSearch(A, D, WithLogger(logging.NewLogger("error")))
+			assert.NoError(t, err)
+
+			assert.NoError(t, search.RunFor(tc.duration, tc.maxIterations))
+			assert.Equal(t, tc.expectedIterations, search.Iterations)
+			assert.Equal(t, tc.expectedIsBest, search.FoundBest)
+		})
+	}
+}
+
+func TestSearchState_CheaperRouteReplacesNode(t *testing.T) {
+	goal := &dummyNode{name: "G"}
+	// two nodes for the same state, reached different ways
+	expensive := &dummyNode{name: "X", cost: 5, neighbors: []*dummyNode{goal}}
+	cheap := &dummyNode{name: "X", neighbors: []*dummyNode{goal}}
+	detour := &dummyNode{name: "A", neighbors: []*dummyNode{cheap}}
+	start := &dummyNode{name: "S", neighbors: []*dummyNode{expensive, detour}}
+
+	search, err := NewSearch(start, goal, WithLogger(logging.NewLogger("error")))
+	assert.NoError(t, err)
+	assert.NoError(t, search.RunIterations(100))
+	assert.True(t, search.FoundBest)
+	assert.Equal(t, 3.0, search.BestCost)
+
+	path := search.CurrentBestPath()
+	if assert.Len(t, path, 4) {
+		assert.Same(t, detour, path[1])
+		assert.Same(t, cheap, path[2], "path should hold the node of the cheaper route")
+	}
+}
+
+func TestSearchState_Skip(t *testing.T) {
+	goal := &dummyNode{name: "G"}
+	blocked := &dummyNode{name: "B", neighbors: []*dummyNode{goal}}
+	detour := &dummyNode{name: "D", cost: 3, neighbors: []*dummyNode{goal}}
+	start := &dummyNode{name: "S", neighbors: []*dummyNode{blocked, detour}}
+	skip := func(n Node) bool {
+		id, _ := n.ID()
+		return id == "B" || id == "G"
+	}
+
+	search, err := NewSearch(start, goal, WithLogger(logging.NewLogger("error")), WithSkip(skip))
+	assert.NoError(t, err)
+	assert.NoError(t, search.RunIterations(100))
+	assert.True(t, search.FoundBest)
+	assert.Equal(t, []Node{start, detour, goal}, search.CurrentBestPath(), "the search should avoid skipped nodes, but not the goal")
+
+	search, err = NewSearch(start, goal, WithLogger(logging.NewLogger("error")), WithSkip(func(Node) bool { return true }))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, search.RunIterations(100), ErrNoPath, "there should be no path when every way to the goal is skipped")
+}
+
 func TestSearchState_CurrentBest(t *testing.T) {
 	type testCase struct {
 		setupFunc func() (*searchNode, []Node)
@@ -12,15 +12,11 @@ import (
 // It is used to register and retrieve the Capacity attribute from attribute collections.
 const CapacityAttributeType core.AttributeType = "capacity"
 
-// defaultDepositAmount is the standard amount of a resource considered deposited
-// when a Deposit action is created. This is an internal constant.
-const defaultDepositAmount = 1.0
-
 // Capacity is an attribute that determines if a location can have resources deposited at it
 // and defines the maximum weight of resources it can hold.
 type Capacity struct {
-	// Size represents the maximum weight capacity of the location.
-	// Resources heavier than this size cannot be deposited.
+	// Size represents the maximum weight capacity of the location: the most total weight of resources it can hold.
+	// Resources heavier than this size cannot be deposited at all.
 	Size float64
 }
 
@@ -69,7 +65,7 @@ func (c *Capacity) CreateAction(holder core.AttributeHolder, params core.CreateA
 
 	return &Deposit{
 		DepResource:    params.Resource,
-		Amount:         defaultDepositAmount,
+		Amount:         weight.batchSize(),
 		ActionLocation: loc,
 		ActionCost:     weight.Amount,
 	}, nil
@@ -85,6 +81,16 @@ func (c *Capacity) Copy() core.Attribute {
 	return &Capacity{Size: c.Size}
 }
 
+// MaxWeight implements core.StorageAttribute, and returns the Size of the location.
+func (c *Capacity) MaxWeight() float64 {
+	return c.Size
+}
+
+// Params returns the parameters of the Capacity attribute, implementing core.ParameterizedAttribute.
+func (c *Capacity) Params() core.AttributeParams {
+	return core.AttributeParams{"size": c.Size}
+}
+
 // String provides a human-readable string representation of the Capacity attribute,
 func (c *Capacity) String() string {
 	var sb strings.Builder
@@ -81,7 +81,7 @@ func TestCapacity_CreateAction(t *testing.T) {
 			params:   core.CreateActionParams{Resource: resLight},
 			wantAction: &Deposit{
 				DepResource:    resLight,
-				Amount:         defaultDepositAmount,
+				Amount:         defaultGatherAmount,
 				ActionLocation: mockLocation,
 				ActionCost:     5.0, // Cost matches weight in the original code
 			},
@@ -121,7 +121,7 @@ func TestCapacity_CreateAction(t *testing.T) {
 			params:   core.CreateActionParams{Resource: resHeavy},
 			wantAction: &Deposit{
 				DepResource:    resHeavy,
-				Amount:         defaultDepositAmount,
+				Amount:         defaultGatherAmount,
 				ActionLocation: mockLocation,
 				ActionCost:     50.0,
 			},
@@ -0,0 +1,75 @@
+package attributes
+
+import (
+	"fmt"
+
+	"Neolithic/internal/core"
+)
+
+// Consume implements Action, and represents the act of using up a Resource the agent carries, such as eating it
+type Consume struct {
+	// Res is the Resource being consumed
+	Res *core.Resource
+	// Amount is the Amount of the Resource being consumed
+	Amount int
+	// ActionCost is the cost of taking the Action
+	ActionCost float64
+}
+
+// Force Consume to implement Action and Consuming
+var (
+	_ core.Action    = (*Consume)(nil)
+	_ core.Consuming = (*Consume)(nil)
+)
+
+// Perform implements Action.Perform, and simulates the act of consuming a Resource. It fails unless the agent carries
+// the full Amount.
+func (c *Consume) Perform(start *core.WorldState, agent core.Agent) *core.WorldState {
+	startAgent, ok := start.GetAgent(agent.Name())
+	if !ok {
+		return nil
+	}
+	if startAgent.Inventory().GetAmount(c.Res) < c.Amount {
+		return nil // fail, not enough of the Resource to consume
+	}
+
+	endAgent := startAgent.DeepCopy()
+	endAgent.Inventory().AdjustAmount(c.Res, -c.Amount)
+
+	end := start.ShallowCopy()
+	end.Agents[endAgent.Name()] = endAgent
+
+	return end
+}
+
+// Cost implements Action.Cost, and returns the ActionCost of the consume Action
+func (c *Consume) Cost(_ core.Agent) float64 {
+	return c.ActionCost
+}
+
+// Description implements Action.Description, and provides a brief description of the consume Action
+func (c *Consume) Description() string {
+	return fmt.Sprintf("consume %d %s", c.Amount, c.Res.Name)
+}
+
+// GetChanges implements Action.GetChanges. Consuming only removes the Resource from the agent.
+func (c *Consume) GetChanges(agent core.Agent) []core.StateChange {
+	return []core.StateChange{
+		{
+			Entity:     agent.Name(),
+			EntityType: core.AgentEntity,
+			Resource:   c.Res,
+			Amount:     -c.Amount,
+		},
+	}
+}
+
+// Resource returns the Resource associated with the Consume action.
+func (c *Consume) Resource() *core.Resource {
+	return c.Res
+}
+
+// ConsumedAmount implements core.Consuming, and returns the amount of the Resource the consume uses up.
+func (c *Consume) ConsumedAmount() int {
+	return c.Amount
+}
@@ -0,0 +1,74 @@
+package attributes
+
+import (
+	"testing"
+
+	"Neolithic/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsume_Perform(t *testing.T) {
+	type testCase struct {
+		amount                int
+		startAmountInAgent    int
+		expectedAmountInAgent int
+		expectNil             bool
+	}
+
+	testCases := map[string]testCase{
+		"can consume": {
+			amount:                1,
+			startAmountInAgent:    3,
+			expectedAmountInAgent: 2,
+		},
+		"can consume everything": {
+			amount:                3,
+			startAmountInAgent:    3,
+			expectedAmountInAgent: 0,
+		},
+		"consume fails, not enough in agent": {
+			amount:             2,
+			startAmountInAgent: 1,
+			expectNil:          true,
+		},
+		"consume fails, nothing in agent": {
+			amount:    1,
+			expectNil: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			agent := testAgent.DeepCopy()
+			agent.Inventory().AdjustAmount(testResource, tc.startAmountInAgent)
+			location := testLocation.DeepCopy()
+			startState := &core.WorldState{
+				Locations: map[string]*core.Location{location.Name: location},
+				Agents:    map[string]core.Agent{agent.Name(): agent},
+			}
+			consume := &Consume{Res: testResource, Amount: tc.amount, ActionCost: 1}
+
+			endState := consume.Perform(startState, agent)
+			if tc.expectNil {
+				assert.Nil(t, endState)
+				return
+			}
+			assert.NotNil(t, endState)
+			endAgent, exists := endState.GetAgent(agent.Name())
+			assert.True(t, exists)
+			assert.Equal(t, tc.expectedAmountInAgent, endAgent.Inventory().GetAmount(testResource))
+			assert.Equal(t, tc.startAmountInAgent, agent.Inventory().GetAmount(testResource), "start agent should be unchanged")
+			assert.Same(t, location, endState.Locations[location.Name], "locations should be untouched")
+		})
+	}
+}
+
+func TestConsume_GetChanges(t *testing.T) {
+	consume := &Consume{Res: testResource, Amount: 2, ActionCost: 1}
+	assert.Equal(t, []core.StateChange{
+		{Entity: testAgent.Name(), EntityType: core.AgentEntity, Resource: testResource, Amount: -2},
+	}, consume.GetChanges(testAgent))
+	assert.Equal(t, testResource, consume.Resource())
+	assert.Equal(t, 2, consume.ConsumedAmount())
+	assert.Equal(t, "consume 2 testResource", consume.Description())
+}
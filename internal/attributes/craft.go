@@ -0,0 +1,118 @@
+package attributes
+
+import (
+	"fmt"
+	"strings"
+
+	"Neolithic/internal/core"
+)
+
+// Craft implements Action, and represents the act of turning resources the agent carries into other resources at a
+// workshop
+type Craft struct {
+	// Recipe is the name of the recipe being crafted
+	Recipe string
+	// Inputs are the resources taken from the agent, sorted by resource name
+	Inputs []core.InventoryEntry
+	// Outputs are the resources given to the agent, sorted by resource name
+	Outputs []core.InventoryEntry
+	// ActionLocation is the workshop where the crafting takes place
+	ActionLocation *core.Location
+	// ActionCost is the cost of taking the Action
+	ActionCost float64
+	// Duration is how long, in seconds, crafting takes
+	Duration float64
+}
+
+// Force Craft to implement Action and RequiresTime
+var (
+	_ core.Action       = (*Craft)(nil)
+	_ core.RequiresTime = (*Craft)(nil)
+)
+
+// Perform implements Action.Perform, and simulates the act of crafting. It fails unless the agent carries every input,
+// and can carry the outputs once the inputs are used up.
+func (c *Craft) Perform(start *core.WorldState, agent core.Agent) *core.WorldState {
+	if _, ok := start.GetLocation(c.ActionLocation.Name); !ok {
+		return nil
+	}
+	startAgent, ok := start.GetAgent(agent.Name())
+	if !ok {
+		return nil
+	}
+	for _, input := range c.Inputs {
+		if startAgent.Inventory().GetAmount(input.Resource) < input.Amount {
+			return nil // fail, missing an input
+		}
+	}
+
+	endAgent := startAgent.DeepCopy()
+	endAgentInv := endAgent.Inventory()
+	for _, input := range c.Inputs {
+		endAgentInv.AdjustAmount(input.Resource, -input.Amount)
+	}
+	for _, output := range c.Outputs {
+		if core.CanCarry(endAgent, output.Resource) < output.Amount {
+			return nil // fail, the outputs weigh more than the agent can carry
+		}
+		endAgentInv.AdjustAmount(output.Resource, output.Amount)
+	}
+
+	end := start.ShallowCopy()
+	end.Agents[endAgent.Name()] = endAgent
+
+	return end
+}
+
+// Cost implements Action.Cost, and returns the ActionCost of the craft Action
+func (c *Craft) Cost(_ core.Agent) float64 {
+	return c.ActionCost
+}
+
+// Description implements Action.Description, and provides a brief description of the craft Action
+func (c *Craft) Description() string {
+	return fmt.Sprintf("craft %s (%s -> %s) at %s", c.Recipe, describeEntries(c.Inputs), describeEntries(c.Outputs),
+		c.ActionLocation)
+}
+
+// describeEntries lists inventory entries as amounts and resource names
+func describeEntries(entries []core.InventoryEntry) string {
+	parts := make([]string, len(entries))
+	for i, entry := range entries {
+		parts[i] = fmt.Sprintf("%d %s", entry.Amount, entry.Resource.Name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GetChanges implements Action.GetChanges. Crafting removes every input from the agent and adds every output to it, so
+// that the planner can chain gathering the inputs, crafting, and depositing the outputs.
+func (c *Craft) GetChanges(agent core.Agent) []core.StateChange {
+	changes := make([]core.StateChange, 0, len(c.Inputs)+len(c.Outputs))
+	for _, input := range c.Inputs {
+		changes = append(changes, core.StateChange{
+			Entity:     agent.Name(),
+			EntityType: core.AgentEntity,
+			Resource:   input.Resource,
+			Amount:     -input.Amount,
+		})
+	}
+	for _, output := range c.Outputs {
+		changes = append(changes, core.StateChange{
+			Entity:     agent.Name(),
+			EntityType: core.AgentEntity,
+			Resource:   output.Resource,
+			Amount:     output.Amount,
+		})
+	}
+	return changes
+}
+
+// Location returns the workshop where the craft action takes place.
+func (c *Craft) Location() *core.Location {
+	return c.ActionLocation
+}
+
+// TimeNeeded implements core.RequiresTime, and returns the Duration of the craft.
+func (c *Craft) TimeNeeded() float64 {
+	return c.Duration
+}
@@ -0,0 +1,162 @@
+package attributes
+
+import (
+	"testing"
+
+	"Neolithic/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCraft_Perform(t *testing.T) {
+	wood := &core.Resource{Name: "Wood"}
+	axe := &core.Resource{Name: "Stone Axe"}
+
+	type testCase struct {
+		startResource    int
+		startWood        int
+		expectedResource int
+		expectedWood     int
+		expectedAxes     int
+		expectNil        bool
+	}
+
+	testCases := map[string]testCase{
+		"can craft": {
+			startResource:    1,
+			startWood:        2,
+			expectedResource: 0,
+			expectedWood:     0,
+			expectedAxes:     1,
+		},
+		"keeps leftover inputs": {
+			startResource:    3,
+			startWood:        5,
+			expectedResource: 2,
+			expectedWood:     3,
+			expectedAxes:     1,
+		},
+		"craft fails, missing an input": {
+			startResource: 1,
+			startWood:     1,
+			expectNil:     true,
+		},
+		"craft fails, nothing in agent": {
+			expectNil: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			agent := testAgent.DeepCopy()
+			agent.Inventory().AdjustAmount(testResource, tc.startResource)
+			agent.Inventory().AdjustAmount(wood, tc.startWood)
+			location := testLocation.DeepCopy()
+			startState := &core.WorldState{
+				Locations: map[string]*core.Location{location.Name: location},
+				Agents:    map[string]core.Agent{agent.Name(): agent},
+			}
+			craft := &Craft{
+				Recipe:         "axe",
+				Inputs:         []core.InventoryEntry{{Resource: testResource, Amount: 1}, {Resource: wood, Amount: 2}},
+				Outputs:        []core.InventoryEntry{{Resource: axe, Amount: 1}},
+				ActionLocation: location,
+				ActionCost:     1,
+			}
+
+			endState := craft.Perform(startState, agent)
+			if tc.expectNil {
+				assert.Nil(t, endState)
+				return
+			}
+			assert.NotNil(t, endState)
+			endAgent, exists := endState.GetAgent(agent.Name())
+			assert.True(t, exists)
+			assert.Equal(t, tc.expectedResource, endAgent.Inventory().GetAmount(testResource))
+			assert.Equal(t, tc.expectedWood, endAgent.Inventory().GetAmount(wood))
+			assert.Equal(t, tc.expectedAxes, endAgent.Inventory().GetAmount(axe))
+			assert.Equal(t, tc.startWood, agent.Inventory().GetAmount(wood), "start agent should be unchanged")
+			assert.Same(t, location, endState.Locations[location.Name], "locations should be untouched")
+		})
+	}
+}
+
+func TestCraft_PerformCarryCapacity(t *testing.T) {
+	wood := core.NewResource("Wood", core.WithResourceAttributes(&Weight{Amount: 1}))
+	log := core.NewResource("Log", core.WithResourceAttributes(&Weight{Amount: 5}))
+
+	type testCase struct {
+		capacity  float64
+		startWood int
+		expectNil bool
+	}
+
+	testCases := map[string]testCase{
+		"outputs fit once the inputs are used": {
+			capacity:  6,
+			startWood: 3,
+		},
+		"craft fails, outputs too heavy to carry": {
+			capacity:  6,
+			startWood: 4,
+			expectNil: true,
+		},
+		"no limit": {
+			startWood: 6,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			agent := &mockCarrier{mockAgent: mockAgent{N: "testCarrier", inventory: core.NewInventory()}, capacity: tc.capacity}
+			agent.Inventory().AdjustAmount(wood, tc.startWood)
+			location := testLocation.DeepCopy()
+			startState := &core.WorldState{
+				Locations: map[string]*core.Location{location.Name: location},
+				Agents:    map[string]core.Agent{agent.Name(): agent},
+			}
+			craft := &Craft{
+				Recipe:         "log",
+				Inputs:         []core.InventoryEntry{{Resource: wood, Amount: 2}},
+				Outputs:        []core.InventoryEntry{{Resource: log, Amount: 1}},
+				ActionLocation: location,
+				ActionCost:     1,
+			}
+
+			endState := craft.Perform(startState, agent)
+			if tc.expectNil {
+				assert.Nil(t, endState)
+				return
+			}
+			require.NotNil(t, endState)
+			endAgent, _ := endState.GetAgent(agent.Name())
+			assert.Equal(t, 1, endAgent.Inventory().GetAmount(log))
+			if tc.capacity > 0 {
+				assert.LessOrEqual(t, core.InventoryWeight(endAgent.Inventory()), tc.capacity)
+			}
+		})
+	}
+}
+
+func TestCraft_GetChanges(t *testing.T) {
+	wood := &core.Resource{Name: "Wood"}
+	axe := &core.Resource{Name: "Stone Axe"}
+	location := testLocation.DeepCopy()
+	craft := &Craft{
+		Recipe:         "axe",
+		Inputs:         []core.InventoryEntry{{Resource: testResource, Amount: 1}, {Resource: wood, Amount: 2}},
+		Outputs:        []core.InventoryEntry{{Resource: axe, Amount: 1}},
+		ActionLocation: location,
+		ActionCost:     1,
+		Duration:       4,
+	}
+
+	assert.Equal(t, []core.StateChange{
+		{Entity: testAgent.Name(), EntityType: core.AgentEntity, Resource: testResource, Amount: -1},
+		{Entity: testAgent.Name(), EntityType: core.AgentEntity, Resource: wood, Amount: -2},
+		{Entity: testAgent.Name(), EntityType: core.AgentEntity, Resource: axe, Amount: 1},
+	}, craft.GetChanges(testAgent))
+	assert.Equal(t, location, craft.Location())
+	assert.Equal(t, 4.0, craft.TimeNeeded())
+	assert.Contains(t, craft.Description(), "craft axe (1 testResource, 2 Wood -> 1 Stone Axe) at ")
+}
@@ -1,42 +1,56 @@
 package attributes
 
 import (
+	"errors"
 	"fmt"
 
 	"Neolithic/internal/core"
 )
 
+var (
+	// ErrNothingToDeposit is the reason a Deposit fails when the agent doesn't carry the resource
+	ErrNothingToDeposit = errors.New("agent carries none of the resource")
+	// ErrLocationFull is the reason a Deposit fails when the location has no room left for the resource
+	ErrLocationFull = errors.New("location has no room left for the resource")
+)
+
 // Deposit implements Action, and represents the act of depositing a Resource at a location
 type Deposit struct {
 	// DepResource is the Resource being deposited
 	DepResource *core.Resource
-	// Amount is the Amount of Resource being deposited
+	// Amount is the Amount of Resource being deposited. For the template created by an attribute, it is the most a
+	// single deposit can leave; the planner takes the Deposit with an amount up to it.
 	Amount int
 	// ActionLocation is the Location the Resource is being deposited
 	ActionLocation *core.Location
 	// ActionCost is the ActionCost of taking the Action
 	ActionCost float64
+	// template is the Deposit this one was made from with WithAmount, or nil if it is a template
+	template *Deposit
 }
 
-// Force Deposit to implement Action
-var _ core.Action = (*Deposit)(nil)
+// Force Deposit to implement Action, Batched and Explaining
+var (
+	_ core.Action     = (*Deposit)(nil)
+	_ core.Batched    = (*Deposit)(nil)
+	_ core.Explaining = (*Deposit)(nil)
+)
 
-// Perform implements Action.Perform, and simulates the act of depositing a Resource in a location
+// Perform implements Action.Perform, and simulates the act of depositing a Resource in a location. If the location
+// doesn't have room for all of it, only what fits is deposited.
 func (d *Deposit) Perform(start *core.WorldState, agent core.Agent) *core.WorldState {
 	startAgent, ok := start.GetAgent(agent.Name())
 	if !ok {
 		return nil
 	}
-	amountToDeposit := minInt(startAgent.Inventory().GetAmount(d.DepResource), d.Amount)
-
-	if amountToDeposit <= 0 {
-		return nil // fail, no DepResource to deposit
-	}
-
 	startLoc, ok := start.GetLocation(d.ActionLocation.Name)
 	if !ok {
 		return nil
 	}
+	amountToDeposit, err := d.depositable(startLoc, startAgent, d.Amount)
+	if err != nil {
+		return nil
+	}
 
 	end := start.ShallowCopy()
 
@@ -92,3 +106,73 @@ func (d *Deposit) Location() *core.Location {
 func (d *Deposit) Resource() *core.Resource {
 	return d.DepResource
 }
+
+// FailureReason implements core.Explaining, and returns ErrNothingToDeposit or ErrLocationFull if the agent can't
+// deposit any of the Resource in the given state.
+func (d *Deposit) FailureReason(state *core.WorldState, agent core.Agent) error {
+	startAgent, ok := state.GetAgent(agent.Name())
+	if !ok {
+		return fmt.Errorf("agent %s is not in the world", agent.Name())
+	}
+	startLoc, ok := state.GetLocation(d.ActionLocation.Name)
+	if !ok {
+		return fmt.Errorf("location %s is not in the world", d.ActionLocation.Name)
+	}
+	_, err := d.depositable(startLoc, startAgent, d.Amount)
+	return err
+}
+
+// depositable returns how much of the Resource the agent can deposit at the location, up to amount: no more than it
+// carries, and no more than fits in the room the location has left. It returns an error if that is nothing.
+func (d *Deposit) depositable(loc *core.Location, agent core.Agent, amount int) (int, error) {
+	amount = minInt(amount, agent.Inventory().GetAmount(d.DepResource))
+	if amount <= 0 {
+		return 0, ErrNothingToDeposit
+	}
+	amount = minInt(amount, core.CanStore(loc, d.DepResource))
+	if amount <= 0 {
+		return 0, fmt.Errorf("%w: %s holds %g of %g", ErrLocationFull, loc.Name, core.InventoryWeight(loc.Inventory),
+			core.StorageCapacity(loc))
+	}
+	return amount, nil
+}
+
+// MaxAmount implements core.Batched, and returns the most the agent can deposit in the given state: the amount of the
+// template, bounded by how much of the Resource the agent carries and by the room left at the location.
+func (d *Deposit) MaxAmount(state *core.WorldState, agent core.Agent) int {
+	startLoc, ok := state.GetLocation(d.ActionLocation.Name)
+	if !ok {
+		return 0
+	}
+	startAgent, ok := state.GetAgent(agent.Name())
+	if !ok {
+		return 0
+	}
+	amount, err := d.depositable(startLoc, startAgent, d.Template().BatchAmount())
+	if err != nil {
+		return 0
+	}
+	return amount
+}
+
+// WithAmount implements core.Batched, and returns a copy of the template that deposits the given amount.
+func (d *Deposit) WithAmount(amount int) core.Batched {
+	template := d.Template().(*Deposit)
+	batch := *template
+	batch.Amount = amount
+	batch.template = template
+	return &batch
+}
+
+// Template implements core.Batched, and returns the Deposit this one was made from, or itself if it is a template.
+func (d *Deposit) Template() core.Batched {
+	if d.template != nil {
+		return d.template
+	}
+	return d
+}
+
+// BatchAmount implements core.Batched, and returns the Amount deposited.
+func (d *Deposit) BatchAmount() int {
+	return d.Amount
+}
@@ -130,3 +130,118 @@ func TestDeposit_String(t *testing.T) {
 		})
 	}
 }
+
+func TestDeposit_Batched(t *testing.T) {
+	type testCase struct {
+		deposit           *Deposit
+		amountInAgent     int
+		expectedMaxAmount int
+	}
+
+	testCases := map[string]testCase{
+		"bounded by the template": {
+			deposit:           testDeposit,
+			amountInAgent:     25,
+			expectedMaxAmount: 10,
+		},
+		"bounded by what the agent carries": {
+			deposit:           testDeposit,
+			amountInAgent:     4,
+			expectedMaxAmount: 4,
+		},
+		"nothing to deposit": {
+			deposit:           testDeposit,
+			expectedMaxAmount: 0,
+		},
+		"batch bounded by its template": {
+			deposit:           testDeposit.WithAmount(2).(*Deposit),
+			amountInAgent:     25,
+			expectedMaxAmount: 10,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			location := testLocation.DeepCopy()
+			agent := testAgent.DeepCopy()
+			agent.Inventory().AdjustAmount(testResource, tc.amountInAgent)
+			state := &core.WorldState{
+				Locations: map[string]*core.Location{location.Name: location},
+				Agents:    map[string]core.Agent{agent.Name(): agent},
+			}
+
+			assert.Equal(t, tc.expectedMaxAmount, tc.deposit.MaxAmount(state, agent))
+		})
+	}
+
+	batch := testDeposit.WithAmount(3)
+	assert.Equal(t, 3, batch.BatchAmount())
+	assert.Same(t, testDeposit, batch.Template())
+	assert.Equal(t, 10, testDeposit.Amount, "the template should be left unchanged")
+}
+
+func TestDeposit_Capacity(t *testing.T) {
+	stone := core.NewResource("stone", core.WithResourceAttributes(&Weight{Amount: 2}))
+	deposit := &Deposit{
+		DepResource:    stone,
+		Amount:         10,
+		ActionLocation: core.NewLocation("pit", core.Coord{}),
+		ActionCost:     1.0,
+	}
+
+	type testCase struct {
+		amountInLocation  int
+		amountInAgent     int
+		expectedDeposited int
+		expectedErr       error
+	}
+
+	testCases := map[string]testCase{
+		"room for everything": {
+			amountInLocation:  0,
+			amountInAgent:     10,
+			expectedDeposited: 10,
+		},
+		"partial deposit when nearly full": {
+			amountInLocation:  6,
+			amountInAgent:     10,
+			expectedDeposited: 4,
+		},
+		"fails when full": {
+			amountInLocation: 10,
+			amountInAgent:    10,
+			expectedErr:      ErrLocationFull,
+		},
+		"fails with nothing to deposit": {
+			amountInLocation: 0,
+			amountInAgent:    0,
+			expectedErr:      ErrNothingToDeposit,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			location := core.NewLocation("pit", core.Coord{}, core.WithAttributes(&Capacity{Size: 20}))
+			location.Inventory.AdjustAmount(stone, tc.amountInLocation)
+			agent := testAgent.DeepCopy()
+			agent.Inventory().AdjustAmount(stone, tc.amountInAgent)
+			state := &core.WorldState{
+				Locations: map[string]*core.Location{location.Name: location},
+				Agents:    map[string]core.Agent{agent.Name(): agent},
+			}
+
+			assert.ErrorIs(t, deposit.FailureReason(state, agent), tc.expectedErr)
+			assert.Equal(t, tc.expectedDeposited, deposit.MaxAmount(state, agent))
+
+			end := deposit.Perform(state, agent)
+			if tc.expectedErr != nil {
+				assert.Nil(t, end)
+				return
+			}
+			endLocation, _ := end.GetLocation("pit")
+			endAgent, _ := end.GetAgent(agent.Name())
+			assert.Equal(t, tc.amountInLocation+tc.expectedDeposited, endLocation.Inventory.GetAmount(stone))
+			assert.Equal(t, tc.amountInAgent-tc.expectedDeposited, endAgent.Inventory().GetAmount(stone))
+		})
+	}
+}
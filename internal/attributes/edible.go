@@ -0,0 +1,80 @@
+package attributes
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"Neolithic/internal/core"
+)
+
+const (
+	// EdibleAttributeType is the attribute type that corresponds to the Edible attribute
+	EdibleAttributeType core.AttributeType = "edible"
+
+	// defaultConsumeAmount is the default amount that the created Consume action will have
+	defaultConsumeAmount = 1
+	// defaultConsumeCost is the default cost of the created Consume action
+	defaultConsumeCost = 1
+)
+
+// Edible is an Attribute that indicates a resource can be eaten, and how nourishing a single unit of it is. It
+// corresponds to the Consume action, which uses up the resource the agent carries.
+type Edible struct {
+	// Nutrition is how much a single unit of the resource restores the need it satisfies
+	Nutrition float64
+}
+
+var _ core.NourishingAttribute = (*Edible)(nil)
+
+// NeedsLocation indicates whether Edible requires an additional location to create an action.
+func (e *Edible) NeedsLocation() bool {
+	return false
+}
+
+// NeedsResource indicates whether Edible requires a resource to create an action.
+func (e *Edible) NeedsResource() bool {
+	return false
+}
+
+// CreateAction creates the action of consuming the resource
+func (e *Edible) CreateAction(holder core.AttributeHolder, _ core.CreateActionParams) (core.Action, error) {
+	res, ok := holder.(*core.Resource)
+	if !ok {
+		return nil, errors.New("edible can only be applied to a resource")
+	}
+
+	return &Consume{
+		Res:        res,
+		Amount:     defaultConsumeAmount,
+		ActionCost: defaultConsumeCost,
+	}, nil
+}
+
+// UnitNutrition implements core.NourishingAttribute, and returns the nutrition of a single unit of the resource.
+func (e *Edible) UnitNutrition() float64 {
+	return e.Nutrition
+}
+
+// Type returns the EdibleAttributeType for the Edible attribute
+func (e *Edible) Type() core.AttributeType {
+	return EdibleAttributeType
+}
+
+// Copy returns a copy of the edible attribute
+func (e *Edible) Copy() core.Attribute {
+	return &Edible{Nutrition: e.Nutrition}
+}
+
+// Params returns the parameters of the edible attribute, implementing core.ParameterizedAttribute.
+func (e *Edible) Params() core.AttributeParams {
+	return core.AttributeParams{"nutrition": e.Nutrition}
+}
+
+// String returns a string representation of the edible attribute
+func (e *Edible) String() string {
+	var sb strings.Builder
+	sb.WriteString("Edible: ")
+	sb.WriteString(strconv.FormatFloat(e.Nutrition, 'f', -1, 64))
+	return sb.String()
+}
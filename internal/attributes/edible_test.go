@@ -0,0 +1,60 @@
+package attributes
+
+import (
+	"testing"
+
+	"Neolithic/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEdible_Needs(t *testing.T) {
+	e := Edible{}
+	assert.False(t, e.NeedsLocation())
+	assert.False(t, e.NeedsResource())
+	assert.Equal(t, EdibleAttributeType, e.Type())
+}
+
+func TestEdible_Copy(t *testing.T) {
+	e1 := &Edible{Nutrition: 0.25}
+	e2, ok := e1.Copy().(*Edible)
+	assert.True(t, ok)
+	assert.NotSame(t, e1, e2)
+	assert.Equal(t, e1, e2)
+	assert.Equal(t, "Edible: 0.25", e1.String())
+}
+
+func TestEdible_CreateAction(t *testing.T) {
+	berries := core.NewResource("Berries")
+
+	testCases := map[string]struct {
+		holder     core.AttributeHolder
+		wantAction core.Action
+		wantErr    string
+	}{
+		"creates consume": {
+			holder: berries,
+			wantAction: &Consume{
+				Res:        berries,
+				Amount:     defaultConsumeAmount,
+				ActionCost: defaultConsumeCost,
+			},
+		},
+		"holder not resource": {
+			holder:  &core.Location{},
+			wantErr: "edible can only be applied to a resource",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			e := &Edible{Nutrition: 0.5}
+			gotAction, gotErr := e.CreateAction(tc.holder, core.CreateActionParams{})
+			assert.Equal(t, tc.wantAction, gotAction)
+			if tc.wantErr != "" {
+				assert.EqualError(t, gotErr, tc.wantErr)
+			} else {
+				assert.NoError(t, gotErr)
+			}
+		})
+	}
+}
@@ -7,20 +7,30 @@ import (
 
 // Gather implements Action, and represents the act of gathering a Resource
 type Gather struct {
-	// Requires is an optional Resource that is required to perform the gather
+	// Requires is an optional Resource that is required to perform the gather, such as a tool
 	Requires *core.Resource
+	// UsesUpRequired indicates that every gather uses up one of the Requires resource, such as a tool that breaks
+	UsesUpRequired bool
 	// Res is the Resource being gathered
 	Res *core.Resource
-	// Amount is the Amount of the Resource being gathered
+	// Amount is the Amount of the Resource being gathered. For the template created by an attribute, it is the most a
+	// single gather can take; the planner takes the Gather with an amount up to it.
 	Amount int
 	// ActionLocation is the Location where the Resource is being gathered
 	ActionLocation *core.Location
 	// ActionCost is the cost of taking the Action
 	ActionCost float64
+	// template is the Gather this one was made from with WithAmount, or nil if it is a template
+	template *Gather
 }
 
-// Force Gather to implement Action
-var _ core.Action = (*Gather)(nil)
+// Force Gather to implement Action, Reserving, RequiresHeld and Batched
+var (
+	_ core.Action       = (*Gather)(nil)
+	_ core.Reserving    = (*Gather)(nil)
+	_ core.RequiresHeld = (*Gather)(nil)
+	_ core.Batched      = (*Gather)(nil)
+)
 
 // Perform implements Action.Perform, and simulates the act of gathering a Resource
 func (g *Gather) Perform(start *core.WorldState, agent core.Agent) *core.WorldState {
@@ -28,26 +38,33 @@ func (g *Gather) Perform(start *core.WorldState, agent core.Agent) *core.WorldSt
 	if !ok {
 		return nil
 	}
-	amountToGather := minInt(g.Amount, gatherLocation.Inventory.GetAmount(g.Res))
+	amountToGather := minInt(g.Amount, gatherLocation.Available(agent.Name(), g.Res))
 	if amountToGather <= 0 {
-		return nil // fail, no DepResource to gather
-	}
-
-	if g.Requires != nil && agent.Inventory().GetAmount(g.Requires) <= 0 {
-		return nil // fail, does not have the necessary tool
+		return nil // fail, no DepResource to gather that isn't reserved by someone else
 	}
 
 	startAgent, ok := start.GetAgent(agent.Name())
 	if !ok {
 		return nil
 	}
+	if g.Requires != nil && startAgent.Inventory().GetAmount(g.Requires) <= 0 {
+		return nil // fail, does not have the necessary tool
+	}
+	amountToGather = minInt(amountToGather, core.CanCarry(startAgent, g.Res))
+	if amountToGather <= 0 {
+		return nil // fail, the agent can't carry any more
+	}
 
 	endAgent := startAgent.DeepCopy()
 	endAgentInv := endAgent.Inventory()
 	endLocation := gatherLocation.DeepCopy()
 
 	endAgentInv.AdjustAmount(g.Res, amountToGather)
+	if g.Requires != nil && g.UsesUpRequired {
+		endAgentInv.AdjustAmount(g.Requires, -1)
+	}
 	endLocation.Inventory.AdjustAmount(g.Res, -amountToGather)
+	endLocation.Release(agent.Name(), g.Res, amountToGather)
 
 	end := start.ShallowCopy()
 	end.Locations[endLocation.Name] = endLocation
@@ -56,19 +73,24 @@ func (g *Gather) Perform(start *core.WorldState, agent core.Agent) *core.WorldSt
 	return end
 }
 
-// Cost implements Action.Cost, and returns the ActionCost of the gather Action
-func (g *Gather) Cost(_ core.Agent) float64 {
-	return g.ActionCost
+// Cost implements Action.Cost, and returns the ActionCost of the gather Action. Gathering costs more the more
+// encumbered the agent already is, up to twice as much when it is fully loaded.
+func (g *Gather) Cost(agent core.Agent) float64 {
+	return g.ActionCost * (1 + core.Encumbrance(agent))
 }
 
 // Description implements Action.Description, and provides a brief description of the gather Action
 func (g *Gather) Description() string {
+	if g.Requires != nil {
+		return fmt.Sprintf("gather %d %s from %s with %s", g.Amount, g.Res.Name, g.ActionLocation, g.Requires.Name)
+	}
 	return fmt.Sprintf("gather %d %s from %s", g.Amount, g.Res.Name, g.ActionLocation)
 }
 
-// GetChanges generates a list of state changes resulting from gathering a resource by a specified agent.
+// GetChanges generates a list of state changes resulting from gathering a resource by a specified agent. A gather that
+// uses up its required resource also takes one of it from the agent.
 func (g *Gather) GetChanges(agent core.Agent) []core.StateChange {
-	return []core.StateChange{
+	changes := []core.StateChange{
 		{
 			Entity:     agent.Name(),
 			EntityType: core.AgentEntity,
@@ -82,6 +104,15 @@ func (g *Gather) GetChanges(agent core.Agent) []core.StateChange {
 			Amount:     -g.Amount,
 		},
 	}
+	if g.Requires != nil && g.UsesUpRequired {
+		changes = append(changes, core.StateChange{
+			Entity:     agent.Name(),
+			EntityType: core.AgentEntity,
+			Resource:   g.Requires,
+			Amount:     -1,
+		})
+	}
+	return changes
 }
 
 // minInt returns the smaller of two integer values a and b.
@@ -101,3 +132,57 @@ func (g *Gather) Location() *core.Location {
 func (g *Gather) Resource() *core.Resource {
 	return g.Res
 }
+
+// ReservedAmount implements core.Reserving, and returns the amount of the Resource the gather takes.
+func (g *Gather) ReservedAmount() int {
+	return g.Amount
+}
+
+// HeldResources implements core.RequiresHeld, and returns the resource the gather requires, if any.
+func (g *Gather) HeldResources() []core.InventoryEntry {
+	if g.Requires == nil {
+		return nil
+	}
+	return []core.InventoryEntry{{Resource: g.Requires, Amount: 1}}
+}
+
+// MaxAmount implements core.Batched, and returns the most the agent can gather in the given state: the amount of the
+// template, bounded by what is available to the agent at the location and by how much more it can carry. It is 0 if
+// the agent lacks the required tool.
+func (g *Gather) MaxAmount(state *core.WorldState, agent core.Agent) int {
+	gatherLocation, ok := state.GetLocation(g.ActionLocation.Name)
+	if !ok {
+		return 0
+	}
+	startAgent, ok := state.GetAgent(agent.Name())
+	if !ok {
+		return 0
+	}
+	if g.Requires != nil && startAgent.Inventory().GetAmount(g.Requires) <= 0 {
+		return 0
+	}
+	amount := minInt(g.Template().BatchAmount(), gatherLocation.Available(agent.Name(), g.Res))
+	return max(0, minInt(amount, core.CanCarry(startAgent, g.Res)))
+}
+
+// WithAmount implements core.Batched, and returns a copy of the template that gathers the given amount.
+func (g *Gather) WithAmount(amount int) core.Batched {
+	template := g.Template().(*Gather)
+	batch := *template
+	batch.Amount = amount
+	batch.template = template
+	return &batch
+}
+
+// Template implements core.Batched, and returns the Gather this one was made from, or itself if it is a template.
+func (g *Gather) Template() core.Batched {
+	if g.template != nil {
+		return g.template
+	}
+	return g
+}
+
+// BatchAmount implements core.Batched, and returns the Amount gathered.
+func (g *Gather) BatchAmount() int {
+	return g.Amount
+}
@@ -25,6 +25,15 @@ func TestGather_Perform(t *testing.T) {
 		ActionCost:     1,
 	}
 
+	testGatherBreaks := &Gather{
+		Requires:       testTool,
+		UsesUpRequired: true,
+		Res:            testResource,
+		Amount:         5,
+		ActionLocation: testLocation.DeepCopy(),
+		ActionCost:     1,
+	}
+
 	type testCase struct {
 		testGather               *Gather
 		startLocation            *core.Location
@@ -32,8 +41,12 @@ func TestGather_Perform(t *testing.T) {
 		agent                    core.Agent
 		startAmountInAgent       int
 		toolInAgent              *core.Resource
+		reservedByOther          int
+		reservedByAgent          int
 		expectedAmountInLocation int
 		expectedAmountInAgent    int
+		expectedReservedByAgent  int
+		expectedToolInAgent      int
 		expectNil                bool
 	}
 
@@ -65,6 +78,18 @@ func TestGather_Perform(t *testing.T) {
 			toolInAgent:              testTool,
 			expectedAmountInLocation: 0,
 			expectedAmountInAgent:    5,
+			expectedToolInAgent:      1,
+		},
+		"gather uses up tool": {
+			testGather:               testGatherBreaks,
+			startLocation:            testLocation.DeepCopy(),
+			startAmountInLocation:    5,
+			agent:                    testAgent.DeepCopy(),
+			startAmountInAgent:       0,
+			toolInAgent:              testTool,
+			expectedAmountInLocation: 0,
+			expectedAmountInAgent:    5,
+			expectedToolInAgent:      0,
 		},
 		"gather fails, no resource in location": {
 			testGather:               testGather,
@@ -76,6 +101,33 @@ func TestGather_Perform(t *testing.T) {
 			expectedAmountInAgent:    0,
 			expectNil:                true,
 		},
+		"gather limited by another agent's reservation": {
+			testGather:               testGather,
+			startLocation:            testLocation.DeepCopy(),
+			startAmountInLocation:    5,
+			agent:                    testAgent.DeepCopy(),
+			reservedByOther:          3,
+			expectedAmountInLocation: 3,
+			expectedAmountInAgent:    2,
+		},
+		"gather fails, everything reserved by another agent": {
+			testGather:            testGather,
+			startLocation:         testLocation.DeepCopy(),
+			startAmountInLocation: 5,
+			agent:                 testAgent.DeepCopy(),
+			reservedByOther:       5,
+			expectNil:             true,
+		},
+		"gather uses up own reservation": {
+			testGather:               testGather,
+			startLocation:            testLocation.DeepCopy(),
+			startAmountInLocation:    8,
+			agent:                    testAgent.DeepCopy(),
+			reservedByAgent:          6,
+			expectedAmountInLocation: 3,
+			expectedAmountInAgent:    5,
+			expectedReservedByAgent:  1,
+		},
 		"gather fails, required tool not present": {
 			testGather:               testGatherRequires,
 			startLocation:            testLocation.DeepCopy(),
@@ -95,6 +147,8 @@ func TestGather_Perform(t *testing.T) {
 			if tc.toolInAgent != nil {
 				tc.agent.Inventory().AdjustAmount(tc.toolInAgent, 1)
 			}
+			tc.startLocation.Reserve("otherAgent", testResource, tc.reservedByOther)
+			tc.startLocation.Reserve(tc.agent.Name(), testResource, tc.reservedByAgent)
 			startState := &core.WorldState{
 				Locations: map[string]*core.Location{tc.startLocation.Name: tc.startLocation},
 				Agents:    map[string]core.Agent{tc.agent.Name(): tc.agent},
@@ -111,9 +165,171 @@ func TestGather_Perform(t *testing.T) {
 			endLoc, exists := endState.GetLocation(tc.startLocation.Name)
 			assert.True(t, exists)
 			assert.Equal(t, tc.expectedAmountInLocation, endLoc.Inventory.GetAmount(testResource))
+			assert.Equal(t, tc.expectedReservedByAgent, endLoc.ReservedBy(tc.agent.Name(), testResource))
 			endAgent, exists := endState.GetAgent(tc.agent.Name())
 			assert.True(t, exists)
 			assert.Equal(t, tc.expectedAmountInAgent, endAgent.Inventory().GetAmount(testResource))
+			if tc.toolInAgent != nil {
+				assert.Equal(t, tc.expectedToolInAgent, endAgent.Inventory().GetAmount(tc.toolInAgent))
+			}
+		})
+	}
+}
+
+func TestGather_RequiredTool(t *testing.T) {
+	testTool := &core.Resource{Name: "testTool"}
+	gather := &Gather{Requires: testTool, Res: testResource, Amount: 2, ActionLocation: &testLocation, ActionCost: 1}
+	breaks := &Gather{Requires: testTool, UsesUpRequired: true, Res: testResource, Amount: 2, ActionLocation: &testLocation, ActionCost: 1}
+	bare := &Gather{Res: testResource, Amount: 2, ActionLocation: &testLocation, ActionCost: 1}
+
+	assert.Equal(t, []core.InventoryEntry{{Resource: testTool, Amount: 1}}, gather.HeldResources())
+	assert.Nil(t, bare.HeldResources())
+
+	assert.Len(t, gather.GetChanges(testAgent), 2)
+	changes := breaks.GetChanges(testAgent)
+	assert.Len(t, changes, 3)
+	assert.Equal(t, core.StateChange{Entity: testAgent.Name(), EntityType: core.AgentEntity, Resource: testTool, Amount: -1}, changes[2])
+}
+
+func TestGather_Batched(t *testing.T) {
+	testTool := &core.Resource{Name: "testTool"}
+	template := &Gather{Res: testResource, Amount: 5, ActionLocation: &testLocation, ActionCost: 1}
+	withTool := &Gather{Requires: testTool, Res: testResource, Amount: 5, ActionLocation: &testLocation, ActionCost: 1}
+
+	type testCase struct {
+		gather            *Gather
+		amountInLocation  int
+		reservedByOther   int
+		toolInAgent       bool
+		expectedMaxAmount int
+	}
+
+	testCases := map[string]testCase{
+		"bounded by the template": {
+			gather:            template,
+			amountInLocation:  20,
+			expectedMaxAmount: 5,
+		},
+		"bounded by the location": {
+			gather:            template,
+			amountInLocation:  3,
+			expectedMaxAmount: 3,
+		},
+		"bounded by reservations": {
+			gather:            template,
+			amountInLocation:  6,
+			reservedByOther:   4,
+			expectedMaxAmount: 2,
+		},
+		"batch bounded by its template": {
+			gather:            template.WithAmount(2).(*Gather),
+			amountInLocation:  20,
+			expectedMaxAmount: 5,
+		},
+		"nothing without the tool": {
+			gather:            withTool,
+			amountInLocation:  20,
+			expectedMaxAmount: 0,
+		},
+		"with the tool": {
+			gather:            withTool,
+			amountInLocation:  20,
+			toolInAgent:       true,
+			expectedMaxAmount: 5,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			location := testLocation.DeepCopy()
+			location.Inventory.AdjustAmount(testResource, tc.amountInLocation)
+			if tc.reservedByOther > 0 {
+				location.Reserve("otherAgent", testResource, tc.reservedByOther)
+			}
+			agent := testAgent.DeepCopy()
+			if tc.toolInAgent {
+				agent.Inventory().AdjustAmount(testTool, 1)
+			}
+			state := &core.WorldState{
+				Locations: map[string]*core.Location{location.Name: location},
+				Agents:    map[string]core.Agent{agent.Name(): agent},
+			}
+
+			assert.Equal(t, tc.expectedMaxAmount, tc.gather.MaxAmount(state, agent))
+		})
+	}
+
+	batch := template.WithAmount(3)
+	assert.Equal(t, 3, batch.BatchAmount())
+	assert.Same(t, template, batch.Template())
+	assert.Same(t, template, batch.WithAmount(4).Template())
+	assert.Same(t, template, template.Template())
+	assert.Contains(t, batch.Description(), "gather 3 testResource from")
+	assert.Equal(t, 5, template.Amount, "the template should be left unchanged")
+}
+
+func TestGather_CarryCapacity(t *testing.T) {
+	stone := core.NewResource("stone", core.WithResourceAttributes(&Weight{Amount: 2}))
+	template := &Gather{Res: stone, Amount: 5, ActionLocation: &testLocation, ActionCost: 3}
+
+	type testCase struct {
+		carrying          int
+		capacity          float64
+		expectedMaxAmount int
+		expectedGathered  int
+		expectedCost      float64
+	}
+
+	testCases := map[string]testCase{
+		"unlimited carrier": {
+			carrying:          4,
+			expectedMaxAmount: 5,
+			expectedGathered:  5,
+			expectedCost:      3,
+		},
+		"empty carrier with room for all of it": {
+			capacity:          20,
+			expectedMaxAmount: 5,
+			expectedGathered:  5,
+			expectedCost:      3,
+		},
+		"bounded by what the carrier can carry": {
+			carrying:          2,
+			capacity:          10,
+			expectedMaxAmount: 3,
+			expectedGathered:  3,
+			expectedCost:      4.2,
+		},
+		"fully loaded carrier can't gather": {
+			carrying:          5,
+			capacity:          10,
+			expectedMaxAmount: 0,
+			expectedCost:      6,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			location := testLocation.DeepCopy()
+			location.Inventory.AdjustAmount(stone, 20)
+			agent := &mockCarrier{mockAgent: mockAgent{N: "testCarrier", inventory: core.NewInventory()}, capacity: tc.capacity}
+			agent.Inventory().AdjustAmount(stone, tc.carrying)
+			state := &core.WorldState{
+				Locations: map[string]*core.Location{location.Name: location},
+				Agents:    map[string]core.Agent{agent.Name(): agent},
+			}
+
+			assert.Equal(t, tc.expectedMaxAmount, template.MaxAmount(state, agent))
+			assert.InDelta(t, tc.expectedCost, template.Cost(agent), 1e-9)
+
+			endState := template.Perform(state, agent)
+			if tc.expectedGathered == 0 {
+				assert.Nil(t, endState)
+				return
+			}
+			assert.NotNil(t, endState)
+			endAgent, _ := endState.GetAgent(agent.Name())
+			assert.Equal(t, tc.carrying+tc.expectedGathered, endAgent.Inventory().GetAmount(stone))
 		})
 	}
 }
@@ -0,0 +1,177 @@
+package attributes
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"Neolithic/internal/core"
+)
+
+const (
+	// RecipeAttributeType is the attribute type that corresponds to the Recipe attribute
+	RecipeAttributeType core.AttributeType = "recipe"
+
+	// defaultCraftCost is the default cost of the Craft action created from a Recipe
+	defaultCraftCost = 1
+)
+
+// Recipe is an Attribute that turns a location into a workshop, where an agent can craft the Outputs from the Inputs
+// it carries. It corresponds to the Craft action, which is created once every resource the recipe names has been
+// registered. A location holds at most one recipe.
+type Recipe struct {
+	// Name is the name of the recipe, used in the description of the Craft action
+	Name string
+	// Inputs are the amounts of each resource, by name, that crafting takes from the agent
+	Inputs map[string]int
+	// Outputs are the amounts of each resource, by name, that crafting gives the agent
+	Outputs map[string]int
+	// Duration is how long, in seconds, crafting takes
+	Duration float64
+	// Cost is the cost of the Craft action
+	Cost float64
+}
+
+// Force Recipe to implement ResourceListAttribute
+var _ core.ResourceListAttribute = (*Recipe)(nil)
+
+// NeedsLocation indicates whether Recipe requires an additional location to create an action.
+func (r *Recipe) NeedsLocation() bool {
+	return false
+}
+
+// NeedsResource indicates whether Recipe requires a resource to create an action. The resources of a recipe are
+// provided through ResourceNames instead.
+func (r *Recipe) NeedsResource() bool {
+	return false
+}
+
+// ResourceNames implements core.ResourceListAttribute, and returns the sorted names of every input and output.
+func (r *Recipe) ResourceNames() []string {
+	seen := map[string]bool{}
+	names := make([]string, 0, len(r.Inputs)+len(r.Outputs))
+	for _, amounts := range []map[string]int{r.Inputs, r.Outputs} {
+		for name := range amounts {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CreateAction creates the action of crafting the recipe at the location holding it
+func (r *Recipe) CreateAction(holder core.AttributeHolder, params core.CreateActionParams) (core.Action, error) {
+	loc, ok := holder.(*core.Location)
+	if !ok {
+		return nil, errors.New("recipe can only be applied to a location")
+	}
+
+	inputs, err := recipeEntries(r.Inputs, params.Resources)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := recipeEntries(r.Outputs, params.Resources)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Craft{
+		Recipe:         r.Name,
+		Inputs:         inputs,
+		Outputs:        outputs,
+		ActionLocation: loc,
+		ActionCost:     r.Cost,
+		Duration:       r.Duration,
+	}, nil
+}
+
+// recipeEntries resolves the named amounts of a recipe to inventory entries, sorted by resource name. Amounts of zero
+// are left out.
+func recipeEntries(amounts map[string]int, resources map[string]*core.Resource) ([]core.InventoryEntry, error) {
+	entries := make([]core.InventoryEntry, 0, len(amounts))
+	for name, amount := range amounts {
+		if amount <= 0 {
+			continue
+		}
+		res, ok := resources[name]
+		if !ok {
+			return nil, fmt.Errorf("recipe resource %q was not provided", name)
+		}
+		entries = append(entries, core.InventoryEntry{Resource: res, Amount: amount})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Resource.Name < entries[j].Resource.Name })
+	return entries, nil
+}
+
+// Type returns the RecipeAttributeType for the Recipe attribute
+func (r *Recipe) Type() core.AttributeType {
+	return RecipeAttributeType
+}
+
+// Copy returns a copy of the recipe attribute
+func (r *Recipe) Copy() core.Attribute {
+	return &Recipe{
+		Name:     r.Name,
+		Inputs:   copyAmounts(r.Inputs),
+		Outputs:  copyAmounts(r.Outputs),
+		Duration: r.Duration,
+		Cost:     r.Cost,
+	}
+}
+
+// Params returns the parameters of the recipe attribute, implementing core.ParameterizedAttribute.
+func (r *Recipe) Params() core.AttributeParams {
+	return core.AttributeParams{
+		"name":     r.Name,
+		"inputs":   copyAmounts(r.Inputs),
+		"outputs":  copyAmounts(r.Outputs),
+		"duration": r.Duration,
+		"cost":     r.Cost,
+	}
+}
+
+// String returns a string representation of the recipe attribute
+func (r *Recipe) String() string {
+	var sb strings.Builder
+	sb.WriteString("Recipe: ")
+	sb.WriteString(r.Name)
+	sb.WriteString(" ")
+	writeAmounts(&sb, r.Inputs)
+	sb.WriteString(" -> ")
+	writeAmounts(&sb, r.Outputs)
+	return sb.String()
+}
+
+// writeAmounts writes named amounts to sb, sorted by name
+func writeAmounts(sb *strings.Builder, amounts map[string]int) {
+	names := make([]string, 0, len(amounts))
+	for name := range amounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sb.WriteString("{")
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(name)
+		sb.WriteString(": ")
+		sb.WriteString(strconv.Itoa(amounts[name]))
+	}
+	sb.WriteString("}")
+}
+
+// copyAmounts returns a copy of named amounts
+func copyAmounts(amounts map[string]int) map[string]int {
+	copied := make(map[string]int, len(amounts))
+	for name, amount := range amounts {
+		copied[name] = amount
+	}
+	return copied
+}
@@ -0,0 +1,98 @@
+package attributes
+
+import (
+	"testing"
+
+	"Neolithic/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecipe_Needs(t *testing.T) {
+	r := Recipe{}
+	assert.False(t, r.NeedsLocation())
+	assert.False(t, r.NeedsResource())
+	assert.Equal(t, RecipeAttributeType, r.Type())
+}
+
+func TestRecipe_ResourceNames(t *testing.T) {
+	r := &Recipe{
+		Inputs:  map[string]int{"Wood": 2, "Stone": 1},
+		Outputs: map[string]int{"Stone Axe": 1, "Wood": 1},
+	}
+	assert.Equal(t, []string{"Stone", "Stone Axe", "Wood"}, r.ResourceNames())
+}
+
+func TestRecipe_Copy(t *testing.T) {
+	r1 := &Recipe{
+		Name:     "axe",
+		Inputs:   map[string]int{"Wood": 2, "Stone": 1},
+		Outputs:  map[string]int{"Stone Axe": 1},
+		Duration: 3,
+		Cost:     2,
+	}
+	r2, ok := r1.Copy().(*Recipe)
+	assert.True(t, ok)
+	assert.NotSame(t, r1, r2)
+	assert.Equal(t, r1, r2)
+
+	r2.Inputs["Wood"] = 5
+	assert.Equal(t, 2, r1.Inputs["Wood"], "copy should not share inputs")
+	assert.Equal(t, "Recipe: axe {Stone: 1, Wood: 2} -> {Stone Axe: 1}", r1.String())
+}
+
+func TestRecipe_CreateAction(t *testing.T) {
+	wood := core.NewResource("Wood")
+	stone := core.NewResource("Stone")
+	axe := core.NewResource("Stone Axe")
+	workshop := core.NewLocation("workshop", core.Coord{})
+
+	recipe := &Recipe{
+		Name:     "axe",
+		Inputs:   map[string]int{"Wood": 2, "Stone": 1},
+		Outputs:  map[string]int{"Stone Axe": 1},
+		Duration: 3,
+		Cost:     2,
+	}
+
+	testCases := map[string]struct {
+		holder     core.AttributeHolder
+		resources  map[string]*core.Resource
+		wantAction core.Action
+		wantErr    string
+	}{
+		"creates craft": {
+			holder:    workshop,
+			resources: map[string]*core.Resource{"Wood": wood, "Stone": stone, "Stone Axe": axe},
+			wantAction: &Craft{
+				Recipe:         "axe",
+				Inputs:         []core.InventoryEntry{{Resource: stone, Amount: 1}, {Resource: wood, Amount: 2}},
+				Outputs:        []core.InventoryEntry{{Resource: axe, Amount: 1}},
+				ActionLocation: workshop,
+				ActionCost:     2,
+				Duration:       3,
+			},
+		},
+		"holder not location": {
+			holder:  wood,
+			wantErr: "recipe can only be applied to a location",
+		},
+		"missing resource": {
+			holder:    workshop,
+			resources: map[string]*core.Resource{"Wood": wood, "Stone": stone},
+			wantErr:   `recipe resource "Stone Axe" was not provided`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			gotAction, gotErr := recipe.CreateAction(tc.holder, core.CreateActionParams{Resources: tc.resources})
+			if tc.wantErr != "" {
+				assert.Nil(t, gotAction)
+				assert.EqualError(t, gotErr, tc.wantErr)
+				return
+			}
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tc.wantAction, gotAction)
+		})
+	}
+}
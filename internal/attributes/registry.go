@@ -0,0 +1,142 @@
+package attributes
+
+import (
+	"fmt"
+
+	"Neolithic/internal/core"
+)
+
+var (
+	// errNoOutputs is returned when a recipe has no outputs.
+	errNoOutputs = fmt.Errorf("%w: a recipe must have at least one output", core.ErrInvalidParam)
+	// errNegative is returned when a parameter that can't be negative is.
+	errNegative = fmt.Errorf("%w: must not be negative", core.ErrInvalidParam)
+	// errAmplitude is returned when a seasonal amplitude is outside of 0 to 1.
+	errAmplitude = fmt.Errorf("%w: must be between 0 and 1", core.ErrInvalidParam)
+)
+
+// Schemas returns the schemas of every attribute provided by this package.
+func Schemas() []core.AttributeSchema {
+	return []core.AttributeSchema{
+		{
+			Type:        CapacityAttributeType,
+			Description: "Allows resources to be deposited at a location, up to a maximum weight.",
+			Params: []core.ParamSpec{
+				{Name: "size", Kind: core.NumberParam, Required: true, Description: "maximum weight the location can hold"},
+			},
+			Factory: func(params core.AttributeParams) (core.Attribute, error) {
+				return &Capacity{Size: params.Float("size")}, nil
+			},
+		},
+		{
+			Type:        EdibleAttributeType,
+			Description: "Makes a resource edible, allowing agents to consume it to satisfy their needs.",
+			Params: []core.ParamSpec{
+				{Name: "nutrition", Kind: core.NumberParam, Required: true, Description: "how much a single unit restores the need it satisfies"},
+			},
+			Factory: func(params core.AttributeParams) (core.Attribute, error) {
+				return &Edible{Nutrition: params.Float("nutrition")}, nil
+			},
+		},
+		{
+			Type:        RecipeAttributeType,
+			Description: "Turns a location into a workshop, where agents can craft resources from other resources they carry.",
+			Params: []core.ParamSpec{
+				{Name: "name", Kind: core.StringParam, Required: true, Description: "name of the recipe"},
+				{Name: "inputs", Kind: core.AmountsParam, Description: "amount of each resource, by name, taken from the agent"},
+				{Name: "outputs", Kind: core.AmountsParam, Required: true, Description: "amount of each resource, by name, given to the agent"},
+				{Name: "duration", Kind: core.NumberParam, Default: 0.0, Description: "seconds crafting takes"},
+				{Name: "cost", Kind: core.NumberParam, Default: float64(defaultCraftCost), Description: "cost of crafting, used by the planner"},
+			},
+			Factory: func(params core.AttributeParams) (core.Attribute, error) {
+				outputs := params.Amounts("outputs")
+				if len(outputs) == 0 {
+					return nil, &core.ParamError{Param: "outputs", Err: errNoOutputs}
+				}
+				return &Recipe{
+					Name:     params.String("name"),
+					Inputs:   params.Amounts("inputs"),
+					Outputs:  outputs,
+					Duration: params.Float("duration"),
+					Cost:     params.Float("cost"),
+				}, nil
+			},
+		},
+		{
+			Type:        RegrowthAttributeType,
+			Description: "Makes a resource grow back at a location over time, up to a cap, optionally following the seasons.",
+			Params: []core.ParamSpec{
+				{Name: "resource", Kind: core.StringParam, Required: true, Description: "name of the resource that grows back"},
+				{Name: "rate", Kind: core.NumberParam, Required: true, Description: "average units that grow back every second"},
+				{Name: "cap", Kind: core.NumberParam, Required: true, Description: "amount at the location above which nothing grows back"},
+				{Name: "season_length", Kind: core.NumberParam, Default: 0.0, Description: "seconds in a full cycle of seasons; 0 for no seasons"},
+				{Name: "season_amplitude", Kind: core.NumberParam, Default: 0.0, Description: "how far the rate swings with the seasons, as a fraction of it, from 0 to 1"},
+				{Name: "season_offset", Kind: core.NumberParam, Default: 0.0, Description: "seconds into the cycle of seasons the simulation starts at"},
+			},
+			Factory: func(params core.AttributeParams) (core.Attribute, error) {
+				for _, name := range []string{"rate", "cap", "season_length"} {
+					if params.Float(name) < 0 {
+						return nil, &core.ParamError{Param: name, Err: errNegative}
+					}
+				}
+				if amplitude := params.Float("season_amplitude"); amplitude < 0 || amplitude > 1 {
+					return nil, &core.ParamError{Param: "season_amplitude", Err: errAmplitude}
+				}
+				return &Regrowth{
+					Resource:        params.String("resource"),
+					Rate:            params.Float("rate"),
+					Cap:             int(params.Float("cap")),
+					SeasonLength:    params.Float("season_length"),
+					SeasonAmplitude: params.Float("season_amplitude"),
+					SeasonOffset:    params.Float("season_offset"),
+				}, nil
+			},
+		},
+		{
+			Type:        ToolAttributeType,
+			Description: "Names the tool used to gather a resource, and how it changes gathering.",
+			Params: []core.ParamSpec{
+				{Name: "resource", Kind: core.StringParam, Required: true, Description: "name of the tool resource"},
+				{Name: "required", Kind: core.BoolParam, Default: true, Description: "whether the resource can only be gathered with the tool"},
+				{Name: "speed", Kind: core.NumberParam, Default: float64(defaultToolSpeed), Description: "factor the amount gathered at once is multiplied by"},
+				{Name: "cost", Kind: core.NumberParam, Default: float64(defaultToolCost), Description: "factor the cost of gathering is multiplied by"},
+				{Name: "breaks", Kind: core.BoolParam, Default: false, Description: "whether every gather uses up the tool"},
+			},
+			Factory: func(params core.AttributeParams) (core.Attribute, error) {
+				return &Tool{
+					Resource: params.String("resource"),
+					Required: params.Bool("required"),
+					Speed:    params.Float("speed"),
+					Cost:     params.Float("cost"),
+					Breaks:   params.Bool("breaks"),
+				}, nil
+			},
+		},
+		{
+			Type:        WeightAttributeType,
+			Description: "Gives a resource a weight, allowing it to be gathered.",
+			Params: []core.ParamSpec{
+				{Name: "amount", Kind: core.NumberParam, Required: true, Description: "weight of a single unit of the resource"},
+				{Name: "batch", Kind: core.NumberParam, Default: 0.0, Description: "most units gathered or deposited at once; 0 for the default"},
+			},
+			Factory: func(params core.AttributeParams) (core.Attribute, error) {
+				if params.Float("batch") < 0 {
+					return nil, &core.ParamError{Param: "batch", Err: errNegative}
+				}
+				return &Weight{Amount: params.Float("amount"), Batch: int(params.Float("batch"))}, nil
+			},
+		},
+	}
+}
+
+// NewRegistry creates an AttributeRegistry with every attribute provided by this package registered. Additional
+// attribute types can be registered on the result.
+func NewRegistry() *core.AttributeRegistry {
+	registry := core.NewAttributeRegistry()
+	for _, schema := range Schemas() {
+		if err := registry.Register(schema); err != nil {
+			panic(err) // the built-in schemas are distinct, so this is a programming error
+		}
+	}
+	return registry
+}
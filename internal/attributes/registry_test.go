@@ -0,0 +1,139 @@
+package attributes
+
+import (
+	"testing"
+
+	"Neolithic/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegistry(t *testing.T) {
+	type testCase struct {
+		attrType core.AttributeType
+		params   core.AttributeParams
+		expected core.Attribute
+	}
+
+	tests := map[string]testCase{
+		"creates capacity": {
+			attrType: CapacityAttributeType,
+			params:   core.AttributeParams{"size": 100},
+			expected: &Capacity{Size: 100},
+		},
+		"creates edible": {
+			attrType: EdibleAttributeType,
+			params:   core.AttributeParams{"nutrition": 0.25},
+			expected: &Edible{Nutrition: 0.25},
+		},
+		"creates recipe": {
+			attrType: RecipeAttributeType,
+			params: core.AttributeParams{
+				"name":    "axe",
+				"inputs":  map[string]any{"Wood": 2, "Stone": 1},
+				"outputs": map[string]any{"Stone Axe": 1},
+			},
+			expected: &Recipe{
+				Name:    "axe",
+				Inputs:  map[string]int{"Wood": 2, "Stone": 1},
+				Outputs: map[string]int{"Stone Axe": 1},
+				Cost:    defaultCraftCost,
+			},
+		},
+		"creates regrowth": {
+			attrType: RegrowthAttributeType,
+			params:   core.AttributeParams{"resource": "Berries", "rate": 0.5, "cap": 100, "season_length": 600, "season_amplitude": 0.5},
+			expected: &Regrowth{Resource: "Berries", Rate: 0.5, Cap: 100, SeasonLength: 600, SeasonAmplitude: 0.5},
+		},
+		"creates tool": {
+			attrType: ToolAttributeType,
+			params:   core.AttributeParams{"resource": "Stone Axe", "speed": 2},
+			expected: &Tool{Resource: "Stone Axe", Required: true, Speed: 2, Cost: defaultToolCost},
+		},
+		"creates weight": {
+			attrType: WeightAttributeType,
+			params:   core.AttributeParams{"amount": 1.5},
+			expected: &Weight{Amount: 1.5},
+		},
+		"creates weight with batch": {
+			attrType: WeightAttributeType,
+			params:   core.AttributeParams{"amount": 1.5, "batch": 4},
+			expected: &Weight{Amount: 1.5, Batch: 4},
+		},
+	}
+
+	registry := NewRegistry()
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			attr, err := registry.New(tc.attrType, tc.params)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, attr)
+
+			params, err := registry.ParamsOf(attr)
+			require.NoError(t, err)
+			roundTripped, err := registry.New(tc.attrType, params)
+			require.NoError(t, err)
+			assert.Equal(t, attr, roundTripped)
+		})
+	}
+}
+
+func TestNewRegistry_RecipeWithoutOutputs(t *testing.T) {
+	registry := NewRegistry()
+	_, err := registry.New(RecipeAttributeType, core.AttributeParams{
+		"name":    "nothing",
+		"inputs":  map[string]any{"Wood": 1},
+		"outputs": map[string]any{},
+	})
+
+	var paramErr *core.ParamError
+	require.ErrorAs(t, err, &paramErr)
+	assert.Equal(t, "outputs", paramErr.Param)
+	assert.ErrorIs(t, err, core.ErrInvalidParam)
+}
+
+func TestNewRegistry_NegativeBatch(t *testing.T) {
+	registry := NewRegistry()
+	_, err := registry.New(WeightAttributeType, core.AttributeParams{"amount": 1, "batch": -2})
+
+	var paramErr *core.ParamError
+	require.ErrorAs(t, err, &paramErr)
+	assert.Equal(t, "batch", paramErr.Param)
+	assert.ErrorIs(t, err, core.ErrInvalidParam)
+}
+
+func TestNewRegistry_InvalidRegrowth(t *testing.T) {
+	type testCase struct {
+		params        core.AttributeParams
+		expectedParam string
+	}
+
+	tests := map[string]testCase{
+		"negative rate": {
+			params:        core.AttributeParams{"resource": "Berries", "rate": -1, "cap": 100},
+			expectedParam: "rate",
+		},
+		"negative cap": {
+			params:        core.AttributeParams{"resource": "Berries", "rate": 1, "cap": -5},
+			expectedParam: "cap",
+		},
+		"amplitude above one": {
+			params:        core.AttributeParams{"resource": "Berries", "rate": 1, "cap": 100, "season_length": 60, "season_amplitude": 1.5},
+			expectedParam: "season_amplitude",
+		},
+	}
+
+	registry := NewRegistry()
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := registry.New(RegrowthAttributeType, tc.params)
+
+			var paramErr *core.ParamError
+			require.ErrorAs(t, err, &paramErr)
+			assert.Equal(t, tc.expectedParam, paramErr.Param)
+			assert.ErrorIs(t, err, core.ErrInvalidParam)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package attributes
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"Neolithic/internal/core"
+)
+
+// RegrowthAttributeType is the attribute type that corresponds to the Regrowth attribute
+const RegrowthAttributeType core.AttributeType = "regrowth"
+
+// Regrowth is an Attribute of a location whose Resource grows back over simulated time, such as berries on a bush, up
+// to a Cap. Growth can follow the seasons: the rate rises and falls along a sine curve that repeats every
+// SeasonLength seconds. Regrowth corresponds to no action; the engine applies it between ticks, so the planner treats
+// the location as static. A location holds at most one regrowth.
+type Regrowth struct {
+	// Resource is the name of the resource that grows back
+	Resource string
+	// Rate is the average number of units that grow back every second
+	Rate float64
+	// Cap is the amount at the location above which nothing grows back
+	Cap int
+	// SeasonLength is the length, in seconds, of a full cycle of seasons. Zero means growth doesn't follow the seasons.
+	SeasonLength float64
+	// SeasonAmplitude is how far the rate swings with the seasons, as a fraction of Rate, from 0 to 1
+	SeasonAmplitude float64
+	// SeasonOffset is how far, in seconds, into the cycle of seasons the simulation starts
+	SeasonOffset float64
+}
+
+// Force Regrowth to implement ResourceListAttribute and RegeneratingAttribute
+var (
+	_ core.ResourceListAttribute = (*Regrowth)(nil)
+	_ core.RegeneratingAttribute = (*Regrowth)(nil)
+)
+
+// NeedsLocation indicates whether Regrowth requires an additional location to create an action.
+func (r *Regrowth) NeedsLocation() bool {
+	return false
+}
+
+// NeedsResource indicates whether Regrowth requires a resource to create an action.
+func (r *Regrowth) NeedsResource() bool {
+	return false
+}
+
+// ResourceNames implements core.ResourceListAttribute, and returns the name of the resource that grows back, so that
+// it is checked to exist.
+func (r *Regrowth) ResourceNames() []string {
+	return []string{r.Resource}
+}
+
+// CreateAction creates no action, since regrowth isn't something an agent does
+func (r *Regrowth) CreateAction(_ core.AttributeHolder, _ core.CreateActionParams) (core.Action, error) {
+	return nil, nil
+}
+
+// Regenerate implements core.RegeneratingAttribute, and returns the whole units of the resource that grow back at the
+// location over deltaTime seconds, starting elapsed seconds into the simulation. Growth is measured from the start of
+// the simulation, so fractions of a unit carry over from one tick to the next, and the location never grows past Cap.
+func (r *Regrowth) Regenerate(loc *core.Location, resource func(name string) (*core.Resource, bool), elapsed, deltaTime float64) []core.InventoryEntry {
+	res, ok := resource(r.Resource)
+	if !ok {
+		return nil
+	}
+	room := r.Cap - loc.Inventory.GetAmount(res)
+	if room <= 0 {
+		return nil
+	}
+
+	grown := int(math.Floor(r.grownBy(elapsed+deltaTime)) - math.Floor(r.grownBy(elapsed)))
+	if grown <= 0 {
+		return nil
+	}
+	return []core.InventoryEntry{{Resource: res, Amount: min(grown, room)}}
+}
+
+// grownBy returns how much has grown back in total by the given time, ignoring the Cap. It is the integral of the
+// seasonal growth rate, Rate * (1 + SeasonAmplitude * sin(2π(t + SeasonOffset) / SeasonLength)).
+func (r *Regrowth) grownBy(t float64) float64 {
+	if r.SeasonLength <= 0 || r.SeasonAmplitude == 0 {
+		return r.Rate * t
+	}
+	angularFrequency := 2 * math.Pi / r.SeasonLength
+	return r.Rate * (t - r.SeasonAmplitude/angularFrequency*math.Cos(angularFrequency*(t+r.SeasonOffset)))
+}
+
+// Type returns the RegrowthAttributeType for the Regrowth attribute
+func (r *Regrowth) Type() core.AttributeType {
+	return RegrowthAttributeType
+}
+
+// Copy returns a copy of the regrowth attribute
+func (r *Regrowth) Copy() core.Attribute {
+	copied := *r
+	return &copied
+}
+
+// Params returns the parameters of the regrowth attribute, implementing core.ParameterizedAttribute.
+func (r *Regrowth) Params() core.AttributeParams {
+	return core.AttributeParams{
+		"resource":         r.Resource,
+		"rate":             r.Rate,
+		"cap":              float64(r.Cap),
+		"season_length":    r.SeasonLength,
+		"season_amplitude": r.SeasonAmplitude,
+		"season_offset":    r.SeasonOffset,
+	}
+}
+
+// String returns a string representation of the regrowth attribute
+func (r *Regrowth) String() string {
+	var sb strings.Builder
+	sb.WriteString("Regrowth: ")
+	sb.WriteString(r.Resource)
+	sb.WriteString(" ")
+	sb.WriteString(strconv.FormatFloat(r.Rate, 'f', -1, 64))
+	sb.WriteString("/s up to ")
+	sb.WriteString(strconv.Itoa(r.Cap))
+	if r.SeasonLength > 0 && r.SeasonAmplitude != 0 {
+		sb.WriteString(", ±")
+		sb.WriteString(strconv.FormatFloat(r.SeasonAmplitude*100, 'f', -1, 64))
+		sb.WriteString("% over ")
+		sb.WriteString(strconv.FormatFloat(r.SeasonLength, 'f', -1, 64))
+		sb.WriteString("s seasons")
+	}
+	return sb.String()
+}
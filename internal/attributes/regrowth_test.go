@@ -0,0 +1,113 @@
+package attributes
+
+import (
+	"testing"
+
+	"Neolithic/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegrowth_Needs(t *testing.T) {
+	r := Regrowth{Resource: "Berries"}
+	assert.False(t, r.NeedsLocation())
+	assert.False(t, r.NeedsResource())
+	assert.Equal(t, RegrowthAttributeType, r.Type())
+	assert.Equal(t, []string{"Berries"}, r.ResourceNames())
+
+	action, err := r.CreateAction(core.NewLocation("bush", core.Coord{}), core.CreateActionParams{})
+	assert.NoError(t, err)
+	assert.Nil(t, action, "regrowth should create no action")
+}
+
+func TestRegrowth_Copy(t *testing.T) {
+	r1 := &Regrowth{Resource: "Berries", Rate: 0.5, Cap: 100, SeasonLength: 600, SeasonAmplitude: 0.8, SeasonOffset: 150}
+	r2, ok := r1.Copy().(*Regrowth)
+	assert.True(t, ok)
+	assert.NotSame(t, r1, r2)
+	assert.Equal(t, r1, r2)
+	assert.Equal(t, "Regrowth: Berries 0.5/s up to 100, ±80% over 600s seasons", r1.String())
+	assert.Equal(t, "Regrowth: Berries 0.5/s up to 100", (&Regrowth{Resource: "Berries", Rate: 0.5, Cap: 100}).String())
+}
+
+func TestRegrowth_Regenerate(t *testing.T) {
+	berries := core.NewResource("Berries")
+	lookup := func(name string) (*core.Resource, bool) {
+		if name == berries.Name {
+			return berries, true
+		}
+		return nil, false
+	}
+
+	type testCase struct {
+		regrowth       *Regrowth
+		startAmount    int
+		elapsed        float64
+		deltaTime      float64
+		expectedAmount int
+	}
+
+	tests := map[string]testCase{
+		"grows at rate": {
+			regrowth:       &Regrowth{Resource: "Berries", Rate: 2, Cap: 100},
+			elapsed:        10,
+			deltaTime:      3,
+			expectedAmount: 6,
+		},
+		"fractions carry over between ticks": {
+			regrowth:       &Regrowth{Resource: "Berries", Rate: 0.5, Cap: 100},
+			elapsed:        1.5,
+			deltaTime:      0.75,
+			expectedAmount: 1,
+		},
+		"less than a unit grows": {
+			regrowth:  &Regrowth{Resource: "Berries", Rate: 0.5, Cap: 100},
+			elapsed:   0.5,
+			deltaTime: 0.75,
+		},
+		"stops at cap": {
+			regrowth:       &Regrowth{Resource: "Berries", Rate: 2, Cap: 10},
+			startAmount:    8,
+			deltaTime:      5,
+			expectedAmount: 2,
+		},
+		"nothing grows past cap": {
+			regrowth:    &Regrowth{Resource: "Berries", Rate: 2, Cap: 10},
+			startAmount: 12,
+			deltaTime:   5,
+		},
+		"grows fast in summer": {
+			regrowth:       &Regrowth{Resource: "Berries", Rate: 10, Cap: 1000, SeasonLength: 4, SeasonAmplitude: 1},
+			deltaTime:      2,
+			expectedAmount: 33,
+		},
+		"grows slowly in winter": {
+			regrowth:       &Regrowth{Resource: "Berries", Rate: 10, Cap: 1000, SeasonLength: 4, SeasonAmplitude: 1},
+			elapsed:        2,
+			deltaTime:      2,
+			expectedAmount: 7,
+		},
+		"offset shifts the seasons": {
+			regrowth:       &Regrowth{Resource: "Berries", Rate: 10, Cap: 1000, SeasonLength: 4, SeasonAmplitude: 1, SeasonOffset: 2},
+			deltaTime:      2,
+			expectedAmount: 7,
+		},
+		"unknown resource": {
+			regrowth:  &Regrowth{Resource: "Wood", Rate: 2, Cap: 100},
+			deltaTime: 5,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			loc := core.NewLocation("bush", core.Coord{}, core.WithInventory(core.InventoryEntry{Resource: berries, Amount: tc.startAmount}))
+
+			grown := tc.regrowth.Regenerate(loc, lookup, tc.elapsed, tc.deltaTime)
+			if tc.expectedAmount == 0 {
+				assert.Empty(t, grown)
+				return
+			}
+			assert.Equal(t, []core.InventoryEntry{{Resource: berries, Amount: tc.expectedAmount}}, grown)
+			assert.Equal(t, tc.startAmount, loc.Inventory.GetAmount(berries), "the location should not be changed")
+		})
+	}
+}
@@ -46,6 +46,23 @@ func (m *mockAgent) Name() string {
 	return m.N
 }
 
+// mockCarrier is a mockAgent that can carry only a limited weight, implementing core.Carrier.
+type mockCarrier struct {
+	mockAgent
+	capacity float64
+}
+
+func (m *mockCarrier) DeepCopy() core.Agent {
+	return &mockCarrier{
+		mockAgent: mockAgent{N: m.N, inventory: m.inventory.DeepCopy()},
+		capacity:  m.capacity,
+	}
+}
+
+func (m *mockCarrier) CarryCapacity() float64 {
+	return m.capacity
+}
+
 // mockAction implements Action and is used for testing.
 type mockAction struct{}
 
@@ -0,0 +1,132 @@
+package attributes
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"Neolithic/internal/core"
+)
+
+const (
+	// ToolAttributeType is the attribute type that corresponds to the Tool attribute
+	ToolAttributeType core.AttributeType = "tool"
+
+	// defaultToolSpeed is the default factor a tool multiplies the amount gathered by
+	defaultToolSpeed = 1
+	// defaultToolCost is the default factor a tool multiplies the cost of gathering by
+	defaultToolCost = 1
+)
+
+// Tool is an Attribute of a resource that names the tool used to gather it. It corresponds to a Gather action that
+// requires the agent to hold the tool, created at every location once the tool has been registered. A Required tool
+// is the only way to gather the resource; otherwise, the resource can also be gathered by hand. The resource must also
+// have a Weight, which sets the cost of gathering it. A resource has at most one tool.
+type Tool struct {
+	// Resource is the name of the tool
+	Resource string
+	// Required indicates that the resource can't be gathered without the tool
+	Required bool
+	// Speed multiplies the most that can be gathered at once with the tool
+	Speed float64
+	// Cost multiplies the cost of gathering with the tool
+	Cost float64
+	// Breaks indicates that the tool is used up by every gather
+	Breaks bool
+}
+
+// Force Tool to implement ResourceListAttribute
+var _ core.ResourceListAttribute = (*Tool)(nil)
+
+// NeedsLocation indicates whether Tool requires an additional location to create an action.
+func (t *Tool) NeedsLocation() bool {
+	return true
+}
+
+// NeedsResource indicates whether Tool requires a resource to create an action. The tool is provided through
+// ResourceNames instead.
+func (t *Tool) NeedsResource() bool {
+	return false
+}
+
+// ResourceNames implements core.ResourceListAttribute, and returns the name of the tool.
+func (t *Tool) ResourceNames() []string {
+	return []string{t.Resource}
+}
+
+// CreateAction creates the action of gathering the resource holding the tool attribute from the location, using the
+// tool. It creates no action if the resource has no Weight.
+func (t *Tool) CreateAction(holder core.AttributeHolder, params core.CreateActionParams) (core.Action, error) {
+	res, ok := holder.(*core.Resource)
+	if !ok {
+		return nil, errors.New("tool can only be applied to a resource")
+	}
+	if params.Location == nil {
+		return nil, errors.New("CreateAction was called for a resource with a tool but no location")
+	}
+	tool, ok := params.Resources[t.Resource]
+	if !ok {
+		return nil, fmt.Errorf("tool resource %q was not provided", t.Resource)
+	}
+	weight, ok := res.Attributes().AttributeByType(WeightAttributeType).(*Weight)
+	if !ok {
+		return nil, nil
+	}
+
+	return &Gather{
+		Requires:       tool,
+		UsesUpRequired: t.Breaks,
+		Res:            res,
+		Amount:         max(1, int(math.Round(float64(weight.batchSize())*t.Speed))),
+		ActionLocation: params.Location,
+		ActionCost:     weight.Amount * t.Cost,
+	}, nil
+}
+
+// requiredTool reports whether the resource can only be gathered with a tool.
+func requiredTool(res *core.Resource) bool {
+	tool, ok := res.Attributes().AttributeByType(ToolAttributeType).(*Tool)
+	return ok && tool.Required
+}
+
+// Type returns the ToolAttributeType for the Tool attribute
+func (t *Tool) Type() core.AttributeType {
+	return ToolAttributeType
+}
+
+// Copy returns a copy of the tool attribute
+func (t *Tool) Copy() core.Attribute {
+	copied := *t
+	return &copied
+}
+
+// Params returns the parameters of the tool attribute, implementing core.ParameterizedAttribute.
+func (t *Tool) Params() core.AttributeParams {
+	return core.AttributeParams{
+		"resource": t.Resource,
+		"required": t.Required,
+		"speed":    t.Speed,
+		"cost":     t.Cost,
+		"breaks":   t.Breaks,
+	}
+}
+
+// String returns a string representation of the tool attribute
+func (t *Tool) String() string {
+	var sb strings.Builder
+	sb.WriteString("Tool: ")
+	sb.WriteString(t.Resource)
+	if t.Required {
+		sb.WriteString(" (required)")
+	}
+	sb.WriteString(" speed ")
+	sb.WriteString(strconv.FormatFloat(t.Speed, 'f', -1, 64))
+	sb.WriteString(" cost ")
+	sb.WriteString(strconv.FormatFloat(t.Cost, 'f', -1, 64))
+	if t.Breaks {
+		sb.WriteString(", breaks")
+	}
+	return sb.String()
+}
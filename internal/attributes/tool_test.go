@@ -0,0 +1,132 @@
+package attributes
+
+import (
+	"testing"
+
+	"Neolithic/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTool_Needs(t *testing.T) {
+	tool := Tool{}
+	assert.True(t, tool.NeedsLocation())
+	assert.False(t, tool.NeedsResource())
+	assert.Equal(t, ToolAttributeType, tool.Type())
+}
+
+func TestTool_Copy(t *testing.T) {
+	t1 := &Tool{Resource: "Stone Axe", Required: true, Speed: 2, Cost: 0.5, Breaks: true}
+	t2, ok := t1.Copy().(*Tool)
+	assert.True(t, ok)
+	assert.NotSame(t, t1, t2)
+	assert.Equal(t, t1, t2)
+	assert.Equal(t, []string{"Stone Axe"}, t1.ResourceNames())
+	assert.Equal(t, "Tool: Stone Axe (required) speed 2 cost 0.5, breaks", t1.String())
+}
+
+func TestTool_CreateAction(t *testing.T) {
+	axe := core.NewResource("Stone Axe")
+	wood := core.NewResource("Wood", core.WithResourceAttributes(&Weight{Amount: 2, Batch: 1}))
+	stone := core.NewResource("Stone", core.WithResourceAttributes(&Weight{Amount: 3}))
+	unweighted := core.NewResource("Sap")
+	forest := core.NewLocation("forest", core.Coord{})
+	resources := map[string]*core.Resource{"Stone Axe": axe}
+
+	testCases := map[string]struct {
+		tool       *Tool
+		holder     core.AttributeHolder
+		params     core.CreateActionParams
+		wantAction core.Action
+		wantErr    string
+	}{
+		"creates gather with tool": {
+			tool:   &Tool{Resource: "Stone Axe", Required: true, Speed: 3, Cost: 0.5},
+			holder: wood,
+			params: core.CreateActionParams{Location: forest, Resources: resources},
+			wantAction: &Gather{
+				Requires:       axe,
+				Res:            wood,
+				Amount:         3,
+				ActionLocation: forest,
+				ActionCost:     1,
+			},
+		},
+		"tool that breaks": {
+			tool:   &Tool{Resource: "Stone Axe", Speed: 1, Cost: 1, Breaks: true},
+			holder: wood,
+			params: core.CreateActionParams{Location: forest, Resources: resources},
+			wantAction: &Gather{
+				Requires:       axe,
+				UsesUpRequired: true,
+				Res:            wood,
+				Amount:         1,
+				ActionLocation: forest,
+				ActionCost:     2,
+			},
+		},
+		"scales the default batch": {
+			tool:   &Tool{Resource: "Stone Axe", Speed: 1.5, Cost: 1},
+			holder: stone,
+			params: core.CreateActionParams{Location: forest, Resources: resources},
+			wantAction: &Gather{
+				Requires:       axe,
+				Res:            stone,
+				Amount:         15,
+				ActionLocation: forest,
+				ActionCost:     3,
+			},
+		},
+		"gathers at least one": {
+			tool:   &Tool{Resource: "Stone Axe", Speed: 0.2, Cost: 1},
+			holder: wood,
+			params: core.CreateActionParams{Location: forest, Resources: resources},
+			wantAction: &Gather{
+				Requires:       axe,
+				Res:            wood,
+				Amount:         1,
+				ActionLocation: forest,
+				ActionCost:     2,
+			},
+		},
+		"resource without weight": {
+			tool:   &Tool{Resource: "Stone Axe", Speed: 1, Cost: 1},
+			holder: unweighted,
+			params: core.CreateActionParams{Location: forest, Resources: resources},
+		},
+		"holder not resource": {
+			tool:    &Tool{Resource: "Stone Axe"},
+			holder:  forest,
+			params:  core.CreateActionParams{Location: forest, Resources: resources},
+			wantErr: "tool can only be applied to a resource",
+		},
+		"no location": {
+			tool:    &Tool{Resource: "Stone Axe"},
+			holder:  wood,
+			params:  core.CreateActionParams{Resources: resources},
+			wantErr: "CreateAction was called for a resource with a tool but no location",
+		},
+		"tool not provided": {
+			tool:    &Tool{Resource: "Stone Axe"},
+			holder:  wood,
+			params:  core.CreateActionParams{Location: forest},
+			wantErr: `tool resource "Stone Axe" was not provided`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			gotAction, gotErr := tc.tool.CreateAction(tc.holder, tc.params)
+			if tc.wantErr != "" {
+				assert.Nil(t, gotAction)
+				assert.EqualError(t, gotErr, tc.wantErr)
+				return
+			}
+			assert.NoError(t, gotErr)
+			if tc.wantAction == nil {
+				assert.Nil(t, gotAction)
+				return
+			}
+			assert.Equal(t, tc.wantAction, gotAction)
+		})
+	}
+}
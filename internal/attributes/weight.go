@@ -11,14 +11,25 @@ const (
 	// WeightAttributeType is the attribute type that corresponds to the Weight attribute
 	WeightAttributeType core.AttributeType = "weight"
 
-	// defaultGatherAmount is the default amount that the created Gather action will have
-	defaultGatherAmount = 1
+	// defaultGatherAmount is the default most units of a resource that a single Gather or Deposit of it moves
+	defaultGatherAmount = 10
 )
 
 // Weight is an Attribute that indicates the weight of a resource. It corresponds to the Gather action, indicating that
 // a resource can be gathered and deposited
 type Weight struct {
+	// Amount is the weight of a single unit of the resource, and the cost of gathering or depositing it
 	Amount float64
+	// Batch is the most units of the resource a single gather or deposit moves. Zero means defaultGatherAmount.
+	Batch int
+}
+
+// batchSize returns the most units of the resource a single gather or deposit moves.
+func (w *Weight) batchSize() int {
+	if w.Batch > 0 {
+		return w.Batch
+	}
+	return defaultGatherAmount
 }
 
 // NeedsLocation indicates whether Weight requires an additional location to create an action.
@@ -31,7 +42,8 @@ func (w *Weight) NeedsResource() bool {
 	return false
 }
 
-// CreateAction creates a specific action of gathering a specific resource from the location
+// CreateAction creates a specific action of gathering a specific resource from the location by hand. It creates no
+// action if the resource requires a Tool.
 func (w *Weight) CreateAction(holder core.AttributeHolder, params core.CreateActionParams) (core.Action, error) {
 	res, ok := holder.(*core.Resource)
 	if !ok {
@@ -40,15 +52,23 @@ func (w *Weight) CreateAction(holder core.AttributeHolder, params core.CreateAct
 	if params.Location == nil {
 		return nil, errors.New("CreateAction was called for a resource with a weight but no location")
 	}
+	if requiredTool(res) {
+		return nil, nil // gathered only with the tool, by the action of the Tool attribute
+	}
 
 	return &Gather{
 		Res:            res,
-		Amount:         defaultGatherAmount,
+		Amount:         w.batchSize(),
 		ActionLocation: params.Location,
 		ActionCost:     w.Amount,
 	}, nil
 }
 
+// UnitWeight implements core.WeightedAttribute, and returns the weight of a single unit of the resource.
+func (w *Weight) UnitWeight() float64 {
+	return w.Amount
+}
+
 // Type returns the WeightAttributeType for the Weight attribute
 func (w *Weight) Type() core.AttributeType {
 	return WeightAttributeType
@@ -56,7 +76,12 @@ func (w *Weight) Type() core.AttributeType {
 
 // Copy returns a copy of the weight attribute
 func (w *Weight) Copy() core.Attribute {
-	return &Weight{Amount: w.Amount}
+	return &Weight{Amount: w.Amount, Batch: w.Batch}
+}
+
+// Params returns the parameters of the weight attribute, implementing core.ParameterizedAttribute.
+func (w *Weight) Params() core.AttributeParams {
+	return core.AttributeParams{"amount": w.Amount, "batch": float64(w.Batch)}
 }
 
 // String returns a string representation fo the weight attribute
@@ -64,5 +89,9 @@ func (w *Weight) String() string {
 	var sb strings.Builder
 	sb.WriteString("Weight: ")
 	sb.WriteString(strconv.FormatFloat(w.Amount, 'f', -1, 64))
+	if w.Batch > 0 {
+		sb.WriteString(", batch ")
+		sb.WriteString(strconv.Itoa(w.Batch))
+	}
 	return sb.String()
 }
@@ -56,6 +56,9 @@ func TestWeight_String(t *testing.T) {
 	w = Weight{Amount: 200}
 	expected = "Weight: 200" // strconv.FormatFloat with 'f' and -1 precision will output "200" for whole numbers
 	assert.Equal(t, expected, w.String(), "Weight.String() should handle whole numbers correctly")
+
+	w = Weight{Amount: 2, Batch: 5}
+	assert.Equal(t, "Weight: 2, batch 5", w.String(), "Weight.String() should include a batch size that was set")
 }
 
 // TestWeight_CreateAction verifies the CreateAction method handles various scenarios correctly.
@@ -64,6 +67,9 @@ func TestWeight_CreateAction(t *testing.T) {
 	mockResource := core.NewResource("Iron Ore") // Assuming core.NewResource exists and returns *core.Resource
 	mockLocation := &core.Location{}             // Assuming core.Location is a struct
 
+	toolResource := core.NewResource("Logs", core.WithResourceAttributes(&Tool{Resource: "Stone Axe", Required: true}))
+	optionalToolResource := core.NewResource("Branches", core.WithResourceAttributes(&Tool{Resource: "Stone Axe"}))
+
 	// Define test cases
 	testCases := map[string]struct {
 		weight     Weight
@@ -110,6 +116,25 @@ func TestWeight_CreateAction(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		"Required Tool": { // gathered only by the action of the Tool attribute
+			weight:     Weight{Amount: 1.0},
+			holder:     toolResource,
+			params:     core.CreateActionParams{Location: mockLocation},
+			wantAction: nil,
+			wantErr:    nil,
+		},
+		"Optional Tool": {
+			weight: Weight{Amount: 1.0},
+			holder: optionalToolResource,
+			params: core.CreateActionParams{Location: mockLocation},
+			wantAction: &Gather{
+				Res:            optionalToolResource,
+				Amount:         defaultGatherAmount,
+				ActionLocation: mockLocation,
+				ActionCost:     1.0,
+			},
+			wantErr: nil,
+		},
 	}
 
 	// Run test cases
@@ -42,3 +42,40 @@ type RequiresTime interface {
 type NeedsResource interface {
 	Resource() *Resource
 }
+
+// Consuming is an interface for Actions that use up a resource the Agent carries, removing it from the world.
+type Consuming interface {
+	NeedsResource
+	// ConsumedAmount returns the amount of the resource the Action uses up
+	ConsumedAmount() int
+}
+
+// RequiresHeld is an interface for Actions that can only be taken while the Agent holds some resources, such as a tool,
+// whether or not the Action uses them up. It lets the planner see prerequisites that GetChanges doesn't show.
+type RequiresHeld interface {
+	// HeldResources returns the resources, and amounts of each, the Agent must hold to take the Action
+	HeldResources() []InventoryEntry
+}
+
+// Explaining is an interface for Actions that can tell why they can't be taken, so that a failure can be reported.
+type Explaining interface {
+	// FailureReason returns why the agent can't take the Action in the given state, or nil if it can
+	FailureReason(state *WorldState, agent Agent) error
+}
+
+// Batched is an interface for Actions that move a variable amount of a resource in a single step, such as gathering or
+// depositing an armful at once. The Action created by an attribute is a template, whose amount is the most a single
+// step can move; the planner takes it with an amount it chooses for the state it is in and the goal it plans for.
+type Batched interface {
+	Action
+	NeedsResource
+	// MaxAmount returns the most the Action can move when taken by the agent in the given state, or 0 if it can't be
+	// taken at all
+	MaxAmount(state *WorldState, agent Agent) int
+	// WithAmount returns a copy of the Action's template that moves the given amount
+	WithAmount(amount int) Batched
+	// Template returns the template the Action was made from, or the Action itself if it is a template
+	Template() Batched
+	// BatchAmount returns the amount the Action moves
+	BatchAmount() int
+}
@@ -0,0 +1,300 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrAttributeTypeRegistered is returned when an AttributeType is registered twice.
+	ErrAttributeTypeRegistered = errors.New("attribute type already registered")
+	// ErrUnknownAttributeType is returned when an AttributeType has not been registered.
+	ErrUnknownAttributeType = errors.New("unknown attribute type")
+	// ErrMissingParam is returned when a required parameter is not provided.
+	ErrMissingParam = errors.New("missing required parameter")
+	// ErrUnknownParam is returned when a parameter is provided that is not in the schema.
+	ErrUnknownParam = errors.New("unknown parameter")
+	// ErrInvalidParam is returned when a parameter has the wrong type.
+	ErrInvalidParam = errors.New("invalid parameter")
+)
+
+// ParamKind is the kind of value a parameter holds.
+type ParamKind string
+
+const (
+	// NumberParam is a numeric parameter. Values are normalized to float64.
+	NumberParam ParamKind = "number"
+	// StringParam is a string parameter.
+	StringParam ParamKind = "string"
+	// BoolParam is a boolean parameter.
+	BoolParam ParamKind = "bool"
+	// AmountsParam maps resource names to whole, non-negative amounts. Values are normalized to map[string]int.
+	AmountsParam ParamKind = "amounts"
+)
+
+// ParamSpec describes a single parameter used to construct an Attribute.
+type ParamSpec struct {
+	// Name is the name of the parameter
+	Name string
+	// Kind is the kind of value the parameter holds
+	Kind ParamKind
+	// Required indicates that the parameter must be provided. Optional parameters take their Default.
+	Required bool
+	// Default is the value used when an optional parameter is not provided
+	Default any
+	// Description is a human-readable description of the parameter, used for tooling
+	Description string
+}
+
+// AttributeParams are the named parameters used to construct an Attribute.
+type AttributeParams map[string]any
+
+// Float returns the named parameter as a float64, or 0 if it is not a number.
+func (p AttributeParams) Float(name string) float64 {
+	v, _ := toFloat(p[name])
+	return v
+}
+
+// String returns the named parameter as a string, or "" if it is not a string.
+func (p AttributeParams) String(name string) string {
+	v, _ := p[name].(string)
+	return v
+}
+
+// Bool returns the named parameter as a bool, or false if it is not a bool.
+func (p AttributeParams) Bool(name string) bool {
+	v, _ := p[name].(bool)
+	return v
+}
+
+// Amounts returns the named parameter as a map of resource names to amounts, or nil if it is not one.
+func (p AttributeParams) Amounts(name string) map[string]int {
+	v, _ := p[name].(map[string]int)
+	return v
+}
+
+// AttributeFactory creates an Attribute from validated parameters.
+type AttributeFactory func(params AttributeParams) (Attribute, error)
+
+// AttributeSchema describes an AttributeType: how to construct it and which parameters it takes.
+type AttributeSchema struct {
+	// Type is the AttributeType the schema describes
+	Type AttributeType
+	// Description is a human-readable description of the attribute, used for tooling
+	Description string
+	// Params are the parameters the attribute takes
+	Params []ParamSpec
+	// Factory creates an instance of the attribute
+	Factory AttributeFactory
+}
+
+// ParameterizedAttribute is an Attribute that can report the parameters it was constructed with. Passing those
+// parameters back to the AttributeRegistry produces an equivalent Attribute.
+type ParameterizedAttribute interface {
+	Attribute
+	// Params returns the parameters of the attribute
+	Params() AttributeParams
+}
+
+// ParamError reports a problem with a specific parameter.
+type ParamError struct {
+	// Param is the name of the parameter
+	Param string
+	// Err is the underlying error
+	Err error
+}
+
+// Error implements error.
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Param, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// AttributeRegistry maps AttributeTypes to the schemas used to construct them, so attributes can be created by name.
+type AttributeRegistry struct {
+	// schemas holds the registered schemas
+	schemas map[AttributeType]AttributeSchema
+}
+
+// NewAttributeRegistry creates an empty AttributeRegistry.
+func NewAttributeRegistry() *AttributeRegistry {
+	return &AttributeRegistry{
+		schemas: map[AttributeType]AttributeSchema{},
+	}
+}
+
+// Register adds a schema to the registry. It returns ErrAttributeTypeRegistered if the type is already registered.
+func (r *AttributeRegistry) Register(schema AttributeSchema) error {
+	if schema.Type == "" {
+		return errors.New("attribute schema has no type")
+	}
+	if schema.Factory == nil {
+		return fmt.Errorf("attribute schema %q has no factory", schema.Type)
+	}
+	if _, exists := r.schemas[schema.Type]; exists {
+		return fmt.Errorf("%w: %q", ErrAttributeTypeRegistered, schema.Type)
+	}
+	r.schemas[schema.Type] = schema
+	return nil
+}
+
+// Schema returns the schema registered for the given type.
+func (r *AttributeRegistry) Schema(attrType AttributeType) (AttributeSchema, bool) {
+	schema, ok := r.schemas[attrType]
+	return schema, ok
+}
+
+// Schemas returns every registered schema, sorted by type.
+func (r *AttributeRegistry) Schemas() []AttributeSchema {
+	schemas := make([]AttributeSchema, 0, len(r.schemas))
+	for _, schema := range r.schemas {
+		schemas = append(schemas, schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Type < schemas[j].Type
+	})
+	return schemas
+}
+
+// Validate checks params against the schema of the given type. On success, it returns a copy of params with defaults
+// filled in and numbers normalized to float64. Problems with a single parameter are returned as a *ParamError.
+func (r *AttributeRegistry) Validate(attrType AttributeType, params AttributeParams) (AttributeParams, error) {
+	schema, ok := r.schemas[attrType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAttributeType, attrType)
+	}
+
+	known := make(map[string]bool, len(schema.Params))
+	validated := make(AttributeParams, len(schema.Params))
+	for _, spec := range schema.Params {
+		known[spec.Name] = true
+
+		value, provided := params[spec.Name]
+		if !provided {
+			if spec.Required {
+				return nil, &ParamError{Param: spec.Name, Err: ErrMissingParam}
+			}
+			value = spec.Default
+		}
+
+		normalized, err := normalizeParam(spec.Kind, value)
+		if err != nil {
+			return nil, &ParamError{Param: spec.Name, Err: err}
+		}
+		validated[spec.Name] = normalized
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !known[name] {
+			return nil, &ParamError{Param: name, Err: ErrUnknownParam}
+		}
+	}
+
+	return validated, nil
+}
+
+// New validates params and constructs an Attribute of the given type.
+func (r *AttributeRegistry) New(attrType AttributeType, params AttributeParams) (Attribute, error) {
+	validated, err := r.Validate(attrType, params)
+	if err != nil {
+		return nil, err
+	}
+	return r.schemas[attrType].Factory(validated)
+}
+
+// ParamsOf returns the parameters of an attribute, so that it can be saved and later recreated with New.
+func (r *AttributeRegistry) ParamsOf(attr Attribute) (AttributeParams, error) {
+	if _, ok := r.schemas[attr.Type()]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAttributeType, attr.Type())
+	}
+	parameterized, ok := attr.(ParameterizedAttribute)
+	if !ok {
+		return nil, fmt.Errorf("attribute type %q does not report its parameters", attr.Type())
+	}
+	return parameterized.Params(), nil
+}
+
+// normalizeParam checks that value is of the given kind, converting numbers to float64.
+func normalizeParam(kind ParamKind, value any) (any, error) {
+	switch kind {
+	case NumberParam:
+		v, ok := toFloat(value)
+		if !ok {
+			return nil, fmt.Errorf("%w: expected a number, got %v", ErrInvalidParam, value)
+		}
+		return v, nil
+	case StringParam:
+		v, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: expected a string, got %v", ErrInvalidParam, value)
+		}
+		return v, nil
+	case BoolParam:
+		v, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: expected a bool, got %v", ErrInvalidParam, value)
+		}
+		return v, nil
+	case AmountsParam:
+		return normalizeAmounts(value)
+	default:
+		return nil, fmt.Errorf("%w: unknown parameter kind %q", ErrInvalidParam, kind)
+	}
+}
+
+// normalizeAmounts checks that value maps names to whole, non-negative numbers, converting it to a map[string]int.
+func normalizeAmounts(value any) (map[string]int, error) {
+	amounts := map[string]int{}
+	switch v := value.(type) {
+	case nil:
+		return amounts, nil
+	case map[string]int:
+		for name, amount := range v {
+			if amount < 0 {
+				return nil, fmt.Errorf("%w: amount of %s must not be negative, got %d", ErrInvalidParam, name, amount)
+			}
+			amounts[name] = amount
+		}
+	case map[string]any:
+		for name, raw := range v {
+			amount, ok := toFloat(raw)
+			if !ok || amount < 0 || amount != float64(int(amount)) {
+				return nil, fmt.Errorf("%w: amount of %s must be a whole, non-negative number, got %v", ErrInvalidParam, name, raw)
+			}
+			amounts[name] = int(amount)
+		}
+	default:
+		return nil, fmt.Errorf("%w: expected a map of amounts, got %v", ErrInvalidParam, value)
+	}
+	return amounts, nil
+}
+
+// toFloat converts any numeric value to a float64.
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,200 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockParamAttribute is a ParameterizedAttribute used for testing the AttributeRegistry
+type mockParamAttribute struct {
+	mockAttribute
+	size  float64
+	label string
+}
+
+func (m *mockParamAttribute) Params() AttributeParams {
+	return AttributeParams{"size": m.size, "label": m.label}
+}
+
+const mockParamAttributeType AttributeType = "mockParam"
+
+var mockParamSchema = AttributeSchema{
+	Type: mockParamAttributeType,
+	Params: []ParamSpec{
+		{Name: "size", Kind: NumberParam, Required: true},
+		{Name: "label", Kind: StringParam, Default: "none"},
+	},
+	Factory: func(params AttributeParams) (Attribute, error) {
+		return &mockParamAttribute{
+			mockAttribute: mockAttribute{attrType: mockParamAttributeType},
+			size:          params.Float("size"),
+			label:         params.String("label"),
+		}, nil
+	},
+}
+
+func TestAttributeRegistry_Register(t *testing.T) {
+	registry := NewAttributeRegistry()
+	require.NoError(t, registry.Register(mockParamSchema))
+
+	err := registry.Register(mockParamSchema)
+	assert.ErrorIs(t, err, ErrAttributeTypeRegistered)
+
+	err = registry.Register(AttributeSchema{Type: "noFactory"})
+	assert.Error(t, err)
+
+	schema, ok := registry.Schema(mockParamAttributeType)
+	assert.True(t, ok)
+	assert.Equal(t, mockParamAttributeType, schema.Type)
+
+	require.NoError(t, registry.Register(AttributeSchema{Type: "aFirst", Factory: mockParamSchema.Factory}))
+	schemas := registry.Schemas()
+	require.Len(t, schemas, 2)
+	assert.Equal(t, AttributeType("aFirst"), schemas[0].Type)
+	assert.Equal(t, mockParamAttributeType, schemas[1].Type)
+}
+
+func TestAttributeRegistry_New(t *testing.T) {
+	type testCase struct {
+		attrType      AttributeType
+		params        AttributeParams
+		expectedSize  float64
+		expectedLabel string
+		expectedErr   error
+		expectedParam string
+	}
+
+	tests := map[string]testCase{
+		"creates attribute with int param": {
+			attrType:      mockParamAttributeType,
+			params:        AttributeParams{"size": 10},
+			expectedSize:  10,
+			expectedLabel: "none",
+		},
+		"creates attribute with all params": {
+			attrType:      mockParamAttributeType,
+			params:        AttributeParams{"size": 2.5, "label": "pit"},
+			expectedSize:  2.5,
+			expectedLabel: "pit",
+		},
+		"unknown type": {
+			attrType:    "unknown",
+			expectedErr: ErrUnknownAttributeType,
+		},
+		"missing required param": {
+			attrType:      mockParamAttributeType,
+			params:        AttributeParams{},
+			expectedErr:   ErrMissingParam,
+			expectedParam: "size",
+		},
+		"unknown param": {
+			attrType:      mockParamAttributeType,
+			params:        AttributeParams{"size": 1, "colour": "red"},
+			expectedErr:   ErrUnknownParam,
+			expectedParam: "colour",
+		},
+		"wrong param kind": {
+			attrType:      mockParamAttributeType,
+			params:        AttributeParams{"size": "big"},
+			expectedErr:   ErrInvalidParam,
+			expectedParam: "size",
+		},
+	}
+
+	registry := NewAttributeRegistry()
+	require.NoError(t, registry.Register(mockParamSchema))
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			attr, err := registry.New(tc.attrType, tc.params)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				if tc.expectedParam != "" {
+					var paramErr *ParamError
+					require.True(t, errors.As(err, &paramErr))
+					assert.Equal(t, tc.expectedParam, paramErr.Param)
+				}
+				return
+			}
+			require.NoError(t, err)
+			created := attr.(*mockParamAttribute)
+			assert.Equal(t, tc.expectedSize, created.size)
+			assert.Equal(t, tc.expectedLabel, created.label)
+		})
+	}
+}
+
+func TestAttributeRegistry_ParamsOf(t *testing.T) {
+	registry := NewAttributeRegistry()
+	require.NoError(t, registry.Register(mockParamSchema))
+
+	original, err := registry.New(mockParamAttributeType, AttributeParams{"size": 3, "label": "bin"})
+	require.NoError(t, err)
+
+	params, err := registry.ParamsOf(original)
+	require.NoError(t, err)
+
+	recreated, err := registry.New(original.Type(), params)
+	require.NoError(t, err)
+	assert.Equal(t, original, recreated)
+
+	_, err = registry.ParamsOf(&mockAttribute{attrType: "unknown"})
+	assert.ErrorIs(t, err, ErrUnknownAttributeType)
+}
+
+func TestAttributeRegistry_ValidateAmounts(t *testing.T) {
+	type testCase struct {
+		value       any
+		expected    map[string]int
+		expectedErr error
+	}
+
+	tests := map[string]testCase{
+		"ints": {
+			value:    map[string]any{"Wood": 2, "Stone": 1},
+			expected: map[string]int{"Wood": 2, "Stone": 1},
+		},
+		"whole floats": {
+			value:    map[string]any{"Wood": 2.0},
+			expected: map[string]int{"Wood": 2},
+		},
+		"already normalized": {
+			value:    map[string]int{"Wood": 3},
+			expected: map[string]int{"Wood": 3},
+		},
+		"fractional amount": {
+			value:       map[string]any{"Wood": 1.5},
+			expectedErr: ErrInvalidParam,
+		},
+		"negative amount": {
+			value:       map[string]any{"Wood": -1},
+			expectedErr: ErrInvalidParam,
+		},
+		"not a map": {
+			value:       "Wood",
+			expectedErr: ErrInvalidParam,
+		},
+	}
+
+	registry := NewAttributeRegistry()
+	require.NoError(t, registry.Register(AttributeSchema{
+		Type:    "amounts",
+		Params:  []ParamSpec{{Name: "inputs", Kind: AmountsParam, Required: true}},
+		Factory: func(AttributeParams) (Attribute, error) { return nil, nil },
+	}))
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			validated, err := registry.Validate("amounts", AttributeParams{"inputs": tc.value})
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, validated.Amounts("inputs"))
+		})
+	}
+}
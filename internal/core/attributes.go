@@ -10,6 +10,8 @@ type CreateActionParams struct {
 	Location *Location
 	// Resource is the resource to be passed to the CreatAction func
 	Resource *Resource
+	// Resources are the resources named by a ResourceListAttribute, keyed by name
+	Resources map[string]*Resource
 }
 
 // AttributeType is a string representing the type of an Attribute.
@@ -36,3 +38,44 @@ type AttributeHolder interface {
 	// Attributes returns the AttributeList held by the AttributeHolder.
 	Attributes() AttributeList
 }
+
+// ResourceListAttribute is an Attribute whose action involves several specific resources, such as the inputs and
+// outputs of a recipe. Its action is created as soon as every resource it names has been registered, with the
+// resources passed in CreateActionParams.Resources: once, or once per location if NeedsLocation is true. NeedsResource
+// is not consulted for it.
+type ResourceListAttribute interface {
+	Attribute
+	// ResourceNames returns the names of the resources the action involves
+	ResourceNames() []string
+}
+
+// RegeneratingAttribute is an Attribute of a Location whose inventory changes on its own over simulated time, such as
+// plants growing back. The Engine applies it every tick; the planner never does, so the world is static within a plan.
+type RegeneratingAttribute interface {
+	Attribute
+	// Regenerate returns the amounts to add to the location over the deltaTime seconds of simulated time that start
+	// elapsed seconds into the simulation. resource looks up a registered resource by name.
+	Regenerate(loc *Location, resource func(name string) (*Resource, bool), elapsed, deltaTime float64) []InventoryEntry
+}
+
+// WeightedAttribute is an Attribute of a Resource that gives every unit of it a weight, which counts against how much a
+// Carrier can carry.
+type WeightedAttribute interface {
+	Attribute
+	// UnitWeight returns the weight of a single unit of the resource
+	UnitWeight() float64
+}
+
+// NourishingAttribute is an Attribute of a Resource that makes every unit of it restore a need when it is consumed.
+type NourishingAttribute interface {
+	Attribute
+	// UnitNutrition returns how much a single unit of the resource restores the need it satisfies
+	UnitNutrition() float64
+}
+
+// StorageAttribute is an Attribute of a Location that limits the total weight of the resources it can hold.
+type StorageAttribute interface {
+	Attribute
+	// MaxWeight returns the most total weight of resources the location can hold, or 0 if there is no limit
+	MaxWeight() float64
+}
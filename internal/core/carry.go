@@ -0,0 +1,80 @@
+package core
+
+import "math"
+
+// UnitWeight returns the weight of a single unit of the resource, or 0 if it has no WeightedAttribute.
+func (r *Resource) UnitWeight() float64 {
+	if r.attributes == nil {
+		return 0
+	}
+	for _, attr := range r.attributes.List() {
+		if weighted, ok := attr.(WeightedAttribute); ok {
+			return weighted.UnitWeight()
+		}
+	}
+	return 0
+}
+
+// InventoryWeight returns the total weight of the resources in the inventory.
+func InventoryWeight(inv Inventory) float64 {
+	var total float64
+	for _, entry := range inv.Entries() {
+		total += float64(entry.Amount) * entry.Resource.UnitWeight()
+	}
+	return total
+}
+
+// CanCarry returns how many more units of the resource the agent can pick up without carrying more than its
+// CarryCapacity. It is math.MaxInt if the agent isn't a Carrier with a limit, or the resource weighs nothing.
+func CanCarry(agent Agent, res *Resource) int {
+	carrier, ok := agent.(Carrier)
+	if !ok || carrier.CarryCapacity() <= 0 {
+		return math.MaxInt
+	}
+	return UnitsWithin(carrier.CarryCapacity()-InventoryWeight(agent.Inventory()), res)
+}
+
+// StorageCapacity returns the most total weight of resources the location can hold, or 0 if it has no StorageAttribute
+// with a limit.
+func StorageCapacity(loc *Location) float64 {
+	if loc.attributes == nil {
+		return 0
+	}
+	for _, attr := range loc.attributes.List() {
+		if storage, ok := attr.(StorageAttribute); ok {
+			return storage.MaxWeight()
+		}
+	}
+	return 0
+}
+
+// CanStore returns how many more units of the resource can be put at the location without it holding more than its
+// StorageCapacity. It is math.MaxInt if the location has no limit, or the resource weighs nothing.
+func CanStore(loc *Location, res *Resource) int {
+	capacity := StorageCapacity(loc)
+	if capacity <= 0 {
+		return math.MaxInt
+	}
+	return UnitsWithin(capacity-InventoryWeight(loc.Inventory), res)
+}
+
+// UnitsWithin returns how many units of the resource fit within the given weight. It is math.MaxInt if the resource
+// weighs nothing.
+func UnitsWithin(room float64, res *Resource) int {
+	unitWeight := res.UnitWeight()
+	if unitWeight <= 0 {
+		return math.MaxInt
+	}
+	// the small tolerance keeps rounding errors in the weights from costing a whole unit
+	return max(0, int(math.Floor(room/unitWeight+1e-9)))
+}
+
+// Encumbrance returns the fraction of its CarryCapacity that the agent carries: 0 when it carries nothing, and 1 when
+// it is fully loaded. It is always 0 for an agent that isn't a Carrier with a limit.
+func Encumbrance(agent Agent) float64 {
+	carrier, ok := agent.(Carrier)
+	if !ok || carrier.CarryCapacity() <= 0 {
+		return 0
+	}
+	return InventoryWeight(agent.Inventory()) / carrier.CarryCapacity()
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockWeightedAttribute is a WeightedAttribute used for testing carry weights
+type mockWeightedAttribute struct {
+	mockAttribute
+	weight float64
+}
+
+func (m *mockWeightedAttribute) UnitWeight() float64 {
+	return m.weight
+}
+
+func (m *mockWeightedAttribute) Copy() Attribute {
+	return &mockWeightedAttribute{mockAttribute: m.mockAttribute, weight: m.weight}
+}
+
+// mockStorageAttribute is a StorageAttribute used for testing location capacities
+type mockStorageAttribute struct {
+	mockAttribute
+	maxWeight float64
+}
+
+func (m *mockStorageAttribute) MaxWeight() float64 {
+	return m.maxWeight
+}
+
+func (m *mockStorageAttribute) Copy() Attribute {
+	return &mockStorageAttribute{mockAttribute: m.mockAttribute, maxWeight: m.maxWeight}
+}
+
+// mockCarrier is a Carrier with an inventory, used for testing carry weights
+type mockCarrier struct {
+	inventory Inventory
+	capacity  float64
+}
+
+func (m *mockCarrier) String() string {
+	return "mockcarrier"
+}
+
+func (m *mockCarrier) Name() string {
+	return "mockcarrier"
+}
+
+func (m *mockCarrier) DeepCopy() Agent {
+	return &mockCarrier{inventory: m.inventory.DeepCopy(), capacity: m.capacity}
+}
+
+func (m *mockCarrier) Inventory() Inventory {
+	return m.inventory
+}
+
+func (m *mockCarrier) CarryCapacity() float64 {
+	return m.capacity
+}
+
+func TestResource_UnitWeight(t *testing.T) {
+	stone := NewResource("stone", WithResourceAttributes(&mockWeightedAttribute{mockAttribute: mockAttribute{attrType: "weight"}, weight: 2.5}))
+	feather := NewResource("feather", WithResourceAttributes(&mockAttribute{attrType: "other"}))
+
+	assert.Equal(t, 2.5, stone.UnitWeight())
+	assert.Equal(t, 0.0, feather.UnitWeight())
+	assert.Equal(t, 0.0, (&Resource{Name: "bare"}).UnitWeight())
+}
+
+func TestCanCarry(t *testing.T) {
+	stone := NewResource("stone", WithResourceAttributes(&mockWeightedAttribute{mockAttribute: mockAttribute{attrType: "weight"}, weight: 2}))
+	pebble := NewResource("pebble", WithResourceAttributes(&mockWeightedAttribute{mockAttribute: mockAttribute{attrType: "weight"}, weight: 0.1}))
+	feather := NewResource("feather")
+
+	type testCase struct {
+		agent              Agent
+		carrying           []InventoryEntry
+		resource           *Resource
+		expectedCanCarry   int
+		expectedEncumbered float64
+	}
+
+	testCases := map[string]testCase{
+		"empty carrier": {
+			agent:              &mockCarrier{inventory: NewInventory(), capacity: 10},
+			resource:           stone,
+			expectedCanCarry:   5,
+			expectedEncumbered: 0,
+		},
+		"partly loaded carrier": {
+			agent:              &mockCarrier{inventory: NewInventory(), capacity: 10},
+			carrying:           []InventoryEntry{{Resource: stone, Amount: 2}, {Resource: pebble, Amount: 10}},
+			resource:           stone,
+			expectedCanCarry:   2,
+			expectedEncumbered: 0.5,
+		},
+		"fully loaded carrier": {
+			agent:              &mockCarrier{inventory: NewInventory(), capacity: 10},
+			carrying:           []InventoryEntry{{Resource: stone, Amount: 5}},
+			resource:           pebble,
+			expectedCanCarry:   0,
+			expectedEncumbered: 1,
+		},
+		"rounding doesn't cost a unit": {
+			agent:              &mockCarrier{inventory: NewInventory(), capacity: 1},
+			carrying:           []InventoryEntry{{Resource: pebble, Amount: 3}},
+			resource:           pebble,
+			expectedCanCarry:   7,
+			expectedEncumbered: 0.3,
+		},
+		"weightless resource": {
+			agent:              &mockCarrier{inventory: NewInventory(), capacity: 10},
+			carrying:           []InventoryEntry{{Resource: stone, Amount: 5}},
+			resource:           feather,
+			expectedCanCarry:   math.MaxInt,
+			expectedEncumbered: 1,
+		},
+		"carrier without a limit": {
+			agent:              &mockCarrier{inventory: NewInventory()},
+			carrying:           []InventoryEntry{{Resource: stone, Amount: 5}},
+			resource:           stone,
+			expectedCanCarry:   math.MaxInt,
+			expectedEncumbered: 0,
+		},
+		"agent that isn't a carrier": {
+			agent:              testAgent,
+			resource:           stone,
+			expectedCanCarry:   math.MaxInt,
+			expectedEncumbered: 0,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for _, entry := range tc.carrying {
+				tc.agent.Inventory().AdjustAmount(entry.Resource, entry.Amount)
+			}
+
+			assert.Equal(t, tc.expectedCanCarry, CanCarry(tc.agent, tc.resource))
+			assert.InDelta(t, tc.expectedEncumbered, Encumbrance(tc.agent), 1e-9)
+		})
+	}
+}
+
+func TestCanStore(t *testing.T) {
+	stone := NewResource("stone", WithResourceAttributes(&mockWeightedAttribute{mockAttribute: mockAttribute{attrType: "weight"}, weight: 2}))
+	pebble := NewResource("pebble", WithResourceAttributes(&mockWeightedAttribute{mockAttribute: mockAttribute{attrType: "weight"}, weight: 0.1}))
+	storage := &mockStorageAttribute{mockAttribute: mockAttribute{attrType: "storage"}, maxWeight: 10}
+
+	type testCase struct {
+		location         *Location
+		holding          []InventoryEntry
+		resource         *Resource
+		expectedCapacity float64
+		expectedCanStore int
+	}
+
+	testCases := map[string]testCase{
+		"empty location": {
+			location:         NewLocation("pit", Coord{}, WithAttributes(storage)),
+			resource:         stone,
+			expectedCapacity: 10,
+			expectedCanStore: 5,
+		},
+		"partly full location": {
+			location:         NewLocation("pit", Coord{}, WithAttributes(storage)),
+			holding:          []InventoryEntry{{Resource: stone, Amount: 2}, {Resource: pebble, Amount: 10}},
+			resource:         stone,
+			expectedCapacity: 10,
+			expectedCanStore: 2,
+		},
+		"full location": {
+			location:         NewLocation("pit", Coord{}, WithAttributes(storage)),
+			holding:          []InventoryEntry{{Resource: stone, Amount: 5}},
+			resource:         pebble,
+			expectedCapacity: 10,
+			expectedCanStore: 0,
+		},
+		"location without storage": {
+			location:         NewLocation("field", Coord{}),
+			holding:          []InventoryEntry{{Resource: stone, Amount: 5}},
+			resource:         stone,
+			expectedCapacity: 0,
+			expectedCanStore: math.MaxInt,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for _, entry := range tc.holding {
+				tc.location.Inventory.AdjustAmount(entry.Resource, entry.Amount)
+			}
+
+			assert.Equal(t, tc.expectedCapacity, StorageCapacity(tc.location))
+			assert.Equal(t, tc.expectedCanStore, CanStore(tc.location, tc.resource))
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package core
+
+import "strings"
+
+// GoalAgent is an Agent used in goal states. It holds only the inventory the real agent of the same name should reach,
+// so that a goal can be about what an agent carries rather than what a location holds.
+type GoalAgent struct {
+	// AgentName is the name of the agent the goal is for
+	AgentName string
+	// GoalInventory is the inventory the agent should reach
+	GoalInventory Inventory
+}
+
+// Ensure GoalAgent implements Agent
+var _ Agent = (*GoalAgent)(nil)
+
+// NewGoalAgent creates a GoalAgent with an empty inventory.
+func NewGoalAgent(name string) *GoalAgent {
+	return &GoalAgent{AgentName: name, GoalInventory: NewInventory()}
+}
+
+// Name implements Agent.
+func (g *GoalAgent) Name() string {
+	return g.AgentName
+}
+
+// Inventory implements Agent.
+func (g *GoalAgent) Inventory() Inventory {
+	return g.GoalInventory
+}
+
+// DeepCopy implements Agent.
+func (g *GoalAgent) DeepCopy() Agent {
+	return &GoalAgent{AgentName: g.AgentName, GoalInventory: g.GoalInventory.DeepCopy()}
+}
+
+// String implements fmt.Stringer.
+func (g *GoalAgent) String() string {
+	var sb strings.Builder
+	sb.WriteString("Goal for agent: ")
+	sb.WriteString(g.AgentName)
+	sb.WriteString("\nInventory ")
+	sb.WriteString(g.GoalInventory.String())
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -17,6 +17,21 @@ type Agent interface {
 	Inventory() Inventory
 }
 
+// Carrier is an Agent that can carry only a limited weight of resources
+type Carrier interface {
+	Agent
+	// CarryCapacity returns the most weight the agent can carry, or 0 if there is no limit
+	CarryCapacity() float64
+}
+
+// Occupant is an Agent that takes up tiles of the grid, which other agents should not move onto
+type Occupant interface {
+	Agent
+	// OccupiedCoords returns the coordinates the agent takes up: the one it stands on, and the one it is moving onto,
+	// if any
+	OccupiedCoords() []Coord
+}
+
 // Locatable is an interface that represents anything with a location associated with it
 type Locatable interface {
 	// Location returns the location of the entity
@@ -29,6 +44,28 @@ type Grid interface {
 	CellAt(coord Coord) Cell
 }
 
+// PathFinder is a Grid that finds paths across itself faster than searching it cell by cell
+type PathFinder interface {
+	Grid
+	// FindPath returns the coordinates of a path from one coordinate to the nearest of the goals, including both
+	FindPath(from Coord, goals ...Coord) ([]Coord, error)
+}
+
+// FlowField gives the next move toward the nearest of its targets from anywhere on a grid, so that many agents heading
+// to the same place can share one search
+type FlowField interface {
+	// Next returns the coordinate to move onto from the given one toward a target, the same coordinate when already on
+	// a target, or false if no target can be reached from it
+	Next(from Coord) (Coord, bool)
+}
+
+// FlowFinder is a Grid that builds FlowFields across itself
+type FlowFinder interface {
+	Grid
+	// FlowField returns the field leading to the nearest of the targets
+	FlowField(targets ...Coord) (FlowField, error)
+}
+
 // Cell is an interface that represents a cell in a grid. It is expected to implement the astar.Node interface for pathfinding.
 type Cell interface {
 	// require implementing astar.Node
@@ -4,6 +4,10 @@ import (
 	"strings"
 )
 
+// DefaultAccessRadius is how many tiles away an agent can use a location from, when the location declares no access of
+// its own
+const DefaultAccessRadius = 1
+
 // Location represents a location in the simulation world.
 type Location struct {
 	// Name is the unique identifier for the location.
@@ -12,8 +16,16 @@ type Location struct {
 	Inventory Inventory
 	// Coord represents the geographical coordinates of the location.
 	Coord Coord
+	// AccessRadius is how many tiles away from Coord, in any direction, an agent can use the location from. Zero means
+	// DefaultAccessRadius. It is ignored if AccessTiles are given.
+	AccessRadius int
+	// AccessTiles are the offsets from Coord of the only tiles an agent can use the location from, such as the
+	// doorway of a hut.
+	AccessTiles []Coord
 	// attributes is a list of special characteristics or properties of the location.
 	attributes AttributeList
+	// reservations maps the name of a claimant to the resources it has reserved at this location.
+	reservations map[string]Inventory
 }
 
 // LocationOption is a functional option type for configuring a new Location.
@@ -59,6 +71,44 @@ func WithAttributes(attributes ...Attribute) LocationOption {
 	}
 }
 
+// WithAccessRadius is a LocationOption that sets how many tiles away from the Location an agent can use it from.
+func WithAccessRadius(radius int) LocationOption {
+	return func(l *Location) {
+		l.AccessRadius = radius
+	}
+}
+
+// WithAccessTiles is a LocationOption that sets the offsets from the Location's coordinates of the only tiles an agent
+// can use it from.
+func WithAccessTiles(offsets ...Coord) LocationOption {
+	return func(l *Location) {
+		l.AccessTiles = append([]Coord(nil), offsets...)
+	}
+}
+
+// AccessCoords returns the coordinates an agent can use the Location from: its AccessTiles if it has any, and
+// otherwise every coordinate within its AccessRadius, row by row. Some may be outside the grid or impassable.
+func (l *Location) AccessCoords() []Coord {
+	if len(l.AccessTiles) > 0 {
+		coords := make([]Coord, len(l.AccessTiles))
+		for i, offset := range l.AccessTiles {
+			coords[i] = Coord{X: l.Coord.X + offset.X, Y: l.Coord.Y + offset.Y}
+		}
+		return coords
+	}
+	radius := l.AccessRadius
+	if radius <= 0 {
+		radius = DefaultAccessRadius
+	}
+	coords := make([]Coord, 0, (2*radius+1)*(2*radius+1))
+	for y := l.Coord.Y - radius; y <= l.Coord.Y+radius; y++ {
+		for x := l.Coord.X - radius; x <= l.Coord.X+radius; x++ {
+			coords = append(coords, Coord{X: x, Y: y})
+		}
+	}
+	return coords
+}
+
 // String returns a string representation of the Location in the format
 // "Location: <name>\nCoordinates: <coordinates>\nInventory: <inventory>\nAttributes: <attributes>".
 func (l *Location) String() string {
@@ -82,6 +132,12 @@ func (l *Location) String() string {
 	} else {
 		sb.WriteString("{}")
 	}
+	for _, claimant := range l.Claimants() {
+		sb.WriteString("\nReserved by ")
+		sb.WriteString(claimant)
+		sb.WriteString(": ")
+		sb.WriteString(l.reservations[claimant].String())
+	}
 	return sb.String()
 }
 
@@ -104,10 +160,13 @@ func (l *Location) DeepCopy() *Location {
 	}
 
 	return &Location{
-		Name:       l.Name,
-		Inventory:  copiedInventory,
-		Coord:      l.Coord,
-		attributes: copiedAttributes,
+		Name:         l.Name,
+		Inventory:    copiedInventory,
+		Coord:        l.Coord,
+		AccessRadius: l.AccessRadius,
+		AccessTiles:  append([]Coord(nil), l.AccessTiles...),
+		attributes:   copiedAttributes,
+		reservations: l.copyReservations(),
 	}
 }
 
@@ -339,3 +339,57 @@ func TestLocation_DeepCopy(t *testing.T) {
 		})
 	}
 }
+
+func TestLocation_AccessCoords(t *testing.T) {
+	type testCase struct {
+		opts     []LocationOption
+		expected []Coord
+	}
+
+	tests := map[string]testCase{
+		"default radius": {
+			expected: []Coord{
+				{X: 4, Y: 4}, {X: 5, Y: 4}, {X: 6, Y: 4},
+				{X: 4, Y: 5}, {X: 5, Y: 5}, {X: 6, Y: 5},
+				{X: 4, Y: 6}, {X: 5, Y: 6}, {X: 6, Y: 6},
+			},
+		},
+		"wider radius": {
+			opts: []LocationOption{WithAccessRadius(2)},
+			expected: func() []Coord {
+				var coords []Coord
+				for y := 3; y <= 7; y++ {
+					for x := 3; x <= 7; x++ {
+						coords = append(coords, Coord{X: x, Y: y})
+					}
+				}
+				return coords
+			}(),
+		},
+		"access tiles": {
+			opts:     []LocationOption{WithAccessTiles(Coord{X: 0, Y: 1}, Coord{X: -2, Y: 0})},
+			expected: []Coord{{X: 5, Y: 6}, {X: 3, Y: 5}},
+		},
+		"access tiles override the radius": {
+			opts:     []LocationOption{WithAccessRadius(3), WithAccessTiles(Coord{X: 1, Y: 1})},
+			expected: []Coord{{X: 6, Y: 6}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			loc := NewLocation("hut", Coord{X: 5, Y: 5}, tc.opts...)
+			assert.Equal(t, tc.expected, loc.AccessCoords())
+		})
+	}
+}
+
+func TestLocation_DeepCopyAccess(t *testing.T) {
+	loc := NewLocation("hut", Coord{X: 5, Y: 5}, WithAccessRadius(2), WithAccessTiles(Coord{X: 0, Y: 1}))
+	copied := loc.DeepCopy()
+	assert.Equal(t, 2, copied.AccessRadius)
+	assert.Equal(t, loc.AccessTiles, copied.AccessTiles)
+
+	copied.AccessTiles[0] = Coord{X: 1, Y: 0}
+	assert.Equal(t, Coord{X: 0, Y: 1}, loc.AccessTiles[0], "the copy's access tiles should not share the original's")
+}
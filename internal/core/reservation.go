@@ -0,0 +1,166 @@
+package core
+
+import (
+	"sort"
+)
+
+// Reserving is an interface for Actions that take resources from a Location. When an Agent plans such an Action, it
+// reserves the resources at the Location so that other Agents don't plan to take them as well.
+type Reserving interface {
+	Locatable
+	NeedsResource
+	// ReservedAmount returns the amount of the resource the Action takes from the Location
+	ReservedAmount() int
+}
+
+// Reserve claims an amount of a resource at the Location on behalf of claimant.
+func (l *Location) Reserve(claimant string, res *Resource, amount int) {
+	if amount <= 0 {
+		return
+	}
+	if l.reservations == nil {
+		l.reservations = map[string]Inventory{}
+	}
+	reserved, ok := l.reservations[claimant]
+	if !ok {
+		reserved = NewInventory()
+		l.reservations[claimant] = reserved
+	}
+	reserved.AdjustAmount(res, amount)
+}
+
+// Release gives up an amount of a resource claimant has reserved at the Location. Releasing more than is reserved
+// releases all of it.
+func (l *Location) Release(claimant string, res *Resource, amount int) {
+	reserved, ok := l.reservations[claimant]
+	if !ok || amount <= 0 {
+		return
+	}
+	reserved.AdjustAmount(res, -min(amount, reserved.GetAmount(res)))
+	if len(reserved.Entries()) == 0 {
+		delete(l.reservations, claimant)
+	}
+}
+
+// ReleaseAll gives up everything claimant has reserved at the Location. It returns true if anything was released.
+func (l *Location) ReleaseAll(claimant string) bool {
+	if _, ok := l.reservations[claimant]; !ok {
+		return false
+	}
+	delete(l.reservations, claimant)
+	return true
+}
+
+// Reserved returns the total amount of a resource reserved at the Location by every claimant.
+func (l *Location) Reserved(res *Resource) int {
+	total := 0
+	for _, reserved := range l.reservations {
+		total += reserved.GetAmount(res)
+	}
+	return total
+}
+
+// ReservedBy returns the amount of a resource claimant has reserved at the Location.
+func (l *Location) ReservedBy(claimant string, res *Resource) int {
+	reserved, ok := l.reservations[claimant]
+	if !ok {
+		return 0
+	}
+	return reserved.GetAmount(res)
+}
+
+// Available returns the amount of a resource at the Location that claimant may take; that is, the amount in the
+// inventory less what has been reserved by everyone else.
+func (l *Location) Available(claimant string, res *Resource) int {
+	available := l.Inventory.GetAmount(res) - (l.Reserved(res) - l.ReservedBy(claimant, res))
+	return max(available, 0)
+}
+
+// Claimants returns the names of everyone with a reservation at the Location, sorted.
+func (l *Location) Claimants() []string {
+	claimants := make([]string, 0, len(l.reservations))
+	for claimant := range l.reservations {
+		claimants = append(claimants, claimant)
+	}
+	sort.Strings(claimants)
+	return claimants
+}
+
+// ReservationsBy returns the resources claimant has reserved at the Location, sorted by resource name.
+func (l *Location) ReservationsBy(claimant string) []InventoryEntry {
+	reserved, ok := l.reservations[claimant]
+	if !ok {
+		return nil
+	}
+	return reserved.Entries()
+}
+
+// copyReservations returns a deep copy of the Location's reservations.
+func (l *Location) copyReservations() map[string]Inventory {
+	if l.reservations == nil {
+		return nil
+	}
+	copied := make(map[string]Inventory, len(l.reservations))
+	for claimant, reserved := range l.reservations {
+		copied[claimant] = reserved.DeepCopy()
+	}
+	return copied
+}
+
+// AvailableTo returns the world as claimant should plan against it: every resource reserved by someone else is taken
+// out of the inventory of its Location. Claimant's own reservations are kept. If nothing is reserved by anyone else,
+// the WorldState itself is returned.
+func (w *WorldState) AvailableTo(claimant string) *WorldState {
+	var available *WorldState
+	for _, name := range w.LocationNames() {
+		loc := w.Locations[name]
+		others := false
+		for _, other := range loc.Claimants() {
+			if other != claimant {
+				others = true
+				break
+			}
+		}
+		if !others {
+			continue
+		}
+
+		if available == nil {
+			available = w.ShallowCopy()
+		}
+		newLoc := loc.DeepCopy()
+		for _, other := range loc.Claimants() {
+			if other == claimant {
+				continue
+			}
+			for _, entry := range loc.ReservationsBy(other) {
+				newLoc.Inventory.AdjustAmount(entry.Resource, -min(entry.Amount, newLoc.Inventory.GetAmount(entry.Resource)))
+			}
+			newLoc.ReleaseAll(other)
+		}
+		available.Locations[name] = newLoc
+	}
+
+	if available == nil {
+		return w
+	}
+	return available
+}
+
+// ReleaseReservations returns a new WorldState in which claimant holds no reservations, or nil if claimant held none.
+func (w *WorldState) ReleaseReservations(claimant string) *WorldState {
+	var released *WorldState
+	for _, name := range w.LocationNames() {
+		loc := w.Locations[name]
+		if len(loc.ReservationsBy(claimant)) == 0 {
+			continue
+		}
+		if released == nil {
+			released = w.ShallowCopy()
+		}
+		newLoc := loc.DeepCopy()
+		newLoc.ReleaseAll(claimant)
+		released.Locations[name] = newLoc
+	}
+	return released
+}
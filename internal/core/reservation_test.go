@@ -0,0 +1,153 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocation_Reservations(t *testing.T) {
+	berries := &Resource{Name: "Berries"}
+	wood := &Resource{Name: "Wood"}
+
+	type testCase struct {
+		reserve           map[string]int
+		release           map[string]int
+		expectedReserved  int
+		expectedAvailable map[string]int
+		expectedClaimants []string
+	}
+
+	tests := map[string]testCase{
+		"no reservations": {
+			expectedReserved:  0,
+			expectedAvailable: map[string]int{"ana": 10, "bo": 10},
+			expectedClaimants: []string{},
+		},
+		"one claimant": {
+			reserve:           map[string]int{"ana": 4},
+			expectedReserved:  4,
+			expectedAvailable: map[string]int{"ana": 10, "bo": 6},
+			expectedClaimants: []string{"ana"},
+		},
+		"two claimants": {
+			reserve:           map[string]int{"ana": 4, "bo": 3},
+			expectedReserved:  7,
+			expectedAvailable: map[string]int{"ana": 7, "bo": 6, "cy": 3},
+			expectedClaimants: []string{"ana", "bo"},
+		},
+		"over reserved": {
+			reserve:           map[string]int{"ana": 8, "bo": 8},
+			expectedReserved:  16,
+			expectedAvailable: map[string]int{"ana": 2, "cy": 0},
+			expectedClaimants: []string{"ana", "bo"},
+		},
+		"partial release": {
+			reserve:           map[string]int{"ana": 4},
+			release:           map[string]int{"ana": 1},
+			expectedReserved:  3,
+			expectedAvailable: map[string]int{"bo": 7},
+			expectedClaimants: []string{"ana"},
+		},
+		"release more than reserved": {
+			reserve:           map[string]int{"ana": 4},
+			release:           map[string]int{"ana": 9, "bo": 2},
+			expectedReserved:  0,
+			expectedAvailable: map[string]int{"bo": 10},
+			expectedClaimants: []string{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			loc := NewLocation("bush", Coord{}, WithInventory(InventoryEntry{Resource: berries, Amount: 10}))
+			for claimant, amount := range tc.reserve {
+				loc.Reserve(claimant, berries, amount)
+			}
+			for claimant, amount := range tc.release {
+				loc.Release(claimant, berries, amount)
+			}
+
+			assert.Equal(t, tc.expectedReserved, loc.Reserved(berries))
+			assert.Equal(t, 0, loc.Reserved(wood))
+			for claimant, amount := range tc.expectedAvailable {
+				assert.Equal(t, amount, loc.Available(claimant, berries), "available to %s", claimant)
+			}
+			assert.Equal(t, tc.expectedClaimants, loc.Claimants())
+			assert.Equal(t, 10, loc.Inventory.GetAmount(berries), "reservations should not change the inventory")
+		})
+	}
+}
+
+func TestLocation_ReleaseAll(t *testing.T) {
+	berries := &Resource{Name: "Berries"}
+	loc := NewLocation("bush", Coord{})
+	loc.Reserve("ana", berries, 3)
+
+	assert.False(t, loc.ReleaseAll("bo"))
+	assert.True(t, loc.ReleaseAll("ana"))
+	assert.Equal(t, 0, loc.ReservedBy("ana", berries))
+	assert.False(t, loc.ReleaseAll("ana"))
+}
+
+func TestLocation_DeepCopyReservations(t *testing.T) {
+	berries := &Resource{Name: "Berries"}
+	loc := NewLocation("bush", Coord{})
+	loc.Reserve("ana", berries, 3)
+
+	copied := loc.DeepCopy()
+	assert.Equal(t, 3, copied.ReservedBy("ana", berries))
+	assert.Equal(t, loc.String(), copied.String())
+
+	copied.Reserve("ana", berries, 2)
+	assert.Equal(t, 3, loc.ReservedBy("ana", berries), "copy should not share reservations")
+	assert.NotEqual(t, loc.String(), copied.String())
+}
+
+func TestWorldState_AvailableTo(t *testing.T) {
+	berries := &Resource{Name: "Berries"}
+
+	bush := NewLocation("bush", Coord{}, WithInventory(InventoryEntry{Resource: berries, Amount: 10}))
+	bush.Reserve("ana", berries, 4)
+	bush.Reserve("bo", berries, 3)
+	depo := NewLocation("depo", Coord{})
+	world := &WorldState{
+		Locations: map[string]*Location{"bush": bush, "depo": depo},
+		Agents:    map[string]Agent{},
+	}
+
+	t.Run("others reserved", func(t *testing.T) {
+		available := world.AvailableTo("ana")
+		availableBush, _ := available.GetLocation("bush")
+		assert.Equal(t, 7, availableBush.Inventory.GetAmount(berries))
+		assert.Equal(t, []string{"ana"}, availableBush.Claimants())
+		assert.Equal(t, 7, availableBush.Available("ana", berries))
+		assert.Same(t, depo, available.Locations["depo"], "untouched locations should be shared")
+		assert.Equal(t, 10, bush.Inventory.GetAmount(berries), "original should be unchanged")
+	})
+
+	t.Run("nothing reserved by others", func(t *testing.T) {
+		solo := &WorldState{Locations: map[string]*Location{"depo": depo}}
+		assert.Same(t, solo, solo.AvailableTo("ana"))
+	})
+}
+
+func TestWorldState_ReleaseReservations(t *testing.T) {
+	berries := &Resource{Name: "Berries"}
+
+	bush := NewLocation("bush", Coord{})
+	bush.Reserve("ana", berries, 4)
+	world := &WorldState{
+		Locations: map[string]*Location{"bush": bush},
+		Agents:    map[string]Agent{},
+	}
+
+	assert.Nil(t, world.ReleaseReservations("bo"))
+
+	released := world.ReleaseReservations("ana")
+	if assert.NotNil(t, released) {
+		releasedBush, _ := released.GetLocation("bush")
+		assert.Empty(t, releasedBush.Claimants())
+	}
+	assert.Equal(t, 4, bush.ReservedBy("ana", berries), "original should be unchanged")
+}
@@ -61,6 +61,20 @@ func (r *Resource) String() string {
 	return sb.String()
 }
 
+// UnitNutrition returns how much a single unit of the resource restores the need it satisfies, or 0 if it has no
+// NourishingAttribute.
+func (r *Resource) UnitNutrition() float64 {
+	if r.attributes == nil {
+		return 0
+	}
+	for _, attr := range r.attributes.List() {
+		if nourishing, ok := attr.(NourishingAttribute); ok {
+			return nourishing.UnitNutrition()
+		}
+	}
+	return 0
+}
+
 // Attributes returns the AttributeList associated with the Resource.
 func (r *Resource) Attributes() AttributeList {
 	return r.attributes
@@ -197,3 +197,26 @@ func TestResource_Attributes(t *testing.T) {
 		})
 	}
 }
+
+// mockNourishingAttribute is a NourishingAttribute used for testing nutrition
+type mockNourishingAttribute struct {
+	mockAttribute
+	nutrition float64
+}
+
+func (m *mockNourishingAttribute) UnitNutrition() float64 {
+	return m.nutrition
+}
+
+func (m *mockNourishingAttribute) Copy() Attribute {
+	return &mockNourishingAttribute{mockAttribute: m.mockAttribute, nutrition: m.nutrition}
+}
+
+func TestResource_UnitNutrition(t *testing.T) {
+	berries := NewResource("berries", WithResourceAttributes(&mockNourishingAttribute{mockAttribute: mockAttribute{attrType: "edible"}, nutrition: 0.25}))
+	stone := NewResource("stone", WithResourceAttributes(&mockAttribute{attrType: "other"}))
+
+	assert.Equal(t, 0.25, berries.UnitNutrition())
+	assert.Equal(t, 0.0, stone.UnitNutrition())
+	assert.Equal(t, 0.0, (&Resource{Name: "bare"}).UnitNutrition())
+}
@@ -71,6 +71,34 @@ func (w *WorldState) ShallowCopy() *WorldState {
 	return newState
 }
 
+// LocationNames returns the names of all locations in the world, sorted. Iterating in this order, rather than over the
+// map, keeps the simulation deterministic.
+func (w *WorldState) LocationNames() []string {
+	return getSortedLocationKeys(w.Locations)
+}
+
+// AgentNames returns the names of all agents in the world, sorted. Iterating in this order, rather than over the map,
+// keeps the simulation deterministic.
+func (w *WorldState) AgentNames() []string {
+	return getSortedAgentKeys(w.Agents)
+}
+
+// Occupancy returns the names of the Occupants taking up each coordinate, in order of name. Coordinates no agent takes
+// up are left out. It is worked out from the agents each time it is called, so it is always up to date.
+func (w *WorldState) Occupancy() map[Coord][]string {
+	occupancy := map[Coord][]string{}
+	for _, name := range w.AgentNames() {
+		occupant, ok := w.Agents[name].(Occupant)
+		if !ok {
+			continue
+		}
+		for _, coord := range occupant.OccupiedCoords() {
+			occupancy[coord] = append(occupancy[coord], name)
+		}
+	}
+	return occupancy
+}
+
 func getSortedLocationKeys(m map[string]*Location) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -105,3 +105,47 @@ func TestWorldStateID(t *testing.T) {
 		})
 	}
 }
+
+func TestWorldStateNames(t *testing.T) {
+	ws := &WorldState{
+		Locations: map[string]*Location{
+			"c": {Name: "c"},
+			"a": {Name: "a"},
+			"b": {Name: "b"},
+		},
+		Agents: map[string]Agent{
+			"zed":   mockAgent{},
+			"alice": mockAgent{},
+		},
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, ws.LocationNames())
+	assert.Equal(t, []string{"alice", "zed"}, ws.AgentNames())
+}
+
+// mockOccupant is a mockAgent that takes up the given coordinates.
+type mockOccupant struct {
+	mockAgent
+	coords []Coord
+}
+
+func (m mockOccupant) OccupiedCoords() []Coord {
+	return m.coords
+}
+
+func TestWorldStateOccupancy(t *testing.T) {
+	ws := &WorldState{
+		Agents: map[string]Agent{
+			"zed":   mockOccupant{coords: []Coord{{X: 1, Y: 1}}},
+			"alice": mockOccupant{coords: []Coord{{X: 1, Y: 1}, {X: 2, Y: 1}}},
+			"bob":   mockOccupant{coords: []Coord{{X: 4, Y: 0}}},
+			"ghost": mockAgent{},
+		},
+	}
+
+	assert.Equal(t, map[Coord][]string{
+		{X: 1, Y: 1}: {"alice", "zed"},
+		{X: 2, Y: 1}: {"alice"},
+		{X: 4, Y: 0}: {"bob"},
+	}, ws.Occupancy())
+}
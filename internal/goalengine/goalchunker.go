@@ -1,17 +1,13 @@
 package goalengine
 
 import (
+	"reflect"
+
 	"Neolithic/internal/core"
 )
 
 const DefaultIncreaseAmount = 100
 
-// GoalEngine is a struct that encapsulates the Goal object and provides mechanisms to manage and process it.
-type GoalEngine struct {
-	// Goal is the current goal of the GoalEngine
-	Goal Goal
-}
-
 // Goal defines a specific objective and includes its name, logic, target location, and associated resource.
 type Goal struct {
 	// Name is the name of the goal
@@ -22,6 +18,20 @@ type Goal struct {
 	Location *core.Location
 	// Resource is the resource that relates to the goal
 	Resource *core.Resource
+	// Agent, if set, is the name of the agent whose inventory the goal is about. Location is ignored for such goals.
+	Agent string
+	// Target is the amount of Resource at Location, or carried by Agent, at which the goal is satisfied and retired. If
+	// zero, the goal is never satisfied.
+	Target int
+	// Priority is the priority of the goal; goals with a higher priority are pursued first
+	Priority float64
+	// PriorityFunc, if set, computes the priority of the goal from the state of the world instead of using Priority
+	PriorityFunc PriorityFunc
+	// Failures is the number of times in a row a chunk of the goal could not be planned. It is managed by the
+	// GoalEngine.
+	Failures int
+	// Retired indicates that the goal has been satisfied and is no longer pursued. It is managed by the GoalEngine.
+	Retired bool
 }
 
 // GoalLogic represents the logic for managing a goal, including chunking, fallback, and termination conditions.
@@ -54,6 +64,21 @@ var AddToLocation ChunkerFunc = func(location *core.Location, resource *core.Res
 	}
 }
 
+// AddOne is a ChunkerFunc that adds a single unit of the resource to the location's inventory
+var AddOne ChunkerFunc = func(location *core.Location, resource *core.Resource) *core.WorldState {
+	goalLocation := core.Location{
+		Name:      location.Name,
+		Inventory: core.NewInventory(),
+	}
+	goalLocation.Inventory.AdjustAmount(resource, 1)
+
+	return &core.WorldState{
+		Locations: map[string]*core.Location{
+			goalLocation.Name: &goalLocation,
+		},
+	}
+}
+
 // FallbackChunk is the function used to create a fallback chunk of a goal
 type FallbackChunk func(*core.WorldState) *core.WorldState
 
@@ -94,9 +119,97 @@ var GiveUpIfNoChange ShouldGiveUp = func(worldState *core.WorldState) bool {
 	return totalResources < 1
 }
 
+// PriorityFunc is the function used to compute the priority of a goal from the state of the world
+type PriorityFunc func(*Goal, *core.WorldState) float64
+
+// ShortfallPriority is a PriorityFunc that scales the goal's Priority by how far the goal's location is from its Target,
+// so that a goal that is nearly satisfied gives way to one that is not. Goals without a Target keep their Priority.
+var ShortfallPriority PriorityFunc = func(goal *Goal, worldState *core.WorldState) float64 {
+	if goal.Target <= 0 {
+		return goal.Priority
+	}
+	shortfall := goal.Target - goal.amountAt(worldState)
+	if shortfall <= 0 {
+		return 0
+	}
+	return goal.Priority * float64(shortfall) / float64(goal.Target)
+}
+
+// Chunkers maps names to the ChunkerFuncs provided by this package, so they can be referred to from data files.
+var Chunkers = map[string]ChunkerFunc{
+	"add_to_location": AddToLocation,
+	"add_one":         AddOne,
+}
+
+// Fallbacks maps names to the FallbackChunks provided by this package, so they can be referred to from data files.
+var Fallbacks = map[string]FallbackChunk{
+	"halve": FallbackChunkFunc,
+}
+
+// GiveUps maps names to the ShouldGiveUps provided by this package, so they can be referred to from data files.
+var GiveUps = map[string]ShouldGiveUp{
+	"less_than_five": GiveUpIfLessThanFive,
+	"no_change":      GiveUpIfNoChange,
+}
+
+// Priorities maps names to the PriorityFuncs provided by this package, so they can be referred to from data files.
+var Priorities = map[string]PriorityFunc{
+	"shortfall": ShortfallPriority,
+}
+
+// ChunkerName returns the name of a ChunkerFunc in Chunkers, or false if it isn't there.
+func ChunkerName(fn ChunkerFunc) (string, bool) {
+	for name, chunker := range Chunkers {
+		if sameFunc(chunker, fn) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// FallbackName returns the name of a FallbackChunk in Fallbacks, or false if it isn't there.
+func FallbackName(fn FallbackChunk) (string, bool) {
+	for name, fallback := range Fallbacks {
+		if sameFunc(fallback, fn) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// GiveUpName returns the name of a ShouldGiveUp in GiveUps, or false if it isn't there.
+func GiveUpName(fn ShouldGiveUp) (string, bool) {
+	for name, giveUp := range GiveUps {
+		if sameFunc(giveUp, fn) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// PriorityName returns the name of a PriorityFunc in Priorities, or false if it isn't there.
+func PriorityName(fn PriorityFunc) (string, bool) {
+	for name, priority := range Priorities {
+		if sameFunc(priority, fn) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// sameFunc reports whether two non-nil functions are the same function. Functions can't be compared with ==, so
+// their code pointers are compared instead.
+func sameFunc(a, b any) bool {
+	aVal, bVal := reflect.ValueOf(a), reflect.ValueOf(b)
+	if aVal.IsNil() || bVal.IsNil() {
+		return false
+	}
+	return aVal.Pointer() == bVal.Pointer()
+}
+
 // GetDelta returns the delta for the goal; that is, the change in amount. It does not return a full WorldState
 func (g *Goal) GetDelta(numRetries int) *core.WorldState {
-	chunk := g.Logic.Chunker(g.Location, g.Resource)
+	chunk := g.Logic.Chunker(g.chunkLocation(), g.Resource)
 
 	for i := 0; i < numRetries; i++ {
 		chunk = g.Logic.Fallback(chunk)
@@ -108,8 +221,42 @@ func (g *Goal) GetDelta(numRetries int) *core.WorldState {
 	return chunk
 }
 
+// CurrentPriority returns the priority of the goal in the given state of the world.
+func (g *Goal) CurrentPriority(worldState *core.WorldState) float64 {
+	if g.PriorityFunc != nil {
+		return g.PriorityFunc(g, worldState)
+	}
+	return g.Priority
+}
+
+// Satisfied reports whether the goal has reached its Target in the given state of the world.
+func (g *Goal) Satisfied(worldState *core.WorldState) bool {
+	return g.Target > 0 && g.amountAt(worldState) >= g.Target
+}
+
+// amountAt returns the amount of the goal's resource at its location, or carried by its agent, in the given state of
+// the world.
+func (g *Goal) amountAt(worldState *core.WorldState) int {
+	if g.Agent != "" {
+		agent, ok := worldState.GetAgent(g.Agent)
+		if !ok {
+			return 0
+		}
+		return agent.Inventory().GetAmount(g.Resource)
+	}
+	if g.Location == nil {
+		return 0
+	}
+	loc, ok := worldState.GetLocation(g.Location.Name)
+	if !ok {
+		return 0
+	}
+	return loc.Inventory.GetAmount(g.Resource)
+}
+
 // GetGoalChunk takes in the current state of the world and returns a chunked goal for that world, based on the Goal's
-// overarching requirements.
+// overarching requirements. A chunk never asks a location to hold more than its core.StorageCapacity; if the location
+// has no room left for the Resource, there is no chunk.
 func (g *Goal) GetGoalChunk(state *core.WorldState, numRetries int) *core.WorldState {
 	// Get the delta based on number of retries
 	delta := g.GetDelta(numRetries)
@@ -117,9 +264,20 @@ func (g *Goal) GetGoalChunk(state *core.WorldState, numRetries int) *core.WorldS
 		return nil
 	}
 
-	// Apply the delta to the location in the result
+	if g.Agent != "" {
+		return g.agentChunk(state, delta)
+	}
+
+	// Apply the delta to the location in the result, adding no more than the location has room for
 	for _, deltaLoc := range delta.Locations {
 		if resultLoc, exists := state.GetLocation(deltaLoc.Name); exists {
+			if increase := deltaLoc.Inventory.GetAmount(g.Resource); increase > 0 {
+				room := core.CanStore(resultLoc, g.Resource)
+				if room <= 0 {
+					return nil // the location is full, so there is nothing to plan for
+				}
+				deltaLoc.Inventory.AdjustAmount(g.Resource, min(increase, room)-increase)
+			}
 			deltaLoc.Inventory.AdjustAmount(g.Resource, resultLoc.Inventory.GetAmount(g.Resource))
 		}
 	}
@@ -127,6 +285,27 @@ func (g *Goal) GetGoalChunk(state *core.WorldState, numRetries int) *core.WorldS
 	return delta
 }
 
-func (g *GoalEngine) GetNextGoal(worldState *core.WorldState, retries int) *core.WorldState {
-	return g.Goal.GetGoalChunk(worldState, retries)
+// chunkLocation returns the location chunks of the goal are made for. Chunkers work in terms of locations, so the chunks
+// of an agent goal are made for a location named after the agent and moved onto the agent by agentChunk.
+func (g *Goal) chunkLocation() *core.Location {
+	if g.Agent != "" {
+		return &core.Location{Name: g.Agent}
+	}
+	return g.Location
+}
+
+// agentChunk turns a delta made for the goal's chunkLocation into a goal state for the inventory of the goal's agent.
+func (g *Goal) agentChunk(state *core.WorldState, delta *core.WorldState) *core.WorldState {
+	goalAgent := core.NewGoalAgent(g.Agent)
+	for _, deltaLoc := range delta.Locations {
+		for _, entry := range deltaLoc.Inventory.Entries() {
+			goalAgent.Inventory().AdjustAmount(entry.Resource, entry.Amount)
+		}
+	}
+	goalAgent.Inventory().AdjustAmount(g.Resource, g.amountAt(state))
+
+	return &core.WorldState{
+		Locations: map[string]*core.Location{},
+		Agents:    map[string]core.Agent{goalAgent.Name(): goalAgent},
+	}
 }
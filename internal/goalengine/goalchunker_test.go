@@ -3,6 +3,7 @@ package goalengine
 import (
 	"testing"
 
+	"Neolithic/internal/attributes"
 	"Neolithic/internal/core"
 
 	"github.com/stretchr/testify/require"
@@ -232,3 +233,121 @@ func TestGoal_GetGoalChunk(t *testing.T) {
 		})
 	}
 }
+
+func TestGoal_GetGoalChunk_Capacity(t *testing.T) {
+	resource := core.NewResource("test-resource", core.WithResourceAttributes(&attributes.Weight{Amount: 2}))
+	goal := Goal{
+		Name:     "test-goal",
+		Resource: resource,
+		Location: &core.Location{Name: "test-location"},
+		Logic: GoalLogic{
+			Chunker:      AddToLocation,
+			Fallback:     FallbackChunkFunc,
+			ShouldGiveUp: GiveUpIfNoChange,
+		},
+	}
+
+	type testCase struct {
+		startingAmount int
+		expectedAmount int
+		expectNil      bool
+	}
+
+	testCases := map[string]testCase{
+		"chunk bounded by the room left": {
+			startingAmount: 40,
+			expectedAmount: 50,
+		},
+		"no chunk when the location is full": {
+			startingAmount: 50,
+			expectNil:      true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			location := core.NewLocation("test-location", core.Coord{}, core.WithAttributes(&attributes.Capacity{Size: 100}))
+			location.Inventory.AdjustAmount(resource, tc.startingAmount)
+			worldState := &core.WorldState{
+				Locations: map[string]*core.Location{location.Name: location},
+			}
+
+			result := goal.GetGoalChunk(worldState, 0)
+			if tc.expectNil {
+				require.Nil(t, result)
+				return
+			}
+			require.NotNil(t, result)
+			resultLoc, exists := result.GetLocation("test-location")
+			require.True(t, exists)
+			require.Equal(t, tc.expectedAmount, resultLoc.Inventory.GetAmount(resource))
+		})
+	}
+}
+
+func TestGoal_GetGoalChunk_Agent(t *testing.T) {
+	resource := &core.Resource{Name: "test-resource"}
+	carrier := core.NewGoalAgent("test-agent")
+	carrier.Inventory().AdjustAmount(resource, 3)
+	worldState := &core.WorldState{
+		Locations: map[string]*core.Location{},
+		Agents:    map[string]core.Agent{carrier.Name(): carrier},
+	}
+
+	goal := Goal{
+		Name:     "test-goal",
+		Resource: resource,
+		Agent:    carrier.Name(),
+		Target:   4,
+		Logic: GoalLogic{
+			Chunker:      AddOne,
+			Fallback:     FallbackChunkFunc,
+			ShouldGiveUp: GiveUpIfNoChange,
+		},
+	}
+
+	result := goal.GetGoalChunk(worldState, 0)
+	require.NotNil(t, result)
+	require.Empty(t, result.Locations)
+	resultAgent, exists := result.GetAgent("test-agent")
+	require.True(t, exists, "expected test agent in result")
+	require.Equal(t, 4, resultAgent.Inventory().GetAmount(resource))
+	require.False(t, goal.Satisfied(worldState))
+
+	carrier.Inventory().AdjustAmount(resource, 1)
+	require.True(t, goal.Satisfied(worldState))
+
+	retried := goal.GetGoalChunk(worldState, 1)
+	require.NotNil(t, retried, "a single unit can't be made less ambitious, so it is kept")
+	retriedAgent, _ := retried.GetAgent("test-agent")
+	require.Equal(t, 5, retriedAgent.Inventory().GetAmount(resource))
+}
+
+func TestLogicNames(t *testing.T) {
+	for name, chunker := range Chunkers {
+		found, ok := ChunkerName(chunker)
+		require.True(t, ok)
+		require.Equal(t, name, found)
+	}
+	for name, fallback := range Fallbacks {
+		found, ok := FallbackName(fallback)
+		require.True(t, ok)
+		require.Equal(t, name, found)
+	}
+	for name, giveUp := range GiveUps {
+		found, ok := GiveUpName(giveUp)
+		require.True(t, ok)
+		require.Equal(t, name, found)
+	}
+
+	for name, priority := range Priorities {
+		found, ok := PriorityName(priority)
+		require.True(t, ok)
+		require.Equal(t, name, found)
+	}
+
+	_, ok := ChunkerName(func(*core.Location, *core.Resource) *core.WorldState { return nil })
+	require.False(t, ok)
+	_, ok = GiveUpName(nil)
+	require.False(t, ok)
+}
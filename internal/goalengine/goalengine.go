@@ -0,0 +1,115 @@
+package goalengine
+
+import (
+	"sort"
+
+	"Neolithic/internal/core"
+)
+
+// DefaultMaxFailures is the default number of times in a row a goal may fail before the GoalEngine moves on to another
+// goal.
+const DefaultMaxFailures = 3
+
+// GoalEngine manages a prioritized set of goals and decides which of them to pursue next. Goals are pursued in order of
+// priority. A goal that keeps failing is moved behind the other goals, so that they get a turn, and a goal that has
+// been satisfied is retired.
+type GoalEngine struct {
+	// Goals are the goals of the GoalEngine
+	Goals []*Goal
+	// MaxFailures is the number of times in a row a goal may fail before it is moved behind the goals that have failed
+	// less. Defaults to DefaultMaxFailures.
+	MaxFailures int
+	// current is the goal of the last chunk returned by GetNextGoal, or nil if there is none
+	current *Goal
+}
+
+// NewGoalEngine creates a GoalEngine that manages copies of the given goals.
+func NewGoalEngine(goals ...Goal) *GoalEngine {
+	engine := &GoalEngine{Goals: make([]*Goal, 0, len(goals))}
+	for _, goal := range goals {
+		engine.Goals = append(engine.Goals, &goal)
+	}
+	return engine
+}
+
+// GetNextGoal chooses the goal to pursue in the given state of the world and returns a chunk of it. Goals that have
+// been satisfied are retired first. Of the remaining goals, the ones that have failed fewer than MaxFailures times in a
+// row come first, followed by those that have failed up to twice as often and so on; within each group, goals are
+// ordered by their current priority and then by the order they were added in. Goals that give up are skipped. It
+// returns nil if there is no goal left to pursue.
+func (g *GoalEngine) GetNextGoal(worldState *core.WorldState) *core.WorldState {
+	g.current = nil
+	for _, goal := range g.candidates(worldState) {
+		chunk := goal.GetGoalChunk(worldState, goal.Failures)
+		if chunk == nil {
+			continue
+		}
+		g.current = goal
+		return chunk
+	}
+	return nil
+}
+
+// Current returns the goal of the last chunk returned by GetNextGoal, or nil if there is none.
+func (g *GoalEngine) Current() *Goal {
+	return g.current
+}
+
+// GoalFailed records that the chunk returned by the last call to GetNextGoal could not be planned. The next chunk of
+// the goal is less ambitious, and the goal moves behind the others once it has failed MaxFailures times in a row.
+func (g *GoalEngine) GoalFailed() {
+	if g.current != nil {
+		g.current.Failures++
+		g.current = nil
+	}
+}
+
+// GoalPlanned records that the chunk returned by the last call to GetNextGoal has been planned for, resetting the
+// goal's failures.
+func (g *GoalEngine) GoalPlanned() {
+	if g.current != nil {
+		g.current.Failures = 0
+	}
+}
+
+// candidates retires the satisfied goals and returns the rest in the order they should be tried.
+func (g *GoalEngine) candidates(worldState *core.WorldState) []*Goal {
+	maxFailures := g.MaxFailures
+	if maxFailures <= 0 {
+		maxFailures = DefaultMaxFailures
+	}
+
+	type candidate struct {
+		goal     *Goal
+		rotation int
+		priority float64
+	}
+	var candidates []candidate
+	for _, goal := range g.Goals {
+		if goal.Retired {
+			continue
+		}
+		if goal.Satisfied(worldState) {
+			goal.Retired = true
+			continue
+		}
+		candidates = append(candidates, candidate{
+			goal:     goal,
+			rotation: goal.Failures / maxFailures,
+			priority: goal.CurrentPriority(worldState),
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].rotation != candidates[j].rotation {
+			return candidates[i].rotation < candidates[j].rotation
+		}
+		return candidates[i].priority > candidates[j].priority
+	})
+
+	goals := make([]*Goal, len(candidates))
+	for i, c := range candidates {
+		goals[i] = c.goal
+	}
+	return goals
+}
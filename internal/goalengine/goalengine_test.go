@@ -0,0 +1,203 @@
+package goalengine
+
+import (
+	"testing"
+
+	"Neolithic/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoalEngine_GetNextGoal(t *testing.T) {
+	berries := &core.Resource{Name: "berries"}
+	wood := &core.Resource{Name: "wood"}
+
+	newGoal := func(name string, resource *core.Resource, priority float64) Goal {
+		return Goal{
+			Name:     name,
+			Location: &core.Location{Name: "depo"},
+			Resource: resource,
+			Priority: priority,
+			Logic: GoalLogic{
+				Chunker:      AddToLocation,
+				Fallback:     FallbackChunkFunc,
+				ShouldGiveUp: GiveUpIfLessThanFive,
+			},
+		}
+	}
+
+	type testCase struct {
+		goals           []Goal
+		depoInventory   map[*core.Resource]int
+		expectedGoal    string
+		expectedRetired []string
+	}
+
+	tests := map[string]testCase{
+		"highest priority first": {
+			goals: []Goal{
+				newGoal("berries", berries, 1),
+				newGoal("wood", wood, 2),
+			},
+			expectedGoal: "wood",
+		},
+		"ties go to the goal added first": {
+			goals: []Goal{
+				newGoal("berries", berries, 1),
+				newGoal("wood", wood, 1),
+			},
+			expectedGoal: "berries",
+		},
+		"priority computed from world state": {
+			goals: func() []Goal {
+				stockBerries := newGoal("berries", berries, 10)
+				stockBerries.Target = 100
+				stockBerries.PriorityFunc = ShortfallPriority
+				return []Goal{stockBerries, newGoal("wood", wood, 2)}
+			}(),
+			depoInventory: map[*core.Resource]int{berries: 90},
+			expectedGoal:  "wood",
+		},
+		"goal that keeps failing gives way": {
+			goals: func() []Goal {
+				stockWood := newGoal("wood", wood, 2)
+				stockWood.Failures = DefaultMaxFailures
+				return []Goal{newGoal("berries", berries, 1), stockWood}
+			}(),
+			expectedGoal: "berries",
+		},
+		"goal that keeps failing comes back once the others fail too": {
+			goals: func() []Goal {
+				stockBerries := newGoal("berries", berries, 1)
+				stockBerries.Failures = DefaultMaxFailures + 1
+				stockWood := newGoal("wood", wood, 2)
+				stockWood.Failures = DefaultMaxFailures
+				return []Goal{stockBerries, stockWood}
+			}(),
+			expectedGoal: "wood",
+		},
+		"goal that gives up is skipped": {
+			goals: func() []Goal {
+				stockWood := newGoal("wood", wood, 2)
+				stockWood.Failures = 10
+				return []Goal{newGoal("berries", berries, 1), stockWood}
+			}(),
+			expectedGoal: "berries",
+		},
+		"satisfied goal is retired": {
+			goals: func() []Goal {
+				stockWood := newGoal("wood", wood, 2)
+				stockWood.Target = 50
+				return []Goal{newGoal("berries", berries, 1), stockWood}
+			}(),
+			depoInventory:   map[*core.Resource]int{wood: 50},
+			expectedGoal:    "berries",
+			expectedRetired: []string{"wood"},
+		},
+		"no goals left": {
+			goals: func() []Goal {
+				stockWood := newGoal("wood", wood, 2)
+				stockWood.Target = 50
+				return []Goal{stockWood}
+			}(),
+			depoInventory:   map[*core.Resource]int{wood: 60},
+			expectedRetired: []string{"wood"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			depo := &core.Location{Name: "depo", Inventory: core.NewInventory()}
+			for res, amount := range tc.depoInventory {
+				depo.Inventory.AdjustAmount(res, amount)
+			}
+			world := &core.WorldState{Locations: map[string]*core.Location{"depo": depo}}
+
+			engine := NewGoalEngine(tc.goals...)
+			chunk := engine.GetNextGoal(world)
+
+			if tc.expectedGoal == "" {
+				assert.Nil(t, chunk)
+				assert.Nil(t, engine.Current())
+			} else {
+				require.NotNil(t, chunk)
+				require.NotNil(t, engine.Current())
+				assert.Equal(t, tc.expectedGoal, engine.Current().Name)
+			}
+
+			var retired []string
+			for _, goal := range engine.Goals {
+				if goal.Retired {
+					retired = append(retired, goal.Name)
+				}
+			}
+			assert.Equal(t, tc.expectedRetired, retired)
+		})
+	}
+}
+
+func TestGoalEngine_Rotation(t *testing.T) {
+	berries := &core.Resource{Name: "berries"}
+	wood := &core.Resource{Name: "wood"}
+	world := &core.WorldState{Locations: map[string]*core.Location{
+		"depo": {Name: "depo", Inventory: core.NewInventory()},
+	}}
+	logic := GoalLogic{
+		Chunker:      AddToLocation,
+		Fallback:     FallbackChunkFunc,
+		ShouldGiveUp: GiveUpIfLessThanFive,
+	}
+
+	engine := NewGoalEngine(
+		Goal{Name: "berries", Location: &core.Location{Name: "depo"}, Resource: berries, Priority: 2, Logic: logic},
+		Goal{Name: "wood", Location: &core.Location{Name: "depo"}, Resource: wood, Priority: 1, Logic: logic},
+	)
+	engine.MaxFailures = 2
+
+	var chosen []string
+	var amounts []int
+	for i := 0; i < 5; i++ {
+		chunk := engine.GetNextGoal(world)
+		require.NotNil(t, chunk)
+		goal := engine.Current()
+		chosen = append(chosen, goal.Name)
+		depo, _ := chunk.GetLocation("depo")
+		amounts = append(amounts, depo.Inventory.GetAmount(goal.Resource))
+		engine.GoalFailed()
+	}
+
+	assert.Equal(t, []string{"berries", "berries", "wood", "wood", "berries"}, chosen)
+	assert.Equal(t, []int{100, 50, 100, 50, 25}, amounts, "each failure should make the goal's next chunk less ambitious")
+
+	engine.GetNextGoal(world)
+	engine.GoalPlanned()
+	assert.Equal(t, 0, engine.Current().Failures)
+}
+
+func TestShortfallPriority(t *testing.T) {
+	berries := &core.Resource{Name: "berries"}
+
+	type testCase struct {
+		target   int
+		amount   int
+		expected float64
+	}
+
+	tests := map[string]testCase{
+		"no target":      {target: 0, amount: 40, expected: 8},
+		"empty":          {target: 100, amount: 0, expected: 8},
+		"partly stocked": {target: 100, amount: 75, expected: 2},
+		"over target":    {target: 100, amount: 120, expected: 0},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			depo := &core.Location{Name: "depo", Inventory: core.NewInventory()}
+			depo.Inventory.AdjustAmount(berries, tc.amount)
+			world := &core.WorldState{Locations: map[string]*core.Location{"depo": depo}}
+			goal := &Goal{Location: &core.Location{Name: "depo"}, Resource: berries, Priority: 8, Target: tc.target}
+
+			assert.InDelta(t, tc.expected, ShortfallPriority(goal, world), 1e-9)
+		})
+	}
+}
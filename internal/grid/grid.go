@@ -2,11 +2,9 @@ package grid
 
 import (
 	"fmt"
-	"math"
 
-	"Neolithic/internal/camera"
 	"Neolithic/internal/core"
-	"github.com/hajimehoshi/ebiten/v2"
+	"Neolithic/internal/pathfind"
 )
 
 // Grid represents the map, divided into Width by Height tiles.
@@ -15,12 +13,20 @@ type Grid struct {
 	Height   int
 	CellSize int
 	Tiles    [][]Tile
+	// paths finds and caches paths across the grid, or is nil until the first path is needed
+	paths *pathfind.Finder
+	// terrainVersion counts the changes to the terrain of the grid
+	terrainVersion int
 }
 
+var (
+	_ core.PathFinder = (*Grid)(nil)
+	_ core.FlowFinder = (*Grid)(nil)
+)
+
 // Tile represents a single square in the Grid
 type Tile interface {
 	core.Cell
-	Draw(*ebiten.Image, *ebiten.GeoM)
 }
 
 // New creates a new instance of Grid
@@ -52,72 +58,57 @@ func (g *Grid) Initialize(MakeTile func(X, Y int, grid *Grid) (Tile, error)) err
 
 		}
 	}
+	g.paths = nil
 	return nil
 }
 
-// DrawCell draws a grid cell
-func (g *Grid) drawCell(screen *ebiten.Image, x, y int, transform *ebiten.GeoM) {
-	worldX := float64(x * g.CellSize)
-	worldY := float64(y * g.CellSize)
-
-	var cellTransform ebiten.GeoM
-
-	cellTransform.Reset()
-	cellTransform.Translate(worldX, worldY)
-	cellTransform.Concat(*transform)
+// CellAt returns the cell at the given coordinate
+func (g *Grid) CellAt(coord core.Coord) core.Cell {
+	x := coord.X
+	y := coord.Y
 
-	g.Tiles[x][y].Draw(screen, &cellTransform)
+	if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
+		return nil
+	}
+	return g.Tiles[x][y]
 }
 
-// Draw draws the Grid, based on the viewport and camera location
-func (g *Grid) Draw(screen *ebiten.Image, viewport *camera.Viewport, camera *camera.Camera) {
-	transform := viewport.GetTransform()
-
-	screenWidth, screenHeight := viewport.Width, viewport.Height
-	cellSize := float64(g.CellSize)
-
-	invZoom := 1.0 / camera.Zoom
-
-	leftWorld := camera.X
-	rightWorld := camera.X + float64(screenWidth)*invZoom
-	topWorld := camera.Y
-	bottomWorld := camera.Y + float64(screenHeight)*invZoom
-
-	left := int(math.Floor(leftWorld / cellSize))
-	right := int(math.Ceil(rightWorld / cellSize))
-	top := int(math.Floor(topWorld / cellSize))
-	bottom := int(math.Ceil(bottomWorld / cellSize))
+// FindPath implements core.PathFinder and returns the coordinates of a path from one coordinate to the nearest of the
+// goals, including both. Paths are found hierarchically and cached, so long paths stay cheap on large grids; see
+// pathfind.Finder.
+func (g *Grid) FindPath(from core.Coord, goals ...core.Coord) ([]core.Coord, error) {
+	return g.finder().FindPath(from, goals...)
+}
 
-	// Clamp indices to grid bounds
-	if left < 0 {
-		left = 0
-	}
-	if right > g.Width {
-		right = g.Width
-	}
-	if top < 0 {
-		top = 0
-	}
-	if bottom > g.Height {
-		bottom = g.Height
+// FlowField implements core.FlowFinder and returns the flow field leading to the nearest of the targets, which is kept
+// until the terrain changes.
+func (g *Grid) FlowField(targets ...core.Coord) (core.FlowField, error) {
+	field, err := g.finder().FlowField(targets...)
+	if err != nil {
+		return nil, err
 	}
+	return field, nil
+}
 
-	for y := top; y < bottom; y++ {
-		for x := left; x < right; x++ {
-			if x >= 0 && x < g.Width && y >= 0 && y < g.Height {
-				g.drawCell(screen, x, y, &transform)
-			}
-		}
+// finder returns the finder of paths across the grid, creating it if needed.
+func (g *Grid) finder() *pathfind.Finder {
+	if g.paths == nil {
+		g.paths = pathfind.New(g, g.Width, g.Height)
 	}
+	return g.paths
 }
 
-// CellAt returns the cell at the given coordinate
-func (g *Grid) CellAt(coord core.Coord) core.Cell {
-	x := coord.X
-	y := coord.Y
-
-	if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
-		return nil
+// TerrainChanged must be called whenever the cost or passability of a tile changes, so that paths found before the
+// change are not reused.
+func (g *Grid) TerrainChanged() {
+	g.terrainVersion++
+	if g.paths != nil {
+		g.paths.Invalidate()
 	}
-	return g.Tiles[x][y]
+}
+
+// TerrainVersion returns a number that changes whenever the terrain of the grid does, so that tiles can tell whether
+// what they found out about their neighbours still holds.
+func (g *Grid) TerrainVersion() int {
+	return g.terrainVersion
 }
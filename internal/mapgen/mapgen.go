@@ -0,0 +1,453 @@
+// Package mapgen generates the terrain of a world procedurally from layers of seeded noise, and places resource
+// locations on it. The same size, parameters and seed always generate the same map.
+package mapgen
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"Neolithic/internal/core"
+	"Neolithic/internal/world"
+)
+
+const (
+	// defaultScale is the default size, in tiles, of the largest features of the terrain
+	defaultScale = 16
+	// defaultOctaves is the default number of layers of noise summed into each map
+	defaultOctaves = 4
+	// defaultPersistence is the default amplitude of each layer of noise relative to the one before
+	defaultPersistence = 0.5
+	// defaultSeaLevel is the default elevation below which the ground is water
+	defaultSeaLevel = 0.3
+	// defaultRockLevel is the default elevation above which the ground is rock
+	defaultRockLevel = 0.75
+	// defaultForestMoisture is the default moisture above which the ground is forest
+	defaultForestMoisture = 0.55
+	// defaultMarshMoisture is the default moisture above which low ground is marsh
+	defaultMarshMoisture = 0.6
+	// defaultMarshElevation is the default height above the sea level up to which wet ground is marsh
+	defaultMarshElevation = 0.1
+	// defaultRiverSource is the default elevation above which rivers may start
+	defaultRiverSource = 0.6
+	// defaultFordSpacing is the default number of tiles between the fords of a river
+	defaultFordSpacing = 8
+
+	// moistureSeedOffset varies the seed of the moisture layer, so that it is unlike the elevation layer
+	moistureSeedOffset = 0x5eed
+)
+
+// ErrInvalidParams is returned when generating a map with parameters that can't be used.
+var ErrInvalidParams = errors.New("invalid map generation parameters")
+
+// Params are the parameters of map generation. Fields left at zero take their defaults, except for Rivers, whose zero
+// value means no rivers.
+type Params struct {
+	// Seed is the seed of the map
+	Seed int64
+	// Scale is the size, in tiles, of the largest features of the terrain. Defaults to 16.
+	Scale float64
+	// Octaves is the number of layers of noise summed into each map, each with finer features than the one before.
+	// Defaults to 4.
+	Octaves int
+	// Persistence is the amplitude of each layer of noise relative to the one before. Defaults to 0.5.
+	Persistence float64
+	// SeaLevel is the elevation, between 0 and 1, below which the ground is water. Defaults to 0.3.
+	SeaLevel float64
+	// RockLevel is the elevation, between 0 and 1, above which the ground is rock. Defaults to 0.75.
+	RockLevel float64
+	// ForestMoisture is the moisture, between 0 and 1, above which the ground is forest. Defaults to 0.55.
+	ForestMoisture float64
+	// MarshMoisture is the moisture, between 0 and 1, above which low ground is marsh. Defaults to 0.6.
+	MarshMoisture float64
+	// MarshElevation is how far above SeaLevel wet ground is low enough to be marsh. Defaults to 0.1.
+	MarshElevation float64
+	// Rivers is the number of rivers, which run downhill from high ground until they reach water or the edge of the map
+	Rivers int
+	// RiverSource is the elevation, between 0 and 1, above which rivers may start. Defaults to 0.6.
+	RiverSource float64
+	// FordSpacing is the number of tiles between the fords of a river, where it is shallow enough to be crossed as
+	// marsh. Defaults to 8.
+	FordSpacing int
+	// Placements are the locations to place on the map, placed in order
+	Placements []Placement
+	// Anchors are tiles agents must be able to stand on and move between, such as where agents start and where the
+	// locations of the world are used from. An anchor on water is raised to marsh, and anchors cut off from the first
+	// are joined to it by a ford of marsh. Locations are then only placed where they can be reached from the anchors.
+	// Anchors outside the map are ignored. If there are none, locations are placed anywhere.
+	Anchors []core.Coord
+}
+
+// Placement describes a kind of location to place on tiles of a given terrain, such as berry bushes in forest.
+type Placement struct {
+	// Name is the name of the kind of location. The locations placed are named after it and numbered from 1, such as
+	// "bush1" and "bush2".
+	Name string
+	// Terrain is the terrain the locations are placed on
+	Terrain world.Terrain
+	// Count is the number of locations to place. Fewer are placed if there isn't room for them all.
+	Count int
+	// MinSpacing is the fewest tiles, along either axis, between any two locations of the map
+	MinSpacing int
+	// Inventory is the inventory each location starts with
+	Inventory []core.InventoryEntry
+	// Attributes are the attributes of each location. Every location gets its own copy of them.
+	Attributes []core.Attribute
+}
+
+// Map is a generated map.
+type Map struct {
+	// Width is the number of tiles across the map
+	Width int
+	// Height is the number of tiles down the map
+	Height int
+	// Elevation is the elevation of each tile, indexed by x and then y, between 0 and 1
+	Elevation [][]float64
+	// Moisture is the moisture of each tile, indexed by x and then y, between 0 and 1
+	Moisture [][]float64
+	// Terrain is the terrain of each tile, indexed by x and then y
+	Terrain [][]world.Terrain
+	// Locations are the locations placed on the map, in the order of the placements that placed them
+	Locations []*core.Location
+}
+
+// withDefaults returns the parameters with every zero field set to its default.
+func (p Params) withDefaults() Params {
+	if p.Scale <= 0 {
+		p.Scale = defaultScale
+	}
+	if p.Octaves <= 0 {
+		p.Octaves = defaultOctaves
+	}
+	if p.Persistence <= 0 {
+		p.Persistence = defaultPersistence
+	}
+	if p.SeaLevel == 0 {
+		p.SeaLevel = defaultSeaLevel
+	}
+	if p.RockLevel == 0 {
+		p.RockLevel = defaultRockLevel
+	}
+	if p.ForestMoisture == 0 {
+		p.ForestMoisture = defaultForestMoisture
+	}
+	if p.MarshMoisture == 0 {
+		p.MarshMoisture = defaultMarshMoisture
+	}
+	if p.MarshElevation == 0 {
+		p.MarshElevation = defaultMarshElevation
+	}
+	if p.RiverSource == 0 {
+		p.RiverSource = defaultRiverSource
+	}
+	if p.FordSpacing <= 0 {
+		p.FordSpacing = defaultFordSpacing
+	}
+	return p
+}
+
+// Generate generates a map of the given size. It returns ErrInvalidParams if the size or a placement can't be used.
+func Generate(width, height int, params Params) (*Map, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("%w: width and height must be positive", ErrInvalidParams)
+	}
+	params = params.withDefaults()
+	if params.Rivers < 0 {
+		return nil, fmt.Errorf("%w: rivers must not be negative", ErrInvalidParams)
+	}
+	for i, placement := range params.Placements {
+		if placement.Name == "" {
+			return nil, fmt.Errorf("%w: placement %d has no name", ErrInvalidParams, i)
+		}
+		if placement.Count < 0 || placement.MinSpacing < 0 {
+			return nil, fmt.Errorf("%w: placement %s: count and spacing must not be negative", ErrInvalidParams, placement.Name)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(params.Seed))
+	m := &Map{
+		Width:     width,
+		Height:    height,
+		Elevation: noiseLayer(width, height, noise{seed: uint64(params.Seed)}, params),
+		Moisture:  noiseLayer(width, height, noise{seed: uint64(params.Seed) + moistureSeedOffset}, params),
+		Terrain:   make([][]world.Terrain, width),
+	}
+	for x := 0; x < width; x++ {
+		m.Terrain[x] = make([]world.Terrain, height)
+		for y := 0; y < height; y++ {
+			m.Terrain[x][y] = classify(m.Elevation[x][y], m.Moisture[x][y], params)
+		}
+	}
+	for i := 0; i < params.Rivers; i++ {
+		m.carveRiver(rng, params)
+	}
+	m.anchor(params.Anchors)
+	reachable := m.reachableFrom(params.Anchors)
+	for _, placement := range params.Placements {
+		m.place(rng, placement, reachable)
+	}
+	return m, nil
+}
+
+// TerrainAt returns the terrain of the tile at the given coordinate. It can be passed to world.MakeTerrainTile.
+func (m *Map) TerrainAt(x, y int) world.Terrain {
+	return m.Terrain[x][y]
+}
+
+// noiseLayer returns a layer of fractal noise over the map, indexed by x and then y, stretched so that its lowest
+// value is 0 and its highest 1.
+func noiseLayer(width, height int, n noise, params Params) [][]float64 {
+	layer := make([][]float64, width)
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for x := 0; x < width; x++ {
+		layer[x] = make([]float64, height)
+		for y := 0; y < height; y++ {
+			value := n.fractal(float64(x), float64(y), params.Scale, params.Octaves, params.Persistence)
+			layer[x][y] = value
+			lowest, highest = math.Min(lowest, value), math.Max(highest, value)
+		}
+	}
+	if highest <= lowest {
+		return layer // a flat layer can't be stretched
+	}
+	for x := range layer {
+		for y := range layer[x] {
+			layer[x][y] = (layer[x][y] - lowest) / (highest - lowest)
+		}
+	}
+	return layer
+}
+
+// classify returns the terrain of a tile with the given elevation and moisture.
+func classify(elevation, moisture float64, params Params) world.Terrain {
+	switch {
+	case elevation < params.SeaLevel:
+		return world.Water
+	case elevation > params.RockLevel:
+		return world.Rock
+	case moisture > params.MarshMoisture && elevation < params.SeaLevel+params.MarshElevation:
+		return world.Marsh
+	case moisture > params.ForestMoisture:
+		return world.Forest
+	default:
+		return world.Grass
+	}
+}
+
+// carveRiver carves a river into the terrain. The river starts at a random tile above the RiverSource elevation and
+// runs to the lowest of the tiles beside it until it reaches water or the edge of the map, or can run no lower, where it
+// ends in a pool. Every FordSpacing tiles, it leaves a ford of marsh that can be crossed.
+func (m *Map) carveRiver(rng *rand.Rand, params Params) {
+	var sources []core.Coord
+	for x := 0; x < m.Width; x++ {
+		for y := 0; y < m.Height; y++ {
+			if m.Elevation[x][y] >= params.RiverSource && m.Terrain[x][y] != world.Water {
+				sources = append(sources, core.Coord{X: x, Y: y})
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return
+	}
+
+	cur := sources[rng.Intn(len(sources))]
+	visited := map[core.Coord]bool{}
+	for length := 0; ; length++ {
+		visited[cur] = true
+		if length > 0 && length%params.FordSpacing == 0 {
+			m.Terrain[cur.X][cur.Y] = world.Marsh
+		} else {
+			m.Terrain[cur.X][cur.Y] = world.Water
+		}
+
+		next, ok := m.downstream(cur, visited)
+		if !ok {
+			return // the river can run no lower, so it ends in a pool
+		}
+		if m.Terrain[next.X][next.Y] == world.Water {
+			return // the river has joined other water
+		}
+		cur = next
+		if cur.X == 0 || cur.Y == 0 || cur.X == m.Width-1 || cur.Y == m.Height-1 {
+			m.Terrain[cur.X][cur.Y] = world.Water
+			return // the river runs off the edge of the map
+		}
+	}
+}
+
+// downstream returns the lowest of the tiles beside the given one that the river hasn't visited, or false if none of
+// them is lower. Only the four tiles that share a side are considered, so that the river has no diagonal gaps.
+func (m *Map) downstream(coord core.Coord, visited map[core.Coord]bool) (core.Coord, bool) {
+	best, found := coord, false
+	lowest := m.Elevation[coord.X][coord.Y]
+	for _, d := range []core.Coord{{X: 0, Y: -1}, {X: -1, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}} {
+		next := core.Coord{X: coord.X + d.X, Y: coord.Y + d.Y}
+		if !m.inBounds(next) || visited[next] {
+			continue
+		}
+		if elevation := m.Elevation[next.X][next.Y]; elevation < lowest {
+			best, found, lowest = next, true, elevation
+		}
+	}
+	return best, found
+}
+
+// place places the locations of the placement on random tiles of its terrain for which reachable returns true, keeping
+// them MinSpacing tiles away from every location already on the map.
+func (m *Map) place(rng *rand.Rand, placement Placement, reachable func(core.Coord) bool) {
+	var candidates []core.Coord
+	for x := 0; x < m.Width; x++ {
+		for y := 0; y < m.Height; y++ {
+			if m.Terrain[x][y] == placement.Terrain && reachable(core.Coord{X: x, Y: y}) {
+				candidates = append(candidates, core.Coord{X: x, Y: y})
+			}
+		}
+	}
+	rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	placed := 0
+	for _, coord := range candidates {
+		if placed >= placement.Count {
+			return
+		}
+		if !m.hasRoom(coord, placement.MinSpacing) {
+			continue
+		}
+		attrs := make([]core.Attribute, len(placement.Attributes))
+		for i, attr := range placement.Attributes {
+			attrs[i] = attr.Copy()
+		}
+		placed++
+		m.Locations = append(m.Locations, core.NewLocation(fmt.Sprintf("%s%d", placement.Name, placed), coord,
+			core.WithInventory(placement.Inventory...),
+			core.WithAttributes(attrs...),
+		))
+	}
+}
+
+// anchor makes every anchor on the map passable, and joins the anchors cut off from the first one to it by a ford.
+func (m *Map) anchor(anchors []core.Coord) {
+	var onMap []core.Coord
+	for _, anchor := range anchors {
+		if !m.inBounds(anchor) {
+			continue
+		}
+		if !m.Terrain[anchor.X][anchor.Y].Properties().Passable {
+			m.Terrain[anchor.X][anchor.Y] = world.Marsh
+		}
+		onMap = append(onMap, anchor)
+	}
+	if len(onMap) < 2 {
+		return
+	}
+
+	regions := m.regions()
+	first := onMap[0]
+	for _, anchor := range onMap[1:] {
+		if regions[anchor.X][anchor.Y] != regions[first.X][first.Y] {
+			m.ford(anchor, first)
+			regions = m.regions()
+		}
+	}
+}
+
+// ford lays marsh over the water between two tiles, along a path that runs across and then down, so that they are in
+// the same region.
+func (m *Map) ford(from, to core.Coord) {
+	cur := from
+	for cur != to {
+		switch {
+		case cur.X < to.X:
+			cur.X++
+		case cur.X > to.X:
+			cur.X--
+		case cur.Y < to.Y:
+			cur.Y++
+		default:
+			cur.Y--
+		}
+		if !m.Terrain[cur.X][cur.Y].Properties().Passable {
+			m.Terrain[cur.X][cur.Y] = world.Marsh
+		}
+	}
+}
+
+// reachableFrom returns whether a tile is in the same region as one of the anchors, where a region is a set of passable
+// tiles connected by their sides. Agents can't cut the corner of a tile they can't cross, so tiles that only touch at
+// a corner are not connected. Every tile is reachable if there are no anchors.
+func (m *Map) reachableFrom(anchors []core.Coord) func(core.Coord) bool {
+	if len(anchors) == 0 {
+		return func(core.Coord) bool { return true }
+	}
+	regions := m.regions()
+	anchored := map[int]bool{}
+	for _, anchor := range anchors {
+		if m.inBounds(anchor) && regions[anchor.X][anchor.Y] > 0 {
+			anchored[regions[anchor.X][anchor.Y]] = true
+		}
+	}
+	return func(coord core.Coord) bool {
+		return anchored[regions[coord.X][coord.Y]]
+	}
+}
+
+// regions labels each passable tile of the map, indexed by x and then y, with the region it belongs to, numbered from
+// 1. Tiles that can't be crossed are labelled 0.
+func (m *Map) regions() [][]int {
+	labels := make([][]int, m.Width)
+	for x := range labels {
+		labels[x] = make([]int, m.Height)
+	}
+	region := 0
+	for x := 0; x < m.Width; x++ {
+		for y := 0; y < m.Height; y++ {
+			if labels[x][y] != 0 || !m.Terrain[x][y].Properties().Passable {
+				continue
+			}
+			region++
+			labels[x][y] = region
+			queue := []core.Coord{{X: x, Y: y}}
+			for len(queue) > 0 {
+				cur := queue[0]
+				queue = queue[1:]
+				for _, d := range []core.Coord{{X: 0, Y: -1}, {X: -1, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}} {
+					next := core.Coord{X: cur.X + d.X, Y: cur.Y + d.Y}
+					if !m.inBounds(next) || labels[next.X][next.Y] != 0 || !m.Terrain[next.X][next.Y].Properties().Passable {
+						continue
+					}
+					labels[next.X][next.Y] = region
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+	return labels
+}
+
+// inBounds reports whether the coordinate is on the map.
+func (m *Map) inBounds(coord core.Coord) bool {
+	return coord.X >= 0 && coord.Y >= 0 && coord.X < m.Width && coord.Y < m.Height
+}
+
+// hasRoom reports whether a location at the coordinate would be more than spacing tiles, along either axis, from
+// every location already on the map. No two locations share a tile, whatever the spacing.
+func (m *Map) hasRoom(coord core.Coord, spacing int) bool {
+	for _, loc := range m.Locations {
+		dx, dy := abs(loc.Coord.X-coord.X), abs(loc.Coord.Y-coord.Y)
+		if max(dx, dy) <= spacing {
+			return false
+		}
+	}
+	return true
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,221 @@
+package mapgen
+
+import (
+	"testing"
+
+	"Neolithic/internal/attributes"
+	"Neolithic/internal/core"
+	"Neolithic/internal/world"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate_Deterministic(t *testing.T) {
+	params := Params{
+		Seed:   3,
+		Rivers: 2,
+		Placements: []Placement{
+			{Name: "bush", Terrain: world.Forest, Count: 4, MinSpacing: 2},
+		},
+	}
+
+	first, err := Generate(32, 24, params)
+	require.NoError(t, err)
+	second, err := Generate(32, 24, params)
+	require.NoError(t, err)
+	assert.Equal(t, first, second, "the same seed should generate the same map")
+
+	params.Seed = 4
+	other, err := Generate(32, 24, params)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Terrain, other.Terrain, "different seeds should generate different terrain")
+}
+
+func TestGenerate_Layers(t *testing.T) {
+	m, err := Generate(40, 30, Params{Seed: 11})
+	require.NoError(t, err)
+	require.Len(t, m.Terrain, 40)
+	require.Len(t, m.Terrain[0], 30)
+
+	counts := map[world.Terrain]int{}
+	lowest, highest := 1.0, 0.0
+	for x := 0; x < m.Width; x++ {
+		for y := 0; y < m.Height; y++ {
+			elevation := m.Elevation[x][y]
+			lowest, highest = min(lowest, elevation), max(highest, elevation)
+			assert.GreaterOrEqual(t, m.Moisture[x][y], 0.0)
+			assert.LessOrEqual(t, m.Moisture[x][y], 1.0)
+
+			terrain := m.TerrainAt(x, y)
+			counts[terrain]++
+			switch {
+			case elevation < defaultSeaLevel:
+				assert.Equal(t, world.Water, terrain, "low ground should be water")
+			case elevation > defaultRockLevel:
+				assert.Equal(t, world.Rock, terrain, "high ground should be rock")
+			default:
+				assert.NotEqual(t, world.Water, terrain, "there should be no water above the sea level without rivers")
+			}
+		}
+	}
+	assert.Equal(t, 0.0, lowest, "elevation should be stretched to start at 0")
+	assert.Equal(t, 1.0, highest, "elevation should be stretched to end at 1")
+	assert.Positive(t, counts[world.Grass]+counts[world.Forest], "there should be open land")
+}
+
+func TestGenerate_Rivers(t *testing.T) {
+	params := Params{Seed: 5, FordSpacing: 3}
+	dry, err := Generate(32, 32, params)
+	require.NoError(t, err)
+	params.Rivers = 3
+	wet, err := Generate(32, 32, params)
+	require.NoError(t, err)
+
+	riverTiles := 0
+	for x := 0; x < wet.Width; x++ {
+		for y := 0; y < wet.Height; y++ {
+			if wet.Terrain[x][y] == dry.Terrain[x][y] {
+				continue
+			}
+			riverTiles++
+			assert.Contains(t, []world.Terrain{world.Water, world.Marsh}, wet.Terrain[x][y])
+		}
+	}
+	assert.Positive(t, riverTiles, "rivers should be carved into the terrain")
+	assert.Equal(t, dry.Elevation, wet.Elevation, "rivers should not change the elevation")
+}
+
+func TestGenerate_Placements(t *testing.T) {
+	berries := core.NewResource("Berries")
+	m, err := Generate(48, 48, Params{
+		Seed: 9,
+		Placements: []Placement{
+			{
+				Name:       "bush",
+				Terrain:    world.Forest,
+				Count:      5,
+				MinSpacing: 3,
+				Inventory:  []core.InventoryEntry{{Resource: berries, Amount: 20}},
+				Attributes: []core.Attribute{&attributes.Capacity{Size: 50}},
+			},
+			{Name: "quarry", Terrain: world.Rock, Count: 2, MinSpacing: 3},
+		},
+	})
+	require.NoError(t, err)
+
+	names := map[string]bool{}
+	for _, loc := range m.Locations {
+		names[loc.Name] = true
+	}
+	assert.Equal(t, map[string]bool{"bush1": true, "bush2": true, "bush3": true, "bush4": true, "bush5": true, "quarry1": true, "quarry2": true}, names)
+
+	for i, loc := range m.Locations {
+		expected := world.Forest
+		if loc.Name[0] == 'q' {
+			expected = world.Rock
+		} else {
+			assert.Equal(t, 20, loc.Inventory.GetAmount(berries), loc.Name)
+			require.Len(t, loc.Attributes().List(), 1, loc.Name)
+		}
+		assert.Equal(t, expected, m.TerrainAt(loc.Coord.X, loc.Coord.Y), loc.Name)
+
+		for _, other := range m.Locations[i+1:] {
+			dx, dy := abs(loc.Coord.X-other.Coord.X), abs(loc.Coord.Y-other.Coord.Y)
+			assert.Greater(t, max(dx, dy), 3, "%s and %s are too close", loc.Name, other.Name)
+		}
+	}
+	assert.NotSame(t, m.Locations[0].Attributes().List()[0], m.Locations[1].Attributes().List()[0],
+		"every location should get its own attributes")
+}
+
+func TestGenerate_Anchors(t *testing.T) {
+	params := Params{
+		Seed:       4,
+		SeaLevel:   0.5,
+		Placements: []Placement{{Name: "bush", Terrain: world.Grass, Count: 40, MinSpacing: 1}},
+	}
+	unanchored, err := Generate(48, 48, params)
+	require.NoError(t, err)
+
+	// pick an anchor on water and two on land that are cut off from each other
+	regions := unanchored.regions()
+	var water, land, island *core.Coord
+	for x := 0; x < unanchored.Width; x++ {
+		for y := 0; y < unanchored.Height; y++ {
+			coord := core.Coord{X: x, Y: y}
+			switch region := regions[x][y]; {
+			case region == 0 && water == nil:
+				water = &coord
+			case region != 0 && land == nil:
+				land = &coord
+			case region != 0 && island == nil && region != regions[land.X][land.Y]:
+				island = &coord
+			}
+		}
+	}
+	require.NotNil(t, water)
+	require.NotNil(t, land)
+	require.NotNil(t, island, "the map should have more than one region")
+
+	params.Anchors = []core.Coord{*land, *water, *island, {X: -1, Y: 60}}
+	m, err := Generate(48, 48, params)
+	require.NoError(t, err)
+
+	regions = m.regions()
+	mainland := regions[land.X][land.Y]
+	assert.Equal(t, world.Marsh, m.TerrainAt(water.X, water.Y), "an anchor on water should be raised to marsh")
+	assert.Equal(t, mainland, regions[water.X][water.Y], "the anchor on water should be joined to the others")
+	assert.Equal(t, mainland, regions[island.X][island.Y], "the island should be joined to the others")
+	require.NotEmpty(t, m.Locations)
+	for _, loc := range m.Locations {
+		assert.Equal(t, mainland, regions[loc.Coord.X][loc.Coord.Y], "%s should be reachable from the anchors", loc.Name)
+	}
+}
+
+func TestGenerate_PlacementsWithoutRoom(t *testing.T) {
+	m, err := Generate(8, 8, Params{
+		Seed:       1,
+		SeaLevel:   -1,
+		RockLevel:  2,
+		Placements: []Placement{{Name: "bush", Terrain: world.Grass, Count: 10, MinSpacing: 4}},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, m.Locations)
+	assert.Less(t, len(m.Locations), 10, "only as many locations as fit should be placed")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	type testCase struct {
+		width, height int
+		params        Params
+	}
+
+	tests := map[string]testCase{
+		"zero width": {
+			width:  0,
+			height: 8,
+		},
+		"negative rivers": {
+			width:  8,
+			height: 8,
+			params: Params{Rivers: -1},
+		},
+		"unnamed placement": {
+			width:  8,
+			height: 8,
+			params: Params{Placements: []Placement{{Terrain: world.Grass, Count: 1}}},
+		},
+		"negative count": {
+			width:  8,
+			height: 8,
+			params: Params{Placements: []Placement{{Name: "bush", Terrain: world.Grass, Count: -1}}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Generate(tc.width, tc.height, tc.params)
+			assert.ErrorIs(t, err, ErrInvalidParams)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package mapgen
+
+import "math"
+
+// noise is seeded value noise: random values on the points of an integer lattice, smoothly interpolated between them.
+// The same seed always gives the same noise.
+type noise struct {
+	// seed is the seed of the noise
+	seed uint64
+}
+
+// at returns the noise at the given point, between 0 and 1.
+func (n noise) at(x, y float64) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	ix, iy := int64(x0), int64(y0)
+	tx, ty := smoothstep(x-x0), smoothstep(y-y0)
+
+	top := lerp(n.lattice(ix, iy), n.lattice(ix+1, iy), tx)
+	bottom := lerp(n.lattice(ix, iy+1), n.lattice(ix+1, iy+1), tx)
+	return lerp(top, bottom, ty)
+}
+
+// fractal returns the sum of octaves layers of noise at the given point, each at twice the frequency and persistence
+// times the amplitude of the one before. scale is the size of the features of the first layer. The sum is normalized
+// to be between 0 and 1.
+func (n noise) fractal(x, y, scale float64, octaves int, persistence float64) float64 {
+	var total, totalAmplitude float64
+	amplitude, frequency := 1.0, 1/scale
+	for i := 0; i < octaves; i++ {
+		// each octave is offset, so that the lattice points of the layers don't line up
+		offset := float64(i) * 17.31
+		total += amplitude * n.at(x*frequency+offset, y*frequency+offset)
+		totalAmplitude += amplitude
+		amplitude *= persistence
+		frequency *= 2
+	}
+	return total / totalAmplitude
+}
+
+// lattice returns the random value at a point of the lattice, between 0 and 1.
+func (n noise) lattice(x, y int64) float64 {
+	h := splitmix64(n.seed ^ splitmix64(uint64(x)^splitmix64(uint64(y))))
+	return float64(h>>11) / float64(1<<53)
+}
+
+// splitmix64 scrambles the bits of x, so that nearby inputs give unrelated outputs.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// smoothstep eases t, between 0 and 1, so that interpolation has no visible creases at the lattice points.
+func smoothstep(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
+
+// lerp interpolates linearly between a and b.
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
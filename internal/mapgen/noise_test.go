@@ -0,0 +1,41 @@
+package mapgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoise_At(t *testing.T) {
+	n := noise{seed: 42}
+	other := noise{seed: 43}
+
+	differs := false
+	for x := 0.0; x < 8; x += 0.37 {
+		for y := 0.0; y < 8; y += 0.41 {
+			value := n.at(x, y)
+			assert.GreaterOrEqual(t, value, 0.0)
+			assert.LessOrEqual(t, value, 1.0)
+			assert.Equal(t, value, noise{seed: 42}.at(x, y), "the same seed should give the same noise")
+			if value != other.at(x, y) {
+				differs = true
+			}
+		}
+	}
+	assert.True(t, differs, "different seeds should give different noise")
+
+	assert.Equal(t, n.lattice(3, 5), n.at(3, 5), "noise should pass through the lattice points")
+	assert.InDelta(t, n.at(3, 5), n.at(3.001, 5), 0.01, "noise should be smooth")
+}
+
+func TestNoise_Fractal(t *testing.T) {
+	n := noise{seed: 7}
+	for x := 0.0; x < 32; x += 3.3 {
+		for y := 0.0; y < 32; y += 2.9 {
+			value := n.fractal(x, y, 16, 4, 0.5)
+			assert.GreaterOrEqual(t, value, 0.0)
+			assert.LessOrEqual(t, value, 1.0)
+		}
+	}
+	assert.Equal(t, n.at(0.25, 0.5), n.fractal(4, 8, 16, 1, 0.5), "a single octave should be plain noise at the scale")
+}
@@ -0,0 +1,88 @@
+package pathfind
+
+import (
+	"Neolithic/internal/astar"
+	"Neolithic/internal/core"
+)
+
+// bounds is an inclusive rectangle of coordinates.
+type bounds struct {
+	minX, minY, maxX, maxY int
+}
+
+// contains returns whether the coordinate is inside the bounds.
+func (b bounds) contains(coord core.Coord) bool {
+	return coord.X >= b.minX && coord.X <= b.maxX && coord.Y >= b.minY && coord.Y <= b.maxY
+}
+
+// boundedCell is a cell searched without leaving bounds, so that searches inside a cluster stay as small as it is.
+type boundedCell struct {
+	cell   core.Cell
+	bounds bounds
+}
+
+var _ astar.Node = (*boundedCell)(nil)
+
+// Heuristic implements astar.Node.
+func (b *boundedCell) Heuristic(goal astar.Node) (float64, error) {
+	return b.cell.Heuristic(goal.(*boundedCell).cell)
+}
+
+// ID implements astar.Node.
+func (b *boundedCell) ID() (string, error) {
+	return b.cell.ID()
+}
+
+// Cost implements astar.Node.
+func (b *boundedCell) Cost(prev astar.Node) float64 {
+	return b.cell.Cost(prev.(*boundedCell).cell)
+}
+
+// GetSuccessors implements astar.Node and returns the successors of the cell that are inside the bounds.
+func (b *boundedCell) GetSuccessors() ([]astar.Node, error) {
+	successors, err := b.cell.GetSuccessors()
+	if err != nil {
+		return nil, err
+	}
+	bounded := make([]astar.Node, 0, len(successors))
+	for _, successor := range successors {
+		cell := successor.(core.Cell)
+		if b.bounds.contains(cell.Coord()) {
+			bounded = append(bounded, &boundedCell{cell: cell, bounds: b.bounds})
+		}
+	}
+	return bounded, nil
+}
+
+// edge is a way from one abstract node to another, and the coordinates it goes through, including both ends.
+type edge struct {
+	to   core.Coord
+	cost float64
+	path []core.Coord
+}
+
+// node is an abstract node: a tile on the border of a cluster where paths cross into the next cluster.
+type node struct {
+	coord core.Coord
+	// edges are the ways to the node's neighbours, in the order they were found
+	edges []edge
+}
+
+// edgeTo returns the edge from the node to the coordinate, or false if there is none.
+func (n *node) edgeTo(coord core.Coord) (edge, bool) {
+	for _, e := range n.edges {
+		if e.to == coord {
+			return e, true
+		}
+	}
+	return edge{}, false
+}
+
+// cluster is a square of the grid, whose border nodes are linked to each other by the paths inside it.
+type cluster struct {
+	bounds bounds
+	// nodes are the coordinates of the cluster's border nodes
+	nodes []core.Coord
+	// linked indicates that the paths between the cluster's nodes have been found
+	linked bool
+}
@@ -0,0 +1,386 @@
+package pathfind
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"Neolithic/internal/astar"
+	"Neolithic/internal/core"
+	"Neolithic/internal/logging"
+)
+
+const (
+	// DefaultClusterSize is the width and height, in tiles, of the clusters a Finder divides the grid into
+	DefaultClusterSize = 16
+	// maxCachedPaths is the most paths a Finder keeps; the cache is emptied when it fills up
+	maxCachedPaths = 1024
+	// maxCachedFields is the most flow fields a Finder keeps; the cache is emptied when it fills up
+	maxCachedFields = 32
+	// minDoubleEntranceWidth is how wide a gap between two clusters must be to get a node at each end instead of a
+	// single one in the middle
+	minDoubleEntranceWidth = 6
+)
+
+var (
+	// ErrNoPath is returned when there is no path between two coordinates
+	ErrNoPath = errors.New("no path found")
+	// ErrOutOfBounds is returned when a coordinate is outside the grid
+	ErrOutOfBounds = errors.New("coordinate is outside the grid")
+)
+
+// Finder finds paths across a grid of cells using hierarchical pathfinding. The grid is divided into square clusters,
+// and the tiles where paths can cross from one cluster into the next become the nodes of a much smaller abstract
+// graph. A long path is found by searching the abstract graph and then filling in the steps inside each cluster, so
+// it costs about the same however far apart its ends are. Paths may be a little longer than the shortest ones.
+//
+// Paths are cached by their start and goals, and flow fields by their goals, until Invalidate is called, which must be
+// done whenever the cost or passability of a cell changes.
+type Finder struct {
+	// Hits is the number of paths and flow fields that were found in the cache
+	Hits int
+	// Misses is the number of paths and flow fields that had to be searched for
+	Misses int
+	// grid is the grid of cells searched
+	grid core.Grid
+	// width and height are the size of the grid
+	width, height int
+	// clusterSize is the width and height of a cluster
+	clusterSize int
+	// clusters are the clusters of the grid indexed by [x][y], or nil if they need to be built
+	clusters [][]*cluster
+	// nodes are the abstract nodes by coordinate
+	nodes map[core.Coord]*node
+	// cache holds the paths found so far
+	cache map[pathKey][]core.Coord
+	// fields holds the flow fields built so far, by their goals
+	fields map[string]*FlowField
+	// logger is the logger
+	logger *slog.Logger
+}
+
+// pathKey identifies a cached path by its start and goals.
+type pathKey struct {
+	from  core.Coord
+	goals string
+}
+
+// Option is an optional configuration to provide when creating a new Finder
+type Option func(*Finder)
+
+// WithClusterSize sets the width and height, in tiles, of the clusters the grid is divided into
+func WithClusterSize(size int) Option {
+	return func(f *Finder) {
+		f.clusterSize = size
+	}
+}
+
+// WithLogger allows the creator to set a given logger
+func WithLogger(l *slog.Logger) Option {
+	return func(f *Finder) {
+		f.logger = l
+	}
+}
+
+// New creates a Finder for a grid of the given width and height.
+func New(grid core.Grid, width, height int, opts ...Option) *Finder {
+	finder := &Finder{
+		grid:   grid,
+		width:  width,
+		height: height,
+		cache:  make(map[pathKey][]core.Coord),
+		fields: make(map[string]*FlowField),
+	}
+	for _, opt := range opts {
+		opt(finder)
+	}
+	if finder.clusterSize <= 0 {
+		finder.clusterSize = DefaultClusterSize
+	}
+	if finder.logger == nil {
+		finder.logger = logging.NewLogger("info")
+	}
+	return finder
+}
+
+// FindPath returns the coordinates of a path from one coordinate to the nearest of the goals, including both. Goals
+// outside the grid are ignored. The returned slice is the caller's to keep.
+func (f *Finder) FindPath(from core.Coord, goals ...core.Coord) ([]core.Coord, error) {
+	if f.grid.CellAt(from) == nil {
+		return nil, fmt.Errorf("%w: %v", ErrOutOfBounds, from)
+	}
+	goals, err := f.inBounds(goals)
+	if err != nil {
+		return nil, err
+	}
+
+	key := pathKey{from: from, goals: fmt.Sprint(goals)}
+	if path, ok := f.cache[key]; ok {
+		f.Hits++
+		return copyPath(path), nil
+	}
+	f.Misses++
+
+	path, err := f.search(from, goals)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.cache) >= maxCachedPaths {
+		clear(f.cache)
+	}
+	f.cache[key] = path
+	return copyPath(path), nil
+}
+
+// FlowField returns the flow field leading to the nearest of the goals, building it the first time it is asked for.
+// Goals outside the grid are ignored.
+func (f *Finder) FlowField(goals ...core.Coord) (*FlowField, error) {
+	goals, err := f.inBounds(goals)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprint(goals)
+	if field, ok := f.fields[key]; ok {
+		f.Hits++
+		return field, nil
+	}
+	f.Misses++
+
+	field, err := newFlowField(f.grid, f.width, f.height, goals)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.fields) >= maxCachedFields {
+		clear(f.fields)
+	}
+	f.fields[key] = field
+	return field, nil
+}
+
+// Invalidate forgets the cached paths and flow fields and the abstract graph, which is rebuilt the next time a path is
+// needed. It must be called whenever the cost or passability of a cell changes.
+func (f *Finder) Invalidate() {
+	f.clusters = nil
+	f.nodes = nil
+	clear(f.cache)
+	clear(f.fields)
+}
+
+// inBounds returns the goals that are inside the grid, or ErrOutOfBounds if there are none.
+func (f *Finder) inBounds(goals []core.Coord) ([]core.Coord, error) {
+	kept := make([]core.Coord, 0, len(goals))
+	for _, goal := range goals {
+		if f.grid.CellAt(goal) != nil {
+			kept = append(kept, goal)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("%w: goals %v", ErrOutOfBounds, goals)
+	}
+	return kept, nil
+}
+
+// search finds a path from one coordinate to the nearest of the goals by searching the abstract graph.
+func (f *Finder) search(from core.Coord, goals []core.Coord) ([]core.Coord, error) {
+	if slices.Contains(goals, from) {
+		return []core.Coord{from}, nil
+	}
+	if f.clusters == nil {
+		if err := f.build(); err != nil {
+			return nil, err
+		}
+	}
+
+	q := &query{finder: f, goals: goals, extra: make(map[core.Coord][]edge)}
+	if err := q.connect(from); err != nil {
+		return nil, err
+	}
+	return q.run(from)
+}
+
+// build divides the grid into clusters and finds the nodes where paths cross between them. The paths inside each
+// cluster are only found once a search reaches it; see link.
+func (f *Finder) build() error {
+	columns := (f.width + f.clusterSize - 1) / f.clusterSize
+	rows := (f.height + f.clusterSize - 1) / f.clusterSize
+	f.nodes = make(map[core.Coord]*node)
+	f.clusters = make([][]*cluster, columns)
+	for cx := range columns {
+		f.clusters[cx] = make([]*cluster, rows)
+		for cy := range rows {
+			minX, minY := cx*f.clusterSize, cy*f.clusterSize
+			f.clusters[cx][cy] = &cluster{bounds: bounds{
+				minX: minX,
+				minY: minY,
+				maxX: min(minX+f.clusterSize, f.width) - 1,
+				maxY: min(minY+f.clusterSize, f.height) - 1,
+			}}
+		}
+	}
+
+	for cx := range columns {
+		for cy := range rows {
+			b := f.clusters[cx][cy].bounds
+			if cx+1 < columns {
+				if err := f.addEntrances(b.minY, b.maxY, func(i int) (core.Coord, core.Coord) {
+					return core.Coord{X: b.maxX, Y: i}, core.Coord{X: b.maxX + 1, Y: i}
+				}); err != nil {
+					return err
+				}
+			}
+			if cy+1 < rows {
+				if err := f.addEntrances(b.minX, b.maxX, func(i int) (core.Coord, core.Coord) {
+					return core.Coord{X: i, Y: b.maxY}, core.Coord{X: i, Y: b.maxY + 1}
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addEntrances adds nodes along the border between two clusters, where pair gives the facing coordinates on either
+// side for each index from first to last. Each unbroken run of crossings gets a node in the middle, or one at each end
+// if it is wide.
+func (f *Finder) addEntrances(first, last int, pair func(i int) (core.Coord, core.Coord)) error {
+	runStart := -1
+	for i := first; i <= last+1; i++ {
+		crossable := false
+		if i <= last {
+			var err error
+			if crossable, err = f.crossable(pair(i)); err != nil {
+				return err
+			}
+		}
+		if crossable {
+			if runStart < 0 {
+				runStart = i
+			}
+			continue
+		}
+		if runStart < 0 {
+			continue
+		}
+		runEnd := i - 1
+		if runEnd-runStart+1 >= minDoubleEntranceWidth {
+			f.addEntrance(pair(runStart))
+			f.addEntrance(pair(runEnd))
+		} else {
+			f.addEntrance(pair((runStart + runEnd) / 2))
+		}
+		runStart = -1
+	}
+	return nil
+}
+
+// crossable returns whether a path can step between the two coordinates in both directions.
+func (f *Finder) crossable(a, b core.Coord) (bool, error) {
+	aToB, err := isSuccessor(f.grid.CellAt(a), b)
+	if err != nil || !aToB {
+		return false, err
+	}
+	return isSuccessor(f.grid.CellAt(b), a)
+}
+
+// addEntrance adds a node on each side of a crossing between clusters, joined by the step between them.
+func (f *Finder) addEntrance(a, b core.Coord) {
+	nodeA, nodeB := f.addNode(a), f.addNode(b)
+	cellA, cellB := f.grid.CellAt(a), f.grid.CellAt(b)
+	nodeA.edges = append(nodeA.edges, edge{to: b, cost: cellB.Cost(cellA), path: []core.Coord{a, b}})
+	nodeB.edges = append(nodeB.edges, edge{to: a, cost: cellA.Cost(cellB), path: []core.Coord{b, a}})
+}
+
+// addNode returns the node at the coordinate, adding it to its cluster if there is none yet.
+func (f *Finder) addNode(coord core.Coord) *node {
+	if n, ok := f.nodes[coord]; ok {
+		return n
+	}
+	n := &node{coord: coord}
+	f.nodes[coord] = n
+	c := f.clusterOf(coord)
+	c.nodes = append(c.nodes, coord)
+	return n
+}
+
+// link finds the paths between every pair of the cluster's nodes, if it hasn't already.
+func (f *Finder) link(c *cluster) error {
+	if c.linked {
+		return nil
+	}
+	for _, from := range c.nodes {
+		for _, to := range c.nodes {
+			if from == to {
+				continue
+			}
+			path, cost, found, err := f.localPath(from, to, c.bounds)
+			if err != nil {
+				return err
+			}
+			if found {
+				n := f.nodes[from]
+				n.edges = append(n.edges, edge{to: to, cost: cost, path: path})
+			}
+		}
+	}
+	c.linked = true
+	return nil
+}
+
+// clusterOf returns the cluster the coordinate is in.
+func (f *Finder) clusterOf(coord core.Coord) *cluster {
+	return f.clusters[coord.X/f.clusterSize][coord.Y/f.clusterSize]
+}
+
+// localPath searches for a path between two coordinates that stays inside the bounds, and returns it with its cost,
+// or false if there is none. Grids of Walkable cells are searched by jump point search, and others cell by cell.
+func (f *Finder) localPath(from, to core.Coord, b bounds) ([]core.Coord, float64, bool, error) {
+	if _, ok := f.grid.CellAt(from).(Walkable); ok {
+		jump := &jumpSearch{grid: f.grid, bounds: b, goal: to}
+		return jump.run(from, astar.WithLogger(f.logger))
+	}
+
+	start := &boundedCell{cell: f.grid.CellAt(from), bounds: b}
+	goal := &boundedCell{cell: f.grid.CellAt(to), bounds: b}
+	search, err := astar.NewSearch(start, goal, astar.WithLogger(f.logger))
+	if err != nil {
+		return nil, 0, false, err
+	}
+	area := (b.maxX - b.minX + 1) * (b.maxY - b.minY + 1)
+	if err = search.RunIterations(2 * area); err != nil {
+		if errors.Is(err, astar.ErrNoPath) {
+			return nil, 0, false, nil
+		}
+		return nil, 0, false, err
+	}
+	if !search.HasSolution() {
+		return nil, 0, false, nil
+	}
+
+	nodes := search.CurrentBestPath()
+	path := make([]core.Coord, len(nodes))
+	for i, n := range nodes {
+		path[i] = n.(*boundedCell).cell.Coord()
+	}
+	return path, search.BestCost, true, nil
+}
+
+// isSuccessor returns whether the coordinate is one of the cell's successors.
+func isSuccessor(cell core.Cell, coord core.Coord) (bool, error) {
+	successors, err := cell.GetSuccessors()
+	if err != nil {
+		return false, err
+	}
+	for _, successor := range successors {
+		if successor.(core.Cell).Coord() == coord {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// copyPath returns a copy of the path.
+func copyPath(path []core.Coord) []core.Coord {
+	return append([]core.Coord(nil), path...)
+}
@@ -0,0 +1,407 @@
+package pathfind
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"Neolithic/internal/astar"
+	"Neolithic/internal/core"
+)
+
+// testGrid is a grid of open and walled cells, indexed by [x][y]. Moving onto a cell costs 1, unless costs says
+// otherwise.
+type testGrid struct {
+	walls [][]bool
+	costs map[core.Coord]float64
+}
+
+// newTestGrid creates a grid from rows of '.' for open cells and '#' for walls.
+func newTestGrid(rows ...string) *testGrid {
+	g := &testGrid{walls: make([][]bool, len(rows[0]))}
+	for x := range g.walls {
+		g.walls[x] = make([]bool, len(rows))
+		for y, row := range rows {
+			g.walls[x][y] = row[x] == '#'
+		}
+	}
+	return g
+}
+
+// newOpenGrid creates an open grid of the given size.
+func newOpenGrid(width, height int) *testGrid {
+	g := &testGrid{walls: make([][]bool, width)}
+	for x := range g.walls {
+		g.walls[x] = make([]bool, height)
+	}
+	return g
+}
+
+func (g *testGrid) width() int  { return len(g.walls) }
+func (g *testGrid) height() int { return len(g.walls[0]) }
+
+func (g *testGrid) open(coord core.Coord) bool {
+	return coord.X >= 0 && coord.X < g.width() && coord.Y >= 0 && coord.Y < g.height() && !g.walls[coord.X][coord.Y]
+}
+
+func (g *testGrid) CellAt(coord core.Coord) core.Cell {
+	if coord.X < 0 || coord.X >= g.width() || coord.Y < 0 || coord.Y >= g.height() {
+		return nil
+	}
+	return &testCell{grid: g, coord: coord}
+}
+
+type testCell struct {
+	grid  *testGrid
+	coord core.Coord
+}
+
+func (c *testCell) Heuristic(goal astar.Node) (float64, error) {
+	g := goal.(*testCell)
+	return math.Hypot(float64(g.coord.X-c.coord.X), float64(g.coord.Y-c.coord.Y)), nil
+}
+
+func (c *testCell) ID() (string, error) {
+	return fmt.Sprintf("%d,%d", c.coord.X, c.coord.Y), nil
+}
+
+func (c *testCell) Cost(prev astar.Node) float64 {
+	p := prev.(*testCell)
+	if p.coord.X != c.coord.X && p.coord.Y != c.coord.Y {
+		return 1.4 * c.MoveCost()
+	}
+	return c.MoveCost()
+}
+
+func (c *testCell) Passable() bool {
+	return c.grid.open(c.coord)
+}
+
+func (c *testCell) MoveCost() float64 {
+	if cost, ok := c.grid.costs[c.coord]; ok {
+		return cost
+	}
+	return 1
+}
+
+func (c *testCell) GetSuccessors() ([]astar.Node, error) {
+	var successors []astar.Node
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			coord := core.Coord{X: c.coord.X + dx, Y: c.coord.Y + dy}
+			if (dx == 0 && dy == 0) || !c.grid.open(coord) {
+				continue
+			}
+			if dx != 0 && dy != 0 &&
+				(!c.grid.open(core.Coord{X: c.coord.X + dx, Y: c.coord.Y}) || !c.grid.open(core.Coord{X: c.coord.X, Y: c.coord.Y + dy})) {
+				continue
+			}
+			successors = append(successors, &testCell{grid: c.grid, coord: coord})
+		}
+	}
+	return successors, nil
+}
+
+func (c *testCell) Coord() core.Coord {
+	return c.coord
+}
+
+// opaqueGrid hides that the cells of a testGrid are Walkable, so that they are searched cell by cell.
+type opaqueGrid struct {
+	*testGrid
+}
+
+func (g opaqueGrid) CellAt(coord core.Coord) core.Cell {
+	if coord.X < 0 || coord.X >= g.width() || coord.Y < 0 || coord.Y >= g.height() {
+		return nil
+	}
+	return &opaqueCell{cell: &testCell{grid: g.testGrid, coord: coord}}
+}
+
+type opaqueCell struct {
+	cell *testCell
+}
+
+func (c *opaqueCell) Heuristic(goal astar.Node) (float64, error) {
+	return c.cell.Heuristic(goal.(*opaqueCell).cell)
+}
+
+func (c *opaqueCell) ID() (string, error) {
+	return c.cell.ID()
+}
+
+func (c *opaqueCell) Cost(prev astar.Node) float64 {
+	return c.cell.Cost(prev.(*opaqueCell).cell)
+}
+
+func (c *opaqueCell) GetSuccessors() ([]astar.Node, error) {
+	successors, err := c.cell.GetSuccessors()
+	for i, successor := range successors {
+		successors[i] = &opaqueCell{cell: successor.(*testCell)}
+	}
+	return successors, err
+}
+
+func (c *opaqueCell) Coord() core.Coord {
+	return c.cell.coord
+}
+
+// assertWalkable asserts that the path goes from one coordinate to the other over open cells, a step at a time.
+func assertWalkable(t *testing.T, g *testGrid, path []core.Coord, from, to core.Coord) {
+	t.Helper()
+	require.NotEmpty(t, path)
+	assert.Equal(t, from, path[0])
+	assert.Equal(t, to, path[len(path)-1])
+	for i, coord := range path {
+		assert.True(t, g.open(coord), "step %d onto wall %v", i, coord)
+		if i > 0 {
+			prev := path[i-1]
+			assert.True(t, coord != prev && coord.IsWithin(prev, 1), "step %d from %v to %v is not a single move", i, prev, coord)
+		}
+	}
+}
+
+func TestFinder_FindPath(t *testing.T) {
+	type testCase struct {
+		grid          *testGrid
+		opaque        bool
+		from, to      core.Coord
+		expectedSteps int
+		expectedError error
+	}
+
+	walled := newTestGrid(
+		"....#....",
+		"....#....",
+		"....#....",
+		"....#....",
+		"....#....",
+		"....#....",
+		".........",
+	)
+	sealed := newTestGrid(
+		"....#....",
+		"....#....",
+		"....#....",
+	)
+
+	tests := map[string]testCase{
+		"same coordinate": {
+			grid:          newOpenGrid(8, 8),
+			from:          core.Coord{X: 2, Y: 2},
+			to:            core.Coord{X: 2, Y: 2},
+			expectedSteps: 0,
+		},
+		"inside one cluster": {
+			grid:          newOpenGrid(8, 8),
+			from:          core.Coord{X: 0, Y: 0},
+			to:            core.Coord{X: 3, Y: 2},
+			expectedSteps: 3,
+		},
+		"across clusters": {
+			grid:          newOpenGrid(12, 12),
+			from:          core.Coord{X: 0, Y: 0},
+			to:            core.Coord{X: 11, Y: 0},
+			expectedSteps: 11,
+		},
+		"around a wall": {
+			grid: walled,
+			from: core.Coord{X: 0, Y: 0},
+			to:   core.Coord{X: 8, Y: 0},
+		},
+		"around a wall cell by cell": {
+			grid:   walled,
+			opaque: true,
+			from:   core.Coord{X: 0, Y: 0},
+			to:     core.Coord{X: 8, Y: 0},
+		},
+		"no way through": {
+			grid:          sealed,
+			from:          core.Coord{X: 0, Y: 0},
+			to:            core.Coord{X: 8, Y: 0},
+			expectedError: ErrNoPath,
+		},
+		"outside the grid": {
+			grid:          newOpenGrid(8, 8),
+			from:          core.Coord{X: 0, Y: 0},
+			to:            core.Coord{X: 8, Y: 0},
+			expectedError: ErrOutOfBounds,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var grid core.Grid = tc.grid
+			if tc.opaque {
+				grid = opaqueGrid{tc.grid}
+			}
+			finder := New(grid, tc.grid.width(), tc.grid.height(), WithClusterSize(4))
+
+			path, err := finder.FindPath(tc.from, tc.to)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assertWalkable(t, tc.grid, path, tc.from, tc.to)
+			if tc.expectedSteps > 0 {
+				assert.Len(t, path, tc.expectedSteps+1)
+			}
+		})
+	}
+}
+
+func TestFinder_FindPathNearestGoal(t *testing.T) {
+	type testCase struct {
+		grid          *testGrid
+		opaque        bool
+		goals         []core.Coord
+		expectedEnd   core.Coord
+		expectedSteps int
+		expectedError error
+	}
+
+	walled := newTestGrid(
+		"....#....",
+		"....#....",
+		"....#....",
+		"....#....",
+		"....#....",
+		"....#....",
+		".........",
+	)
+	sealed := newTestGrid(
+		"....#....",
+		"....#....",
+		"....#....",
+	)
+	from := core.Coord{X: 0, Y: 0}
+
+	tests := map[string]testCase{
+		"nearest of two": {
+			grid:          newOpenGrid(12, 12),
+			goals:         []core.Coord{{X: 11, Y: 11}, {X: 3, Y: 0}},
+			expectedEnd:   core.Coord{X: 3, Y: 0},
+			expectedSteps: 3,
+		},
+		"nearest by path rather than by distance": {
+			grid:          walled,
+			goals:         []core.Coord{{X: 5, Y: 0}, {X: 2, Y: 5}},
+			expectedEnd:   core.Coord{X: 2, Y: 5},
+			expectedSteps: 5,
+		},
+		"nearest by path cell by cell": {
+			grid:          walled,
+			opaque:        true,
+			goals:         []core.Coord{{X: 5, Y: 0}, {X: 2, Y: 5}},
+			expectedEnd:   core.Coord{X: 2, Y: 5},
+			expectedSteps: 5,
+		},
+		"unreachable goal passed over": {
+			grid:          sealed,
+			goals:         []core.Coord{{X: 8, Y: 0}, {X: 2, Y: 2}},
+			expectedEnd:   core.Coord{X: 2, Y: 2},
+			expectedSteps: 2,
+		},
+		"goal outside the grid ignored": {
+			grid:          newOpenGrid(8, 8),
+			goals:         []core.Coord{{X: 8, Y: 0}, {X: 3, Y: 3}},
+			expectedEnd:   core.Coord{X: 3, Y: 3},
+			expectedSteps: 3,
+		},
+		"already at a goal": {
+			grid:        newOpenGrid(8, 8),
+			goals:       []core.Coord{{X: 3, Y: 3}, from},
+			expectedEnd: from,
+		},
+		"no goal reachable": {
+			grid:          sealed,
+			goals:         []core.Coord{{X: 8, Y: 0}, {X: 6, Y: 2}},
+			expectedError: ErrNoPath,
+		},
+		"every goal outside the grid": {
+			grid:          newOpenGrid(8, 8),
+			goals:         []core.Coord{{X: 8, Y: 0}, {X: 0, Y: -1}},
+			expectedError: ErrOutOfBounds,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var grid core.Grid = tc.grid
+			if tc.opaque {
+				grid = opaqueGrid{tc.grid}
+			}
+			finder := New(grid, tc.grid.width(), tc.grid.height(), WithClusterSize(4))
+
+			path, err := finder.FindPath(from, tc.goals...)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assertWalkable(t, tc.grid, path, from, tc.expectedEnd)
+			assert.Len(t, path, tc.expectedSteps+1)
+		})
+	}
+}
+
+func TestFinder_LongPath(t *testing.T) {
+	// walls every 16 columns, with gaps at alternate ends, make the way from one corner to the other wind across the
+	// whole map, further than a plain search of the tiles can go
+	g := newOpenGrid(256, 256)
+	for x := 15; x < 255; x += 16 {
+		gap := 0
+		if (x/16)%2 == 0 {
+			gap = 255
+		}
+		for y := range 256 {
+			g.walls[x][y] = y != gap
+		}
+	}
+	from, to := core.Coord{X: 0, Y: 255}, core.Coord{X: 255, Y: 0}
+
+	plain, err := astar.NewSearch(g.CellAt(from), g.CellAt(to))
+	require.NoError(t, err)
+	require.NoError(t, plain.RunIterations(10000))
+	assert.False(t, plain.HasSolution())
+
+	finder := New(g, 256, 256)
+	path, err := finder.FindPath(from, to)
+	require.NoError(t, err)
+	assertWalkable(t, g, path, from, to)
+	assert.Greater(t, len(path), 15*255) // down or up the length of the map between each pair of walls
+}
+
+func TestFinder_Cache(t *testing.T) {
+	g := newOpenGrid(12, 12)
+	finder := New(g, 12, 12, WithClusterSize(4))
+	from, to := core.Coord{X: 0, Y: 5}, core.Coord{X: 11, Y: 5}
+
+	first, err := finder.FindPath(from, to)
+	require.NoError(t, err)
+	first[1] = core.Coord{X: 99, Y: 99} // the caller's copy may be changed freely
+
+	second, err := finder.FindPath(from, to)
+	require.NoError(t, err)
+	assert.Equal(t, 1, finder.Hits)
+	assert.Equal(t, 1, finder.Misses)
+	assertWalkable(t, g, second, from, to)
+
+	// wall off the middle but for a gap at the bottom
+	for y := range 11 {
+		g.walls[6][y] = true
+	}
+	finder.Invalidate()
+
+	third, err := finder.FindPath(from, to)
+	require.NoError(t, err)
+	assert.Equal(t, 2, finder.Misses)
+	assertWalkable(t, g, third, from, to)
+	assert.Contains(t, third, core.Coord{X: 6, Y: 11})
+}
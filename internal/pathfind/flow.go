@@ -0,0 +1,114 @@
+package pathfind
+
+import (
+	"container/heap"
+	"math"
+
+	"Neolithic/internal/core"
+)
+
+// FlowField gives the next move toward the nearest of its targets from every coordinate of the grid that can reach
+// one. It is found once by searching outward from the targets over the whole grid, after which any number of agents
+// heading there can follow it without searching at all.
+type FlowField struct {
+	targets []core.Coord
+	width   int
+	height  int
+	// next holds the index of the coordinate to move onto from each coordinate, -1 where the target can't be reached
+	next []int
+}
+
+var _ core.FlowField = (*FlowField)(nil)
+
+// Targets returns the coordinates the field leads to.
+func (f *FlowField) Targets() []core.Coord {
+	return f.targets
+}
+
+// Next implements core.FlowField.
+func (f *FlowField) Next(from core.Coord) (core.Coord, bool) {
+	if from.X < 0 || from.X >= f.width || from.Y < 0 || from.Y >= f.height {
+		return core.Coord{}, false
+	}
+	next := f.next[f.index(from)]
+	if next < 0 {
+		return core.Coord{}, false
+	}
+	return f.coord(next), true
+}
+
+// index returns the index of the coordinate in next.
+func (f *FlowField) index(coord core.Coord) int {
+	return coord.X*f.height + coord.Y
+}
+
+// coord returns the coordinate at the index in next.
+func (f *FlowField) coord(index int) core.Coord {
+	return core.Coord{X: index / f.height, Y: index % f.height}
+}
+
+// newFlowField builds the flow field toward the targets over a grid of the given size, which must all be inside it.
+// The cost of reaching the nearest target from each coordinate is found by Dijkstra's algorithm, going backward over
+// the moves between cells, which must be possible in both directions. Walkable targets that can't be moved onto are
+// left out.
+func newFlowField(grid core.Grid, width, height int, targets []core.Coord) (*FlowField, error) {
+	field := &FlowField{targets: targets, width: width, height: height, next: make([]int, width*height)}
+	costs := make([]float64, width*height)
+	for i := range costs {
+		costs[i] = math.Inf(1)
+		field.next[i] = -1
+	}
+
+	open := &flowQueue{}
+	for _, target := range targets {
+		if cell, ok := grid.CellAt(target).(Walkable); ok && !cell.Passable() {
+			continue
+		}
+		targetIndex := field.index(target)
+		costs[targetIndex] = 0
+		field.next[targetIndex] = targetIndex
+		heap.Push(open, flowEntry{index: targetIndex})
+	}
+	for open.Len() > 0 {
+		current := heap.Pop(open).(flowEntry)
+		if current.cost > costs[current.index] {
+			continue // already reached more cheaply
+		}
+		cell := grid.CellAt(field.coord(current.index))
+		predecessors, err := cell.GetSuccessors()
+		if err != nil {
+			return nil, err
+		}
+		for _, predecessor := range predecessors {
+			prevCell := predecessor.(core.Cell)
+			prevIndex := field.index(prevCell.Coord())
+			cost := current.cost + cell.Cost(prevCell)
+			if cost < costs[prevIndex] {
+				costs[prevIndex] = cost
+				field.next[prevIndex] = current.index
+				heap.Push(open, flowEntry{index: prevIndex, cost: cost})
+			}
+		}
+	}
+	return field, nil
+}
+
+// flowEntry is a coordinate waiting to be visited by newFlowField, with the cost of reaching the target from it.
+type flowEntry struct {
+	index int
+	cost  float64
+}
+
+// flowQueue implements heap.Interface for flowEntry, cheapest first.
+type flowQueue []flowEntry
+
+func (q flowQueue) Len() int           { return len(q) }
+func (q flowQueue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q flowQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *flowQueue) Push(x any)        { *q = append(*q, x.(flowEntry)) }
+func (q *flowQueue) Pop() any {
+	old := *q
+	entry := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return entry
+}
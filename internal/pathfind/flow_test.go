@@ -0,0 +1,114 @@
+package pathfind
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"Neolithic/internal/astar"
+	"Neolithic/internal/core"
+)
+
+func TestFlowField(t *testing.T) {
+	g := newTestGrid(
+		"....#....",
+		"....#....",
+		"....#....",
+		".........",
+		"....#####",
+		"....#....",
+	)
+	target := core.Coord{X: 8, Y: 0}
+
+	field, err := newFlowField(g, g.width(), g.height(), []core.Coord{target})
+	require.NoError(t, err)
+	assert.Equal(t, []core.Coord{target}, field.Targets())
+
+	t.Run("leads to the target by the shortest path", func(t *testing.T) {
+		for _, from := range []core.Coord{{X: 0, Y: 0}, {X: 3, Y: 5}, {X: 5, Y: 3}} {
+			path := []core.Coord{from}
+			cost := 0.0
+			for coord := from; coord != target; {
+				next, ok := field.Next(coord)
+				require.True(t, ok)
+				cost += g.CellAt(next).Cost(g.CellAt(coord))
+				path = append(path, next)
+				coord = next
+			}
+			assertWalkable(t, g, path, from, target)
+
+			plain, err := astar.NewSearch(g.CellAt(from), g.CellAt(target))
+			require.NoError(t, err)
+			require.NoError(t, plain.RunIterations(10000))
+			assert.InDelta(t, plain.BestCost, cost, 1e-9)
+		}
+	})
+
+	t.Run("stays at the target", func(t *testing.T) {
+		next, ok := field.Next(target)
+		assert.True(t, ok)
+		assert.Equal(t, target, next)
+	})
+
+	t.Run("can't be followed from where the target can't be reached", func(t *testing.T) {
+		for _, from := range []core.Coord{{X: 6, Y: 5}, {X: 4, Y: 0}, {X: -1, Y: 0}} {
+			_, ok := field.Next(from)
+			assert.False(t, ok, "from %v", from)
+		}
+	})
+}
+
+func TestFlowField_NearestTarget(t *testing.T) {
+	g := newTestGrid(
+		"....#....",
+		"....#....",
+		"....#....",
+		".........",
+	)
+	left, right := core.Coord{X: 0, Y: 0}, core.Coord{X: 8, Y: 0}
+
+	field, err := newFlowField(g, g.width(), g.height(), []core.Coord{left, right})
+	require.NoError(t, err)
+
+	for from, expected := range map[core.Coord]core.Coord{
+		{X: 3, Y: 2}: left,
+		{X: 5, Y: 2}: right,
+		{X: 3, Y: 3}: left,
+		{X: 6, Y: 3}: right,
+	} {
+		path := []core.Coord{from}
+		for coord := from; ; {
+			next, ok := field.Next(coord)
+			require.True(t, ok)
+			if next == coord {
+				break
+			}
+			path = append(path, next)
+			coord = next
+		}
+		assertWalkable(t, g, path, from, expected)
+	}
+}
+
+func TestFinder_FlowField(t *testing.T) {
+	g := newOpenGrid(8, 8)
+	finder := New(g, 8, 8)
+	target := core.Coord{X: 7, Y: 7}
+
+	first, err := finder.FlowField(target)
+	require.NoError(t, err)
+	second, err := finder.FlowField(target)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, finder.Hits)
+
+	finder.Invalidate()
+	third, err := finder.FlowField(target)
+	require.NoError(t, err)
+	assert.NotSame(t, first, third)
+	assert.Equal(t, 2, finder.Misses)
+
+	_, err = finder.FlowField(core.Coord{X: 8, Y: 0})
+	assert.ErrorIs(t, err, ErrOutOfBounds)
+}
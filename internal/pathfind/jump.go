@@ -0,0 +1,280 @@
+package pathfind
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"Neolithic/internal/astar"
+	"Neolithic/internal/core"
+)
+
+// Walkable is a cell whose cost is set by its ground alone: moving onto it costs its MoveCost, or 1.4 times as much
+// diagonally, and it can't be moved onto at all unless it is Passable. Diagonal moves must not cut the corner of a
+// cell that isn't passable. Grids of Walkable cells are searched by jump point search.
+type Walkable interface {
+	core.Cell
+	// Passable returns whether the cell can be moved onto
+	Passable() bool
+	// MoveCost returns how costly the cell is to move onto
+	MoveCost() float64
+}
+
+// jumpSearch is a jump point search between two coordinates inside bounds. Rather than adding every neighbour of a
+// coordinate to the search, it jumps in a straight line until something makes a turn worth considering: a wall ending
+// beside the line, a change of ground, or the goal. On open ground only a handful of coordinates are ever added, where
+// plain A* adds nearly every one it passes. A change of ground ends a jump, so paths across mixed ground are close to,
+// but not always, the shortest.
+type jumpSearch struct {
+	grid   core.Grid
+	bounds bounds
+	goal   core.Coord
+}
+
+// walkable returns the cell at the coordinate, or false if it is outside the bounds or can't be moved onto.
+func (j *jumpSearch) walkable(x, y int) (Walkable, bool) {
+	coord := core.Coord{X: x, Y: y}
+	if !j.bounds.contains(coord) {
+		return nil, false
+	}
+	cell, ok := j.grid.CellAt(coord).(Walkable)
+	if !ok || !cell.Passable() {
+		return nil, false
+	}
+	return cell, true
+}
+
+// open returns whether the coordinate can be moved onto.
+func (j *jumpSearch) open(x, y int) bool {
+	_, ok := j.walkable(x, y)
+	return ok
+}
+
+// jump moves from the coordinate in the direction dx, dy until it reaches a jump point, and returns it, or false if
+// the way is blocked first. Every coordinate jumped over has the move cost given.
+func (j *jumpSearch) jump(x, y, dx, dy int, moveCost float64) (core.Coord, bool) {
+	for {
+		cell, ok := j.walkable(x, y)
+		if !ok {
+			return core.Coord{}, false
+		}
+		coord := core.Coord{X: x, Y: y}
+		if coord == j.goal || cell.MoveCost() != moveCost {
+			return coord, true
+		}
+
+		if dx != 0 && dy != 0 {
+			if _, ok := j.jump(x+dx, y, dx, 0, moveCost); ok {
+				return coord, true
+			}
+			if _, ok := j.jump(x, y+dy, 0, dy, moveCost); ok {
+				return coord, true
+			}
+			if !j.open(x+dx, y) || !j.open(x, y+dy) {
+				return core.Coord{}, false // can't cut the corner
+			}
+		} else if dx != 0 {
+			if (j.open(x, y-1) && !j.open(x-dx, y-1)) || (j.open(x, y+1) && !j.open(x-dx, y+1)) {
+				return coord, true
+			}
+		} else if (j.open(x-1, y) && !j.open(x-1, y-dy)) || (j.open(x+1, y) && !j.open(x+1, y-dy)) {
+			return coord, true
+		}
+		x, y = x+dx, y+dy
+	}
+}
+
+// directions returns the directions worth jumping in from the coordinate, having arrived from parent, or every open
+// direction if it is the start of the search.
+func (j *jumpSearch) directions(coord core.Coord, parent *core.Coord) [][2]int {
+	x, y := coord.X, coord.Y
+	var dirs [][2]int
+	if parent == nil {
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				if (dx != 0 || dy != 0) && j.open(x+dx, y+dy) &&
+					(dx == 0 || dy == 0 || (j.open(x+dx, y) && j.open(x, y+dy))) {
+					dirs = append(dirs, [2]int{dx, dy})
+				}
+			}
+		}
+		return dirs
+	}
+
+	dx, dy := sign(x-parent.X), sign(y-parent.Y)
+	switch {
+	case dx != 0 && dy != 0:
+		if j.open(x, y+dy) {
+			dirs = append(dirs, [2]int{0, dy})
+		}
+		if j.open(x+dx, y) {
+			dirs = append(dirs, [2]int{dx, 0})
+		}
+		if j.open(x, y+dy) && j.open(x+dx, y) && j.open(x+dx, y+dy) {
+			dirs = append(dirs, [2]int{dx, dy})
+		}
+	case dx != 0:
+		ahead, up, down := j.open(x+dx, y), j.open(x, y+1), j.open(x, y-1)
+		if ahead {
+			dirs = append(dirs, [2]int{dx, 0})
+			if up && j.open(x+dx, y+1) {
+				dirs = append(dirs, [2]int{dx, 1})
+			}
+			if down && j.open(x+dx, y-1) {
+				dirs = append(dirs, [2]int{dx, -1})
+			}
+		}
+		if up {
+			dirs = append(dirs, [2]int{0, 1})
+		}
+		if down {
+			dirs = append(dirs, [2]int{0, -1})
+		}
+	default:
+		ahead, right, left := j.open(x, y+dy), j.open(x+1, y), j.open(x-1, y)
+		if ahead {
+			dirs = append(dirs, [2]int{0, dy})
+			if right && j.open(x+1, y+dy) {
+				dirs = append(dirs, [2]int{1, dy})
+			}
+			if left && j.open(x-1, y+dy) {
+				dirs = append(dirs, [2]int{-1, dy})
+			}
+		}
+		if right {
+			dirs = append(dirs, [2]int{1, 0})
+		}
+		if left {
+			dirs = append(dirs, [2]int{-1, 0})
+		}
+	}
+	return dirs
+}
+
+// run searches for a path from one coordinate to the goal, and returns every coordinate along it with its cost, or
+// false if there is none.
+func (j *jumpSearch) run(from core.Coord, opts ...astar.Option) ([]core.Coord, float64, bool, error) {
+	search, err := astar.NewSearch(&jumpNode{search: j, coord: from}, &jumpNode{search: j, coord: j.goal}, opts...)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	area := (j.bounds.maxX - j.bounds.minX + 1) * (j.bounds.maxY - j.bounds.minY + 1)
+	if err = search.RunIterations(2 * area); err != nil {
+		if errors.Is(err, astar.ErrNoPath) {
+			return nil, 0, false, nil
+		}
+		return nil, 0, false, err
+	}
+	if !search.HasSolution() {
+		return nil, 0, false, nil
+	}
+
+	jumpPoints := search.CurrentBestPath()
+	path := []core.Coord{from}
+	for i := 1; i < len(jumpPoints); i++ {
+		prev, cur := jumpPoints[i-1].(*jumpNode).coord, jumpPoints[i].(*jumpNode).coord
+		dx, dy := sign(cur.X-prev.X), sign(cur.Y-prev.Y)
+		for coord := prev; coord != cur; {
+			coord = core.Coord{X: coord.X + dx, Y: coord.Y + dy}
+			path = append(path, coord)
+		}
+	}
+	return path, search.BestCost, true, nil
+}
+
+// jumpNode implements astar.Node for a jump point, and remembers the jump point it was reached from, which decides
+// the directions worth jumping in next.
+type jumpNode struct {
+	search *jumpSearch
+	coord  core.Coord
+	parent *core.Coord
+}
+
+var _ astar.Node = (*jumpNode)(nil)
+
+// Heuristic implements astar.Node.
+func (n *jumpNode) Heuristic(goal astar.Node) (float64, error) {
+	g, ok := goal.(*jumpNode)
+	if !ok {
+		return 0, fmt.Errorf("heuristic called on non-jumpNode %T", goal)
+	}
+	return octile(n.coord, g.coord), nil
+}
+
+// ID implements astar.Node.
+func (n *jumpNode) ID() (string, error) {
+	return n.coord.String(), nil
+}
+
+// Cost implements astar.Node and returns the cost of every move along the straight or diagonal line from prev.
+func (n *jumpNode) Cost(prev astar.Node) float64 {
+	from := prev.(*jumpNode).coord
+	dx, dy := sign(n.coord.X-from.X), sign(n.coord.Y-from.Y)
+	step := 1.0
+	if dx != 0 && dy != 0 {
+		step = 1.4
+	}
+	cost := 0.0
+	for coord := from; coord != n.coord; {
+		coord = core.Coord{X: coord.X + dx, Y: coord.Y + dy}
+		cell, ok := n.search.walkable(coord.X, coord.Y)
+		if !ok {
+			return math.Inf(1)
+		}
+		cost += step * cell.MoveCost()
+	}
+	return cost
+}
+
+// GetSuccessors implements astar.Node and returns the jump points reached from the node.
+func (n *jumpNode) GetSuccessors() ([]astar.Node, error) {
+	var successors []astar.Node
+	for _, dir := range n.search.directions(n.coord, n.parent) {
+		x, y := n.coord.X+dir[0], n.coord.Y+dir[1]
+		cell, ok := n.search.walkable(x, y)
+		if !ok {
+			continue
+		}
+		if point, ok := n.search.jump(x, y, dir[0], dir[1], cell.MoveCost()); ok {
+			parent := n.coord
+			successors = append(successors, &jumpNode{search: n.search, coord: point, parent: &parent})
+		}
+	}
+	return successors, nil
+}
+
+// octile returns the cost of the shortest path between the coordinates on open ground: as many diagonal moves as
+// possible, and straight moves for the rest.
+func octile(a, b core.Coord) float64 {
+	dx, dy := abs(b.X-a.X), abs(b.Y-a.Y)
+	return float64(max(dx, dy)) + 0.4*float64(min(dx, dy))
+}
+
+// nearest returns the octile distance from the coordinate to the nearest of the goals.
+func nearest(coord core.Coord, goals []core.Coord) float64 {
+	best := math.Inf(1)
+	for _, goal := range goals {
+		best = math.Min(best, octile(coord, goal))
+	}
+	return best
+}
+
+// sign returns -1, 0 or 1 as n is negative, zero or positive.
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,120 @@
+package pathfind
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"Neolithic/internal/astar"
+	"Neolithic/internal/core"
+)
+
+func TestJumpSearch(t *testing.T) {
+	type testCase struct {
+		grid     *testGrid
+		from, to core.Coord
+	}
+
+	tests := map[string]testCase{
+		"open ground": {
+			grid: newOpenGrid(12, 12),
+			from: core.Coord{X: 1, Y: 2},
+			to:   core.Coord{X: 10, Y: 7},
+		},
+		"around walls": {
+			grid: newTestGrid(
+				"............",
+				"..#######...",
+				"........#...",
+				"..####..#...",
+				"..#.....#...",
+				"..#..####...",
+				"..#.........",
+				"............",
+			),
+			from: core.Coord{X: 4, Y: 4},
+			to:   core.Coord{X: 11, Y: 0},
+		},
+		"through a gap": {
+			grid: newTestGrid(
+				"....#....",
+				"....#....",
+				".........",
+				"....#....",
+				"....#....",
+			),
+			from: core.Coord{X: 0, Y: 0},
+			to:   core.Coord{X: 8, Y: 4},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := bounds{maxX: tc.grid.width() - 1, maxY: tc.grid.height() - 1}
+			jump := &jumpSearch{grid: tc.grid, bounds: b, goal: tc.to}
+
+			path, cost, found, err := jump.run(tc.from)
+			require.NoError(t, err)
+			require.True(t, found)
+			assertWalkable(t, tc.grid, path, tc.from, tc.to)
+
+			plain, err := astar.NewSearch(tc.grid.CellAt(tc.from), tc.grid.CellAt(tc.to))
+			require.NoError(t, err)
+			require.NoError(t, plain.RunIterations(10000))
+			require.True(t, plain.FoundBest)
+			assert.InDelta(t, plain.BestCost, cost, 1e-9, "jump point search should find the shortest path on uniform ground")
+		})
+	}
+}
+
+func TestJumpSearch_MixedGround(t *testing.T) {
+	// a band of costly ground down the middle, cheapest to cross where it is thinnest
+	g := newOpenGrid(9, 9)
+	g.costs = map[core.Coord]float64{}
+	for x := 3; x <= 5; x++ {
+		for y := range 9 {
+			if x == 4 || y != 6 {
+				g.costs[core.Coord{X: x, Y: y}] = 3
+			}
+		}
+	}
+	from, to := core.Coord{X: 0, Y: 6}, core.Coord{X: 8, Y: 6}
+	jump := &jumpSearch{grid: g, bounds: bounds{maxX: 8, maxY: 8}, goal: to}
+
+	path, cost, found, err := jump.run(from)
+	require.NoError(t, err)
+	require.True(t, found)
+	assertWalkable(t, g, path, from, to)
+	assert.InDelta(t, 10.0, cost, 1e-9)
+	assert.Len(t, path, 9)
+}
+
+func TestJumpSearch_NoPath(t *testing.T) {
+	g := newTestGrid(
+		"..#..",
+		"..#..",
+		"..#..",
+	)
+	jump := &jumpSearch{grid: g, bounds: bounds{maxX: 4, maxY: 2}, goal: core.Coord{X: 4, Y: 0}}
+
+	_, _, found, err := jump.run(core.Coord{X: 0, Y: 0})
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestJumpNode_Cost(t *testing.T) {
+	g := newTestGrid(
+		"..#..",
+		".....",
+	)
+	jump := &jumpSearch{grid: g, bounds: bounds{maxX: 4, maxY: 1}}
+	node := func(x, y int) *jumpNode {
+		return &jumpNode{search: jump, coord: core.Coord{X: x, Y: y}}
+	}
+
+	assert.Equal(t, 4.0, node(4, 1).Cost(node(0, 1)))
+	assert.Equal(t, 1.4, node(1, 1).Cost(node(0, 0)))
+	assert.True(t, math.IsInf(node(4, 0).Cost(node(0, 0)), 1), "a line through a wall can't be taken")
+}
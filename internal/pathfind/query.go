@@ -0,0 +1,174 @@
+package pathfind
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"Neolithic/internal/astar"
+	"Neolithic/internal/core"
+)
+
+// sink is the coordinate of the node every goal of a query leads to at no cost, so that a single search finds the
+// nearest of them. It is outside any grid.
+var sink = core.Coord{X: -1, Y: -1}
+
+// query is a single search of the abstract graph, with the start and goals of the path joined to the nodes of their
+// clusters by edges that only this search uses.
+type query struct {
+	finder *Finder
+	// goals are the coordinates the path may end at
+	goals []core.Coord
+	// extra are the edges this search adds to the abstract graph, by the coordinate they leave from
+	extra map[core.Coord][]edge
+}
+
+// connect joins the start of the path to the nodes of its cluster, and the nodes of each goal's cluster to the goal.
+// The start is joined straight to the goals in its own cluster, and every goal to the sink.
+func (q *query) connect(from core.Coord) error {
+	f := q.finder
+	fromCluster := f.clusterOf(from)
+	if err := f.link(fromCluster); err != nil {
+		return err
+	}
+	for _, nodeCoord := range fromCluster.nodes {
+		if err := q.addLocalEdge(from, nodeCoord, fromCluster); err != nil {
+			return err
+		}
+	}
+
+	for _, goal := range q.goals {
+		goalCluster := f.clusterOf(goal)
+		if err := f.link(goalCluster); err != nil {
+			return err
+		}
+		if goalCluster == fromCluster {
+			if err := q.addLocalEdge(from, goal, goalCluster); err != nil {
+				return err
+			}
+		}
+		for _, nodeCoord := range goalCluster.nodes {
+			if err := q.addLocalEdge(nodeCoord, goal, goalCluster); err != nil {
+				return err
+			}
+		}
+		q.extra[goal] = append(q.extra[goal], edge{to: sink, path: []core.Coord{goal}})
+	}
+	return nil
+}
+
+// addLocalEdge adds an edge between two coordinates of the cluster if there is a path between them inside it.
+func (q *query) addLocalEdge(from, to core.Coord, c *cluster) error {
+	if from == to {
+		return nil
+	}
+	path, cost, found, err := q.finder.localPath(from, to, c.bounds)
+	if err != nil || !found {
+		return err
+	}
+	q.extra[from] = append(q.extra[from], edge{to: to, cost: cost, path: path})
+	return nil
+}
+
+// run searches the abstract graph from the coordinate to the sink, and fills in the steps of each edge it takes.
+func (q *query) run(from core.Coord) ([]core.Coord, error) {
+	search, err := astar.NewSearch(&abstractNode{query: q, coord: from}, &abstractNode{query: q, coord: sink},
+		astar.WithLogger(q.finder.logger))
+	if err != nil {
+		return nil, err
+	}
+	// every node is visited at most about once, so this is only reached if the search is broken
+	if err = search.RunIterations(2 * (len(q.finder.nodes) + len(q.goals) + 2)); err != nil {
+		if errors.Is(err, astar.ErrNoPath) {
+			return nil, fmt.Errorf("%w: from %v to %v", ErrNoPath, from, q.goals)
+		}
+		return nil, err
+	}
+	if !search.HasSolution() {
+		return nil, fmt.Errorf("%w: from %v to %v", ErrNoPath, from, q.goals)
+	}
+
+	abstractPath := search.CurrentBestPath()
+	path := []core.Coord{from}
+	for i := 1; i < len(abstractPath); i++ {
+		prev, cur := abstractPath[i-1].(*abstractNode), abstractPath[i].(*abstractNode)
+		e, ok := q.edge(prev.coord, cur.coord)
+		if !ok {
+			return nil, fmt.Errorf("no edge from %v to %v", prev.coord, cur.coord)
+		}
+		path = append(path, e.path[1:]...)
+	}
+	return path, nil
+}
+
+// edges returns the edges leaving the coordinate, linking its cluster first if it is a node.
+func (q *query) edges(coord core.Coord) ([]edge, error) {
+	extra := q.extra[coord]
+	n, ok := q.finder.nodes[coord]
+	if !ok {
+		return extra, nil
+	}
+	if err := q.finder.link(q.finder.clusterOf(coord)); err != nil {
+		return nil, err
+	}
+	if len(extra) == 0 {
+		return n.edges, nil
+	}
+	return append(append([]edge(nil), n.edges...), extra...), nil
+}
+
+// edge returns the edge between two coordinates, or false if there is none.
+func (q *query) edge(from, to core.Coord) (edge, bool) {
+	for _, e := range q.extra[from] {
+		if e.to == to {
+			return e, true
+		}
+	}
+	if n, ok := q.finder.nodes[from]; ok {
+		return n.edgeTo(to)
+	}
+	return edge{}, false
+}
+
+// abstractNode implements astar.Node for a coordinate of the abstract graph.
+type abstractNode struct {
+	query *query
+	coord core.Coord
+}
+
+var _ astar.Node = (*abstractNode)(nil)
+
+// Heuristic implements astar.Node and estimates the cost to the nearest goal. The goal searched for is always the sink.
+func (a *abstractNode) Heuristic(_ astar.Node) (float64, error) {
+	if a.coord == sink {
+		return 0, nil
+	}
+	return nearest(a.coord, a.query.goals), nil
+}
+
+// ID implements astar.Node.
+func (a *abstractNode) ID() (string, error) {
+	return a.coord.String(), nil
+}
+
+// Cost implements astar.Node and returns the cost of the edge from prev to this node.
+func (a *abstractNode) Cost(prev astar.Node) float64 {
+	e, ok := a.query.edge(prev.(*abstractNode).coord, a.coord)
+	if !ok {
+		return math.Inf(1)
+	}
+	return e.cost
+}
+
+// GetSuccessors implements astar.Node and returns the coordinates the node's edges lead to.
+func (a *abstractNode) GetSuccessors() ([]astar.Node, error) {
+	edges, err := a.query.edges(a.coord)
+	if err != nil {
+		return nil, err
+	}
+	successors := make([]astar.Node, len(edges))
+	for i, e := range edges {
+		successors[i] = &abstractNode{query: a.query, coord: e.to}
+	}
+	return successors, nil
+}
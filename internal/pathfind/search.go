@@ -0,0 +1,132 @@
+package pathfind
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"Neolithic/internal/astar"
+	"Neolithic/internal/core"
+)
+
+// Search searches the grid cell by cell for a path from one coordinate to the nearest of the goals, running the A*
+// search for at most maxIterations, and returns the coordinates of the path, including both ends. Unlike a Finder, it
+// keeps nothing between searches, so it suits short searches around things that come and go, such as other agents,
+// which can be left out with astar.WithSkip. Goals outside the grid are ignored.
+func Search(grid core.Grid, from core.Coord, goals []core.Coord, maxIterations int, opts ...astar.Option) ([]core.Coord, error) {
+	start := grid.CellAt(from)
+	if start == nil {
+		return nil, fmt.Errorf("%w: %v", ErrOutOfBounds, from)
+	}
+	set := &goalSet{coords: make(map[core.Coord]bool, len(goals))}
+	for _, goal := range goals {
+		if cell := grid.CellAt(goal); cell != nil && !set.coords[goal] {
+			set.cells = append(set.cells, cell)
+			set.coords[goal] = true
+		}
+	}
+	if len(set.cells) == 0 {
+		return nil, fmt.Errorf("%w: goals %v", ErrOutOfBounds, goals)
+	}
+	if set.coords[from] {
+		return []core.Coord{from}, nil
+	}
+
+	search, err := astar.NewSearch(&goalCell{cell: start, goals: set}, &goalCell{goals: set}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err = search.RunIterations(maxIterations); err != nil {
+		if errors.Is(err, astar.ErrNoPath) {
+			return nil, fmt.Errorf("%w: from %v to %v", ErrNoPath, from, goals)
+		}
+		return nil, err
+	}
+	if !search.FoundBest {
+		return nil, fmt.Errorf("%w: from %v to %v", ErrNoPath, from, goals)
+	}
+
+	nodes := search.CurrentBestPath()
+	path := make([]core.Coord, 0, len(nodes))
+	for _, n := range nodes {
+		if cell := n.(*goalCell).cell; cell != nil {
+			path = append(path, cell.Coord())
+		}
+	}
+	return path, nil
+}
+
+// goalSet are the cells a Search may end at.
+type goalSet struct {
+	cells  []core.Cell
+	coords map[core.Coord]bool
+}
+
+// goalCell is a cell searched by Search, or the sink every goal leads to at no cost if cell is nil.
+type goalCell struct {
+	cell  core.Cell
+	goals *goalSet
+}
+
+var _ core.Cell = (*goalCell)(nil)
+
+// Heuristic implements astar.Node and returns the cell's estimate to the nearest goal. The goal searched for is always
+// the sink.
+func (g *goalCell) Heuristic(_ astar.Node) (float64, error) {
+	if g.cell == nil {
+		return 0, nil
+	}
+	best := math.Inf(1)
+	for _, goal := range g.goals.cells {
+		estimate, err := g.cell.Heuristic(goal)
+		if err != nil {
+			return 0, err
+		}
+		best = math.Min(best, estimate)
+	}
+	return best, nil
+}
+
+// ID implements astar.Node.
+func (g *goalCell) ID() (string, error) {
+	if g.cell == nil {
+		return "sink", nil
+	}
+	return g.cell.ID()
+}
+
+// Cost implements astar.Node.
+func (g *goalCell) Cost(prev astar.Node) float64 {
+	if g.cell == nil {
+		return 0
+	}
+	return g.cell.Cost(prev.(*goalCell).cell)
+}
+
+// GetSuccessors implements astar.Node and returns the cell's successors, and the sink if the cell is a goal.
+func (g *goalCell) GetSuccessors() ([]astar.Node, error) {
+	if g.cell == nil {
+		return nil, nil
+	}
+	successors, err := g.cell.GetSuccessors()
+	if err != nil {
+		return nil, err
+	}
+	wrapped := make([]astar.Node, 0, len(successors)+1)
+	for _, successor := range successors {
+		wrapped = append(wrapped, &goalCell{cell: successor.(core.Cell), goals: g.goals})
+	}
+	if g.goals.coords[g.cell.Coord()] {
+		wrapped = append(wrapped, &goalCell{goals: g.goals})
+	}
+	return wrapped, nil
+}
+
+// Coord implements core.Cell, so that the cells of a Search can be told apart by coordinate, such as by
+// astar.WithSkip. The sink is at the same coordinate as the sink of a Finder's query, outside the grid.
+func (g *goalCell) Coord() core.Coord {
+	if g.cell == nil {
+		return sink
+	}
+	return g.cell.Coord()
+}
@@ -0,0 +1,85 @@
+package pathfind
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"Neolithic/internal/astar"
+	"Neolithic/internal/core"
+)
+
+func TestSearch(t *testing.T) {
+	type testCase struct {
+		goals         []core.Coord
+		skip          []core.Coord
+		expectedEnd   core.Coord
+		expectedSteps int
+		expectedError error
+	}
+
+	g := newTestGrid(
+		"....#....",
+		"....#....",
+		"....#....",
+		"....#....",
+		".........",
+	)
+	from := core.Coord{X: 0, Y: 0}
+
+	tests := map[string]testCase{
+		"nearest by path": {
+			goals:         []core.Coord{{X: 5, Y: 0}, {X: 3, Y: 3}},
+			expectedEnd:   core.Coord{X: 3, Y: 3},
+			expectedSteps: 3,
+		},
+		"around a skipped cell": {
+			goals:         []core.Coord{{X: 2, Y: 0}},
+			skip:          []core.Coord{{X: 1, Y: 0}, {X: 1, Y: 1}},
+			expectedEnd:   core.Coord{X: 2, Y: 0},
+			expectedSteps: 4,
+		},
+		"already at a goal": {
+			goals:       []core.Coord{from, {X: 3, Y: 3}},
+			expectedEnd: from,
+		},
+		"goal outside the grid ignored": {
+			goals:         []core.Coord{{X: 9, Y: 0}, {X: 0, Y: 2}},
+			expectedEnd:   core.Coord{X: 0, Y: 2},
+			expectedSteps: 2,
+		},
+		"every goal outside the grid": {
+			goals:         []core.Coord{{X: 9, Y: 0}},
+			expectedError: ErrOutOfBounds,
+		},
+		"every goal cut off": {
+			goals:         []core.Coord{{X: 3, Y: 3}},
+			skip:          []core.Coord{{X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}},
+			expectedError: ErrNoPath,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			skip := func(node astar.Node) bool {
+				for _, coord := range tc.skip {
+					if node.(core.Cell).Coord() == coord {
+						return true
+					}
+				}
+				return false
+			}
+
+			path, err := Search(g, from, tc.goals, 1000, astar.WithSkip(skip))
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assertWalkable(t, g, path, from, tc.expectedEnd)
+			assert.Len(t, path, tc.expectedSteps+1)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"Neolithic/internal/core"
 	"fmt"
 	"math"
+	"slices"
 )
 
 // GoapNode represents a point in a GOAP process, where the planner is choosing a plan
@@ -25,6 +26,8 @@ type GoapRunInfo struct {
 	Agent core.Agent
 	// PossibleNextActions are all actions that the agent could take
 	PossibleNextActions []core.Action
+	// Goal is the state being planned for, if known. It bounds the amounts that core.Batched actions are taken with.
+	Goal *core.WorldState
 }
 
 // Ensure GoapNode implements astar.Node
@@ -45,8 +48,14 @@ func (g *GoapNode) ID() (string, error) {
 	return g.State.ID()
 }
 
-// Cost implements astar.Node and returns the cost of performing the action associated with this node.
-func (g *GoapNode) Cost(_ astar.Node) float64 {
+// Cost implements astar.Node and returns the cost of performing the action associated with this node. The action is
+// costed for the agent as it is in the previous node, so that what the agent carries by then counts.
+func (g *GoapNode) Cost(prev astar.Node) float64 {
+	if prevNode, ok := prev.(*GoapNode); ok && prevNode.State != nil {
+		if agent, ok := prevNode.State.GetAgent(g.GoapRunInfo.Agent.Name()); ok {
+			return g.Action.Cost(agent)
+		}
+	}
 	return g.Action.Cost(g.GoapRunInfo.Agent)
 }
 
@@ -56,10 +65,10 @@ func (g *GoapNode) GetSuccessors() ([]astar.Node, error) {
 		return g.successors, nil
 	}
 	successors := make([]astar.Node, 0)
-	for _, action := range g.GoapRunInfo.PossibleNextActions {
+	addSuccessor := func(action core.Action) {
 		newState := action.Perform(g.State, g.GoapRunInfo.Agent)
 		if newState == nil {
-			continue
+			return
 		}
 		successors = append(successors, &GoapNode{
 			Action:      action,
@@ -67,26 +76,145 @@ func (g *GoapNode) GetSuccessors() ([]astar.Node, error) {
 			GoapRunInfo: g.GoapRunInfo,
 		})
 	}
+	for _, action := range g.GoapRunInfo.PossibleNextActions {
+		batched, ok := action.(core.Batched)
+		if !ok {
+			addSuccessor(action)
+			continue
+		}
+		for _, amount := range g.batchAmounts(batched) {
+			if amount == batched.BatchAmount() {
+				addSuccessor(batched) // a full batch is the template itself
+				continue
+			}
+			addSuccessor(batched.WithAmount(amount))
+		}
+	}
 	g.successors = successors
 	return successors, nil
 }
 
+// batchAmounts returns the amounts, in increasing order, to take a core.Batched action with from this node: the most
+// it can move, along with any smaller amount that would bring a location or agent in the goal to exactly the amount of
+// the action's resource the goal requires. It returns nothing if the action can't be taken.
+func (g *GoapNode) batchAmounts(batched core.Batched) []int {
+	most := batched.MaxAmount(g.State, g.GoapRunInfo.Agent)
+	if most <= 0 {
+		return nil
+	}
+	amounts := []int{most}
+	if g.GoapRunInfo.Goal == nil {
+		return amounts
+	}
+	res := batched.Resource()
+	for _, target := range goalTargets(g.GoapRunInfo.Goal) {
+		goalAmount := target.inventory.GetAmount(res)
+		current, ok := target.current(g.State)
+		if goalAmount == 0 || !ok {
+			continue // the goal doesn't constrain the resource there
+		}
+		diff := goalAmount - current.GetAmount(res)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > 0 && diff < most && !slices.Contains(amounts, diff) {
+			amounts = append(amounts, diff)
+		}
+	}
+	slices.Sort(amounts)
+	return amounts
+}
+
+// GoalReached reports whether state satisfies goal; that is, whether every location and agent in goal holds exactly the
+// amounts of resources that it does in goal. Locations and agents in goal that are missing from state are skipped. This
+// is the same condition under which the heuristic is zero.
+func GoalReached(state, goal *core.WorldState) bool {
+	for _, target := range goalTargets(goal) {
+		current, ok := target.current(state)
+		if !ok {
+			continue
+		}
+		for _, entry := range target.inventory.Entries() {
+			if current.GetAmount(entry.Resource) != entry.Amount {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// goalTarget is a location or agent in a goal state, along with the inventory the goal requires of it.
+type goalTarget struct {
+	// entityType is the type of the entity
+	entityType core.EntityType
+	// name is the name of the entity
+	name string
+	// inventory is the inventory the goal requires of the entity
+	inventory core.Inventory
+}
+
+// goalTargets returns the locations and agents of a goal state, in a deterministic order.
+func goalTargets(goal *core.WorldState) []goalTarget {
+	targets := make([]goalTarget, 0, len(goal.Locations)+len(goal.Agents))
+	for _, name := range goal.LocationNames() {
+		targets = append(targets, goalTarget{entityType: core.LocationEntity, name: name, inventory: goal.Locations[name].Inventory})
+	}
+	for _, name := range goal.AgentNames() {
+		targets = append(targets, goalTarget{entityType: core.AgentEntity, name: name, inventory: goal.Agents[name].Inventory()})
+	}
+	return targets
+}
+
+// current returns the inventory of the target's entity in state, or false if the entity isn't there.
+func (t goalTarget) current(state *core.WorldState) (core.Inventory, bool) {
+	if t.entityType == core.AgentEntity {
+		agent, ok := state.GetAgent(t.name)
+		if !ok {
+			return nil, false
+		}
+		return agent.Inventory(), true
+	}
+	loc, ok := state.GetLocation(t.name)
+	if !ok {
+		return nil, false
+	}
+	return loc.Inventory, true
+}
+
+// exceedsStorage reports whether the target is a location that the goal asks to hold more weight than the
+// core.StorageCapacity of the location in state, which no plan can reach.
+func (t goalTarget) exceedsStorage(state *core.WorldState) bool {
+	if t.entityType != core.LocationEntity {
+		return false
+	}
+	loc, ok := state.GetLocation(t.name)
+	if !ok {
+		return false
+	}
+	capacity := core.StorageCapacity(loc)
+	// the small tolerance keeps rounding errors in the weights from ruling out a goal that just fits
+	return capacity > 0 && core.InventoryWeight(t.inventory) > capacity+1e-9
+}
+
 // heuristic is the function used to estimate how close to the goal a given Action is. It does so by calculating the
 // lowest "cost per unit" of all Action(s) that operates on a resource relevant to the goal. That value is then
-// multiplied by the difference in amount of that resource between the current and the goal location.
+// multiplied by the difference in amount of that resource between the current and the goal location or agent.
 // This heuristic is admissible because it always chooses the least "cost per unit" available, meaning it cannot
-// overestimate the total cost of a given path.
+// overestimate the total cost of a given path. A goal that asks a location to hold more weight than it can store is
+// unreachable, so its heuristic is +Inf.
 func (g *GoapNode) heuristic(cur, goal *GoapNode) (float64, error) {
 	var totalCost float64
-	for _, goalLocation := range goal.State.Locations {
-		currentVersionOfGoalLocation, ok := cur.State.GetLocation(goalLocation.Name)
+	for _, target := range goalTargets(goal.State) {
+		currentInventory, ok := target.current(cur.State)
 		if !ok {
 			// TODO: this makes it impossible to have goal states with new locations. Need to fix that in the future
 			continue // no version of the location in the current state
 		}
+		if target.exceedsStorage(cur.State) {
+			return math.Inf(1), nil // no plan can fit what the goal asks for
+		}
 
-		currentInventory := currentVersionOfGoalLocation.Inventory
-		goalInventory := goalLocation.Inventory
+		goalInventory := target.inventory
 
 		for _, entry := range goalInventory.Entries() {
 			currentAmount := currentInventory.GetAmount(entry.Resource)
@@ -103,35 +231,160 @@ func (g *GoapNode) heuristic(cur, goal *GoapNode) (float64, error) {
 			var relevantActions []core.Action
 			var err error
 			if diff > 0 {
-				relevantActions, err = cur.getActionsThatAdd(entry.Resource, goalLocation.Name)
+				relevantActions, err = cur.getActionsThatAdd(entry.Resource, target.entityType, target.name)
 				if err != nil {
 					return math.Inf(1), err
 				}
 			} else {
-				relevantActions, err = cur.getActionsThatRemove(entry.Resource, goalLocation.Name)
+				relevantActions, err = cur.getActionsThatRemove(entry.Resource, target.entityType, target.name)
 				if err != nil {
 					return math.Inf(1), err
 				}
 			}
 
 			for _, action := range relevantActions {
-				change := getRelatedChange(action, entry.Resource, cur.GoapRunInfo.Agent, goalLocation.Name)
+				change := getRelatedChange(action, entry.Resource, cur.GoapRunInfo.Agent, target.entityType, target.name)
 				effectAmount := change.Amount
 
-				costPerUnit := action.Cost(cur.GoapRunInfo.Agent) / math.Abs(float64(effectAmount))
+				costPerUnit := unloadedCost(action, cur.GoapRunInfo.Agent) / math.Abs(float64(effectAmount))
 				if costPerUnit < bestCostPerUnit {
 					bestCostPerUnit = costPerUnit
 				}
 			}
+			if diff > 0 && math.IsInf(bestCostPerUnit, 1) {
+				bestCostPerUnit, err = cur.chainedCostPerUnit(entry.Resource, target.entityType, target.name)
+				if err != nil {
+					return math.Inf(1), err
+				}
+			}
 			totalCost += requiredChange * bestCostPerUnit
 		}
 	}
 	return totalCost, nil
 }
 
+// chainedCostPerUnit estimates the cost per unit of adding the given Resource to the given entity when none of the
+// actions the agent can take right now do so, such as depositing a resource the agent has yet to craft. The estimate
+// follows chains of actions back to ones the agent can take, where an action that can't be taken yet also pays for
+// producing what it is missing, such as the inputs of a craft. Only chains that create the Resource, rather than just
+// move it between entities, are counted, so that a plain gather and deposit is left to the rest of the heuristic.
+// Gathering with a tool counts as creating the Resource, since the agent may have made the tool for it. It returns +Inf
+// if the Resource can't be created that way.
+func (g *GoapNode) chainedCostPerUnit(res *core.Resource, entityType core.EntityType, entityName string) (float64, error) {
+	successors, err := g.GetSuccessors()
+	if err != nil {
+		return math.Inf(1), err
+	}
+	possible := make(map[core.Action]bool, len(successors))
+	for _, successor := range successors {
+		possible[successor.(*GoapNode).Action] = true
+	}
+
+	_, created, err := g.chainCosts(res, entityType, entityName, possible, map[string]bool{})
+	return created, err
+}
+
+// chainCosts returns the lowest cost per unit of adding the given Resource to the given entity through any chain of
+// actions, and through a chain that creates the Resource somewhere along the way. possible holds the actions the agent
+// can take right now, and visited the resources and entities already being estimated, to break cycles such as
+// gathering what was just deposited.
+func (g *GoapNode) chainCosts(res *core.Resource, entityType core.EntityType, entityName string, possible map[core.Action]bool, visited map[string]bool) (float64, float64, error) {
+	key := fmt.Sprintf("%s/%s/%s", entityType, entityName, res.Name)
+	if visited[key] {
+		return math.Inf(1), math.Inf(1), nil
+	}
+	visited[key] = true
+	defer delete(visited, key)
+
+	agent := g.GoapRunInfo.Agent
+	bestAny, bestCreated := math.Inf(1), math.Inf(1)
+	for _, action := range g.GoapRunInfo.PossibleNextActions {
+		relevantChange := getRelatedChange(action, res, agent, entityType, entityName)
+		if relevantChange == nil || relevantChange.Amount <= 0 {
+			continue
+		}
+
+		changes := action.GetChanges(agent)
+		creates := true
+		for _, change := range changes {
+			if change.Resource == res && change.Amount < 0 {
+				creates = false // only moves the resource
+			}
+		}
+		if held, ok := action.(core.RequiresHeld); ok && len(held.HeldResources()) > 0 {
+			creates = true // produced with a tool, which counts as creating it
+		}
+
+		costAny := unloadedCost(action, agent)
+		// extraToCreate is the least extra cost of getting one of the missing inputs through a chain that creates it
+		extraToCreate := math.Inf(1)
+		if !possible[action] {
+			for _, change := range requiredInputs(action, agent, changes) {
+				missing := change.Amount
+				if current, ok := (goalTarget{entityType: change.EntityType, name: change.Entity}).current(g.State); ok {
+					missing -= current.GetAmount(change.Resource)
+				}
+				if missing <= 0 {
+					continue
+				}
+				inputAny, inputCreated, err := g.chainCosts(change.Resource, change.EntityType, change.Entity, possible, visited)
+				if err != nil {
+					return math.Inf(1), math.Inf(1), err
+				}
+				costAny += float64(missing) * inputAny
+				extraToCreate = math.Min(extraToCreate, float64(missing)*(inputCreated-inputAny))
+			}
+		}
+		if math.IsInf(costAny, 1) {
+			continue
+		}
+
+		costCreated := costAny
+		if !creates {
+			costCreated += extraToCreate
+		}
+		amount := float64(relevantChange.Amount)
+		bestAny = math.Min(bestAny, costAny/amount)
+		bestCreated = math.Min(bestCreated, costCreated/amount)
+	}
+	return bestAny, bestCreated, nil
+}
+
+// requiredInputs returns what the agent must have before taking the action, as changes with positive amounts: the
+// resources the changes take away, along with the resources it must hold if it is a core.RequiresHeld.
+func requiredInputs(action core.Action, agent core.Agent, changes []core.StateChange) []core.StateChange {
+	inputs := make([]core.StateChange, 0, len(changes))
+	for _, change := range changes {
+		if change.Amount < 0 {
+			change.Amount = -change.Amount
+			inputs = append(inputs, change)
+		}
+	}
+	held, ok := action.(core.RequiresHeld)
+	if !ok {
+		return inputs
+	}
+	for _, entry := range held.HeldResources() {
+		idx := slices.IndexFunc(inputs, func(input core.StateChange) bool {
+			return input.EntityType == core.AgentEntity && input.Entity == agent.Name() && input.Resource == entry.Resource
+		})
+		if idx >= 0 {
+			inputs[idx].Amount = max(inputs[idx].Amount, entry.Amount)
+			continue
+		}
+		inputs = append(inputs, core.StateChange{
+			Entity:     agent.Name(),
+			EntityType: core.AgentEntity,
+			Resource:   entry.Resource,
+			Amount:     entry.Amount,
+		})
+	}
+	return inputs
+}
+
 // getActionsThatAdd returns all actions that the agent on the GoapNode can take that _add_ the given Resource to the given
-// Location
-func (g *GoapNode) getActionsThatAdd(res *core.Resource, locName string) ([]core.Action, error) {
+// entity
+func (g *GoapNode) getActionsThatAdd(res *core.Resource, entityType core.EntityType, entityName string) ([]core.Action, error) {
 	addActions := make([]core.Action, 0)
 
 	successors, err := g.GetSuccessors()
@@ -142,7 +395,7 @@ func (g *GoapNode) getActionsThatAdd(res *core.Resource, locName string) ([]core
 	for _, successor := range successors {
 		action := successor.(*GoapNode).Action
 
-		relevantChange := getRelatedChange(action, res, g.GoapRunInfo.Agent, locName)
+		relevantChange := getRelatedChange(action, res, g.GoapRunInfo.Agent, entityType, entityName)
 		if relevantChange == nil {
 			continue
 		}
@@ -156,8 +409,8 @@ func (g *GoapNode) getActionsThatAdd(res *core.Resource, locName string) ([]core
 }
 
 // getActionsThatRemove returns all actions that the agent on the GoapNode can take that _remove_ the given Resource
-// from the given location.
-func (g *GoapNode) getActionsThatRemove(res *core.Resource, locName string) ([]core.Action, error) {
+// from the given entity.
+func (g *GoapNode) getActionsThatRemove(res *core.Resource, entityType core.EntityType, entityName string) ([]core.Action, error) {
 	removeActions := make([]core.Action, 0)
 
 	successors, err := g.GetSuccessors()
@@ -168,7 +421,7 @@ func (g *GoapNode) getActionsThatRemove(res *core.Resource, locName string) ([]c
 	for _, successor := range successors {
 		action := successor.(*GoapNode).Action
 
-		relevantChange := getRelatedChange(action, res, g.GoapRunInfo.Agent, locName)
+		relevantChange := getRelatedChange(action, res, g.GoapRunInfo.Agent, entityType, entityName)
 		if relevantChange == nil {
 			continue
 		}
@@ -181,10 +434,30 @@ func (g *GoapNode) getActionsThatRemove(res *core.Resource, locName string) ([]c
 	return removeActions, nil
 }
 
-func getRelatedChange(action core.Action, res *core.Resource, agent core.Agent, locName string) *core.StateChange {
+// unloadedCost returns the cost of the action for the agent as if it carried nothing. Actions cost more the more the
+// agent carries, and the agent may have put down what it carries by the time it takes the action, so the heuristic
+// uses this lower bound to stay admissible.
+func unloadedCost(action core.Action, agent core.Agent) float64 {
+	return action.Cost(unloadedAgent{agent})
+}
+
+// unloadedAgent is an agent as it would be if it carried nothing. It is never a core.Carrier, so it is never
+// encumbered.
+type unloadedAgent struct {
+	core.Agent
+}
+
+// Inventory implements core.Agent and returns an empty inventory.
+func (u unloadedAgent) Inventory() core.Inventory {
+	return core.NewInventory()
+}
+
+// getRelatedChange returns the change the action makes to the given resource of the given entity, or nil if there is
+// none.
+func getRelatedChange(action core.Action, res *core.Resource, agent core.Agent, entityType core.EntityType, entityName string) *core.StateChange {
 	changes := action.GetChanges(agent)
 	for _, change := range changes {
-		if change.EntityType == core.LocationEntity && change.Entity == locName && change.Resource == res {
+		if change.EntityType == entityType && change.Entity == entityName && change.Resource == res {
 			return &change
 		}
 	}
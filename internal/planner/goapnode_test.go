@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"Neolithic/internal/astar"
+	"Neolithic/internal/attributes"
 	"Neolithic/internal/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -55,7 +56,7 @@ func TestActions_AStar(t *testing.T) {
 				gatherTest,
 				gatherTest,
 				depositTest2,
-				depositTest2,
+				depositTest2.WithAmount(10),
 			},
 			expectedIterations: 17,
 			expectedCost:       53,
@@ -355,3 +356,436 @@ func TestActions_GetSuccessors(t *testing.T) {
 		})
 	}
 }
+
+func TestGoalReached(t *testing.T) {
+	res := &core.Resource{Name: "Berries"}
+
+	newState := func(amounts map[string]int) *core.WorldState {
+		state := &core.WorldState{Locations: map[string]*core.Location{}}
+		for name, amount := range amounts {
+			loc := core.NewLocation(name, core.Coord{})
+			loc.Inventory.AdjustAmount(res, amount)
+			state.Locations[name] = loc
+		}
+		return state
+	}
+
+	type testCase struct {
+		state    map[string]int
+		goal     map[string]int
+		expected bool
+	}
+
+	tests := map[string]testCase{
+		"amounts match": {
+			state:    map[string]int{"depo": 10, "bush": 3},
+			goal:     map[string]int{"depo": 10},
+			expected: true,
+		},
+		"amount too low": {
+			state:    map[string]int{"depo": 9},
+			goal:     map[string]int{"depo": 10},
+			expected: false,
+		},
+		"amount too high": {
+			state:    map[string]int{"depo": 11},
+			goal:     map[string]int{"depo": 10},
+			expected: false,
+		},
+		"goal location missing from state": {
+			state:    map[string]int{"bush": 3},
+			goal:     map[string]int{"depo": 10},
+			expected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, GoalReached(newState(tc.state), newState(tc.goal)))
+		})
+	}
+
+	t.Run("agent goals", func(t *testing.T) {
+		state := &core.WorldState{
+			Locations: map[string]*core.Location{},
+			Agents:    map[string]core.Agent{testAgent.Name(): testAgent.DeepCopy()},
+		}
+		state.Agents[testAgent.Name()].Inventory().AdjustAmount(res, 2)
+
+		goalAgent := core.NewGoalAgent(testAgent.Name())
+		goalAgent.Inventory().AdjustAmount(res, 2)
+		goal := &core.WorldState{Agents: map[string]core.Agent{goalAgent.Name(): goalAgent}}
+		assert.True(t, GoalReached(state, goal))
+
+		goalAgent.Inventory().AdjustAmount(res, 1)
+		assert.False(t, GoalReached(state, goal))
+	})
+}
+
+func TestActions_AgentGoal(t *testing.T) {
+	startState := &core.WorldState{
+		Locations: map[string]*core.Location{
+			testLocation.Name:  testLocation.DeepCopy(),
+			testLocation2.Name: testLocation2.DeepCopy(),
+		},
+		Agents: map[string]core.Agent{
+			testAgent.Name(): testAgent.DeepCopy(),
+		},
+	}
+	startState.Locations[testLocation.Name].Inventory.AdjustAmount(testResource, 100)
+
+	goalAgent := core.NewGoalAgent(testAgent.Name())
+	goalAgent.Inventory().AdjustAmount(testResource, 20)
+	goalState := &core.WorldState{
+		Locations: map[string]*core.Location{},
+		Agents:    map[string]core.Agent{goalAgent.Name(): goalAgent},
+	}
+
+	runInfo := &GoapRunInfo{
+		Agent:               testAgent,
+		PossibleNextActions: []core.Action{gatherTest, gatherTest2, depositTest, depositTest2},
+	}
+	startNode := &GoapNode{State: startState, GoapRunInfo: runInfo}
+	goalNode := &GoapNode{State: goalState, GoapRunInfo: runInfo}
+
+	distance, err := startNode.Heuristic(goalNode)
+	require.NoError(t, err)
+	assert.Equal(t, 20.0, distance)
+
+	search, err := astar.NewSearch(startNode, goalNode, astar.WithLogger(logging.NewLogger("error")))
+	require.NoError(t, err)
+	require.NoError(t, search.RunIterations(1000))
+	assert.True(t, search.FoundBest)
+	assert.Equal(t, 20.0, search.BestCost)
+
+	solutionActions := make([]core.Action, 0)
+	for _, node := range search.CurrentBestPath() {
+		solutionActions = append(solutionActions, node.(*GoapNode).Action)
+	}
+	assert.Equal(t, []core.Action{nil, gatherTest, gatherTest}, solutionActions)
+}
+
+func TestActions_Batched(t *testing.T) {
+	startState := &core.WorldState{
+		Locations: map[string]*core.Location{
+			testLocation.Name:  testLocation.DeepCopy(),
+			testLocation2.Name: testLocation2.DeepCopy(),
+		},
+		Agents: map[string]core.Agent{
+			testAgent.Name(): testAgent.DeepCopy(),
+		},
+	}
+	startState.Locations[testLocation.Name].Inventory.AdjustAmount(testResource, 100)
+
+	goalLocation := testLocation2.DeepCopy()
+	goalLocation.Inventory.AdjustAmount(testResource, 15)
+	goalState := &core.WorldState{
+		Locations: map[string]*core.Location{goalLocation.Name: goalLocation},
+		Agents:    map[string]core.Agent{},
+	}
+
+	runInfo := &GoapRunInfo{
+		Agent:               testAgent,
+		PossibleNextActions: []core.Action{gatherTest, depositTest2},
+		Goal:                goalState,
+	}
+	startNode := &GoapNode{State: startState, GoapRunInfo: runInfo}
+	goalNode := &GoapNode{State: goalState, GoapRunInfo: runInfo}
+
+	search, err := astar.NewSearch(startNode, goalNode, astar.WithLogger(logging.NewLogger("error")))
+	require.NoError(t, err)
+	require.NoError(t, search.RunIterations(1000))
+	assert.True(t, search.FoundBest)
+
+	solutionActions := make([]core.Action, 0)
+	for _, node := range search.CurrentBestPath() {
+		solutionActions = append(solutionActions, node.(*GoapNode).Action)
+	}
+	require.Len(t, solutionActions, 4, "the deposit should be batched to exactly the goal amount")
+	deposit, ok := solutionActions[3].(*attributes.Deposit)
+	require.True(t, ok)
+	assert.Equal(t, 15, deposit.Amount)
+	assert.Same(t, depositTest2, deposit.Template())
+
+	successors, err := startNode.GetSuccessors()
+	require.NoError(t, err)
+	var gatherAmounts []int
+	for _, successor := range successors {
+		gatherAmounts = append(gatherAmounts, successor.(*GoapNode).Action.(*attributes.Gather).Amount)
+	}
+	assert.Equal(t, []int{10}, gatherAmounts, "the agent carries nothing, so nothing can be deposited yet")
+}
+
+func TestActions_Consume(t *testing.T) {
+	startState := &core.WorldState{
+		Locations: map[string]*core.Location{
+			testLocation.Name: testLocation.DeepCopy(),
+		},
+		Agents: map[string]core.Agent{
+			testAgent.Name(): testAgent.DeepCopy(),
+		},
+	}
+	startState.Agents[testAgent.Name()].Inventory().AdjustAmount(testResource, 5)
+
+	goalAgent := core.NewGoalAgent(testAgent.Name())
+	goalAgent.Inventory().AdjustAmount(testResource, 2)
+	goalState := &core.WorldState{
+		Locations: map[string]*core.Location{},
+		Agents:    map[string]core.Agent{goalAgent.Name(): goalAgent},
+	}
+
+	runInfo := &GoapRunInfo{
+		Agent:               testAgent,
+		PossibleNextActions: []core.Action{gatherTest, depositTest, consumeTest},
+	}
+	startNode := &GoapNode{State: startState, GoapRunInfo: runInfo}
+	goalNode := &GoapNode{State: goalState, GoapRunInfo: runInfo}
+
+	search, err := astar.NewSearch(startNode, goalNode, astar.WithLogger(logging.NewLogger("error")))
+	require.NoError(t, err)
+	require.NoError(t, search.RunIterations(1000))
+	assert.True(t, search.FoundBest)
+	assert.Equal(t, 3.0, search.BestCost)
+
+	solutionActions := make([]core.Action, 0)
+	for _, node := range search.CurrentBestPath() {
+		solutionActions = append(solutionActions, node.(*GoapNode).Action)
+	}
+	assert.Equal(t, []core.Action{nil, consumeTest, consumeTest, consumeTest}, solutionActions)
+}
+
+func TestActions_Craft(t *testing.T) {
+	wood := &core.Resource{Name: "Wood"}
+	stone := &core.Resource{Name: "Stone"}
+	axe := &core.Resource{Name: "Stone Axe"}
+
+	forest := core.NewLocation("forest", core.Coord{}, core.WithInventory(core.InventoryEntry{Resource: wood, Amount: 10}))
+	quarry := core.NewLocation("quarry", core.Coord{}, core.WithInventory(core.InventoryEntry{Resource: stone, Amount: 10}))
+	workshop := core.NewLocation("workshop", core.Coord{})
+	depot := core.NewLocation("depot", core.Coord{})
+
+	gatherWood := &attributes.Gather{Res: wood, Amount: 2, ActionLocation: forest, ActionCost: 2}
+	gatherStone := &attributes.Gather{Res: stone, Amount: 1, ActionLocation: quarry, ActionCost: 2}
+	craft := &attributes.Craft{
+		Recipe:         "axe",
+		Inputs:         []core.InventoryEntry{{Resource: stone, Amount: 1}, {Resource: wood, Amount: 2}},
+		Outputs:        []core.InventoryEntry{{Resource: axe, Amount: 1}},
+		ActionLocation: workshop,
+		ActionCost:     3,
+	}
+	depositAxe := &attributes.Deposit{DepResource: axe, Amount: 1, ActionLocation: depot, ActionCost: 1}
+	depositWood := &attributes.Deposit{DepResource: wood, Amount: 1, ActionLocation: depot, ActionCost: 1}
+
+	startState := &core.WorldState{
+		Locations: map[string]*core.Location{
+			forest.Name:   forest,
+			quarry.Name:   quarry,
+			workshop.Name: workshop,
+			depot.Name:    depot,
+		},
+		Agents: map[string]core.Agent{
+			testAgent.Name(): testAgent.DeepCopy(),
+		},
+	}
+	goalState := &core.WorldState{
+		Locations: map[string]*core.Location{
+			depot.Name: core.NewLocation("depot", core.Coord{}, core.WithInventory(core.InventoryEntry{Resource: axe, Amount: 1})),
+		},
+		Agents: map[string]core.Agent{},
+	}
+
+	runInfo := &GoapRunInfo{
+		Agent:               testAgent,
+		PossibleNextActions: []core.Action{depositWood, gatherWood, craft, gatherStone, depositAxe},
+	}
+	startNode := &GoapNode{State: startState, GoapRunInfo: runInfo}
+	goalNode := &GoapNode{State: goalState, GoapRunInfo: runInfo}
+
+	h, err := startNode.Heuristic(goalNode)
+	require.NoError(t, err)
+	assert.Equal(t, 8.0, h, "heuristic should chain gathering the inputs, crafting and depositing")
+
+	search, err := astar.NewSearch(startNode, goalNode, astar.WithLogger(logging.NewLogger("error")))
+	require.NoError(t, err)
+	require.NoError(t, search.RunIterations(1000))
+	require.True(t, search.FoundBest)
+	assert.Equal(t, 8.0, search.BestCost)
+
+	solutionActions := make([]core.Action, 0)
+	for _, node := range search.CurrentBestPath() {
+		solutionActions = append(solutionActions, node.(*GoapNode).Action)
+	}
+	require.Len(t, solutionActions, 5)
+	assert.ElementsMatch(t, []core.Action{gatherWood, gatherStone}, solutionActions[1:3], "inputs should be gathered first")
+	assert.Equal(t, []core.Action{craft, depositAxe}, solutionActions[3:])
+}
+
+func TestActions_Tool(t *testing.T) {
+	branches := &core.Resource{Name: "Branches"}
+	stone := &core.Resource{Name: "Stone"}
+	axe := &core.Resource{Name: "Stone Axe"}
+	wood := &core.Resource{Name: "Wood"}
+
+	thicket := core.NewLocation("thicket", core.Coord{}, core.WithInventory(core.InventoryEntry{Resource: branches, Amount: 10}))
+	quarry := core.NewLocation("quarry", core.Coord{}, core.WithInventory(core.InventoryEntry{Resource: stone, Amount: 10}))
+	forest := core.NewLocation("forest", core.Coord{}, core.WithInventory(core.InventoryEntry{Resource: wood, Amount: 10}))
+	workshop := core.NewLocation("workshop", core.Coord{})
+	depot := core.NewLocation("depot", core.Coord{})
+
+	gatherBranches := &attributes.Gather{Res: branches, Amount: 1, ActionLocation: thicket, ActionCost: 1}
+	gatherStone := &attributes.Gather{Res: stone, Amount: 1, ActionLocation: quarry, ActionCost: 1}
+	craft := &attributes.Craft{
+		Recipe:         "axe",
+		Inputs:         []core.InventoryEntry{{Resource: branches, Amount: 1}, {Resource: stone, Amount: 1}},
+		Outputs:        []core.InventoryEntry{{Resource: axe, Amount: 1}},
+		ActionLocation: workshop,
+		ActionCost:     2,
+	}
+	gatherWood := &attributes.Gather{Requires: axe, Res: wood, Amount: 2, ActionLocation: forest, ActionCost: 1}
+	depositWood := &attributes.Deposit{DepResource: wood, Amount: 2, ActionLocation: depot, ActionCost: 1}
+
+	startState := &core.WorldState{
+		Locations: map[string]*core.Location{
+			thicket.Name:  thicket,
+			quarry.Name:   quarry,
+			forest.Name:   forest,
+			workshop.Name: workshop,
+			depot.Name:    depot,
+		},
+		Agents: map[string]core.Agent{
+			testAgent.Name(): testAgent.DeepCopy(),
+		},
+	}
+	goalState := &core.WorldState{
+		Locations: map[string]*core.Location{
+			depot.Name: core.NewLocation("depot", core.Coord{}, core.WithInventory(core.InventoryEntry{Resource: wood, Amount: 2})),
+		},
+		Agents: map[string]core.Agent{},
+	}
+
+	runInfo := &GoapRunInfo{
+		Agent:               testAgent,
+		PossibleNextActions: []core.Action{depositWood, gatherWood, craft, gatherStone, gatherBranches},
+	}
+	startNode := &GoapNode{State: startState, GoapRunInfo: runInfo}
+	goalNode := &GoapNode{State: goalState, GoapRunInfo: runInfo}
+
+	h, err := startNode.Heuristic(goalNode)
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, h, "heuristic should chain crafting the tool before gathering with it")
+
+	search, err := astar.NewSearch(startNode, goalNode, astar.WithLogger(logging.NewLogger("error")))
+	require.NoError(t, err)
+	require.NoError(t, search.RunIterations(1000))
+	require.True(t, search.FoundBest)
+	assert.Equal(t, 6.0, search.BestCost)
+
+	solutionActions := make([]core.Action, 0)
+	for _, node := range search.CurrentBestPath() {
+		solutionActions = append(solutionActions, node.(*GoapNode).Action)
+	}
+	require.Len(t, solutionActions, 6)
+	assert.ElementsMatch(t, []core.Action{gatherBranches, gatherStone}, solutionActions[1:3], "tool inputs should be gathered first")
+	assert.Equal(t, []core.Action{craft, gatherWood, depositWood}, solutionActions[3:])
+}
+
+func TestGoapNode_CostEncumbered(t *testing.T) {
+	stone := core.NewResource("Stone", core.WithResourceAttributes(&attributes.Weight{Amount: 2}))
+	quarry := core.NewLocation("quarry", core.Coord{}, core.WithInventory(core.InventoryEntry{Resource: stone, Amount: 10}))
+	carrier := &mockCarrier{mockAgent: mockAgent{N: "testCarrier", inventory: core.NewInventory()}, capacity: 8}
+	gather := &attributes.Gather{Res: stone, Amount: 2, ActionLocation: quarry, ActionCost: 2}
+
+	runInfo := &GoapRunInfo{Agent: carrier, PossibleNextActions: []core.Action{gather}}
+	start := &GoapNode{
+		State: &core.WorldState{
+			Locations: map[string]*core.Location{quarry.Name: quarry},
+			Agents:    map[string]core.Agent{carrier.Name(): carrier.DeepCopy()},
+		},
+		GoapRunInfo: runInfo,
+	}
+
+	first, err := start.GetSuccessors()
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	assert.Equal(t, 2.0, first[0].Cost(start), "the first gather is taken unencumbered")
+
+	second, err := first[0].GetSuccessors()
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	assert.Equal(t, 3.0, second[0].Cost(first[0]), "the second gather is taken carrying half the agent's capacity")
+}
+
+func TestGoapNode_HeuristicEncumbered(t *testing.T) {
+	stone := core.NewResource("Stone", core.WithResourceAttributes(&attributes.Weight{Amount: 2}))
+	quarry := core.NewLocation("quarry", core.Coord{}, core.WithInventory(core.InventoryEntry{Resource: stone, Amount: 10}))
+	carrier := &mockCarrier{mockAgent: mockAgent{N: "testCarrier", inventory: core.NewInventory()}, capacity: 8}
+	carrier.Inventory().AdjustAmount(stone, 2) // carrying half its capacity
+	gather := &attributes.Gather{Res: stone, Amount: 2, ActionLocation: quarry, ActionCost: 2}
+
+	runInfo := &GoapRunInfo{Agent: carrier, PossibleNextActions: []core.Action{gather}}
+	cur := &GoapNode{
+		State: &core.WorldState{
+			Locations: map[string]*core.Location{quarry.Name: quarry},
+			Agents:    map[string]core.Agent{carrier.Name(): carrier.DeepCopy()},
+		},
+		GoapRunInfo: runInfo,
+	}
+	goalCarrier := carrier.DeepCopy()
+	goalCarrier.Inventory().AdjustAmount(stone, 2)
+	goal := &GoapNode{
+		State:       &core.WorldState{Agents: map[string]core.Agent{carrier.Name(): goalCarrier}},
+		GoapRunInfo: runInfo,
+	}
+
+	val, err := cur.Heuristic(goal)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, val, "each unit should be estimated as if the agent carried nothing, which never costs more")
+}
+
+func TestGoapNode_HeuristicCapacity(t *testing.T) {
+	stone := core.NewResource("Stone", core.WithResourceAttributes(&attributes.Weight{Amount: 2}))
+	pit := core.NewLocation("pit", core.Coord{}, core.WithAttributes(&attributes.Capacity{Size: 20}))
+	deposit := &attributes.Deposit{DepResource: stone, Amount: 10, ActionLocation: pit, ActionCost: 1}
+	carrier := testAgent.DeepCopy()
+	carrier.Inventory().AdjustAmount(stone, 20)
+
+	type testCase struct {
+		goalAmount       int
+		expectedDistance float64
+	}
+
+	testCases := map[string]testCase{
+		"goal fits in the location": {
+			goalAmount:       10,
+			expectedDistance: 1.0,
+		},
+		"goal exceeds the location's capacity": {
+			goalAmount:       11,
+			expectedDistance: math.Inf(1),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			runInfo := &GoapRunInfo{Agent: carrier, PossibleNextActions: []core.Action{deposit}}
+			cur := &GoapNode{
+				State: &core.WorldState{
+					Locations: map[string]*core.Location{pit.Name: pit.DeepCopy()},
+					Agents:    map[string]core.Agent{carrier.Name(): carrier.DeepCopy()},
+				},
+				GoapRunInfo: runInfo,
+			}
+			goal := &GoapNode{
+				State: &core.WorldState{
+					Locations: map[string]*core.Location{pit.Name: core.NewLocation(pit.Name, core.Coord{},
+						core.WithInventory(core.InventoryEntry{Resource: stone, Amount: tc.goalAmount}))},
+				},
+				GoapRunInfo: runInfo,
+			}
+
+			val, err := cur.Heuristic(goal)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedDistance, val)
+		})
+	}
+}
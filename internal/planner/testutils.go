@@ -46,6 +46,12 @@ var (
 		ActionLocation: core.NewLocation("testLocation2", core.Coord{}),
 		ActionCost:     1.0,
 	}
+
+	consumeTest = &attributes.Consume{
+		Res:        testResource,
+		Amount:     1,
+		ActionCost: 1.0,
+	}
 )
 
 func init() {
@@ -58,7 +64,7 @@ type mockAgent struct {
 }
 
 func (m *mockAgent) String() string {
-	return "mockAgent"
+	return "mockAgent " + m.N + " " + m.inventory.String()
 }
 
 func (m *mockAgent) DeepCopy() core.Agent {
@@ -76,6 +82,23 @@ func (m *mockAgent) Name() string {
 	return m.N
 }
 
+// mockCarrier is a mockAgent that can carry only a limited weight, implementing core.Carrier.
+type mockCarrier struct {
+	mockAgent
+	capacity float64
+}
+
+func (m *mockCarrier) DeepCopy() core.Agent {
+	return &mockCarrier{
+		mockAgent: mockAgent{N: m.N, inventory: m.inventory.DeepCopy()},
+		capacity:  m.capacity,
+	}
+}
+
+func (m *mockCarrier) CarryCapacity() float64 {
+	return m.capacity
+}
+
 // mockAction implements Action and is used for testing.
 type mockAction struct{}
 
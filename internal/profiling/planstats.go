@@ -0,0 +1,71 @@
+package profiling
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"Neolithic/internal/agent"
+	"Neolithic/internal/core"
+)
+
+// PlannerStats collects the statistics of every search the GOAP planner finishes.
+type PlannerStats struct {
+	// plans are the statistics of each search, in the order they finished
+	plans []agent.PlanStats
+}
+
+var _ agent.PlanStatsRecorder = (*PlannerStats)(nil)
+
+// NewPlannerStats creates an empty PlannerStats.
+func NewPlannerStats() *PlannerStats {
+	return &PlannerStats{}
+}
+
+// RecordPlan implements agent.PlanStatsRecorder.
+func (p *PlannerStats) RecordPlan(stats agent.PlanStats) {
+	p.plans = append(p.plans, stats)
+}
+
+// Plans returns the statistics of every search recorded so far.
+func (p *PlannerStats) Plans() []agent.PlanStats {
+	return p.plans
+}
+
+// Attach sets the PlannerStats as the recorder of every agent in the world.
+func (p *PlannerStats) Attach(world *core.WorldState) {
+	for _, name := range world.AgentNames() {
+		if a, ok := world.Agents[name].(*agent.Agent); ok && a.Behavior != nil {
+			a.Behavior.PlanStats = p
+		}
+	}
+}
+
+// WriteSummary writes a human-readable summary of the recorded statistics to w.
+func (p *PlannerStats) WriteSummary(w io.Writer) {
+	found := 0
+	var iterations, expanded, maxIterations, maxOpen, maxClosed int
+	var wallTime, maxWallTime time.Duration
+	for _, stats := range p.plans {
+		if stats.Found {
+			found++
+		}
+		iterations += stats.Iterations
+		expanded += stats.Expanded
+		wallTime += stats.WallTime
+		maxIterations = max(maxIterations, stats.Iterations)
+		maxOpen = max(maxOpen, stats.OpenSet)
+		maxClosed = max(maxClosed, stats.ClosedSet)
+		maxWallTime = max(maxWallTime, stats.WallTime)
+	}
+
+	fmt.Fprintf(w, "planner searches: %d (%d found, %d given up)\n", len(p.plans), found, len(p.plans)-found)
+	if len(p.plans) == 0 {
+		return
+	}
+	count := len(p.plans)
+	fmt.Fprintf(w, "  iterations: total %d, mean %d, max %d\n", iterations, iterations/count, maxIterations)
+	fmt.Fprintf(w, "  nodes expanded: total %d, mean %d\n", expanded, expanded/count)
+	fmt.Fprintf(w, "  largest open set: %d, largest closed set: %d\n", maxOpen, maxClosed)
+	fmt.Fprintf(w, "  wall time: total %s, mean %s, max %s\n", wallTime, wallTime/time.Duration(count), maxWallTime)
+}
@@ -0,0 +1,68 @@
+package profiling
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"Neolithic/internal/agent"
+	"Neolithic/internal/core"
+	"Neolithic/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlannerStats_WriteSummary(t *testing.T) {
+	type testCase struct {
+		plans    []agent.PlanStats
+		expected string
+	}
+
+	tests := map[string]testCase{
+		"no searches": {
+			expected: "planner searches: 0 (0 found, 0 given up)\n",
+		},
+		"several searches": {
+			plans: []agent.PlanStats{
+				{Found: true, Iterations: 10, Expanded: 8, OpenSet: 3, ClosedSet: 8, WallTime: time.Millisecond},
+				{Found: true, Iterations: 30, Expanded: 20, OpenSet: 1, ClosedSet: 25, WallTime: 3 * time.Millisecond},
+				{Found: false, Iterations: 50, Expanded: 50, OpenSet: 0, ClosedSet: 50, WallTime: 5 * time.Millisecond},
+			},
+			expected: "planner searches: 3 (2 found, 1 given up)\n" +
+				"  iterations: total 90, mean 30, max 50\n" +
+				"  nodes expanded: total 78, mean 26\n" +
+				"  largest open set: 3, largest closed set: 50\n" +
+				"  wall time: total 9ms, mean 3ms, max 5ms\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			stats := NewPlannerStats()
+			for _, plan := range tc.plans {
+				stats.RecordPlan(plan)
+			}
+
+			var sb strings.Builder
+			stats.WriteSummary(&sb)
+			assert.Equal(t, tc.expected, sb.String())
+			assert.Equal(t, tc.plans, stats.Plans())
+		})
+	}
+}
+
+func TestPlannerStats_Attach(t *testing.T) {
+	logger := logging.NewLogger("error")
+	ana := agent.NewAgent("ana", logger)
+	bo := agent.NewAgent("bo", logger)
+	world := &core.WorldState{
+		Locations: map[string]*core.Location{},
+		Agents:    map[string]core.Agent{"ana": ana, "bo": bo},
+	}
+
+	stats := NewPlannerStats()
+	stats.Attach(world)
+
+	assert.Same(t, stats, ana.Behavior.PlanStats)
+	assert.Same(t, stats, bo.Behavior.PlanStats)
+	assert.Same(t, stats, ana.DeepCopy().(*agent.Agent).Behavior.PlanStats, "copies of the agent should keep the recorder")
+}
@@ -0,0 +1,123 @@
+// Package profiling provides opt-in diagnostics for the simulation: CPU, heap and execution trace profiles, and
+// statistics about the GOAP planner. Nothing is written unless it is asked for.
+package profiling
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// Config selects the profiles to write. A profile is only written if its path is set.
+type Config struct {
+	// CPUProfile is the path to write a CPU profile to
+	CPUProfile string
+	// HeapProfile is the path to write a heap profile to when profiling stops
+	HeapProfile string
+	// Trace is the path to write an execution trace to
+	Trace string
+}
+
+// RegisterFlags registers command-line flags that set the Config on fs.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.CPUProfile, "cpuprofile", "", "path to write a CPU profile to")
+	fs.StringVar(&c.HeapProfile, "memprofile", "", "path to write a heap profile to on exit")
+	fs.StringVar(&c.Trace, "trace", "", "path to write an execution trace to")
+}
+
+// Enabled reports whether any profile is selected.
+func (c Config) Enabled() bool {
+	return c.CPUProfile != "" || c.HeapProfile != "" || c.Trace != ""
+}
+
+// Session is a set of running profiles, started by Start.
+type Session struct {
+	// config is the Config the session was started with
+	config Config
+	// cpuFile is the file the CPU profile is written to, or nil if not profiling the CPU
+	cpuFile *os.File
+	// traceFile is the file the trace is written to, or nil if not tracing
+	traceFile *os.File
+}
+
+// Start starts the profiles selected by the Config. Stop must be called to finish writing them.
+func Start(config Config) (*Session, error) {
+	s := &Session{config: config}
+
+	if config.CPUProfile != "" {
+		file, err := os.Create(config.CPUProfile)
+		if err != nil {
+			return nil, fmt.Errorf("could not create CPU profile: %w", err)
+		}
+		if err = pprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("could not start CPU profile: %w", err)
+		}
+		s.cpuFile = file
+	}
+
+	if config.Trace != "" {
+		file, err := os.Create(config.Trace)
+		if err != nil {
+			s.stopCPUProfile()
+			return nil, fmt.Errorf("could not create trace: %w", err)
+		}
+		if err = trace.Start(file); err != nil {
+			file.Close()
+			s.stopCPUProfile()
+			return nil, fmt.Errorf("could not start trace: %w", err)
+		}
+		s.traceFile = file
+	}
+
+	return s, nil
+}
+
+// Stop stops the running profiles and writes the heap profile, if one was selected.
+func (s *Session) Stop() error {
+	var errs []error
+
+	errs = append(errs, s.stopCPUProfile())
+
+	if s.traceFile != nil {
+		trace.Stop()
+		errs = append(errs, s.traceFile.Close())
+		s.traceFile = nil
+	}
+
+	if s.config.HeapProfile != "" {
+		errs = append(errs, writeHeapProfile(s.config.HeapProfile))
+	}
+
+	return errors.Join(errs...)
+}
+
+// stopCPUProfile stops the CPU profile, if one is running, and closes its file.
+func (s *Session) stopCPUProfile() error {
+	if s.cpuFile == nil {
+		return nil
+	}
+	pprof.StopCPUProfile()
+	err := s.cpuFile.Close()
+	s.cpuFile = nil
+	return err
+}
+
+// writeHeapProfile writes a heap profile to the file at path.
+func writeHeapProfile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create heap profile: %w", err)
+	}
+	defer file.Close()
+
+	runtime.GC() // get up-to-date statistics on what is still in use
+	if err = pprof.WriteHeapProfile(file); err != nil {
+		return fmt.Errorf("could not write heap profile: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,93 @@
+package profiling
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStart(t *testing.T) {
+	type testCase struct {
+		config        func(dir string) Config
+		expectedFiles []string
+	}
+
+	tests := map[string]testCase{
+		"nothing enabled": {
+			config:        func(string) Config { return Config{} },
+			expectedFiles: []string{},
+		},
+		"all profiles": {
+			config: func(dir string) Config {
+				return Config{
+					CPUProfile:  filepath.Join(dir, "cpu.pprof"),
+					HeapProfile: filepath.Join(dir, "mem.pprof"),
+					Trace:       filepath.Join(dir, "run.trace"),
+				}
+			},
+			expectedFiles: []string{"cpu.pprof", "mem.pprof", "run.trace"},
+		},
+		"heap only": {
+			config: func(dir string) Config {
+				return Config{HeapProfile: filepath.Join(dir, "mem.pprof")}
+			},
+			expectedFiles: []string{"mem.pprof"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			config := tc.config(dir)
+			assert.Equal(t, len(tc.expectedFiles) > 0, config.Enabled())
+
+			session, err := Start(config)
+			require.NoError(t, err)
+			require.NoError(t, session.Stop())
+
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			names := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+				info, err := entry.Info()
+				require.NoError(t, err)
+				assert.Positive(t, info.Size(), "%s should not be empty", entry.Name())
+			}
+			assert.Equal(t, tc.expectedFiles, names)
+		})
+	}
+
+	t.Run("unwritable path", func(t *testing.T) {
+		_, err := Start(Config{CPUProfile: filepath.Join(t.TempDir(), "missing", "cpu.pprof")})
+		assert.Error(t, err)
+	})
+
+	t.Run("unwritable trace", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := Start(Config{
+			CPUProfile:  filepath.Join(dir, "cpu.pprof"),
+			HeapProfile: filepath.Join(dir, "mem.pprof"),
+			Trace:       filepath.Join(dir, "missing", "run.trace"),
+		})
+		assert.Error(t, err)
+		assert.NoFileExists(t, filepath.Join(dir, "mem.pprof"), "a failed start should not write the heap profile")
+
+		session, err := Start(Config{CPUProfile: filepath.Join(dir, "again.pprof")})
+		require.NoError(t, err, "the CPU profile should have been stopped")
+		require.NoError(t, session.Stop())
+	})
+}
+
+func TestConfig_RegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	config := &Config{}
+	config.RegisterFlags(fs)
+
+	require.NoError(t, fs.Parse([]string{"-cpuprofile", "cpu.pprof", "-trace", "run.trace"}))
+	assert.Equal(t, Config{CPUProfile: "cpu.pprof", Trace: "run.trace"}, *config)
+}
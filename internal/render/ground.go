@@ -0,0 +1,110 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"os"
+
+	_ "image/png"
+
+	"Neolithic/internal/world"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	grass1Path = "assets/grass_1.png"
+	grass2Path = "assets/grass_2.png"
+	grass3Path = "assets/grass_3.png"
+)
+
+// Ground represents the sprites used to draw the base terrain of a tile
+type Ground struct {
+	// Images are the interchangeable sprites for the ground. Having more than one allows for terrain variation.
+	Images []*ebiten.Image
+	// Seed varies which sprite is drawn on each tile, so different simulations have different looking terrain
+	Seed int64
+}
+
+// NewRGBGround creates a new ground with a single color.
+func NewRGBGround(col color.RGBA, cellSize int) *Ground {
+	image := ebiten.NewImage(cellSize, cellSize)
+	image.Fill(col)
+	return &Ground{
+		Images: []*ebiten.Image{image},
+	}
+}
+
+// terrainColors are the colors of the terrains that are drawn as a single color rather than with sprites
+var terrainColors = map[world.Terrain]color.RGBA{
+	world.Forest: {R: 34, G: 85, B: 34, A: 255},
+	world.Marsh:  {R: 85, G: 107, B: 67, A: 255},
+	world.Rock:   {R: 128, G: 124, B: 118, A: 255},
+	world.Water:  {R: 48, G: 96, B: 176, A: 255},
+}
+
+// NewTerrainGrounds creates a Ground for every world.Terrain: grass is drawn with the grass sprites, and the other
+// terrains with a single color. The seed varies the sprites, as in Ground.Seed.
+func NewTerrainGrounds(seed int64, cellSize int) (map[world.Terrain]*Ground, error) {
+	grounds := make(map[world.Terrain]*Ground, len(terrainColors)+1)
+	for _, terrain := range world.Terrains() {
+		if terrain == world.Grass {
+			grass, err := NewGrassGround()
+			if err != nil {
+				return nil, err
+			}
+			grounds[terrain] = grass
+		} else {
+			grounds[terrain] = NewRGBGround(terrainColors[terrain], cellSize)
+		}
+		grounds[terrain].Seed = seed
+	}
+	return grounds, nil
+}
+
+// NewGrassGround creates a new ground with a Grass texture
+func NewGrassGround() (*Ground, error) {
+	return NewVariedGround([]string{grass1Path, grass2Path, grass3Path})
+}
+
+// NewVariedGround creates a new ground, taking in an array of paths to images. This allows one to pass in a number of
+// similar sprites for terrain variation.
+func NewVariedGround(paths []string) (*Ground, error) {
+	images := make([]*ebiten.Image, len(paths))
+
+	for i, path := range paths {
+		img, err := loadSprite(path)
+		if err != nil {
+			return nil, err
+		}
+
+		images[i] = img
+	}
+
+	return &Ground{
+		Images: images,
+	}, nil
+}
+
+// ImageAt selects the sprite to use for the tile at the given coordinates. The selection is a stable function of the
+// coordinates and the seed, so the same tile is always drawn with the same sprite.
+func (g *Ground) ImageAt(x, y int) *ebiten.Image {
+	h := uint32(x)*73856093 ^ uint32(y)*19349663 ^ uint32(g.Seed)*83492791
+	return g.Images[h%uint32(len(g.Images))]
+}
+
+// loadSprite loads a sprite from a file
+func loadSprite(filePath string) (*ebiten.Image, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+
+	sprite := ebiten.NewImageFromImage(img)
+	return sprite, nil
+}
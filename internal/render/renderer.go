@@ -0,0 +1,156 @@
+package render
+
+import (
+	"image/color"
+	"math"
+
+	"Neolithic/internal/agent"
+	"Neolithic/internal/camera"
+	"Neolithic/internal/core"
+	"Neolithic/internal/grid"
+	"Neolithic/internal/world"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Renderer draws a world.Engine to the screen. It holds all the images needed for drawing, so that the engine itself
+// has no rendering dependencies.
+type Renderer struct {
+	// grounds are the sprite sets used to draw the tiles of the grid, by the terrain of the tile
+	grounds map[world.Terrain]*Ground
+	// villagerImage is the sprite used to represent a villager
+	villagerImage *ebiten.Image
+	// locationImage is the sprite used to represent a location
+	locationImage *ebiten.Image
+}
+
+// NewRenderer creates a new Renderer, loading the sprites it needs. The seed varies the terrain sprites; passing the
+// engine's seed keeps the look of a world reproducible along with the simulation. cellSize is the size of the tiles of
+// the grid being drawn.
+func NewRenderer(seed int64, cellSize int) (*Renderer, error) {
+	grounds, err := NewTerrainGrounds(seed, cellSize)
+	if err != nil {
+		return nil, err
+	}
+
+	villagerImg := ebiten.NewImage(8, 8)
+	villagerImg.Fill(color.RGBA{
+		R: 70,
+		G: 80,
+		B: 100,
+		A: 255,
+	})
+
+	locationImg := ebiten.NewImage(10, 10)
+	locationImg.Fill(color.RGBA{
+		R: 170,
+		G: 80,
+		B: 20,
+		A: 255,
+	})
+
+	return &Renderer{
+		grounds:       grounds,
+		villagerImage: villagerImg,
+		locationImage: locationImg,
+	}, nil
+}
+
+// Draw draws the engine's world state on the screen
+func (r *Renderer) Draw(screen *ebiten.Image, engine *world.Engine, viewport *camera.Viewport, camera *camera.Camera) {
+	worldGrid := engine.World.Grid.(*grid.Grid)
+	r.drawGrid(screen, worldGrid, viewport, camera)
+	transform := viewport.GetTransform()
+
+	for _, l := range engine.World.Locations {
+		DrawEntity(screen, &transform, worldGrid.CellSize, r.locationImage, l.Coord)
+	}
+	for _, a := range engine.World.Agents {
+		x, y := a.(*agent.Agent).InterpolatedPosition()
+		DrawEntityAt(screen, &transform, worldGrid.CellSize, r.villagerImage, x, y)
+	}
+}
+
+// drawGrid draws the Grid, based on the viewport and camera location
+func (r *Renderer) drawGrid(screen *ebiten.Image, g *grid.Grid, viewport *camera.Viewport, camera *camera.Camera) {
+	transform := viewport.GetTransform()
+
+	screenWidth, screenHeight := viewport.Width, viewport.Height
+	cellSize := float64(g.CellSize)
+
+	invZoom := 1.0 / camera.Zoom
+
+	leftWorld := camera.X
+	rightWorld := camera.X + float64(screenWidth)*invZoom
+	topWorld := camera.Y
+	bottomWorld := camera.Y + float64(screenHeight)*invZoom
+
+	left := int(math.Floor(leftWorld / cellSize))
+	right := int(math.Ceil(rightWorld / cellSize))
+	top := int(math.Floor(topWorld / cellSize))
+	bottom := int(math.Ceil(bottomWorld / cellSize))
+
+	// Clamp indices to grid bounds
+	if left < 0 {
+		left = 0
+	}
+	if right > g.Width {
+		right = g.Width
+	}
+	if top < 0 {
+		top = 0
+	}
+	if bottom > g.Height {
+		bottom = g.Height
+	}
+
+	for y := top; y < bottom; y++ {
+		for x := left; x < right; x++ {
+			if x >= 0 && x < g.Width && y >= 0 && y < g.Height {
+				r.drawCell(screen, g, x, y, &transform)
+			}
+		}
+	}
+}
+
+// drawCell draws a grid cell
+func (r *Renderer) drawCell(screen *ebiten.Image, g *grid.Grid, x, y int, transform *ebiten.GeoM) {
+	worldX := float64(x * g.CellSize)
+	worldY := float64(y * g.CellSize)
+
+	var cellTransform ebiten.GeoM
+
+	cellTransform.Reset()
+	cellTransform.Translate(worldX, worldY)
+	cellTransform.Concat(*transform)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM = cellTransform
+	terrain, _ := world.TerrainAt(g, core.Coord{X: x, Y: y})
+	ground, ok := r.grounds[terrain]
+	if !ok {
+		ground = r.grounds[world.Grass]
+	}
+	screen.DrawImage(ground.ImageAt(x, y), op)
+}
+
+// DrawEntity draws an entity on the screen at a given position. Entity can be an agent or a location
+func DrawEntity(screen *ebiten.Image, transform *ebiten.GeoM, cellSize int, entityImg *ebiten.Image, position core.Coord) {
+	DrawEntityAt(screen, transform, cellSize, entityImg, float64(position.X), float64(position.Y))
+}
+
+// DrawEntityAt draws an entity on the screen at a position given in tiles, which may lie between tiles, such as for an
+// agent moving from one tile to the next
+func DrawEntityAt(screen *ebiten.Image, transform *ebiten.GeoM, cellSize int, entityImg *ebiten.Image, x, y float64) {
+	size := entityImg.Bounds().Size().X // assuming villager is square
+	worldX := x*float64(cellSize) + float64(size/2)
+	worldY := y*float64(cellSize) + float64(size/2)
+
+	var cellTransform ebiten.GeoM
+	cellTransform.Reset()
+	cellTransform.Translate(worldX, worldY)
+	cellTransform.Concat(*transform)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM = cellTransform
+	screen.DrawImage(entityImg, op)
+}
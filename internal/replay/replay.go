@@ -0,0 +1,177 @@
+// Package replay records the inputs of a simulation run so that it can be replayed later. A replay log holds the
+// starting state of the run and the delta time of every tick; since the simulation is deterministic, replaying those
+// inputs from the same start reproduces the run exactly. The world ID after each tick is recorded as well, so a
+// replay can check that it hasn't diverged from the original.
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"Neolithic/internal/core"
+	"Neolithic/internal/snapshot"
+	"Neolithic/internal/world"
+)
+
+// Version is the current version of the replay log format.
+const Version = 1
+
+// ErrUnsupportedVersion is returned when reading a replay log written in a different format version.
+var ErrUnsupportedVersion = errors.New("unsupported replay version")
+
+// Log is a recording of a simulation run.
+type Log struct {
+	// Version is the version of the format the log was written in
+	Version int `json:"version"`
+	// Start is the state of the engine when recording began
+	Start *snapshot.Snapshot `json:"start"`
+	// Ticks are the ticks of the run, in order
+	Ticks []Tick `json:"ticks"`
+}
+
+// Tick is the record of a single engine tick.
+type Tick struct {
+	// DeltaTime is the delta time the engine was ticked with
+	DeltaTime float64 `json:"delta_time"`
+	// WorldID is the ID of the world state after the tick
+	WorldID string `json:"world_id"`
+}
+
+// DivergenceError is returned when a replay produces a different world state than the recorded run.
+type DivergenceError struct {
+	// Tick is the index of the first tick that diverged
+	Tick int
+	// Expected is the recorded world ID
+	Expected string
+	// Got is the world ID produced by the replay
+	Got string
+}
+
+// Error implements error.
+func (e *DivergenceError) Error() string {
+	return fmt.Sprintf("replay diverged at tick %d: expected world %s, got %s", e.Tick, e.Expected, e.Got)
+}
+
+// Recorder ticks an engine and records every tick to a Log.
+type Recorder struct {
+	// engine is the engine being recorded
+	engine *world.Engine
+	// log is the log being recorded to
+	log *Log
+}
+
+// NewRecorder starts recording the engine from its current state.
+func NewRecorder(engine *world.Engine, registry *core.AttributeRegistry) (*Recorder, error) {
+	start, err := snapshot.Take(engine, registry)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{
+		engine: engine,
+		log: &Log{
+			Version: Version,
+			Start:   start,
+			Ticks:   []Tick{},
+		},
+	}, nil
+}
+
+// Tick ticks the engine and records the tick.
+func (r *Recorder) Tick(deltaTime float64) error {
+	if err := r.engine.Tick(deltaTime); err != nil {
+		return err
+	}
+	id, err := r.engine.World.ID()
+	if err != nil {
+		return err
+	}
+	r.log.Ticks = append(r.log.Ticks, Tick{DeltaTime: deltaTime, WorldID: id})
+	return nil
+}
+
+// Log returns the log recorded so far.
+func (r *Recorder) Log() *Log {
+	return r.log
+}
+
+// Replay restores the starting state of the log and ticks it with the recorded inputs. It returns a *DivergenceError
+// as soon as a tick produces a different world than the one recorded, along with the engine as of that tick.
+func Replay(l *Log, registry *core.AttributeRegistry, logger *slog.Logger) (*world.Engine, error) {
+	if l.Version != Version {
+		return nil, fmt.Errorf("%w: %d (expected %d)", ErrUnsupportedVersion, l.Version, Version)
+	}
+	if l.Start == nil {
+		return nil, errors.New("replay log has no starting state")
+	}
+
+	engine, err := l.Start.Restore(registry, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, tick := range l.Ticks {
+		if err = engine.Tick(tick.DeltaTime); err != nil {
+			return engine, fmt.Errorf("tick %d: %w", i, err)
+		}
+		id, err := engine.World.ID()
+		if err != nil {
+			return engine, err
+		}
+		if id != tick.WorldID {
+			return engine, &DivergenceError{Tick: i, Expected: tick.WorldID, Got: id}
+		}
+	}
+	return engine, nil
+}
+
+// Write writes the log to w as JSON.
+func Write(w io.Writer, l *Log) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
+
+// Read reads a log written by Write.
+func Read(r io.Reader) (*Log, error) {
+	l := &Log{}
+	if err := json.NewDecoder(r).Decode(l); err != nil {
+		return nil, err
+	}
+	if l.Version != Version {
+		return nil, fmt.Errorf("%w: %d (expected %d)", ErrUnsupportedVersion, l.Version, Version)
+	}
+	return l, nil
+}
+
+// SaveFile writes the log to the file at path.
+func SaveFile(path string, l *Log) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err = Write(file, l); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// LoadFile reads the log at path.
+func LoadFile(path string) (*Log, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	l, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return l, nil
+}
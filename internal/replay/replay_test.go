@@ -0,0 +1,137 @@
+package replay
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"Neolithic/internal/attributes"
+	"Neolithic/internal/logging"
+	"Neolithic/internal/scenario"
+	"Neolithic/internal/world"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deltaTime = 1.0 / 60.0
+
+// crowdedScenario has several agents competing for the same bush, so the order they are ticked in matters.
+const crowdedScenario = `
+seed: 3
+grid: {width: 12, height: 12}
+resources:
+  - name: Berries
+    attributes:
+      - type: weight
+        amount: 1
+locations:
+  - name: bush
+    coord: {x: 2, y: 2}
+    inventory:
+      Berries: 20
+  - name: depo
+    coord: {x: 9, y: 9}
+    attributes:
+      - type: capacity
+        size: 100
+agents:
+  - name: ana
+    position: {x: 0, y: 0}
+    goal: {name: stock, location: depo, resource: Berries}
+  - name: bo
+    position: {x: 11, y: 0}
+    goal: {name: stock, location: depo, resource: Berries}
+  - name: cy
+    position: {x: 0, y: 11}
+    goal: {name: stock, location: depo, resource: Berries}
+`
+
+func newCrowdedEngine(t *testing.T) *world.Engine {
+	t.Helper()
+	file, err := scenario.Parse("crowded.yaml", []byte(crowdedScenario))
+	require.NoError(t, err)
+	engine, err := file.Build(logging.NewLogger("error"))
+	require.NoError(t, err)
+	return engine
+}
+
+func record(t *testing.T, engine *world.Engine, ticks int) *Log {
+	t.Helper()
+	recorder, err := NewRecorder(engine, attributes.NewRegistry())
+	require.NoError(t, err)
+	for i := 0; i < ticks; i++ {
+		require.NoError(t, recorder.Tick(deltaTime))
+	}
+	return recorder.Log()
+}
+
+func TestRecorder_Deterministic(t *testing.T) {
+	first := record(t, newCrowdedEngine(t), 600)
+	second := record(t, newCrowdedEngine(t), 600)
+
+	require.Len(t, first.Ticks, 600)
+	assert.Equal(t, int64(3), first.Start.Seed)
+	assert.Equal(t, first.Ticks, second.Ticks)
+}
+
+func TestReplay(t *testing.T) {
+	logger := logging.NewLogger("error")
+	registry := attributes.NewRegistry()
+
+	original := newCrowdedEngine(t)
+	recorded := record(t, original, 600)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, recorded))
+	read, err := Read(&buf)
+	require.NoError(t, err)
+
+	t.Run("matches recording", func(t *testing.T) {
+		replayed, err := Replay(read, registry, logger)
+		require.NoError(t, err)
+
+		originalID, err := original.World.ID()
+		require.NoError(t, err)
+		replayedID, err := replayed.World.ID()
+		require.NoError(t, err)
+		assert.Equal(t, originalID, replayedID)
+	})
+
+	t.Run("reports divergence", func(t *testing.T) {
+		tampered := *read
+		tampered.Ticks = append([]Tick{}, read.Ticks...)
+		tampered.Ticks[100].WorldID = "tampered"
+
+		_, err := Replay(&tampered, registry, logger)
+		var divergence *DivergenceError
+		require.ErrorAs(t, err, &divergence)
+		assert.Equal(t, 100, divergence.Tick)
+		assert.Equal(t, "tampered", divergence.Expected)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := Replay(&Log{Version: 99}, registry, logger)
+		assert.ErrorIs(t, err, ErrUnsupportedVersion)
+	})
+}
+
+func TestRead(t *testing.T) {
+	type testCase struct {
+		input     string
+		expectErr error
+	}
+
+	tests := map[string]testCase{
+		"unsupported version": {
+			input:     `{"version": 2, "ticks": []}`,
+			expectErr: ErrUnsupportedVersion,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tc.input))
+			assert.ErrorIs(t, err, tc.expectErr)
+		})
+	}
+}
@@ -0,0 +1,770 @@
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+
+	"Neolithic/internal/agent"
+	"Neolithic/internal/attributes"
+	"Neolithic/internal/core"
+	"Neolithic/internal/goalengine"
+	"Neolithic/internal/grid"
+	"Neolithic/internal/mapgen"
+	"Neolithic/internal/world"
+)
+
+const (
+	// defaultChunker is the chunker used by goals that don't name one
+	defaultChunker = "add_to_location"
+	// defaultFallback is the fallback used by goals that don't name one
+	defaultFallback = "halve"
+	// defaultGiveUp is the give up function used by goals that don't name one
+	defaultGiveUp = "no_change"
+)
+
+var (
+	// ErrRequired is returned when a required field is missing
+	ErrRequired = errors.New("field is required")
+	// ErrDuplicateName is returned when two entities of the same kind share a name
+	ErrDuplicateName = errors.New("duplicate name")
+	// ErrOutOfBounds is returned when a coordinate is outside the grid
+	ErrOutOfBounds = errors.New("coordinate is outside the grid")
+	// ErrUnknownReference is returned when a field refers to a resource, location or function that doesn't exist
+	ErrUnknownReference = errors.New("unknown reference")
+	// ErrConflictingFields is returned when fields that exclude each other are both given
+	ErrConflictingFields = errors.New("conflicting fields")
+	// ErrImpassable is returned when an agent is placed, or a location can only be used from, where agents can't move
+	ErrImpassable = errors.New("coordinate is impassable")
+)
+
+// Option is a functional option type for configuring how a scenario is built.
+type Option func(*options)
+
+// options are the settings applied by Options.
+type options struct {
+	// seed overrides the seed of the scenario, if set
+	seed *int64
+}
+
+// WithSeed is an Option that seeds the simulation, and any map it generates, with the given seed instead of the seed
+// of the scenario.
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = &seed
+	}
+}
+
+// Load reads the scenario file at path and builds an Engine from it.
+func Load(path string, logger *slog.Logger, opts ...Option) (*world.Engine, error) {
+	file, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return file.Build(logger, opts...)
+}
+
+// Build validates the scenario and builds an Engine from it. All errors are of type *Error.
+func (f *File) Build(logger *slog.Logger, opts ...Option) (*world.Engine, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cellSize := f.Grid.CellSize
+	if cellSize == 0 {
+		cellSize = defaultCellSize
+	}
+	worldGrid, err := grid.New(f.Grid.Width, f.Grid.Height, cellSize)
+	if err != nil {
+		return nil, f.errorAt(fieldPath{"grid"}, err)
+	}
+
+	resources, err := f.buildResources()
+	if err != nil {
+		return nil, err
+	}
+
+	terrainAt, err := f.buildTerrain()
+	if err != nil {
+		return nil, err
+	}
+	var generated []*core.Location
+	if f.Grid.Generate != nil {
+		if f.Grid.Terrain != nil {
+			return nil, f.errorAt(fieldPath{"grid", "generate"}, fmt.Errorf("%w: only one of terrain and generate may be given", ErrConflictingFields))
+		}
+		generatedMap, err := f.generateMap(resources, o.seed)
+		if err != nil {
+			return nil, err
+		}
+		terrainAt, generated = generatedMap.TerrainAt, generatedMap.Locations
+	}
+	if err = worldGrid.Initialize(world.MakeTerrainTile(terrainAt)); err != nil {
+		return nil, f.errorAt(fieldPath{"grid"}, err)
+	}
+
+	var engineOpts []world.EngineOption
+	switch {
+	case o.seed != nil:
+		engineOpts = append(engineOpts, world.WithSeed(*o.seed))
+	case f.Seed != nil:
+		engineOpts = append(engineOpts, world.WithSeed(*f.Seed))
+	}
+
+	engine, err := world.NewEngine(worldGrid, logger, engineOpts...)
+	if err != nil {
+		return nil, f.errorAt(fieldPath{"grid"}, err)
+	}
+
+	locations, err := f.buildLocations(resources, worldGrid)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, loc := range locations {
+		if err = engine.AddLocation(loc); err != nil {
+			return nil, f.errorAt(fieldPath{"locations", i}, err)
+		}
+	}
+	for _, loc := range generated {
+		if err = engine.AddLocation(loc); err != nil {
+			return nil, f.errorAt(fieldPath{"grid", "generate"}, err)
+		}
+	}
+	locations = append(locations, generated...)
+	for i, spec := range f.Resources {
+		if err = engine.AddResource(resources[spec.Name]); err != nil {
+			return nil, f.errorAt(fieldPath{"resources", i}, err)
+		}
+	}
+
+	agentNames := map[string]bool{}
+	for i, spec := range f.Agents {
+		path := fieldPath{"agents", i}
+		if agentNames[spec.Name] {
+			return nil, f.errorAt(path.with("name"), fmt.Errorf("%w: agent %q", ErrDuplicateName, spec.Name))
+		}
+		agentNames[spec.Name] = true
+
+		newAgent, err := f.buildAgent(path, spec, resources, locations, worldGrid, logger)
+		if err != nil {
+			return nil, err
+		}
+		if err = engine.AddAgent(newAgent); err != nil {
+			return nil, f.errorAt(path, err)
+		}
+	}
+
+	return engine, nil
+}
+
+// buildTerrain returns the terrain of each coordinate of the grid, as described by the scenario.
+func (f *File) buildTerrain() (func(x, y int) world.Terrain, error) {
+	spec := f.Grid.Terrain
+	if spec == nil {
+		return func(_, _ int) world.Terrain { return world.Grass }, nil
+	}
+	path := fieldPath{"grid", "terrain"}
+
+	defaultTerrain := world.Grass
+	if spec.Default != "" {
+		var err error
+		if defaultTerrain, err = world.ParseTerrain(spec.Default); err != nil {
+			return nil, f.errorAt(path.with("default"), err)
+		}
+	}
+
+	type area struct {
+		terrain    world.Terrain
+		minX, minY int
+		maxX, maxY int
+	}
+	areas := make([]area, 0, len(spec.Areas))
+	for i, areaSpec := range spec.Areas {
+		areaPath := path.with("areas", i)
+		terrain, err := world.ParseTerrain(areaSpec.Terrain)
+		if err != nil {
+			return nil, f.errorAt(areaPath.with("terrain"), err)
+		}
+		from, err := f.buildCoord(areaPath.with("from"), areaSpec.From)
+		if err != nil {
+			return nil, err
+		}
+		to, err := f.buildCoord(areaPath.with("to"), areaSpec.To)
+		if err != nil {
+			return nil, err
+		}
+		areas = append(areas, area{
+			terrain: terrain,
+			minX:    min(from.X, to.X),
+			minY:    min(from.Y, to.Y),
+			maxX:    max(from.X, to.X),
+			maxY:    max(from.Y, to.Y),
+		})
+	}
+
+	return func(x, y int) world.Terrain {
+		terrain := defaultTerrain
+		for _, a := range areas {
+			if x >= a.minX && x <= a.maxX && y >= a.minY && y <= a.maxY {
+				terrain = a.terrain
+			}
+		}
+		return terrain
+	}, nil
+}
+
+// generateMap generates the terrain of the grid and the locations placed on it, as described by the scenario. The
+// map is seeded by override if it is given, and otherwise by the seed of the scenario unless the map has its own.
+func (f *File) generateMap(resources map[string]*core.Resource, override *int64) (*mapgen.Map, error) {
+	spec := f.Grid.Generate
+	path := fieldPath{"grid", "generate"}
+
+	seed := world.DefaultSeed
+	switch {
+	case override != nil:
+		seed = *override
+	case spec.Seed != nil:
+		seed = *spec.Seed
+	case f.Seed != nil:
+		seed = *f.Seed
+	}
+	if spec.Rivers < 0 {
+		return nil, f.errorAt(path.with("rivers"), fmt.Errorf("rivers must not be negative, got %d", spec.Rivers))
+	}
+
+	declared := make(map[string]bool, len(f.Locations))
+	for _, locSpec := range f.Locations {
+		declared[locSpec.Name] = true
+	}
+	placedNames := map[string]bool{}
+	placements := make([]mapgen.Placement, 0, len(spec.Placements))
+	for i, placementSpec := range spec.Placements {
+		placementPath := path.with("placements", i)
+		placement, err := f.buildPlacement(placementPath, placementSpec, resources)
+		if err != nil {
+			return nil, err
+		}
+		// the names of the locations that may be placed must not clash with each other or with declared locations
+		for n := 1; n <= placement.Count; n++ {
+			name := fmt.Sprintf("%s%d", placement.Name, n)
+			if declared[name] || placedNames[name] {
+				return nil, f.errorAt(placementPath.with("name"), fmt.Errorf("%w: location %q", ErrDuplicateName, name))
+			}
+			placedNames[name] = true
+		}
+		placements = append(placements, placement)
+	}
+
+	generatedMap, err := mapgen.Generate(f.Grid.Width, f.Grid.Height, mapgen.Params{
+		Seed:           seed,
+		Scale:          spec.Scale,
+		Octaves:        spec.Octaves,
+		Persistence:    spec.Persistence,
+		SeaLevel:       spec.SeaLevel,
+		RockLevel:      spec.RockLevel,
+		ForestMoisture: spec.ForestMoisture,
+		MarshMoisture:  spec.MarshMoisture,
+		MarshElevation: spec.MarshElevation,
+		Rivers:         spec.Rivers,
+		RiverSource:    spec.RiverSource,
+		FordSpacing:    spec.FordSpacing,
+		Placements:     placements,
+		Anchors:        f.anchors(),
+	})
+	if err != nil {
+		return nil, f.errorAt(path, err)
+	}
+	return generatedMap, nil
+}
+
+// anchors returns the tiles a generated map must keep passable and connected, which every generated location must be
+// reachable from: where the agents start, and for each declared location, the tile it is used from nearest to it.
+// Specs that are invalid are skipped here and reported when they are built.
+func (f *File) anchors() []core.Coord {
+	var anchors []core.Coord
+	for _, spec := range f.Agents {
+		anchors = append(anchors, core.Coord{X: spec.Position.X, Y: spec.Position.Y})
+	}
+	for i, spec := range f.Locations {
+		coord := core.Coord{X: spec.Coord.X, Y: spec.Coord.Y}
+		var opts []core.LocationOption
+		if spec.Access != nil {
+			accessOpt, err := f.buildAccess(fieldPath{"locations", i, "access"}, *spec.Access)
+			if err != nil {
+				continue
+			}
+			opts = append(opts, accessOpt)
+		}
+		access := core.NewLocation(spec.Name, coord, opts...).AccessCoords()
+		distance := func(c core.Coord) int {
+			dx, dy := c.X-coord.X, c.Y-coord.Y
+			return dx*dx + dy*dy
+		}
+		nearest := slices.MinFunc(access, func(a, b core.Coord) int {
+			return distance(a) - distance(b)
+		})
+		anchors = append(anchors, nearest)
+	}
+	return anchors
+}
+
+// buildPlacement converts a PlacementSpec to a mapgen.Placement, resolving its terrain, inventory and attributes.
+func (f *File) buildPlacement(path fieldPath, spec PlacementSpec, resources map[string]*core.Resource) (mapgen.Placement, error) {
+	if spec.Name == "" {
+		return mapgen.Placement{}, f.errorAt(path.with("name"), ErrRequired)
+	}
+	terrain, err := world.ParseTerrain(spec.Terrain)
+	if err != nil {
+		return mapgen.Placement{}, f.errorAt(path.with("terrain"), err)
+	}
+	if !terrain.Properties().Passable {
+		return mapgen.Placement{}, f.errorAt(path.with("terrain"), fmt.Errorf("locations can't be placed on %s, which can't be crossed", terrain))
+	}
+	if spec.Count < 0 {
+		return mapgen.Placement{}, f.errorAt(path.with("count"), fmt.Errorf("count must not be negative, got %d", spec.Count))
+	}
+	if spec.MinSpacing < 0 {
+		return mapgen.Placement{}, f.errorAt(path.with("min_spacing"), fmt.Errorf("spacing must not be negative, got %d", spec.MinSpacing))
+	}
+
+	entries, err := f.buildInventory(path.with("inventory"), spec.Inventory, resources)
+	if err != nil {
+		return mapgen.Placement{}, err
+	}
+	attrs, err := f.buildAttributes(path.with("attributes"), spec.Attributes)
+	if err != nil {
+		return mapgen.Placement{}, err
+	}
+	template := core.NewLocation(spec.Name, core.Coord{}, core.WithAttributes(attrs...))
+	if err = f.checkResourceNames(path.with("attributes"), spec.Attributes, template.Attributes(), resources); err != nil {
+		return mapgen.Placement{}, err
+	}
+
+	return mapgen.Placement{
+		Name:       spec.Name,
+		Terrain:    terrain,
+		Count:      spec.Count,
+		MinSpacing: spec.MinSpacing,
+		Inventory:  entries,
+		Attributes: attrs,
+	}, nil
+}
+
+// buildResources creates every resource in the scenario, keyed by name.
+func (f *File) buildResources() (map[string]*core.Resource, error) {
+	resources := make(map[string]*core.Resource, len(f.Resources))
+	for i, spec := range f.Resources {
+		path := fieldPath{"resources", i}
+		if spec.Name == "" {
+			return nil, f.errorAt(path.with("name"), ErrRequired)
+		}
+		if _, exists := resources[spec.Name]; exists {
+			return nil, f.errorAt(path.with("name"), fmt.Errorf("%w: resource %q", ErrDuplicateName, spec.Name))
+		}
+
+		attrs, err := f.buildAttributes(path.with("attributes"), spec.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		resources[spec.Name] = core.NewResource(spec.Name, core.WithResourceAttributes(attrs...))
+	}
+
+	for i, spec := range f.Resources {
+		path := fieldPath{"resources", i, "attributes"}
+		if err := f.checkResourceNames(path, spec.Attributes, resources[spec.Name].Attributes(), resources); err != nil {
+			return nil, err
+		}
+	}
+	return resources, nil
+}
+
+// buildLocations creates every location in the scenario, in the order they are declared. Every location must have an
+// access tile on the grid that agents can move onto.
+func (f *File) buildLocations(resources map[string]*core.Resource, worldGrid *grid.Grid) ([]*core.Location, error) {
+	locations := make([]*core.Location, 0, len(f.Locations))
+	names := map[string]bool{}
+	for i, spec := range f.Locations {
+		path := fieldPath{"locations", i}
+		if spec.Name == "" {
+			return nil, f.errorAt(path.with("name"), ErrRequired)
+		}
+		if names[spec.Name] {
+			return nil, f.errorAt(path.with("name"), fmt.Errorf("%w: location %q", ErrDuplicateName, spec.Name))
+		}
+		names[spec.Name] = true
+
+		coord, err := f.buildCoord(path.with("coord"), spec.Coord)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, err := f.buildInventory(path.with("inventory"), spec.Inventory, resources)
+		if err != nil {
+			return nil, err
+		}
+
+		attrs, err := f.buildAttributes(path.with("attributes"), spec.Attributes)
+		if err != nil {
+			return nil, err
+		}
+
+		opts := []core.LocationOption{core.WithInventory(entries...), core.WithAttributes(attrs...)}
+		if spec.Access != nil {
+			accessOpt, err := f.buildAccess(path.with("access"), *spec.Access)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, accessOpt)
+		}
+
+		loc := core.NewLocation(spec.Name, coord, opts...)
+		if err = f.checkResourceNames(path.with("attributes"), spec.Attributes, loc.Attributes(), resources); err != nil {
+			return nil, err
+		}
+		if !slices.ContainsFunc(loc.AccessCoords(), func(c core.Coord) bool { return passable(worldGrid, c) }) {
+			accessPath := path.with("coord")
+			if spec.Access != nil {
+				accessPath = path.with("access")
+			}
+			return nil, f.errorAt(accessPath, fmt.Errorf("%w: location %q has no access tile agents can move onto", ErrImpassable, spec.Name))
+		}
+		locations = append(locations, loc)
+	}
+	return locations, nil
+}
+
+// buildAccess converts an AccessSpec to the option setting a location's access tiles.
+func (f *File) buildAccess(path fieldPath, spec AccessSpec) (core.LocationOption, error) {
+	if spec.Radius < 0 {
+		return nil, f.errorAt(path.with("radius"), fmt.Errorf("radius must not be negative, got %d", spec.Radius))
+	}
+	if len(spec.Tiles) == 0 {
+		return core.WithAccessRadius(spec.Radius), nil
+	}
+	if spec.Radius != 0 {
+		return nil, f.errorAt(path.with("tiles"), fmt.Errorf("%w: only one of radius and tiles may be given", ErrConflictingFields))
+	}
+	offsets := make([]core.Coord, len(spec.Tiles))
+	for i, tile := range spec.Tiles {
+		offsets[i] = core.Coord{X: tile.X, Y: tile.Y}
+	}
+	return core.WithAccessTiles(offsets...), nil
+}
+
+// checkResourceNames checks that every resource named by the core.ResourceListAttributes in attrs, such as the inputs
+// and outputs of a recipe, exists. Otherwise, the attribute's action would silently never be created. The error points
+// at the parameter that names the missing resource.
+func (f *File) checkResourceNames(path fieldPath, specs []AttributeSpec, attrs core.AttributeList, resources map[string]*core.Resource) error {
+	for i, spec := range specs {
+		listAttr, ok := attrs.AttributeByType(core.AttributeType(spec.Type)).(core.ResourceListAttribute)
+		if !ok {
+			continue
+		}
+		for _, name := range listAttr.ResourceNames() {
+			if _, exists := resources[name]; exists {
+				continue
+			}
+			return f.errorAt(resourceNamePath(path.with(i), spec, name), fmt.Errorf("%w: resource %q", ErrUnknownReference, name))
+		}
+	}
+	return nil
+}
+
+// resourceNamePath returns the path to the parameter naming the resource, either as its value or as one of its keys, or
+// the path to the attribute if no parameter names it.
+func resourceNamePath(path fieldPath, spec AttributeSpec, name string) fieldPath {
+	params := make([]string, 0, len(spec.Params))
+	for param := range spec.Params {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	for _, param := range params {
+		switch value := spec.Params[param].(type) {
+		case string:
+			if value == name {
+				return path.with(param)
+			}
+		case map[string]any:
+			if _, named := value[name]; named {
+				return path.with(param, name)
+			}
+		}
+	}
+	return path
+}
+
+// buildAgent creates an agent and its goal engine. The agent must start on a coordinate of the grid it can move onto.
+func (f *File) buildAgent(path fieldPath, spec AgentSpec, resources map[string]*core.Resource, locations []*core.Location, worldGrid *grid.Grid, logger *slog.Logger) (*agent.Agent, error) {
+	if spec.Name == "" {
+		return nil, f.errorAt(path.with("name"), ErrRequired)
+	}
+
+	position, err := f.buildCoord(path.with("position"), spec.Position)
+	if err != nil {
+		return nil, err
+	}
+	if !passable(worldGrid, position) {
+		return nil, f.errorAt(path.with("position"), fmt.Errorf("%w: agent %q starts at %v", ErrImpassable, spec.Name, position))
+	}
+
+	entries, err := f.buildInventory(path.with("inventory"), spec.Inventory, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.CarryCapacity < 0 {
+		return nil, f.errorAt(path.with("carry_capacity"), fmt.Errorf("carry capacity must not be negative, got %v", spec.CarryCapacity))
+	}
+
+	if spec.Speed < 0 {
+		return nil, f.errorAt(path.with("speed"), fmt.Errorf("speed must not be negative, got %v", spec.Speed))
+	}
+
+	newAgent := agent.NewAgent(spec.Name, logger)
+	newAgent.Position = position
+	newAgent.MaxCarry = spec.CarryCapacity
+	newAgent.Speed = spec.Speed
+	for _, entry := range entries {
+		newAgent.Inventory().AdjustAmount(entry.Resource, entry.Amount)
+	}
+
+	if spec.Goal != nil && len(spec.Goals) > 0 {
+		return nil, f.errorAt(path.with("goals"), fmt.Errorf("%w: only one of goal and goals may be given", ErrConflictingFields))
+	}
+	if spec.Goal != nil {
+		goal, err := f.buildGoal(path.with("goal"), *spec.Goal, resources, locations)
+		if err != nil {
+			return nil, err
+		}
+		newAgent.Behavior.GoalEngine = goalengine.NewGoalEngine(goal)
+	}
+	if len(spec.Goals) > 0 {
+		goals := make([]goalengine.Goal, 0, len(spec.Goals))
+		for i, goalSpec := range spec.Goals {
+			goal, err := f.buildGoal(path.with("goals", i), goalSpec, resources, locations)
+			if err != nil {
+				return nil, err
+			}
+			goals = append(goals, goal)
+		}
+		newAgent.Behavior.GoalEngine = goalengine.NewGoalEngine(goals...)
+	}
+	if newAgent.Behavior.GoalEngine != nil {
+		newAgent.Behavior.GoalEngine.MaxFailures = spec.MaxGoalFailures
+	}
+
+	if spec.Planning != nil {
+		newAgent.Behavior.Planning = agent.PlanningBudget{
+			IterationsPerTick: spec.Planning.IterationsPerTick,
+			TimePerTick:       spec.Planning.TimePerTick,
+			MaxIterations:     spec.Planning.MaxIterations,
+			AcceptAfter:       spec.Planning.AcceptAfter,
+		}
+	}
+
+	needNames := map[string]bool{}
+	for i, needSpec := range spec.Needs {
+		needPath := path.with("needs", i)
+		if needNames[needSpec.Name] {
+			return nil, f.errorAt(needPath.with("name"), fmt.Errorf("%w: need %q", ErrDuplicateName, needSpec.Name))
+		}
+		needNames[needSpec.Name] = true
+
+		need, err := f.buildNeed(needPath, needSpec, resources)
+		if err != nil {
+			return nil, err
+		}
+		newAgent.Behavior.Needs = append(newAgent.Behavior.Needs, need)
+	}
+
+	return newAgent, nil
+}
+
+// buildNeed creates a need, resolving its resource by name.
+func (f *File) buildNeed(path fieldPath, spec NeedSpec, resources map[string]*core.Resource) (*agent.Need, error) {
+	if spec.Name == "" {
+		return nil, f.errorAt(path.with("name"), ErrRequired)
+	}
+	if spec.Resource == "" && spec.RestPerSecond <= 0 {
+		return nil, f.errorAt(path.with("resource"), fmt.Errorf("%w: a need is satisfied by a resource or by resting", ErrRequired))
+	}
+
+	need := &agent.Need{
+		Name:           spec.Name,
+		Level:          floatOrDefault(spec.Level, 1),
+		DecayPerSecond: spec.DecayPerSecond,
+		Threshold:      spec.Threshold,
+		Weight:         floatOrDefault(spec.Weight, 1),
+		RestorePerUnit: spec.RestorePerUnit,
+		RestPerSecond:  spec.RestPerSecond,
+	}
+	if need.Level < 0 || need.Level > 1 {
+		return nil, f.errorAt(path.with("level"), fmt.Errorf("level must be between 0 and 1, got %g", need.Level))
+	}
+	if spec.Resource != "" {
+		resource, ok := resources[spec.Resource]
+		if !ok {
+			return nil, f.errorAt(path.with("resource"), fmt.Errorf("%w: resource %q", ErrUnknownReference, spec.Resource))
+		}
+		need.Resource = resource
+	}
+	return need, nil
+}
+
+// buildGoal creates a goal, resolving its location, resource and logic functions by name.
+func (f *File) buildGoal(path fieldPath, spec GoalSpec, resources map[string]*core.Resource, locations []*core.Location) (goalengine.Goal, error) {
+	var goalLocation *core.Location
+	for _, loc := range locations {
+		if loc.Name == spec.Location {
+			goalLocation = loc.DeepCopy()
+			break
+		}
+	}
+	if goalLocation == nil {
+		return goalengine.Goal{}, f.errorAt(path.with("location"), fmt.Errorf("%w: location %q", ErrUnknownReference, spec.Location))
+	}
+
+	resource, ok := resources[spec.Resource]
+	if !ok {
+		return goalengine.Goal{}, f.errorAt(path.with("resource"), fmt.Errorf("%w: resource %q", ErrUnknownReference, spec.Resource))
+	}
+
+	chunkerName := valueOrDefault(spec.Chunker, defaultChunker)
+	chunker, ok := goalengine.Chunkers[chunkerName]
+	if !ok {
+		return goalengine.Goal{}, f.errorAt(path.with("chunker"), fmt.Errorf("%w: chunker %q", ErrUnknownReference, chunkerName))
+	}
+
+	fallbackName := valueOrDefault(spec.Fallback, defaultFallback)
+	fallback, ok := goalengine.Fallbacks[fallbackName]
+	if !ok {
+		return goalengine.Goal{}, f.errorAt(path.with("fallback"), fmt.Errorf("%w: fallback %q", ErrUnknownReference, fallbackName))
+	}
+
+	giveUpName := valueOrDefault(spec.GiveUp, defaultGiveUp)
+	giveUp, ok := goalengine.GiveUps[giveUpName]
+	if !ok {
+		return goalengine.Goal{}, f.errorAt(path.with("give_up"), fmt.Errorf("%w: give up function %q", ErrUnknownReference, giveUpName))
+	}
+
+	var priorityFunc goalengine.PriorityFunc
+	if spec.PriorityFunc != "" {
+		priorityFunc, ok = goalengine.Priorities[spec.PriorityFunc]
+		if !ok {
+			return goalengine.Goal{}, f.errorAt(path.with("priority_func"), fmt.Errorf("%w: priority function %q", ErrUnknownReference, spec.PriorityFunc))
+		}
+	}
+
+	return goalengine.Goal{
+		Name: spec.Name,
+		Logic: goalengine.GoalLogic{
+			Chunker:      chunker,
+			Fallback:     fallback,
+			ShouldGiveUp: giveUp,
+		},
+		Location:     goalLocation,
+		Resource:     resource,
+		Target:       spec.Target,
+		Priority:     spec.Priority,
+		PriorityFunc: priorityFunc,
+	}, nil
+}
+
+// buildCoord converts a CoordSpec to a core.Coord, checking that it lies on the grid.
+func (f *File) buildCoord(path fieldPath, spec CoordSpec) (core.Coord, error) {
+	if spec.X < 0 || spec.X >= f.Grid.Width {
+		return core.Coord{}, f.errorAt(path.with("x"), fmt.Errorf("%w: x %d not in [0, %d)", ErrOutOfBounds, spec.X, f.Grid.Width))
+	}
+	if spec.Y < 0 || spec.Y >= f.Grid.Height {
+		return core.Coord{}, f.errorAt(path.with("y"), fmt.Errorf("%w: y %d not in [0, %d)", ErrOutOfBounds, spec.Y, f.Grid.Height))
+	}
+	return core.Coord{X: spec.X, Y: spec.Y}, nil
+}
+
+// passable reports whether agents can move onto the coordinate of the grid.
+func passable(worldGrid *grid.Grid, coord core.Coord) bool {
+	tile, ok := worldGrid.CellAt(coord).(*world.Tile)
+	return ok && tile.Passable()
+}
+
+// buildInventory converts an inventory map to inventory entries, sorted by resource name.
+func (f *File) buildInventory(path fieldPath, spec map[string]int, resources map[string]*core.Resource) ([]core.InventoryEntry, error) {
+	names := make([]string, 0, len(spec))
+	for name := range spec {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]core.InventoryEntry, 0, len(names))
+	for _, name := range names {
+		res, ok := resources[name]
+		if !ok {
+			return nil, f.errorAt(path.with(name), fmt.Errorf("%w: resource %q", ErrUnknownReference, name))
+		}
+		if spec[name] < 0 {
+			return nil, f.errorAt(path.with(name), fmt.Errorf("amount must not be negative, got %d", spec[name]))
+		}
+		entries = append(entries, core.InventoryEntry{Resource: res, Amount: spec[name]})
+	}
+	return entries, nil
+}
+
+// buildAttributes creates the attributes described by the specs.
+func (f *File) buildAttributes(path fieldPath, specs []AttributeSpec) ([]core.Attribute, error) {
+	attrs := make([]core.Attribute, 0, len(specs))
+	for i, spec := range specs {
+		attr, err := f.buildAttribute(path.with(i), spec)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs, nil
+}
+
+// buildAttribute creates a single attribute from its type and parameters, using the attribute registry.
+func (f *File) buildAttribute(path fieldPath, spec AttributeSpec) (core.Attribute, error) {
+	if spec.Type == "" {
+		return nil, f.errorAt(path.with("type"), ErrRequired)
+	}
+
+	registry := f.Registry
+	if registry == nil {
+		registry = attributes.NewRegistry()
+	}
+
+	attr, err := registry.New(core.AttributeType(spec.Type), spec.Params)
+	if err != nil {
+		var paramErr *core.ParamError
+		switch {
+		case errors.As(err, &paramErr):
+			return nil, f.errorAt(path.with(paramErr.Param), paramErr.Err)
+		case errors.Is(err, core.ErrUnknownAttributeType):
+			return nil, f.errorAt(path.with("type"), fmt.Errorf("%w: %w", ErrUnknownReference, err))
+		default:
+			return nil, f.errorAt(path, err)
+		}
+	}
+	return attr, nil
+}
+
+// floatOrDefault returns the value pointed to by value, or def if value is nil.
+func floatOrDefault(value *float64, def float64) float64 {
+	if value == nil {
+		return def
+	}
+	return *value
+}
+
+// valueOrDefault returns value, or def if value is empty.
+func valueOrDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
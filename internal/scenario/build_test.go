@@ -0,0 +1,899 @@
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"Neolithic/internal/agent"
+	"Neolithic/internal/attributes"
+	"Neolithic/internal/core"
+	"Neolithic/internal/grid"
+	"Neolithic/internal/logging"
+	"Neolithic/internal/mapgen"
+	"Neolithic/internal/world"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validScenario = `
+seed: 7
+grid:
+  width: 10
+  height: 10
+resources:
+  - name: Berries
+    attributes:
+      - type: weight
+        amount: 2
+locations:
+  - name: bush
+    coord: {x: 1, y: 2}
+    inventory:
+      Berries: 50
+  - name: depo
+    coord: {x: 5, y: 5}
+    attributes:
+      - type: capacity
+        size: 100
+agents:
+  - name: villager
+    position: {x: 3, y: 4}
+    inventory:
+      Berries: 1
+    goal:
+      name: stock berries
+      location: depo
+      resource: Berries
+    speed: 3
+    planning:
+      iterations_per_tick: 5000
+      time_per_tick: 2ms
+`
+
+func TestFile_Build(t *testing.T) {
+	file, err := Parse("valid.yaml", []byte(validScenario))
+	require.NoError(t, err)
+
+	engine, err := file.Build(logging.NewLogger("error"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), engine.Seed())
+
+	require.Len(t, engine.Registry.Resources, 1)
+	berries := engine.Registry.Resources[0]
+	weight, ok := berries.Attributes().AttributeByType(attributes.WeightAttributeType).(*attributes.Weight)
+	require.True(t, ok)
+	assert.Equal(t, 2.0, weight.Amount)
+
+	bush, ok := engine.World.GetLocation("bush")
+	require.True(t, ok)
+	assert.Equal(t, 50, bush.Inventory.GetAmount(berries))
+	assert.Equal(t, 1, bush.Coord.X)
+	assert.Equal(t, 2, bush.Coord.Y)
+
+	depo, ok := engine.World.GetLocation("depo")
+	require.True(t, ok)
+	assert.NotNil(t, depo.Attributes().AttributeByType(attributes.CapacityAttributeType))
+
+	a, ok := engine.World.GetAgent("villager")
+	require.True(t, ok)
+	villager := a.(*agent.Agent)
+	assert.Equal(t, 3, villager.Position.X)
+	assert.Equal(t, 4, villager.Position.Y)
+	assert.Equal(t, 1, villager.Inventory().GetAmount(berries))
+	assert.Equal(t, 3.0, villager.Speed)
+	require.NotNil(t, villager.Behavior.GoalEngine)
+	require.Len(t, villager.Behavior.GoalEngine.Goals, 1)
+	goal := villager.Behavior.GoalEngine.Goals[0]
+	assert.Equal(t, "stock berries", goal.Name)
+	assert.Equal(t, "depo", goal.Location.Name)
+	assert.Equal(t, berries, goal.Resource)
+	assert.NotNil(t, goal.Logic.Chunker)
+	assert.NotNil(t, goal.Logic.Fallback)
+	assert.NotNil(t, goal.Logic.ShouldGiveUp)
+	assert.Equal(t, agent.PlanningBudget{IterationsPerTick: 5000, TimePerTick: 2 * time.Millisecond}, villager.Behavior.Planning)
+}
+
+func TestFile_BuildGoals(t *testing.T) {
+	file, err := Parse("goals.yaml", []byte(`
+grid: {width: 10, height: 10}
+resources:
+  - name: Berries
+  - name: Wood
+locations:
+  - name: depo
+agents:
+  - name: villager
+    max_goal_failures: 5
+    goals:
+      - name: stock berries
+        location: depo
+        resource: Berries
+        target: 100
+        priority: 2
+        priority_func: shortfall
+      - name: stock wood
+        location: depo
+        resource: Wood
+        priority: 1
+`))
+	require.NoError(t, err)
+
+	engine, err := file.Build(logging.NewLogger("error"))
+	require.NoError(t, err)
+
+	a, ok := engine.World.GetAgent("villager")
+	require.True(t, ok)
+	goalEngine := a.(*agent.Agent).Behavior.GoalEngine
+	require.NotNil(t, goalEngine)
+	assert.Equal(t, 5, goalEngine.MaxFailures)
+	require.Len(t, goalEngine.Goals, 2)
+
+	stockBerries, stockWood := goalEngine.Goals[0], goalEngine.Goals[1]
+	assert.Equal(t, "stock berries", stockBerries.Name)
+	assert.Equal(t, 100, stockBerries.Target)
+	assert.Equal(t, 2.0, stockBerries.Priority)
+	assert.NotNil(t, stockBerries.PriorityFunc)
+	assert.Equal(t, "stock wood", stockWood.Name)
+	assert.Equal(t, 1.0, stockWood.Priority)
+	assert.Nil(t, stockWood.PriorityFunc)
+}
+
+func TestFile_BuildNeeds(t *testing.T) {
+	file, err := Parse("needs.yaml", []byte(`
+grid: {width: 10, height: 10}
+resources:
+  - name: Berries
+    attributes:
+      - type: edible
+        nutrition: 0.25
+locations:
+  - name: bush
+agents:
+  - name: villager
+    needs:
+      - name: hunger
+        level: 0.5
+        decay_per_second: 0.01
+        threshold: 0.4
+        weight: 3
+        resource: Berries
+      - name: fatigue
+        decay_per_second: 0.005
+        threshold: 0.2
+        rest_per_second: 0.1
+`))
+	require.NoError(t, err)
+
+	engine, err := file.Build(logging.NewLogger("error"))
+	require.NoError(t, err)
+
+	a, ok := engine.World.GetAgent("villager")
+	require.True(t, ok)
+	needs := a.(*agent.Agent).Behavior.Needs
+	require.Len(t, needs, 2)
+
+	berries := engine.Registry.Resources[0]
+	assert.Equal(t, &agent.Need{
+		Name:           "hunger",
+		Level:          0.5,
+		DecayPerSecond: 0.01,
+		Threshold:      0.4,
+		Weight:         3,
+		Resource:       berries,
+	}, needs[0])
+	assert.Equal(t, 0.25, berries.UnitNutrition(), "the need should be restored by the nutrition of the berries")
+	assert.Equal(t, &agent.Need{
+		Name:           "fatigue",
+		Level:          1,
+		DecayPerSecond: 0.005,
+		Threshold:      0.2,
+		Weight:         1,
+		RestPerSecond:  0.1,
+	}, needs[1])
+}
+
+func TestFile_BuildRecipe(t *testing.T) {
+	engine, err := Load("../../scenarios/crafting.yaml", logging.NewLogger("error"))
+	require.NoError(t, err)
+
+	var crafts []*attributes.Craft
+	for _, action := range engine.Registry.Actions {
+		if craft, ok := action.(*attributes.Craft); ok {
+			crafts = append(crafts, craft)
+		}
+	}
+	require.Len(t, crafts, 1)
+	craft := crafts[0]
+	assert.Equal(t, "stone axe", craft.Recipe)
+	assert.Equal(t, "workshop", craft.ActionLocation.Name)
+	assert.Equal(t, 2.0, craft.TimeNeeded())
+	require.Len(t, craft.Inputs, 2)
+	assert.Equal(t, "Stone", craft.Inputs[0].Resource.Name)
+	assert.Equal(t, 1, craft.Inputs[0].Amount)
+	assert.Equal(t, "Wood", craft.Inputs[1].Resource.Name)
+	assert.Equal(t, 2, craft.Inputs[1].Amount)
+	require.Len(t, craft.Outputs, 1)
+	assert.Equal(t, "Stone Axe", craft.Outputs[0].Resource.Name)
+	assert.Contains(t, engine.Registry.Resources, craft.Outputs[0].Resource)
+}
+
+func TestFile_BuildTools(t *testing.T) {
+	engine, err := Load("../../scenarios/tools.yaml", logging.NewLogger("error"))
+	require.NoError(t, err)
+
+	var woodGathers []*attributes.Gather
+	for _, action := range engine.Registry.Actions {
+		if gather, ok := action.(*attributes.Gather); ok && gather.Res.Name == "Wood" {
+			woodGathers = append(woodGathers, gather)
+		}
+	}
+	require.Len(t, woodGathers, len(engine.Registry.Locations), "wood should be gathered only with the axe, at every location")
+	for _, gather := range woodGathers {
+		require.NotNil(t, gather.Requires)
+		assert.Equal(t, "Stone Axe", gather.Requires.Name)
+		assert.Equal(t, 2, gather.Amount)
+	}
+}
+
+func TestFile_BuildCarryCapacity(t *testing.T) {
+	engine, err := Load("../../scenarios/hauling.yaml", logging.NewLogger("error"))
+	require.NoError(t, err)
+
+	a, ok := engine.World.GetAgent("villager")
+	require.True(t, ok)
+	villager := a.(*agent.Agent)
+	assert.Equal(t, 6.0, villager.CarryCapacity())
+
+	stone, ok := engine.Registry.Resource("Stone")
+	require.True(t, ok)
+	assert.Equal(t, 3, core.CanCarry(villager, stone))
+}
+
+func TestFile_BuildAccess(t *testing.T) {
+	file, err := Parse("test.yaml", []byte(`
+grid: {width: 10, height: 10}
+locations:
+  - name: hut
+    coord: {x: 4, y: 4}
+    access:
+      tiles: [{x: 0, y: 1}, {x: -1, y: 1}]
+  - name: field
+    coord: {x: 7, y: 7}
+    access: {radius: 2}
+  - name: bush
+    coord: {x: 1, y: 1}
+`))
+	require.NoError(t, err)
+	engine, err := file.Build(logging.NewLogger("error"))
+	require.NoError(t, err)
+
+	locations := map[string]*core.Location{}
+	for _, loc := range engine.Registry.Locations {
+		locations[loc.Name] = loc
+	}
+	require.Len(t, locations, 3)
+	assert.Equal(t, []core.Coord{{X: 4, Y: 5}, {X: 3, Y: 5}}, locations["hut"].AccessCoords())
+	assert.Equal(t, 2, locations["field"].AccessRadius)
+	assert.Len(t, locations["field"].AccessCoords(), 25)
+	assert.Len(t, locations["bush"].AccessCoords(), 9)
+}
+
+func TestFile_BuildTerrain(t *testing.T) {
+	engine, err := Load("../../scenarios/lake.yaml", logging.NewLogger("error"))
+	require.NoError(t, err)
+	worldGrid := engine.World.Grid.(*grid.Grid)
+
+	tests := map[core.Coord]world.Terrain{
+		{X: 0, Y: 0}:   world.Grass,
+		{X: 7, Y: 6}:   world.Marsh,
+		{X: 10, Y: 10}: world.Water,
+		{X: 12, Y: 3}:  world.Forest,
+		{X: 23, Y: 23}: world.Rock,
+	}
+	for coord, expected := range tests {
+		terrain, ok := world.TerrainAt(worldGrid, coord)
+		require.True(t, ok)
+		assert.Equal(t, expected, terrain, "terrain at %v", coord)
+	}
+}
+
+func TestFile_BuildGenerated(t *testing.T) {
+	file, err := ReadFile("../../scenarios/generated.yaml")
+	require.NoError(t, err)
+	engine, err := file.Build(logging.NewLogger("error"))
+	require.NoError(t, err)
+	worldGrid := engine.World.Grid.(*grid.Grid)
+
+	params := mapgen.Params{Seed: *file.Seed, Rivers: file.Grid.Generate.Rivers}
+	expected, err := mapgen.Generate(file.Grid.Width, file.Grid.Height, params)
+	require.NoError(t, err)
+	for x := 0; x < file.Grid.Width; x++ {
+		for y := 0; y < file.Grid.Height; y++ {
+			terrain, ok := world.TerrainAt(worldGrid, core.Coord{X: x, Y: y})
+			require.True(t, ok)
+			require.Equal(t, expected.TerrainAt(x, y), terrain, "terrain at (%d, %d)", x, y)
+		}
+	}
+
+	berries, ok := engine.Registry.Resource("Berries")
+	require.True(t, ok)
+	for _, name := range []string{"bush1", "bush4", "quarry1", "depo"} {
+		loc, ok := engine.World.GetLocation(name)
+		require.True(t, ok, "missing location %s", name)
+		terrain, _ := world.TerrainAt(worldGrid, loc.Coord)
+		assert.True(t, terrain.Properties().Passable, "%s should be on land", name)
+	}
+	bush, _ := engine.World.GetLocation("bush1")
+	assert.Equal(t, 100, bush.Inventory.GetAmount(berries))
+	villager, ok := engine.World.GetAgent("villager")
+	require.True(t, ok)
+	terrain, _ := world.TerrainAt(worldGrid, villager.(*agent.Agent).Position)
+	assert.True(t, terrain.Properties().Passable, "the villager should start on land")
+
+	t.Run("generate seed overrides scenario seed", func(t *testing.T) {
+		seed := *file.Seed + 1
+		file.Grid.Generate.Seed = &seed
+		reseeded, err := file.Build(logging.NewLogger("error"))
+		require.NoError(t, err)
+		assert.Equal(t, *file.Seed, reseeded.Seed())
+
+		different := false
+		for x := 0; x < file.Grid.Width && !different; x++ {
+			for y := 0; y < file.Grid.Height; y++ {
+				terrain, _ := world.TerrainAt(reseeded.World.Grid.(*grid.Grid), core.Coord{X: x, Y: y})
+				if terrain != expected.TerrainAt(x, y) {
+					different = true
+					break
+				}
+			}
+		}
+		assert.True(t, different, "a different seed should generate different terrain")
+	})
+
+	t.Run("seed option overrides every seed of the scenario", func(t *testing.T) {
+		seed := *file.Seed + 2
+		overridden, err := file.Build(logging.NewLogger("error"), WithSeed(seed))
+		require.NoError(t, err)
+		assert.Equal(t, seed, overridden.Seed())
+
+		reseeded, err := mapgen.Generate(file.Grid.Width, file.Grid.Height, mapgen.Params{Seed: seed, Rivers: file.Grid.Generate.Rivers})
+		require.NoError(t, err)
+		overriddenGrid := overridden.World.Grid.(*grid.Grid)
+		for x := 0; x < file.Grid.Width; x++ {
+			for y := 0; y < file.Grid.Height; y++ {
+				terrain, _ := world.TerrainAt(overriddenGrid, core.Coord{X: x, Y: y})
+				require.Equal(t, reseeded.TerrainAt(x, y), terrain, "terrain at (%d, %d)", x, y)
+			}
+		}
+	})
+}
+
+func TestLoad_GeneratedSeeds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the generated scenario across many seeds")
+	}
+	for seed := int64(1); seed <= 40; seed++ {
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			t.Parallel()
+			engine, err := Load("../../scenarios/generated.yaml", logging.NewLogger("error"), WithSeed(seed))
+			require.NoError(t, err)
+
+			for tick := 0; tick < 3000; tick++ {
+				require.NoError(t, engine.Tick(1.0/60), "tick %d", tick)
+			}
+		})
+	}
+}
+
+func TestFile_BuildErrors(t *testing.T) {
+	type testCase struct {
+		scenario      string
+		expectedField string
+		expectedLine  int
+		expectedErr   error
+	}
+
+	tests := map[string]testCase{
+		"invalid grid size": {
+			scenario: `
+grid:
+  width: 0
+  height: 10
+`,
+			expectedField: "grid",
+			expectedLine:  3,
+		},
+		"location out of bounds": {
+			scenario: `
+grid: {width: 10, height: 10}
+locations:
+  - name: bush
+    coord:
+      x: 1
+      y: 12
+`,
+			expectedField: "locations[0].coord.y",
+			expectedLine:  7,
+			expectedErr:   ErrOutOfBounds,
+		},
+		"unknown resource in inventory": {
+			scenario: `
+grid: {width: 10, height: 10}
+locations:
+  - name: bush
+    inventory:
+      Acorns: 5
+`,
+			expectedField: "locations[0].inventory.Acorns",
+			expectedLine:  6,
+			expectedErr:   ErrUnknownReference,
+		},
+		"duplicate location": {
+			scenario: `
+grid: {width: 10, height: 10}
+locations:
+  - name: bush
+  - name: bush
+`,
+			expectedField: "locations[1].name",
+			expectedLine:  5,
+			expectedErr:   ErrDuplicateName,
+		},
+		"unknown attribute type": {
+			scenario: `
+grid: {width: 10, height: 10}
+resources:
+  - name: Berries
+    attributes:
+      - type: sparkle
+`,
+			expectedField: "resources[0].attributes[0].type",
+			expectedLine:  6,
+			expectedErr:   ErrUnknownReference,
+		},
+		"missing attribute parameter": {
+			scenario: `
+grid: {width: 10, height: 10}
+resources:
+  - name: Berries
+    attributes:
+      - type: weight
+`,
+			expectedField: "resources[0].attributes[0].amount",
+			expectedLine:  6,
+			expectedErr:   core.ErrMissingParam,
+		},
+		"unknown attribute parameter": {
+			scenario: `
+grid: {width: 10, height: 10}
+resources:
+  - name: Berries
+    attributes:
+      - type: weight
+        amount: 1
+        colour: red
+`,
+			expectedField: "resources[0].attributes[0].colour",
+			expectedLine:  8,
+			expectedErr:   core.ErrUnknownParam,
+		},
+		"wrong attribute parameter type": {
+			scenario: `
+grid: {width: 10, height: 10}
+locations:
+  - name: depo
+    attributes:
+      - type: capacity
+        size: big
+`,
+			expectedField: "locations[0].attributes[0].size",
+			expectedLine:  7,
+			expectedErr:   core.ErrInvalidParam,
+		},
+		"unknown goal location": {
+			scenario: `
+grid: {width: 10, height: 10}
+resources:
+  - name: Berries
+agents:
+  - name: villager
+    goal:
+      location: nowhere
+      resource: Berries
+`,
+			expectedField: "agents[0].goal.location",
+			expectedLine:  8,
+			expectedErr:   ErrUnknownReference,
+		},
+		"unknown chunker": {
+			scenario: `
+grid: {width: 10, height: 10}
+resources:
+  - name: Berries
+locations:
+  - name: depo
+agents:
+  - name: villager
+    goal:
+      location: depo
+      resource: Berries
+      chunker: everything
+`,
+			expectedField: "agents[0].goal.chunker",
+			expectedLine:  12,
+			expectedErr:   ErrUnknownReference,
+		},
+		"unknown priority function": {
+			scenario: `
+grid: {width: 10, height: 10}
+resources:
+  - name: Berries
+locations:
+  - name: depo
+agents:
+  - name: villager
+    goals:
+      - location: depo
+        resource: Berries
+        priority_func: urgent
+`,
+			expectedField: "agents[0].goals[0].priority_func",
+			expectedLine:  12,
+			expectedErr:   ErrUnknownReference,
+		},
+		"both goal and goals": {
+			scenario: `
+grid: {width: 10, height: 10}
+resources:
+  - name: Berries
+locations:
+  - name: depo
+agents:
+  - name: villager
+    goal: {location: depo, resource: Berries}
+    goals:
+      - {location: depo, resource: Berries}
+`,
+			expectedField: "agents[0].goals",
+			expectedLine:  11,
+			expectedErr:   ErrConflictingFields,
+		},
+		"recipe with unknown input": {
+			scenario: `
+grid: {width: 10, height: 10}
+resources:
+  - name: Wood
+locations:
+  - name: workshop
+    attributes:
+      - type: recipe
+        name: axe
+        inputs:
+          Wood: 2
+          Flint: 1
+        outputs:
+          Wood: 1
+`,
+			expectedField: "locations[0].attributes[0].inputs.Flint",
+			expectedLine:  12,
+			expectedErr:   ErrUnknownReference,
+		},
+		"tool that isn't a resource": {
+			scenario: `
+grid: {width: 10, height: 10}
+resources:
+  - name: Wood
+    attributes:
+      - type: weight
+        amount: 1
+      - type: tool
+        resource: Stone Axe
+`,
+			expectedField: "resources[0].attributes[1].resource",
+			expectedLine:  9,
+			expectedErr:   ErrUnknownReference,
+		},
+		"regrowth of unknown resource": {
+			scenario: `
+grid: {width: 10, height: 10}
+resources:
+  - name: Wood
+locations:
+  - name: bush
+    attributes:
+      - type: regrowth
+        resource: Berries
+        rate: 1
+        cap: 10
+`,
+			expectedField: "locations[0].attributes[0].resource",
+			expectedLine:  9,
+			expectedErr:   ErrUnknownReference,
+		},
+		"recipe without outputs": {
+			scenario: `
+grid: {width: 10, height: 10}
+resources:
+  - name: Wood
+locations:
+  - name: workshop
+    attributes:
+      - type: recipe
+        name: axe
+        inputs:
+          Wood: 2
+        outputs: {}
+`,
+			expectedField: "locations[0].attributes[0].outputs",
+			expectedLine:  12,
+			expectedErr:   core.ErrInvalidParam,
+		},
+		"need without a way to satisfy it": {
+			scenario: `
+grid: {width: 10, height: 10}
+agents:
+  - name: villager
+    needs:
+      - name: hunger
+        threshold: 0.5
+`,
+			expectedField: "agents[0].needs[0].resource",
+			expectedLine:  6,
+			expectedErr:   ErrRequired,
+		},
+		"need with unknown resource": {
+			scenario: `
+grid: {width: 10, height: 10}
+agents:
+  - name: villager
+    needs:
+      - name: hunger
+        resource: Berries
+`,
+			expectedField: "agents[0].needs[0].resource",
+			expectedLine:  7,
+			expectedErr:   ErrUnknownReference,
+		},
+		"negative carry capacity": {
+			scenario: `
+grid: {width: 10, height: 10}
+agents:
+  - name: villager
+    carry_capacity: -1
+`,
+			expectedField: "agents[0].carry_capacity",
+			expectedLine:  5,
+		},
+		"negative speed": {
+			scenario: `
+grid: {width: 10, height: 10}
+agents:
+  - name: villager
+    speed: -2
+`,
+			expectedField: "agents[0].speed",
+			expectedLine:  5,
+		},
+		"unknown terrain": {
+			scenario: `
+grid:
+  width: 10
+  height: 10
+  terrain:
+    areas:
+      - terrain: lava
+        from: {x: 0, y: 0}
+        to: {x: 2, y: 2}
+`,
+			expectedField: "grid.terrain.areas[0].terrain",
+			expectedLine:  7,
+			expectedErr:   world.ErrUnknownTerrain,
+		},
+		"terrain area out of bounds": {
+			scenario: `
+grid:
+  width: 10
+  height: 10
+  terrain:
+    default: forest
+    areas:
+      - terrain: water
+        from: {x: 0, y: 0}
+        to: {x: 2, y: 10}
+`,
+			expectedField: "grid.terrain.areas[0].to.y",
+			expectedLine:  10,
+			expectedErr:   ErrOutOfBounds,
+		},
+		"terrain and generate": {
+			scenario: `
+grid:
+  width: 10
+  height: 10
+  terrain:
+    default: forest
+  generate:
+    rivers: 1
+`,
+			expectedField: "grid.generate",
+			expectedLine:  8,
+			expectedErr:   ErrConflictingFields,
+		},
+		"placement on water": {
+			scenario: `
+grid:
+  width: 10
+  height: 10
+  generate:
+    placements:
+      - name: bush
+        terrain: water
+        count: 1
+`,
+			expectedField: "grid.generate.placements[0].terrain",
+			expectedLine:  8,
+		},
+		"placement name clashes with location": {
+			scenario: `
+grid:
+  width: 10
+  height: 10
+  generate:
+    placements:
+      - name: bush
+        terrain: grass
+        count: 3
+locations:
+  - name: bush2
+    coord: {x: 1, y: 1}
+`,
+			expectedField: "grid.generate.placements[0].name",
+			expectedLine:  7,
+			expectedErr:   ErrDuplicateName,
+		},
+		"unknown resource in placement": {
+			scenario: `
+grid:
+  width: 10
+  height: 10
+  generate:
+    placements:
+      - name: bush
+        terrain: grass
+        count: 1
+        inventory:
+          Acorns: 5
+`,
+			expectedField: "grid.generate.placements[0].inventory.Acorns",
+			expectedLine:  11,
+			expectedErr:   ErrUnknownReference,
+		},
+		"negative access radius": {
+			scenario: `
+grid: {width: 10, height: 10}
+locations:
+  - name: hut
+    access: {radius: -1}
+`,
+			expectedField: "locations[0].access.radius",
+			expectedLine:  5,
+		},
+		"both access radius and tiles": {
+			scenario: `
+grid: {width: 10, height: 10}
+locations:
+  - name: hut
+    access:
+      radius: 2
+      tiles: [{x: 0, y: 1}]
+`,
+			expectedField: "locations[0].access.tiles",
+			expectedLine:  7,
+			expectedErr:   ErrConflictingFields,
+		},
+		"agent on water": {
+			scenario: `
+grid:
+  width: 10
+  height: 10
+  terrain:
+    areas:
+      - {terrain: water, from: {x: 0, y: 0}, to: {x: 2, y: 2}}
+agents:
+  - name: villager
+    position: {x: 1, y: 1}
+`,
+			expectedField: "agents[0].position",
+			expectedLine:  10,
+			expectedErr:   ErrImpassable,
+		},
+		"location surrounded by water": {
+			scenario: `
+grid:
+  width: 10
+  height: 10
+  terrain:
+    areas:
+      - {terrain: water, from: {x: 0, y: 0}, to: {x: 4, y: 4}}
+locations:
+  - name: fishing spot
+    coord: {x: 2, y: 2}
+`,
+			expectedField: "locations[0].coord",
+			expectedLine:  10,
+			expectedErr:   ErrImpassable,
+		},
+		"access tiles on water or off the grid": {
+			scenario: `
+grid:
+  width: 10
+  height: 10
+  terrain:
+    areas:
+      - {terrain: water, from: {x: 0, y: 0}, to: {x: 2, y: 9}}
+locations:
+  - name: jetty
+    coord: {x: 4, y: 4}
+    access:
+      tiles: [{x: -3, y: 0}, {x: 0, y: 6}]
+`,
+			expectedField: "locations[0].access",
+			expectedLine:  12,
+			expectedErr:   ErrImpassable,
+		},
+		"duplicate need": {
+			scenario: `
+grid: {width: 10, height: 10}
+agents:
+  - name: villager
+    needs:
+      - {name: fatigue, rest_per_second: 0.1}
+      - {name: fatigue, rest_per_second: 0.2}
+`,
+			expectedField: "agents[0].needs[1].name",
+			expectedLine:  7,
+			expectedErr:   ErrDuplicateName,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, err := Parse("test.yaml", []byte(tc.scenario))
+			require.NoError(t, err)
+
+			_, err = file.Build(logging.NewLogger("error"))
+			require.Error(t, err)
+
+			var scenarioErr *Error
+			require.True(t, errors.As(err, &scenarioErr))
+			assert.Equal(t, "test.yaml", scenarioErr.File)
+			assert.Equal(t, tc.expectedField, scenarioErr.Field)
+			assert.Equal(t, tc.expectedLine, scenarioErr.Line)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestLoad_DefaultScenarioFile(t *testing.T) {
+	engine, err := Load("../../scenarios/default.yaml", logging.NewLogger("error"))
+	require.NoError(t, err)
+
+	expected, err := Default(logging.NewLogger("error"))
+	require.NoError(t, err)
+
+	assert.Equal(t, len(expected.Registry.Actions), len(engine.Registry.Actions))
+	for name, expectedLoc := range expected.World.Locations {
+		loc, ok := engine.World.GetLocation(name)
+		require.True(t, ok, "missing location %s", name)
+		assert.Equal(t, expectedLoc.String(), loc.String())
+	}
+	for name, expectedAgent := range expected.World.Agents {
+		a, ok := engine.World.GetAgent(name)
+		require.True(t, ok, "missing agent %s", name)
+		assert.Equal(t, expectedAgent.String(), a.String())
+	}
+}
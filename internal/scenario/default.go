@@ -0,0 +1,92 @@
+package scenario
+
+import (
+	"log/slog"
+
+	"Neolithic/internal/agent"
+	"Neolithic/internal/attributes"
+	"Neolithic/internal/core"
+	"Neolithic/internal/goalengine"
+	"Neolithic/internal/grid"
+	"Neolithic/internal/world"
+)
+
+const (
+	// defaultWidth is the width of the default scenario's grid
+	defaultWidth = 32
+	// defaultHeight is the height of the default scenario's grid
+	defaultHeight = 32
+	// defaultCellSize is the size of a cell in the default scenario's grid
+	defaultCellSize = 16
+)
+
+// Default builds the default scenario: three berry bushes, a deposit location, and a single agent whose goal is to
+// gather berries at the deposit.
+func Default(logger *slog.Logger, opts ...Option) (*world.Engine, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	worldGrid, err := grid.New(defaultWidth, defaultHeight, defaultCellSize)
+	if err != nil {
+		return nil, err
+	}
+	if err = worldGrid.Initialize(world.MakeTile); err != nil {
+		return nil, err
+	}
+
+	var engineOpts []world.EngineOption
+	if o.seed != nil {
+		engineOpts = append(engineOpts, world.WithSeed(*o.seed))
+	}
+	engine, err := world.NewEngine(worldGrid, logger, engineOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	baseCapacityAttr := &attributes.Capacity{Size: 100}
+
+	loc1 := core.NewLocation("loc1", core.Coord{X: 3, Y: 14}, core.WithAttributes(baseCapacityAttr))
+	loc2 := core.NewLocation("loc2", core.Coord{X: 21, Y: 4}, core.WithAttributes(baseCapacityAttr))
+	loc3 := core.NewLocation("loc3", core.Coord{X: 27, Y: 30}, core.WithAttributes(baseCapacityAttr))
+	depo := core.NewLocation("depo", core.Coord{X: 16, Y: 16}, core.WithAttributes(baseCapacityAttr))
+
+	res1 := core.NewResource("Berries", core.WithResourceAttributes(&attributes.Weight{Amount: 1}))
+	res2 := core.NewResource("Wood", core.WithResourceAttributes(&attributes.Weight{Amount: 1}))
+	res3 := core.NewResource("Stone", core.WithResourceAttributes(&attributes.Weight{Amount: 1}))
+
+	loc1.Inventory.AdjustAmount(res1, 2000)
+	loc2.Inventory.AdjustAmount(res1, 1000)
+	loc3.Inventory.AdjustAmount(res1, 2000)
+
+	goalDepo := depo.DeepCopy()
+
+	testAgent := agent.NewAgent("agent", logger)
+	testAgent.Behavior.GoalEngine = goalengine.NewGoalEngine(goalengine.Goal{
+		Name: "gather berries",
+		Logic: goalengine.GoalLogic{
+			Chunker:      goalengine.AddToLocation,
+			Fallback:     goalengine.FallbackChunkFunc,
+			ShouldGiveUp: goalengine.GiveUpIfNoChange,
+		},
+		Location: goalDepo,
+		Resource: res1,
+	})
+
+	for _, loc := range []*core.Location{loc1, loc2, loc3, depo} {
+		if err = engine.AddLocation(loc); err != nil {
+			return nil, err
+		}
+	}
+	for _, res := range []*core.Resource{res1, res2, res3} {
+		if err = engine.AddResource(res); err != nil {
+			return nil, err
+		}
+	}
+	if err = engine.AddAgent(testAgent); err != nil {
+		return nil, err
+	}
+
+	return engine, nil
+}
@@ -0,0 +1,28 @@
+package scenario
+
+import (
+	"testing"
+
+	"Neolithic/internal/agent"
+	"Neolithic/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	engine, err := Default(logging.NewLogger("error"))
+	require.NoError(t, err)
+
+	assert.Len(t, engine.World.Locations, 4)
+	for _, name := range []string{"loc1", "loc2", "loc3", "depo"} {
+		_, ok := engine.World.GetLocation(name)
+		assert.True(t, ok, "missing location %s", name)
+	}
+	assert.Len(t, engine.Registry.Resources, 3)
+
+	a, ok := engine.World.GetAgent("agent")
+	require.True(t, ok)
+	testAgent := a.(*agent.Agent)
+	assert.NotNil(t, testAgent.Behavior.GoalEngine)
+	assert.Equal(t, engine.Registry.Actions, testAgent.Behavior.PossibleActions)
+}
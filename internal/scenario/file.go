@@ -0,0 +1,392 @@
+package scenario
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"Neolithic/internal/core"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the top level of a scenario file. Scenario files are written in YAML; JSON files are accepted as well, since
+// JSON is a subset of YAML.
+type File struct {
+	// Seed is the seed of the simulation. Defaults to world.DefaultSeed.
+	Seed *int64 `yaml:"seed"`
+	// Grid describes the size of the world
+	Grid GridSpec `yaml:"grid"`
+	// Resources are the resources that exist in the world
+	Resources []ResourceSpec `yaml:"resources"`
+	// Locations are the locations that exist in the world
+	Locations []LocationSpec `yaml:"locations"`
+	// Agents are the agents that exist in the world
+	Agents []AgentSpec `yaml:"agents"`
+	// Registry is used to construct attributes by type. If nil, the registry from attributes.NewRegistry is used.
+	Registry *core.AttributeRegistry `yaml:"-"`
+	// name is the name of the file, used when reporting errors
+	name string
+	// root is the parsed document, used to find the line of a field when reporting errors
+	root *yaml.Node
+}
+
+// GridSpec describes the world grid.
+type GridSpec struct {
+	// Width is the number of tiles across the grid
+	Width int `yaml:"width"`
+	// Height is the number of tiles down the grid
+	Height int `yaml:"height"`
+	// CellSize is the size of a tile when drawn. Defaults to 16.
+	CellSize int `yaml:"cell_size"`
+	// Terrain describes the ground of the grid. Every tile is grass if neither it nor Generate is given.
+	Terrain *TerrainSpec `yaml:"terrain"`
+	// Generate generates the ground of the grid, and locations on it, from seeded noise. Only one of Terrain and
+	// Generate may be given.
+	Generate *GenerateSpec `yaml:"generate"`
+}
+
+// TerrainSpec describes the terrain of the grid as a default terrain covered by rectangular areas of other terrains.
+// Terrains are named as in world.Terrains.
+type TerrainSpec struct {
+	// Default is the terrain of the tiles outside every area. Defaults to grass.
+	Default string `yaml:"default"`
+	// Areas are rectangles of terrain. Where areas overlap, the later one is used.
+	Areas []TerrainAreaSpec `yaml:"areas"`
+}
+
+// TerrainAreaSpec describes a rectangle of terrain, including both corners.
+type TerrainAreaSpec struct {
+	// Terrain is the name of the terrain of the area
+	Terrain string `yaml:"terrain"`
+	// From is one corner of the area
+	From CoordSpec `yaml:"from"`
+	// To is the opposite corner of the area
+	To CoordSpec `yaml:"to"`
+}
+
+// GenerateSpec describes a procedurally generated map. See mapgen.Params; fields left out take the defaults of the
+// mapgen package.
+type GenerateSpec struct {
+	// Seed is the seed of the map. Defaults to the seed of the scenario.
+	Seed *int64 `yaml:"seed"`
+	// Scale is the size, in tiles, of the largest features of the terrain
+	Scale float64 `yaml:"scale"`
+	// Octaves is the number of layers of noise summed into each map
+	Octaves int `yaml:"octaves"`
+	// Persistence is the amplitude of each layer of noise relative to the one before
+	Persistence float64 `yaml:"persistence"`
+	// SeaLevel is the elevation, between 0 and 1, below which the ground is water
+	SeaLevel float64 `yaml:"sea_level"`
+	// RockLevel is the elevation, between 0 and 1, above which the ground is rock
+	RockLevel float64 `yaml:"rock_level"`
+	// ForestMoisture is the moisture, between 0 and 1, above which the ground is forest
+	ForestMoisture float64 `yaml:"forest_moisture"`
+	// MarshMoisture is the moisture, between 0 and 1, above which low ground is marsh
+	MarshMoisture float64 `yaml:"marsh_moisture"`
+	// MarshElevation is how far above the sea level wet ground is low enough to be marsh
+	MarshElevation float64 `yaml:"marsh_elevation"`
+	// Rivers is the number of rivers
+	Rivers int `yaml:"rivers"`
+	// RiverSource is the elevation, between 0 and 1, above which rivers may start
+	RiverSource float64 `yaml:"river_source"`
+	// FordSpacing is the number of tiles between the fords of a river
+	FordSpacing int `yaml:"ford_spacing"`
+	// Placements are the locations to place on the generated terrain
+	Placements []PlacementSpec `yaml:"placements"`
+}
+
+// PlacementSpec describes locations placed on generated terrain. See mapgen.Placement.
+type PlacementSpec struct {
+	// Name is the name of the kind of location. The locations are named after it and numbered from 1.
+	Name string `yaml:"name"`
+	// Terrain is the name of the terrain the locations are placed on
+	Terrain string `yaml:"terrain"`
+	// Count is the number of locations to place
+	Count int `yaml:"count"`
+	// MinSpacing is the fewest tiles, along either axis, between any two generated locations
+	MinSpacing int `yaml:"min_spacing"`
+	// Inventory maps resource names to the amount of that resource at each location
+	Inventory map[string]int `yaml:"inventory"`
+	// Attributes are the attributes of each location
+	Attributes []AttributeSpec `yaml:"attributes"`
+}
+
+// CoordSpec describes a coordinate on the grid.
+type CoordSpec struct {
+	X int `yaml:"x"`
+	Y int `yaml:"y"`
+}
+
+// AttributeSpec describes an attribute by its type. Every other key is a parameter of the attribute, such as
+// `size` for a capacity; the parameters are checked against the schema in the attribute registry.
+type AttributeSpec struct {
+	// Type is the core.AttributeType of the attribute
+	Type string `yaml:"type"`
+	// Params are the parameters used to construct the attribute
+	Params core.AttributeParams `yaml:",inline"`
+}
+
+// ResourceSpec describes a resource.
+type ResourceSpec struct {
+	// Name is the unique name of the resource
+	Name string `yaml:"name"`
+	// Attributes are the attributes of the resource
+	Attributes []AttributeSpec `yaml:"attributes"`
+}
+
+// LocationSpec describes a location.
+type LocationSpec struct {
+	// Name is the unique name of the location
+	Name string `yaml:"name"`
+	// Coord is where the location is on the grid
+	Coord CoordSpec `yaml:"coord"`
+	// Inventory maps resource names to the amount of that resource at the location
+	Inventory map[string]int `yaml:"inventory"`
+	// Attributes are the attributes of the location
+	Attributes []AttributeSpec `yaml:"attributes"`
+	// Access describes the tiles agents can use the location from. Defaults to every tile within
+	// core.DefaultAccessRadius.
+	Access *AccessSpec `yaml:"access"`
+}
+
+// AccessSpec describes the tiles agents can use a location from, either as a radius around it or as a list of tiles.
+// Only one of Radius and Tiles may be given.
+type AccessSpec struct {
+	// Radius is how many tiles away from the location, in any direction, an agent can use it from
+	Radius int `yaml:"radius"`
+	// Tiles are the offsets from the location of the only tiles an agent can use it from, such as the doorway of a hut
+	Tiles []CoordSpec `yaml:"tiles"`
+}
+
+// AgentSpec describes an agent.
+type AgentSpec struct {
+	// Name is the unique name of the agent
+	Name string `yaml:"name"`
+	// Position is where the agent starts on the grid
+	Position CoordSpec `yaml:"position"`
+	// Inventory maps resource names to the amount of that resource the agent carries
+	Inventory map[string]int `yaml:"inventory"`
+	// Goal is the goal of the agent, for agents with a single goal. An agent without a goal stays idle.
+	Goal *GoalSpec `yaml:"goal"`
+	// Goals are the goals of an agent with several goals. Only one of Goal and Goals may be given.
+	Goals []GoalSpec `yaml:"goals"`
+	// MaxGoalFailures is the number of times in a row a goal may fail before the agent moves on to another. Defaults
+	// to goalengine.DefaultMaxFailures.
+	MaxGoalFailures int `yaml:"max_goal_failures"`
+	// Planning limits how much work the planner does for the agent each tick. Defaults to the agent package defaults.
+	Planning *PlanningSpec `yaml:"planning"`
+	// Needs are the needs of the agent, such as hunger or fatigue
+	Needs []NeedSpec `yaml:"needs"`
+	// CarryCapacity is the most weight of resources the agent can carry, counted from their weight attributes. Zero
+	// means no limit.
+	CarryCapacity float64 `yaml:"carry_capacity"`
+	// Speed is how many tiles the agent moves in a second over open ground. Defaults to agent.DefaultSpeed.
+	Speed float64 `yaml:"speed"`
+}
+
+// NeedSpec describes one of an agent's needs. See agent.Need. A need is satisfied by consuming a resource, by resting,
+// or both; at least one of Resource and RestPerSecond must be given.
+type NeedSpec struct {
+	// Name is the name of the need, unique for the agent
+	Name string `yaml:"name"`
+	// Level is the level the need starts at, from 0 (critical) to 1 (fully satisfied). Defaults to 1.
+	Level *float64 `yaml:"level"`
+	// DecayPerSecond is how much the level drops every second
+	DecayPerSecond float64 `yaml:"decay_per_second"`
+	// Threshold is the level below which the agent acts to satisfy the need
+	Threshold float64 `yaml:"threshold"`
+	// Weight scales the priority of the goal the need generates. Defaults to 1.
+	Weight *float64 `yaml:"weight"`
+	// Resource is the name of the resource consumed to satisfy the need
+	Resource string `yaml:"resource"`
+	// RestorePerUnit is how much the level rises for every unit of the resource consumed. Defaults to the nutrition of
+	// the resource, if it is edible.
+	RestorePerUnit float64 `yaml:"restore_per_unit"`
+	// RestPerSecond is how much the level rises every second the agent rests
+	RestPerSecond float64 `yaml:"rest_per_second"`
+}
+
+// PlanningSpec describes an agent's planning budget. See agent.PlanningBudget.
+type PlanningSpec struct {
+	// IterationsPerTick is the most planner iterations run in a single tick
+	IterationsPerTick int `yaml:"iterations_per_tick"`
+	// TimePerTick is the most time spent planning in a single tick, such as "2ms"
+	TimePerTick time.Duration `yaml:"time_per_tick"`
+	// MaxIterations is the most planner iterations spent on a single goal
+	MaxIterations int `yaml:"max_iterations"`
+	// AcceptAfter is the number of iterations after which the best plan found so far is accepted
+	AcceptAfter int `yaml:"accept_after"`
+}
+
+// GoalSpec describes an agent's goal. The logic functions are referred to by the names in goalengine.Chunkers,
+// goalengine.Fallbacks and goalengine.GiveUps.
+type GoalSpec struct {
+	// Name is the name of the goal
+	Name string `yaml:"name"`
+	// Location is the name of the location the goal relates to
+	Location string `yaml:"location"`
+	// Resource is the name of the resource the goal relates to
+	Resource string `yaml:"resource"`
+	// Chunker is the name of the chunker function. Defaults to "add_to_location".
+	Chunker string `yaml:"chunker"`
+	// Fallback is the name of the fallback function. Defaults to "halve".
+	Fallback string `yaml:"fallback"`
+	// GiveUp is the name of the give up function. Defaults to "no_change".
+	GiveUp string `yaml:"give_up"`
+	// Target is the amount of the resource at the location at which the goal is satisfied. If zero, the goal is never
+	// satisfied.
+	Target int `yaml:"target"`
+	// Priority is the priority of the goal; goals with a higher priority are pursued first
+	Priority float64 `yaml:"priority"`
+	// PriorityFunc is the name of a function in goalengine.Priorities that computes the priority from the world. If
+	// empty, Priority is used as is.
+	PriorityFunc string `yaml:"priority_func"`
+}
+
+// Error describes a problem with a scenario file, pointing at the file, line and field that caused it.
+type Error struct {
+	// File is the name of the scenario file
+	File string
+	// Line is the line of the offending field, or 0 if unknown
+	Line int
+	// Field is the path to the offending field, such as "locations[1].coord.x"
+	Field string
+	// Err is the underlying error
+	Err error
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.File)
+	if e.Line > 0 {
+		sb.WriteString(":")
+		sb.WriteString(strconv.Itoa(e.Line))
+	}
+	if e.Field != "" {
+		sb.WriteString(": ")
+		sb.WriteString(e.Field)
+	}
+	sb.WriteString(": ")
+	sb.WriteString(e.Err.Error())
+	return sb.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ReadFile reads and parses the scenario file at path.
+func ReadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(path, data)
+}
+
+// Parse parses a scenario from data. Name is used to identify the scenario in errors. Unknown fields are rejected.
+func Parse(name string, data []byte) (*File, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, &Error{File: name, Err: err}
+	}
+
+	file := &File{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(file); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, &Error{File: name, Err: errors.New("scenario is empty")}
+		}
+		return nil, &Error{File: name, Err: err}
+	}
+
+	file.name = name
+	file.root = &root
+	return file, nil
+}
+
+// fieldPath is the path to a field in a scenario file, made up of mapping keys (string) and sequence indexes (int).
+type fieldPath []any
+
+// with returns a new path with the given elements appended.
+func (p fieldPath) with(elems ...any) fieldPath {
+	newPath := make(fieldPath, 0, len(p)+len(elems))
+	newPath = append(newPath, p...)
+	return append(newPath, elems...)
+}
+
+// String returns the path in the form "locations[1].coord.x".
+func (p fieldPath) String() string {
+	var sb strings.Builder
+	for _, elem := range p {
+		switch e := elem.(type) {
+		case int:
+			sb.WriteString("[")
+			sb.WriteString(strconv.Itoa(e))
+			sb.WriteString("]")
+		default:
+			if sb.Len() > 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString(fmt.Sprint(e))
+		}
+	}
+	return sb.String()
+}
+
+// line finds the line of the field in the document. If the field itself is missing, the line of the closest
+// enclosing field is returned.
+func (p fieldPath) line(root *yaml.Node) int {
+	if root == nil {
+		return 0
+	}
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, elem := range p {
+		next := childNode(node, elem)
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return node.Line
+}
+
+// childNode returns the child of a mapping or sequence node, or nil if it doesn't exist.
+func childNode(node *yaml.Node, elem any) *yaml.Node {
+	switch e := elem.(type) {
+	case int:
+		if node.Kind == yaml.SequenceNode && e >= 0 && e < len(node.Content) {
+			return node.Content[e]
+		}
+	case string:
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == e {
+					return node.Content[i+1]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// errorAt creates an Error for the field at the given path.
+func (f *File) errorAt(path fieldPath, err error) *Error {
+	return &Error{
+		File:  f.name,
+		Line:  path.line(f.root),
+		Field: path.String(),
+		Err:   err,
+	}
+}
@@ -0,0 +1,70 @@
+package scenario
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	type testCase struct {
+		data          string
+		expectedError string
+		expectedFile  File
+	}
+
+	tests := map[string]testCase{
+		"parses yaml": {
+			data: "grid:\n  width: 4\n  height: 5\n",
+			expectedFile: File{
+				Grid: GridSpec{Width: 4, Height: 5},
+			},
+		},
+		"parses json": {
+			data: `{"grid": {"width": 4, "height": 5, "cell_size": 8}, "resources": [{"name": "Wood"}]}`,
+			expectedFile: File{
+				Grid:      GridSpec{Width: 4, Height: 5, CellSize: 8},
+				Resources: []ResourceSpec{{Name: "Wood"}},
+			},
+		},
+		"rejects unknown fields": {
+			data:          "grid:\n  width: 4\n  hieght: 5\n",
+			expectedError: "test.yaml: yaml: unmarshal errors:\n  line 3: field hieght not found in type scenario.GridSpec",
+		},
+		"rejects wrong types": {
+			data:          "grid:\n  width: wide\n",
+			expectedError: "line 2: cannot unmarshal !!str `wide` into int",
+		},
+		"rejects empty files": {
+			data:          "",
+			expectedError: "test.yaml: scenario is empty",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, err := Parse("test.yaml", []byte(tc.data))
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				var scenarioErr *Error
+				assert.True(t, errors.As(err, &scenarioErr))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedFile.Grid, file.Grid)
+			assert.Equal(t, tc.expectedFile.Resources, file.Resources)
+		})
+	}
+}
+
+func TestError_Error(t *testing.T) {
+	err := &Error{File: "world.yaml", Line: 12, Field: "locations[1].coord.x", Err: ErrOutOfBounds}
+	assert.Equal(t, "world.yaml:12: locations[1].coord.x: coordinate is outside the grid", err.Error())
+	assert.ErrorIs(t, err, ErrOutOfBounds)
+
+	noLine := &Error{File: "world.yaml", Err: errors.New("boom")}
+	assert.Equal(t, "world.yaml: boom", noLine.Error())
+}
@@ -0,0 +1,804 @@
+// Package snapshot saves and restores the full state of a simulation, so that a run can be stopped and later resumed
+// exactly where it left off.
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+
+	"Neolithic/internal/agent"
+	"Neolithic/internal/attributes"
+	"Neolithic/internal/core"
+	"Neolithic/internal/goalengine"
+	"Neolithic/internal/grid"
+	"Neolithic/internal/world"
+)
+
+// Version is the current version of the snapshot format. It is increased whenever the format changes in a way that
+// older snapshots can't be read.
+const Version = 2
+
+var (
+	// ErrUnsupportedVersion is returned when reading a snapshot written in a different format version.
+	ErrUnsupportedVersion = errors.New("unsupported snapshot version")
+	// ErrUnknownAction is returned when a snapshot refers to an action that the restored registry didn't create.
+	ErrUnknownAction = errors.New("unknown action")
+)
+
+// Snapshot is a serializable representation of a world.Engine.
+type Snapshot struct {
+	// Version is the version of the format the snapshot was written in
+	Version int `json:"version"`
+	// Seed is the seed of the simulation
+	Seed int64 `json:"seed"`
+	// Elapsed is the simulated time, in seconds, the engine had been ticked for
+	Elapsed float64 `json:"elapsed,omitempty"`
+	// Grid describes the world grid
+	Grid GridSnapshot `json:"grid"`
+	// Resources are the registered resources, in registration order
+	Resources []ResourceSnapshot `json:"resources"`
+	// Locations are the locations in the world, in registration order
+	Locations []LocationSnapshot `json:"locations"`
+	// Agents are the agents in the world, sorted by name
+	Agents []AgentSnapshot `json:"agents"`
+}
+
+// GridSnapshot describes the world grid.
+type GridSnapshot struct {
+	Width    int `json:"width"`
+	Height   int `json:"height"`
+	CellSize int `json:"cell_size"`
+	// Terrain is the terrain of the grid, one string per row from the top, with each tile written as the Symbol of its
+	// world.Terrain. It is omitted if every tile is grass.
+	Terrain []string `json:"terrain,omitempty"`
+}
+
+// AttributeSnapshot describes an attribute by its type and the parameters needed to recreate it.
+type AttributeSnapshot struct {
+	Type   core.AttributeType   `json:"type"`
+	Params core.AttributeParams `json:"params,omitempty"`
+}
+
+// InventoryEntrySnapshot is a single entry of an inventory, referring to the resource by name.
+type InventoryEntrySnapshot struct {
+	Resource string `json:"resource"`
+	Amount   int    `json:"amount"`
+}
+
+// ResourceSnapshot describes a resource.
+type ResourceSnapshot struct {
+	Name       string              `json:"name"`
+	Attributes []AttributeSnapshot `json:"attributes,omitempty"`
+}
+
+// LocationSnapshot describes a location as it currently is in the world.
+type LocationSnapshot struct {
+	Name       string                   `json:"name"`
+	Coord      core.Coord               `json:"coord"`
+	Inventory  []InventoryEntrySnapshot `json:"inventory,omitempty"`
+	Attributes []AttributeSnapshot      `json:"attributes,omitempty"`
+	// AccessRadius and AccessTiles are the tiles agents can use the location from, as in core.Location
+	AccessRadius int          `json:"access_radius,omitempty"`
+	AccessTiles  []core.Coord `json:"access_tiles,omitempty"`
+	// Reservations are the resources agents have reserved at the location
+	Reservations []ReservationSnapshot `json:"reservations,omitempty"`
+}
+
+// ReservationSnapshot describes the resources a single claimant has reserved at a location.
+type ReservationSnapshot struct {
+	Claimant  string                   `json:"claimant"`
+	Inventory []InventoryEntrySnapshot `json:"inventory"`
+}
+
+// AgentSnapshot describes an agent, including its behavior.
+type AgentSnapshot struct {
+	Name      string                   `json:"name"`
+	Position  core.Coord               `json:"position"`
+	Inventory []InventoryEntrySnapshot `json:"inventory,omitempty"`
+	// State is the agent's current behavioral state
+	State agent.StateSnapshot `json:"state"`
+	// Plan is the remainder of the agent's current plan, or nil if it has none
+	Plan *PlanSnapshot `json:"plan,omitempty"`
+	// Goals are the goals of the agent's goal engine, or nil if it has none
+	Goals []GoalSnapshot `json:"goals,omitempty"`
+	// MaxGoalFailures is the MaxFailures of the agent's goal engine
+	MaxGoalFailures int `json:"max_goal_failures,omitempty"`
+	// Planning is the agent's planning budget, or nil if it uses the defaults
+	Planning *agent.PlanningBudget `json:"planning,omitempty"`
+	// Needs are the agent's needs and their current levels
+	Needs []NeedSnapshot `json:"needs,omitempty"`
+	// CarryCapacity is the most weight the agent can carry, or 0 if there is no limit
+	CarryCapacity float64 `json:"carry_capacity,omitempty"`
+	// Speed is the agent's speed in tiles per second, or 0 if it uses agent.DefaultSpeed
+	Speed float64 `json:"speed,omitempty"`
+}
+
+// NeedSnapshot describes one of an agent's needs, referring to its resource by name.
+type NeedSnapshot struct {
+	Name           string  `json:"name"`
+	Level          float64 `json:"level"`
+	DecayPerSecond float64 `json:"decay_per_second"`
+	Threshold      float64 `json:"threshold"`
+	Weight         float64 `json:"weight"`
+	Resource       string  `json:"resource,omitempty"`
+	RestorePerUnit float64 `json:"restore_per_unit,omitempty"`
+	RestPerSecond  float64 `json:"rest_per_second,omitempty"`
+	Resting        bool    `json:"resting,omitempty"`
+}
+
+// PlanSnapshot is the list of actions left in a plan, along with the goal the plan was made to reach.
+type PlanSnapshot struct {
+	Actions []ActionRef `json:"actions"`
+	// Goal is the inventory of each location and agent in the plan's goal, or nil if the goal is unknown
+	Goal []GoalLocationSnapshot `json:"goal,omitempty"`
+}
+
+// GoalLocationSnapshot is the inventory a plan's goal requires at a single location, or of a single agent. Only one of
+// Location and Agent is set.
+type GoalLocationSnapshot struct {
+	Location  string                   `json:"location,omitempty"`
+	Agent     string                   `json:"agent,omitempty"`
+	Inventory []InventoryEntrySnapshot `json:"inventory,omitempty"`
+}
+
+// ActionRef refers to one of the actions created by the world.Registry. Actions are identified by their type and the
+// location and resource they act on; Ordinal distinguishes actions that share all three. A core.Batched action that the
+// planner took with a smaller amount than the registry's refers to the registry's action, along with the Amount.
+type ActionRef struct {
+	Type     string `json:"type"`
+	Location string `json:"location,omitempty"`
+	Resource string `json:"resource,omitempty"`
+	Ordinal  int    `json:"ordinal,omitempty"`
+	Amount   int    `json:"amount,omitempty"`
+}
+
+// GoalSnapshot describes a goal and its progress, referring to its logic functions by their names in the goalengine
+// package.
+type GoalSnapshot struct {
+	Name         string  `json:"name"`
+	Location     string  `json:"location"`
+	Resource     string  `json:"resource"`
+	Agent        string  `json:"agent,omitempty"`
+	Chunker      string  `json:"chunker"`
+	Fallback     string  `json:"fallback"`
+	GiveUp       string  `json:"give_up"`
+	Target       int     `json:"target,omitempty"`
+	Priority     float64 `json:"priority,omitempty"`
+	PriorityFunc string  `json:"priority_func,omitempty"`
+	Failures     int     `json:"failures,omitempty"`
+	Retired      bool    `json:"retired,omitempty"`
+}
+
+// Take creates a snapshot of the engine. The registry is used to record the parameters of each attribute.
+func Take(engine *world.Engine, registry *core.AttributeRegistry) (*Snapshot, error) {
+	worldGrid, ok := engine.World.Grid.(*grid.Grid)
+	if !ok {
+		return nil, fmt.Errorf("cannot snapshot grid of type %T", engine.World.Grid)
+	}
+
+	snapshot := &Snapshot{
+		Version: Version,
+		Seed:    engine.Seed(),
+		Elapsed: engine.Elapsed(),
+		Grid: GridSnapshot{
+			Width:    worldGrid.Width,
+			Height:   worldGrid.Height,
+			CellSize: worldGrid.CellSize,
+			Terrain:  snapshotTerrain(worldGrid),
+		},
+	}
+
+	for _, res := range engine.Registry.Resources {
+		attrs, err := snapshotAttributes(res.Attributes(), registry)
+		if err != nil {
+			return nil, fmt.Errorf("resource %s: %w", res.Name, err)
+		}
+		snapshot.Resources = append(snapshot.Resources, ResourceSnapshot{Name: res.Name, Attributes: attrs})
+	}
+
+	for _, registered := range engine.Registry.Locations {
+		loc, ok := engine.World.GetLocation(registered.Name)
+		if !ok {
+			return nil, fmt.Errorf("registered location %s is not in the world", registered.Name)
+		}
+		attrs, err := snapshotAttributes(loc.Attributes(), registry)
+		if err != nil {
+			return nil, fmt.Errorf("location %s: %w", loc.Name, err)
+		}
+		locSnapshot := LocationSnapshot{
+			Name:         loc.Name,
+			Coord:        loc.Coord,
+			Inventory:    snapshotInventory(loc.Inventory),
+			Attributes:   attrs,
+			AccessRadius: loc.AccessRadius,
+			AccessTiles:  loc.AccessTiles,
+		}
+		for _, claimant := range loc.Claimants() {
+			reservation := ReservationSnapshot{Claimant: claimant}
+			for _, entry := range loc.ReservationsBy(claimant) {
+				reservation.Inventory = append(reservation.Inventory, InventoryEntrySnapshot{Resource: entry.Resource.Name, Amount: entry.Amount})
+			}
+			locSnapshot.Reservations = append(locSnapshot.Reservations, reservation)
+		}
+		snapshot.Locations = append(snapshot.Locations, locSnapshot)
+	}
+
+	refs := actionRefs(engine.Registry.Actions)
+
+	names := make([]string, 0, len(engine.World.Agents))
+	for name := range engine.World.Agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		a, ok := engine.World.Agents[name].(*agent.Agent)
+		if !ok {
+			return nil, fmt.Errorf("cannot snapshot agent of type %T", engine.World.Agents[name])
+		}
+		agentSnapshot, err := snapshotAgent(a, refs)
+		if err != nil {
+			return nil, fmt.Errorf("agent %s: %w", name, err)
+		}
+		snapshot.Agents = append(snapshot.Agents, agentSnapshot)
+	}
+
+	return snapshot, nil
+}
+
+// Restore builds a new engine from the snapshot. The registry is used to recreate attributes.
+func (s *Snapshot) Restore(registry *core.AttributeRegistry, logger *slog.Logger) (*world.Engine, error) {
+	if s.Version != Version {
+		return nil, fmt.Errorf("%w: %d (expected %d)", ErrUnsupportedVersion, s.Version, Version)
+	}
+
+	worldGrid, err := grid.New(s.Grid.Width, s.Grid.Height, s.Grid.CellSize)
+	if err != nil {
+		return nil, err
+	}
+	terrainAt, err := s.Grid.terrainAt()
+	if err != nil {
+		return nil, err
+	}
+	if err = worldGrid.Initialize(world.MakeTerrainTile(terrainAt)); err != nil {
+		return nil, err
+	}
+
+	engine, err := world.NewEngine(worldGrid, logger, world.WithSeed(s.Seed), world.WithElapsed(s.Elapsed))
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make(map[string]*core.Resource, len(s.Resources))
+	for _, resSnapshot := range s.Resources {
+		attrs, err := restoreAttributes(resSnapshot.Attributes, registry)
+		if err != nil {
+			return nil, fmt.Errorf("resource %s: %w", resSnapshot.Name, err)
+		}
+		resources[resSnapshot.Name] = core.NewResource(resSnapshot.Name, core.WithResourceAttributes(attrs...))
+	}
+
+	for _, locSnapshot := range s.Locations {
+		attrs, err := restoreAttributes(locSnapshot.Attributes, registry)
+		if err != nil {
+			return nil, fmt.Errorf("location %s: %w", locSnapshot.Name, err)
+		}
+		entries, err := restoreInventory(locSnapshot.Inventory, resources)
+		if err != nil {
+			return nil, fmt.Errorf("location %s: %w", locSnapshot.Name, err)
+		}
+		loc := core.NewLocation(locSnapshot.Name, locSnapshot.Coord,
+			core.WithInventory(entries...),
+			core.WithAttributes(attrs...),
+			core.WithAccessRadius(locSnapshot.AccessRadius),
+			core.WithAccessTiles(locSnapshot.AccessTiles...),
+		)
+		for _, reservation := range locSnapshot.Reservations {
+			reserved, err := restoreInventory(reservation.Inventory, resources)
+			if err != nil {
+				return nil, fmt.Errorf("location %s: reservation of %s: %w", locSnapshot.Name, reservation.Claimant, err)
+			}
+			for _, entry := range reserved {
+				loc.Reserve(reservation.Claimant, entry.Resource, entry.Amount)
+			}
+		}
+		if err = engine.AddLocation(loc); err != nil {
+			return nil, fmt.Errorf("location %s: %w", locSnapshot.Name, err)
+		}
+	}
+
+	for _, resSnapshot := range s.Resources {
+		if err = engine.AddResource(resources[resSnapshot.Name]); err != nil {
+			return nil, fmt.Errorf("resource %s: %w", resSnapshot.Name, err)
+		}
+	}
+
+	actions := make(map[ActionRef]core.Action, len(engine.Registry.Actions))
+	for action, ref := range actionRefs(engine.Registry.Actions) {
+		actions[ref] = action
+	}
+
+	for _, agentSnapshot := range s.Agents {
+		a, err := restoreAgent(agentSnapshot, engine, resources, actions, logger)
+		if err != nil {
+			return nil, fmt.Errorf("agent %s: %w", agentSnapshot.Name, err)
+		}
+		if err = engine.AddAgent(a); err != nil {
+			return nil, fmt.Errorf("agent %s: %w", agentSnapshot.Name, err)
+		}
+	}
+
+	return engine, nil
+}
+
+// snapshotTerrain returns the terrain of the grid in the form of GridSnapshot.Terrain, or nil if every tile is grass.
+func snapshotTerrain(g *grid.Grid) []string {
+	rows := make([]string, g.Height)
+	allGrass := true
+	for y := 0; y < g.Height; y++ {
+		row := make([]byte, g.Width)
+		for x := 0; x < g.Width; x++ {
+			terrain, _ := world.TerrainAt(g, core.Coord{X: x, Y: y})
+			props := terrain.Properties()
+			if props != world.Grass.Properties() {
+				allGrass = false
+			}
+			row[x] = props.Symbol
+		}
+		rows[y] = string(row)
+	}
+	if allGrass {
+		return nil
+	}
+	return rows
+}
+
+// terrainAt returns the terrain of each coordinate of the grid, as recorded in Terrain. It returns an error if Terrain
+// doesn't match the size of the grid or holds an unknown symbol.
+func (g GridSnapshot) terrainAt() (func(x, y int) world.Terrain, error) {
+	if g.Terrain == nil {
+		return func(_, _ int) world.Terrain { return world.Grass }, nil
+	}
+	if len(g.Terrain) != g.Height {
+		return nil, fmt.Errorf("terrain has %d rows, expected %d", len(g.Terrain), g.Height)
+	}
+	terrains := make([][]world.Terrain, g.Height)
+	for y, row := range g.Terrain {
+		if len(row) != g.Width {
+			return nil, fmt.Errorf("terrain row %d has %d tiles, expected %d", y, len(row), g.Width)
+		}
+		terrains[y] = make([]world.Terrain, g.Width)
+		for x := 0; x < len(row); x++ {
+			terrain, ok := world.TerrainBySymbol(row[x])
+			if !ok {
+				return nil, fmt.Errorf("%w: symbol %q at (%d, %d)", world.ErrUnknownTerrain, row[x], x, y)
+			}
+			terrains[y][x] = terrain
+		}
+	}
+	return func(x, y int) world.Terrain { return terrains[y][x] }, nil
+}
+
+// Write writes the snapshot to w as JSON.
+func Write(w io.Writer, s *Snapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Read reads a snapshot written by Write. It returns ErrUnsupportedVersion if the snapshot was written in a
+// different format version.
+func Read(r io.Reader) (*Snapshot, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	if header.Version != Version {
+		return nil, fmt.Errorf("%w: %d (expected %d)", ErrUnsupportedVersion, header.Version, Version)
+	}
+
+	snapshot := &Snapshot{}
+	if err = json.Unmarshal(data, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// SaveFile takes a snapshot of the engine and writes it to the file at path, using the attributes from
+// attributes.NewRegistry.
+func SaveFile(path string, engine *world.Engine) error {
+	snapshot, err := Take(engine, attributes.NewRegistry())
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err = Write(file, snapshot); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// LoadFile reads the snapshot at path and restores an engine from it, using the attributes from
+// attributes.NewRegistry.
+func LoadFile(path string, logger *slog.Logger) (*world.Engine, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	snapshot, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return snapshot.Restore(attributes.NewRegistry(), logger)
+}
+
+// snapshotAgent creates a snapshot of a single agent.
+func snapshotAgent(a *agent.Agent, refs map[core.Action]ActionRef) (AgentSnapshot, error) {
+	state, err := a.SnapshotState()
+	if err != nil {
+		return AgentSnapshot{}, err
+	}
+
+	agentSnapshot := AgentSnapshot{
+		Name:          a.Name(),
+		Position:      a.Position,
+		Inventory:     snapshotInventory(a.Inventory()),
+		State:         state,
+		CarryCapacity: a.MaxCarry,
+		Speed:         a.Speed,
+	}
+
+	if a.Behavior.CurPlan != nil {
+		remaining, err := agent.RemainingActions(a.Behavior.CurPlan)
+		if err != nil {
+			return AgentSnapshot{}, err
+		}
+		planSnapshot := &PlanSnapshot{Actions: make([]ActionRef, 0, len(remaining))}
+		for _, action := range remaining {
+			ref, ok := refs[action]
+			if batched, isBatched := action.(core.Batched); !ok && isBatched {
+				ref, ok = refs[batched.Template()]
+				ref.Amount = batched.BatchAmount()
+			}
+			if !ok {
+				return AgentSnapshot{}, fmt.Errorf("%w: %s is not in the registry", ErrUnknownAction, action.Description())
+			}
+			planSnapshot.Actions = append(planSnapshot.Actions, ref)
+		}
+		if goal := agent.PlanGoal(a.Behavior.CurPlan); goal != nil {
+			for _, name := range goal.LocationNames() {
+				planSnapshot.Goal = append(planSnapshot.Goal, GoalLocationSnapshot{
+					Location:  name,
+					Inventory: snapshotInventory(goal.Locations[name].Inventory),
+				})
+			}
+			for _, name := range goal.AgentNames() {
+				planSnapshot.Goal = append(planSnapshot.Goal, GoalLocationSnapshot{
+					Agent:     name,
+					Inventory: snapshotInventory(goal.Agents[name].Inventory()),
+				})
+			}
+		}
+		agentSnapshot.Plan = planSnapshot
+	}
+
+	if a.Behavior.GoalEngine != nil {
+		agentSnapshot.Goals = make([]GoalSnapshot, 0, len(a.Behavior.GoalEngine.Goals))
+		for _, goal := range a.Behavior.GoalEngine.Goals {
+			goalSnapshot, err := snapshotGoal(goal)
+			if err != nil {
+				return AgentSnapshot{}, err
+			}
+			agentSnapshot.Goals = append(agentSnapshot.Goals, *goalSnapshot)
+		}
+		agentSnapshot.MaxGoalFailures = a.Behavior.GoalEngine.MaxFailures
+	}
+
+	if a.Behavior.Planning != (agent.PlanningBudget{}) {
+		planning := a.Behavior.Planning
+		agentSnapshot.Planning = &planning
+	}
+
+	for _, need := range a.Behavior.Needs {
+		needSnapshot := NeedSnapshot{
+			Name:           need.Name,
+			Level:          need.Level,
+			DecayPerSecond: need.DecayPerSecond,
+			Threshold:      need.Threshold,
+			Weight:         need.Weight,
+			RestorePerUnit: need.RestorePerUnit,
+			RestPerSecond:  need.RestPerSecond,
+			Resting:        need.Resting,
+		}
+		if need.Resource != nil {
+			needSnapshot.Resource = need.Resource.Name
+		}
+		agentSnapshot.Needs = append(agentSnapshot.Needs, needSnapshot)
+	}
+
+	return agentSnapshot, nil
+}
+
+// restoreAgent recreates a single agent. The agent is not added to the engine.
+func restoreAgent(agentSnapshot AgentSnapshot, engine *world.Engine, resources map[string]*core.Resource, actions map[ActionRef]core.Action, logger *slog.Logger) (*agent.Agent, error) {
+	a := agent.NewAgent(agentSnapshot.Name, logger)
+	a.Position = agentSnapshot.Position
+	a.MaxCarry = agentSnapshot.CarryCapacity
+	a.Speed = agentSnapshot.Speed
+
+	entries, err := restoreInventory(agentSnapshot.Inventory, resources)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		a.Inventory().AdjustAmount(entry.Resource, entry.Amount)
+	}
+
+	if agentSnapshot.Goals != nil {
+		goals := make([]goalengine.Goal, 0, len(agentSnapshot.Goals))
+		for _, goalSnapshot := range agentSnapshot.Goals {
+			goal, err := restoreGoal(goalSnapshot, engine, resources)
+			if err != nil {
+				return nil, err
+			}
+			goals = append(goals, goal)
+		}
+		a.Behavior.GoalEngine = goalengine.NewGoalEngine(goals...)
+		a.Behavior.GoalEngine.MaxFailures = agentSnapshot.MaxGoalFailures
+	}
+
+	if agentSnapshot.Planning != nil {
+		a.Behavior.Planning = *agentSnapshot.Planning
+	}
+
+	for _, needSnapshot := range agentSnapshot.Needs {
+		need := &agent.Need{
+			Name:           needSnapshot.Name,
+			Level:          needSnapshot.Level,
+			DecayPerSecond: needSnapshot.DecayPerSecond,
+			Threshold:      needSnapshot.Threshold,
+			Weight:         needSnapshot.Weight,
+			RestorePerUnit: needSnapshot.RestorePerUnit,
+			RestPerSecond:  needSnapshot.RestPerSecond,
+			Resting:        needSnapshot.Resting,
+		}
+		if needSnapshot.Resource != "" {
+			res, ok := resources[needSnapshot.Resource]
+			if !ok {
+				return nil, fmt.Errorf("need %s: unknown resource %s", needSnapshot.Name, needSnapshot.Resource)
+			}
+			need.Resource = res
+		}
+		a.Behavior.Needs = append(a.Behavior.Needs, need)
+	}
+
+	if agentSnapshot.Plan != nil {
+		planActions := make([]core.Action, 0, len(agentSnapshot.Plan.Actions))
+		for _, ref := range agentSnapshot.Plan.Actions {
+			templateRef := ref
+			templateRef.Amount = 0
+			action, ok := actions[templateRef]
+			if !ok {
+				return nil, fmt.Errorf("%w: %+v", ErrUnknownAction, ref)
+			}
+			if ref.Amount > 0 {
+				batched, isBatched := action.(core.Batched)
+				if !isBatched {
+					return nil, fmt.Errorf("%w: %+v takes no amount", ErrUnknownAction, ref)
+				}
+				action = batched.WithAmount(ref.Amount)
+			}
+			planActions = append(planActions, action)
+		}
+		goal, err := restorePlanGoal(agentSnapshot.Plan.Goal, resources)
+		if err != nil {
+			return nil, err
+		}
+		a.Behavior.CurPlan = agent.NewPlan(planActions, goal)
+	}
+
+	if err = a.RestoreState(agentSnapshot.State, logger); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// snapshotGoal creates a snapshot of a goal, looking up the names of its logic functions.
+func snapshotGoal(goal *goalengine.Goal) (*GoalSnapshot, error) {
+	chunker, ok := goalengine.ChunkerName(goal.Logic.Chunker)
+	if !ok {
+		return nil, fmt.Errorf("goal %s: chunker is not in goalengine.Chunkers", goal.Name)
+	}
+	fallback, ok := goalengine.FallbackName(goal.Logic.Fallback)
+	if !ok {
+		return nil, fmt.Errorf("goal %s: fallback is not in goalengine.Fallbacks", goal.Name)
+	}
+	giveUp, ok := goalengine.GiveUpName(goal.Logic.ShouldGiveUp)
+	if !ok {
+		return nil, fmt.Errorf("goal %s: give up function is not in goalengine.GiveUps", goal.Name)
+	}
+
+	goalSnapshot := &GoalSnapshot{
+		Name:     goal.Name,
+		Agent:    goal.Agent,
+		Chunker:  chunker,
+		Fallback: fallback,
+		GiveUp:   giveUp,
+		Target:   goal.Target,
+		Priority: goal.Priority,
+		Failures: goal.Failures,
+		Retired:  goal.Retired,
+	}
+	if goal.PriorityFunc != nil {
+		priorityFunc, ok := goalengine.PriorityName(goal.PriorityFunc)
+		if !ok {
+			return nil, fmt.Errorf("goal %s: priority function is not in goalengine.Priorities", goal.Name)
+		}
+		goalSnapshot.PriorityFunc = priorityFunc
+	}
+	if goal.Location != nil {
+		goalSnapshot.Location = goal.Location.Name
+	}
+	if goal.Resource != nil {
+		goalSnapshot.Resource = goal.Resource.Name
+	}
+	return goalSnapshot, nil
+}
+
+// restoreGoal recreates a goal, resolving its location, resource and logic functions by name.
+func restoreGoal(goalSnapshot GoalSnapshot, engine *world.Engine, resources map[string]*core.Resource) (goalengine.Goal, error) {
+	goal := goalengine.Goal{
+		Name: goalSnapshot.Name,
+		Logic: goalengine.GoalLogic{
+			Chunker:      goalengine.Chunkers[goalSnapshot.Chunker],
+			Fallback:     goalengine.Fallbacks[goalSnapshot.Fallback],
+			ShouldGiveUp: goalengine.GiveUps[goalSnapshot.GiveUp],
+		},
+	}
+	if goal.Logic.Chunker == nil || goal.Logic.Fallback == nil || goal.Logic.ShouldGiveUp == nil {
+		return goalengine.Goal{}, fmt.Errorf("goal %s: unknown logic function", goalSnapshot.Name)
+	}
+	goal.Agent = goalSnapshot.Agent
+	goal.Target = goalSnapshot.Target
+	goal.Priority = goalSnapshot.Priority
+	goal.Failures = goalSnapshot.Failures
+	goal.Retired = goalSnapshot.Retired
+	if goalSnapshot.PriorityFunc != "" {
+		priorityFunc, ok := goalengine.Priorities[goalSnapshot.PriorityFunc]
+		if !ok {
+			return goalengine.Goal{}, fmt.Errorf("goal %s: unknown priority function %s", goalSnapshot.Name, goalSnapshot.PriorityFunc)
+		}
+		goal.PriorityFunc = priorityFunc
+	}
+
+	if goalSnapshot.Location != "" {
+		loc, ok := engine.World.GetLocation(goalSnapshot.Location)
+		if !ok {
+			return goalengine.Goal{}, fmt.Errorf("goal %s: unknown location %s", goalSnapshot.Name, goalSnapshot.Location)
+		}
+		goal.Location = loc.DeepCopy()
+	}
+	if goalSnapshot.Resource != "" {
+		res, ok := resources[goalSnapshot.Resource]
+		if !ok {
+			return goalengine.Goal{}, fmt.Errorf("goal %s: unknown resource %s", goalSnapshot.Name, goalSnapshot.Resource)
+		}
+		goal.Resource = res
+	}
+	return goal, nil
+}
+
+// restorePlanGoal recreates the goal of a plan in the same form the goal engine creates it: a world state holding only
+// the locations, agents and inventories the goal cares about. It returns nil if the goal is unknown.
+func restorePlanGoal(snapshots []GoalLocationSnapshot, resources map[string]*core.Resource) (*core.WorldState, error) {
+	if snapshots == nil {
+		return nil, nil
+	}
+	goal := &core.WorldState{Locations: make(map[string]*core.Location, len(snapshots))}
+	for _, goalSnapshot := range snapshots {
+		entries, err := restoreInventory(goalSnapshot.Inventory, resources)
+		if err != nil {
+			return nil, fmt.Errorf("plan goal: %w", err)
+		}
+		inv := core.NewInventory()
+		for _, entry := range entries {
+			inv.AdjustAmount(entry.Resource, entry.Amount)
+		}
+		if goalSnapshot.Agent != "" {
+			if goal.Agents == nil {
+				goal.Agents = map[string]core.Agent{}
+			}
+			goal.Agents[goalSnapshot.Agent] = &core.GoalAgent{AgentName: goalSnapshot.Agent, GoalInventory: inv}
+			continue
+		}
+		goal.Locations[goalSnapshot.Location] = &core.Location{Name: goalSnapshot.Location, Inventory: inv}
+	}
+	return goal, nil
+}
+
+// actionRefs creates an ActionRef for every action.
+func actionRefs(actions []core.Action) map[core.Action]ActionRef {
+	refs := make(map[core.Action]ActionRef, len(actions))
+	ordinals := map[ActionRef]int{}
+	for _, action := range actions {
+		ref := ActionRef{Type: fmt.Sprintf("%T", action)}
+		if locatable, ok := action.(core.Locatable); ok && locatable.Location() != nil {
+			ref.Location = locatable.Location().Name
+		}
+		if needsResource, ok := action.(core.NeedsResource); ok && needsResource.Resource() != nil {
+			ref.Resource = needsResource.Resource().Name
+		}
+		ordinal := ordinals[ref]
+		ordinals[ref]++
+		ref.Ordinal = ordinal
+		refs[action] = ref
+	}
+	return refs
+}
+
+// snapshotAttributes records the type and parameters of every attribute in the list.
+func snapshotAttributes(attrs core.AttributeList, registry *core.AttributeRegistry) ([]AttributeSnapshot, error) {
+	var snapshots []AttributeSnapshot
+	for _, attr := range attrs.List() {
+		params, err := registry.ParamsOf(attr)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, AttributeSnapshot{Type: attr.Type(), Params: params})
+	}
+	return snapshots, nil
+}
+
+// restoreAttributes recreates attributes through the registry.
+func restoreAttributes(snapshots []AttributeSnapshot, registry *core.AttributeRegistry) ([]core.Attribute, error) {
+	attrs := make([]core.Attribute, 0, len(snapshots))
+	for _, attrSnapshot := range snapshots {
+		attr, err := registry.New(attrSnapshot.Type, attrSnapshot.Params)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", attrSnapshot.Type, err)
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs, nil
+}
+
+// snapshotInventory records the entries of an inventory by resource name.
+func snapshotInventory(inv core.Inventory) []InventoryEntrySnapshot {
+	var entries []InventoryEntrySnapshot
+	for _, entry := range inv.Entries() {
+		entries = append(entries, InventoryEntrySnapshot{Resource: entry.Resource.Name, Amount: entry.Amount})
+	}
+	return entries
+}
+
+// restoreInventory resolves inventory entries by resource name.
+func restoreInventory(snapshots []InventoryEntrySnapshot, resources map[string]*core.Resource) ([]core.InventoryEntry, error) {
+	entries := make([]core.InventoryEntry, 0, len(snapshots))
+	for _, entrySnapshot := range snapshots {
+		res, ok := resources[entrySnapshot.Resource]
+		if !ok {
+			return nil, fmt.Errorf("unknown resource %s", entrySnapshot.Resource)
+		}
+		entries = append(entries, core.InventoryEntry{Resource: res, Amount: entrySnapshot.Amount})
+	}
+	return entries, nil
+}
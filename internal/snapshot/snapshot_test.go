@@ -0,0 +1,344 @@
+package snapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"Neolithic/internal/agent"
+	"Neolithic/internal/attributes"
+	"Neolithic/internal/core"
+	"Neolithic/internal/logging"
+	"Neolithic/internal/scenario"
+	"Neolithic/internal/world"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deltaTime = 1.0 / 60.0
+
+func tickEngine(t *testing.T, engine *world.Engine, ticks int) {
+	t.Helper()
+	for i := 0; i < ticks; i++ {
+		require.NoError(t, engine.Tick(deltaTime))
+	}
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	logger := logging.NewLogger("error")
+	registry := attributes.NewRegistry()
+
+	type testCase struct {
+		ticksBefore int
+		ticksAfter  int
+	}
+
+	tests := map[string]testCase{
+		"fresh world": {
+			ticksBefore: 0,
+			ticksAfter:  600,
+		},
+		"mid run": {
+			ticksBefore: 450,
+			ticksAfter:  900,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			original, err := scenario.Default(logger)
+			require.NoError(t, err)
+			tickEngine(t, original, tc.ticksBefore)
+
+			taken, err := Take(original, registry)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, Write(&buf, taken))
+			read, err := Read(&buf)
+			require.NoError(t, err)
+			assert.Equal(t, taken, read)
+
+			restored, err := read.Restore(registry, logger)
+			require.NoError(t, err)
+
+			retaken, err := Take(restored, registry)
+			require.NoError(t, err)
+			assert.Equal(t, taken, retaken)
+
+			tickEngine(t, original, tc.ticksAfter)
+			tickEngine(t, restored, tc.ticksAfter)
+
+			originalID, err := original.World.ID()
+			require.NoError(t, err)
+			restoredID, err := restored.World.ID()
+			require.NoError(t, err)
+			assert.Equal(t, originalID, restoredID)
+
+			originalAgent, _ := original.World.GetAgent("agent")
+			restoredAgent, _ := restored.World.GetAgent("agent")
+			assert.Equal(t, originalAgent.(*agent.Agent).Position, restoredAgent.(*agent.Agent).Position)
+			assert.IsType(t, originalAgent.(*agent.Agent).Behavior.CurState, restoredAgent.(*agent.Agent).Behavior.CurState)
+		})
+	}
+}
+
+func TestSnapshot_RoundTripNeeds(t *testing.T) {
+	logger := logging.NewLogger("error")
+	registry := attributes.NewRegistry()
+
+	original, err := scenario.Load("../../scenarios/survival.yaml", logger)
+	require.NoError(t, err)
+	tickEngine(t, original, 3000)
+
+	taken, err := Take(original, registry)
+	require.NoError(t, err)
+	require.Len(t, taken.Agents, 1)
+	require.Len(t, taken.Agents[0].Needs, 3)
+
+	restored, err := taken.Restore(registry, logger)
+	require.NoError(t, err)
+	retaken, err := Take(restored, registry)
+	require.NoError(t, err)
+	assert.Equal(t, taken, retaken)
+
+	tickEngine(t, original, 3000)
+	tickEngine(t, restored, 3000)
+
+	originalID, err := original.World.ID()
+	require.NoError(t, err)
+	restoredID, err := restored.World.ID()
+	require.NoError(t, err)
+	assert.Equal(t, originalID, restoredID)
+
+	originalAgent, _ := original.World.GetAgent("villager")
+	restoredAgent, _ := restored.World.GetAgent("villager")
+	for i, need := range originalAgent.(*agent.Agent).Behavior.Needs {
+		assert.Equal(t, need.Level, restoredAgent.(*agent.Agent).Behavior.Needs[i].Level, need.Name)
+	}
+}
+
+func TestSnapshot_RoundTripRegrowth(t *testing.T) {
+	logger := logging.NewLogger("error")
+	registry := attributes.NewRegistry()
+
+	original, err := scenario.Load("../../scenarios/regrowth.yaml", logger)
+	require.NoError(t, err)
+	tickEngine(t, original, 1100)
+
+	taken, err := Take(original, registry)
+	require.NoError(t, err)
+	assert.Equal(t, original.Elapsed(), taken.Elapsed)
+	require.NotNil(t, taken.Agents[0].Plan)
+	assert.Condition(t, func() bool {
+		for _, ref := range taken.Agents[0].Plan.Actions {
+			if ref.Amount > 0 {
+				return true
+			}
+		}
+		return false
+	}, "the plan should deposit a partial batch")
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, taken))
+	read, err := Read(&buf)
+	require.NoError(t, err)
+	restored, err := read.Restore(registry, logger)
+	require.NoError(t, err)
+	assert.Equal(t, original.Elapsed(), restored.Elapsed())
+	retaken, err := Take(restored, registry)
+	require.NoError(t, err)
+	assert.Equal(t, taken, retaken)
+
+	tickEngine(t, original, 1800)
+	tickEngine(t, restored, 1800)
+
+	originalID, err := original.World.ID()
+	require.NoError(t, err)
+	restoredID, err := restored.World.ID()
+	require.NoError(t, err)
+	assert.Equal(t, originalID, restoredID, "regrowth should carry on from where it left off")
+}
+
+func TestSnapshot_RoundTripCarryCapacity(t *testing.T) {
+	logger := logging.NewLogger("error")
+	registry := attributes.NewRegistry()
+
+	original, err := scenario.Load("../../scenarios/hauling.yaml", logger)
+	require.NoError(t, err)
+	tickEngine(t, original, 600)
+
+	taken, err := Take(original, registry)
+	require.NoError(t, err)
+	require.Len(t, taken.Agents, 1)
+	assert.Equal(t, 6.0, taken.Agents[0].CarryCapacity)
+
+	restored, err := taken.Restore(registry, logger)
+	require.NoError(t, err)
+	retaken, err := Take(restored, registry)
+	require.NoError(t, err)
+	assert.Equal(t, taken, retaken)
+
+	tickEngine(t, original, 1200)
+	tickEngine(t, restored, 1200)
+
+	originalID, err := original.World.ID()
+	require.NoError(t, err)
+	restoredID, err := restored.World.ID()
+	require.NoError(t, err)
+	assert.Equal(t, originalID, restoredID)
+
+	depo, ok := original.World.GetLocation("depo")
+	require.True(t, ok)
+	stone, ok := original.Registry.Resource("Stone")
+	require.True(t, ok)
+	assert.Positive(t, depo.Inventory.GetAmount(stone), "the villager should have hauled stone to the deposit")
+	villager, _ := original.World.GetAgent("villager")
+	assert.LessOrEqual(t, core.InventoryWeight(villager.Inventory()), 6.0, "the villager should never carry more than it can")
+}
+
+func TestSnapshot_RoundTripTerrain(t *testing.T) {
+	logger := logging.NewLogger("error")
+	registry := attributes.NewRegistry()
+
+	original, err := scenario.Load("../../scenarios/lake.yaml", logger)
+	require.NoError(t, err)
+	tickEngine(t, original, 300)
+
+	taken, err := Take(original, registry)
+	require.NoError(t, err)
+	require.Len(t, taken.Grid.Terrain, taken.Grid.Height)
+	assert.Equal(t, byte('w'), taken.Grid.Terrain[10][10])
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, taken))
+	read, err := Read(&buf)
+	require.NoError(t, err)
+	restored, err := read.Restore(registry, logger)
+	require.NoError(t, err)
+	retaken, err := Take(restored, registry)
+	require.NoError(t, err)
+	assert.Equal(t, taken, retaken)
+
+	tickEngine(t, original, 600)
+	tickEngine(t, restored, 600)
+
+	originalID, err := original.World.ID()
+	require.NoError(t, err)
+	restoredID, err := restored.World.ID()
+	require.NoError(t, err)
+	assert.Equal(t, originalID, restoredID)
+
+	grassOnly, err := scenario.Default(logger)
+	require.NoError(t, err)
+	taken, err = Take(grassOnly, registry)
+	require.NoError(t, err)
+	assert.Nil(t, taken.Grid.Terrain, "an all grass grid should be left out")
+}
+
+func TestSnapshot_RoundTripAccess(t *testing.T) {
+	logger := logging.NewLogger("error")
+	registry := attributes.NewRegistry()
+
+	original, err := scenario.Load("../../scenarios/access.yaml", logger)
+	require.NoError(t, err)
+	tickEngine(t, original, 450)
+
+	taken, err := Take(original, registry)
+	require.NoError(t, err)
+	require.Len(t, taken.Locations, 2)
+	assert.Equal(t, 2, taken.Locations[0].AccessRadius)
+	assert.Equal(t, []core.Coord{{X: 0, Y: 2}}, taken.Locations[1].AccessTiles)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, taken))
+	read, err := Read(&buf)
+	require.NoError(t, err)
+	restored, err := read.Restore(registry, logger)
+	require.NoError(t, err)
+	retaken, err := Take(restored, registry)
+	require.NoError(t, err)
+	assert.Equal(t, taken, retaken)
+
+	tickEngine(t, original, 600)
+	tickEngine(t, restored, 600)
+
+	originalID, err := original.World.ID()
+	require.NoError(t, err)
+	restoredID, err := restored.World.ID()
+	require.NoError(t, err)
+	assert.Equal(t, originalID, restoredID)
+}
+
+func TestRead(t *testing.T) {
+	type testCase struct {
+		input     string
+		expectErr error
+	}
+
+	tests := map[string]testCase{
+		"unsupported version": {
+			input:     `{"version": 99}`,
+			expectErr: ErrUnsupportedVersion,
+		},
+		"missing version": {
+			input:     `{"grid": {"width": 2, "height": 2, "cell_size": 16}}`,
+			expectErr: ErrUnsupportedVersion,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tc.input))
+			assert.ErrorIs(t, err, tc.expectErr)
+		})
+	}
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := Read(strings.NewReader(`{`))
+		assert.Error(t, err)
+	})
+}
+
+func TestSnapshot_Restore(t *testing.T) {
+	logger := logging.NewLogger("error")
+	registry := attributes.NewRegistry()
+
+	type testCase struct {
+		snapshot  *Snapshot
+		expectErr error
+	}
+
+	tests := map[string]testCase{
+		"unsupported version": {
+			snapshot:  &Snapshot{Version: 99},
+			expectErr: ErrUnsupportedVersion,
+		},
+		"unknown action in plan": {
+			snapshot: &Snapshot{
+				Version: Version,
+				Grid:    GridSnapshot{Width: 4, Height: 4, CellSize: 16},
+				Agents: []AgentSnapshot{{
+					Name:  "agent",
+					State: agent.StateSnapshot{Kind: agent.IdleStateKind},
+					Plan:  &PlanSnapshot{Actions: []ActionRef{{Type: "*attributes.Gather", Location: "nowhere"}}},
+				}},
+			},
+			expectErr: ErrUnknownAction,
+		},
+		"unknown terrain symbol": {
+			snapshot: &Snapshot{
+				Version: Version,
+				Grid:    GridSnapshot{Width: 2, Height: 2, CellSize: 16, Terrain: []string{"gw", "g?"}},
+			},
+			expectErr: world.ErrUnknownTerrain,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := tc.snapshot.Restore(registry, logger)
+			assert.ErrorIs(t, err, tc.expectErr)
+		})
+	}
+}
@@ -2,18 +2,19 @@ package world
 
 import (
 	"errors"
-	"image/color"
 	"log/slog"
 
 	"Neolithic/internal/agent"
-	"Neolithic/internal/camera"
 	"Neolithic/internal/core"
 	"Neolithic/internal/grid"
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
-// cellSize is the size of the cells in the world grid
-const cellSize = 16
+const (
+	// cellSize is the size of the cells in the world grid
+	cellSize = 16
+	// DefaultSeed is the seed used by engines that aren't given one
+	DefaultSeed int64 = 1
+)
 
 var (
 	// ErrAgentAlreadyExists is thrown when an agent with a duplicate name is added to the world.
@@ -22,61 +23,86 @@ var (
 	ErrLocationAlreadyExists = errors.New("location already exists")
 )
 
-// Engine is the main struct that holds the world state and the images for the villager and location.
+// Engine is the main struct that holds the world state. It has no rendering dependencies, so it can be run headless;
+// drawing is handled separately by the render package.
 type Engine struct {
 	// World is the main world state
 	World *core.WorldState
 	// Registry holds all actions, resources, and locations and creates actions when new resources and locations are provided.
 	Registry *Registry
-	// villagerImage is the sprite used to represent a villager
-	villagerImage *ebiten.Image
-	// locationImage is the sprite used to represent a location
-	locationImage *ebiten.Image
+	// elapsed is the simulated time, in seconds, that the engine has been ticked for
+	elapsed float64
+	// seed is the seed of the simulation
+	seed int64
 	// logger is the logger
 	logger *slog.Logger
 }
 
-// NewEngine creates a new Engine.
-func NewEngine(grid *grid.Grid, logger *slog.Logger) (*Engine, error) {
-	villagerImg := ebiten.NewImage(8, 8)
-	villagerImg.Fill(color.RGBA{
-		R: 70,
-		G: 80,
-		B: 100,
-		A: 255,
-	})
-
-	locationImg := ebiten.NewImage(10, 10)
-	locationImg.Fill(color.RGBA{
-		R: 170,
-		G: 80,
-		B: 20,
-		A: 255,
-	})
+// EngineOption is a functional option type for configuring a new Engine.
+type EngineOption func(*Engine)
+
+// WithSeed is an EngineOption that sets the seed of the simulation.
+func WithSeed(seed int64) EngineOption {
+	return func(e *Engine) {
+		e.seed = seed
+	}
+}
+
+// WithElapsed is an EngineOption that sets the simulated time the engine has already been ticked for, such as when
+// resuming a simulation.
+func WithElapsed(elapsed float64) EngineOption {
+	return func(e *Engine) {
+		e.elapsed = elapsed
+	}
+}
 
+// NewEngine creates a new Engine. Unless a seed is given, the engine is seeded with DefaultSeed.
+func NewEngine(grid *grid.Grid, logger *slog.Logger, opts ...EngineOption) (*Engine, error) {
 	world := &core.WorldState{
 		Grid:      grid,
 		Locations: map[string]*core.Location{},
 		Agents:    map[string]core.Agent{},
 	}
 
-	return &Engine{
+	engine := &Engine{
 		World: world,
 		Registry: &Registry{
 			Actions:   []core.Action{},
 			Locations: []*core.Location{},
 			Resources: []*core.Resource{},
 		},
-		villagerImage: villagerImg,
-		locationImage: locationImg,
-		logger:        logger,
-	}, nil
+		seed:   DefaultSeed,
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(engine)
+	}
+	return engine, nil
 }
 
-// Tick ticks the world state. Iterates through all agents and allows them to run their behavior based on their current state and the world state.
+// Seed returns the seed of the simulation.
+func (e *Engine) Seed() int64 {
+	return e.seed
+}
+
+// Elapsed returns the simulated time, in seconds, that the engine has been ticked for.
+func (e *Engine) Elapsed() float64 {
+	return e.elapsed
+}
+
+// Tick ticks the world state. Locations regenerate first, then it iterates through all agents in order of name and
+// allows them to run their behavior based on their current state and the world state. Each agent is taken from the
+// latest world state, so it sees the changes made by the agents before it.
 func (e *Engine) Tick(deltaTime float64) error {
 	e.logger.Debug("engine tick", "deltaTime", deltaTime)
-	for _, a := range e.World.Agents {
+	e.regenerate(deltaTime)
+	e.elapsed += deltaTime
+
+	for _, name := range e.World.AgentNames() {
+		a, ok := e.World.GetAgent(name)
+		if !ok {
+			continue
+		}
 		aStruct := a.(*agent.Agent)
 		newWorld, err := aStruct.Tick(e.World, deltaTime)
 		if err != nil {
@@ -91,35 +117,41 @@ func (e *Engine) Tick(deltaTime float64) error {
 	return nil
 }
 
-// Draw draws the world state on the screen
-func (e *Engine) Draw(screen *ebiten.Image, viewport *camera.Viewport, camera *camera.Camera) {
-	e.World.Grid.(*grid.Grid).Draw(screen, viewport, camera)
-	transform := viewport.GetTransform()
-
-	for _, l := range e.World.Locations {
-		DrawEntity(screen, &transform, 16, e.locationImage, l.Coord)
+// regenerate applies the core.RegeneratingAttributes of every location, in order of name, for deltaTime seconds. A
+// location that changes is replaced by a copy, so that earlier world states are left as they were.
+func (e *Engine) regenerate(deltaTime float64) {
+	var regenerated *core.WorldState
+	for _, name := range e.World.LocationNames() {
+		loc := e.World.Locations[name]
+		var endLoc *core.Location
+		for _, attr := range loc.Attributes().List() {
+			regenerating, ok := attr.(core.RegeneratingAttribute)
+			if !ok {
+				continue
+			}
+			for _, entry := range regenerating.Regenerate(loc, e.Registry.Resource, e.elapsed, deltaTime) {
+				if entry.Amount == 0 {
+					continue
+				}
+				if endLoc == nil {
+					endLoc = loc.DeepCopy()
+				}
+				endLoc.Inventory.AdjustAmount(entry.Resource, entry.Amount)
+			}
+		}
+		if endLoc == nil {
+			continue
+		}
+		if regenerated == nil {
+			regenerated = e.World.ShallowCopy()
+		}
+		regenerated.Locations[name] = endLoc
 	}
-	for _, a := range e.World.Agents {
-		DrawEntity(screen, &transform, 16, e.villagerImage, a.(*agent.Agent).Position)
+	if regenerated != nil {
+		e.World = regenerated
 	}
 }
 
-// DrawEntity draws an entity on the screen at a given position. Entity can be an agent or a location
-func DrawEntity(screen *ebiten.Image, transform *ebiten.GeoM, cellSize int, entityImg *ebiten.Image, position core.Coord) {
-	size := entityImg.Bounds().Size().X // assuming villager is square
-	worldX := float64(position.X*cellSize + size/2)
-	worldY := float64(position.Y*cellSize + size/2)
-
-	var cellTransform ebiten.GeoM
-	cellTransform.Reset()
-	cellTransform.Translate(worldX, worldY)
-	cellTransform.Concat(*transform)
-
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM = cellTransform
-	screen.DrawImage(entityImg, op)
-}
-
 // AddLocation adds a new location to the world and registers it in the registry. It returns an error if registration fails.
 func (e *Engine) AddLocation(location *core.Location) error {
 	_, exists := e.World.GetLocation(location.Name)
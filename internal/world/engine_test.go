@@ -50,8 +50,6 @@ func TestNewEngine(t *testing.T) {
 			assert.NoError(t, err)
 			assert.NotNil(t, engine)
 			assert.NotNil(t, engine.World)
-			assert.NotNil(t, engine.villagerImage)
-			assert.NotNil(t, engine.locationImage)
 			assert.NotNil(t, engine.logger)
 			assert.Equal(t, tc.expectedState.Locations, engine.World.Locations)
 			assert.Equal(t, tc.expectedState.Agents, engine.World.Agents)
@@ -79,10 +77,8 @@ func TestEngine_Tick(t *testing.T) {
 	normalAgent.Behavior.CurState = agent.NewIdle(normalAgent, logger)
 
 	errorAgent := agent.NewAgent("error", logger)
-	moveState := agent.NewMoving(errorAgent, logger)
-	moveState.Target = &core.Coord{X: -5, Y: -5}
-	errorAgent.Behavior.CurState = moveState
-	errorAgent.Behavior.CurPlan = &agent.MockPlan{Complete: false}
+	errorAgent.Behavior.CurState = agent.NewPerforming(errorAgent, logger)
+	errorAgent.Behavior.CurPlan = &agent.MockPlan{Complete: false, NextAction: &mockVanishingAction{}}
 
 	stateChangeAgent := agent.NewAgent("stateChange", logger)
 	stateChangeAgent.Behavior.CurState = agent.NewPerforming(stateChangeAgent, logger)
@@ -133,7 +129,7 @@ func TestEngine_Tick(t *testing.T) {
 			agents: map[string]core.Agent{
 				errorAgent.Name(): errorAgent,
 			},
-			expectedError: errors.New("heuristic called on non-Tile"),
+			expectedError: errors.New("agent does not exist in deep copied world"),
 		},
 	}
 
@@ -166,3 +162,93 @@ func TestEngine_Tick(t *testing.T) {
 		})
 	}
 }
+
+func TestEngine_Seed(t *testing.T) {
+	type testCase struct {
+		opts         []EngineOption
+		expectedSeed int64
+	}
+
+	tests := map[string]testCase{
+		"default seed": {
+			expectedSeed: DefaultSeed,
+		},
+		"given seed": {
+			opts:         []EngineOption{WithSeed(42)},
+			expectedSeed: 42,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			worldGrid, err := grid.New(2, 2, cellSize)
+			assert.NoError(t, err)
+			assert.NoError(t, worldGrid.Initialize(testMakeTile))
+
+			engine, err := NewEngine(worldGrid, logging.NewLogger("error"), tc.opts...)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectedSeed, engine.Seed())
+		})
+	}
+}
+
+func TestEngine_TickRegenerates(t *testing.T) {
+	type testCase struct {
+		amount         int
+		resource       string
+		expectedAmount int
+		expectChanged  bool
+	}
+
+	tests := map[string]testCase{
+		"regenerates": {
+			amount:         3,
+			resource:       "berries",
+			expectedAmount: 8,
+			expectChanged:  true,
+		},
+		"nothing regenerates": {
+			resource:       "berries",
+			expectedAmount: 5,
+		},
+		"unknown resource": {
+			amount:         3,
+			resource:       "wood",
+			expectedAmount: 5,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			worldGrid, err := grid.New(2, 2, cellSize)
+			assert.NoError(t, err)
+			assert.NoError(t, worldGrid.Initialize(testMakeTile))
+			engine, err := NewEngine(worldGrid, logging.NewLogger("error"), WithElapsed(10))
+			assert.NoError(t, err)
+
+			berries := core.NewResource("berries")
+			assert.NoError(t, engine.AddResource(berries))
+			regrowth := &mockRegeneratingAttribute{mockAttribute: mockAttribute{attrType: "regrowth"}, resource: tc.resource, amount: tc.amount}
+			bush := core.NewLocation("bush", core.Coord{},
+				core.WithInventory(core.InventoryEntry{Resource: berries, Amount: 5}),
+				core.WithAttributes(regrowth),
+			)
+			assert.NoError(t, engine.AddLocation(bush))
+			before := engine.World
+
+			assert.NoError(t, engine.Tick(0.5))
+
+			assert.Equal(t, 10.5, engine.Elapsed())
+			loc, ok := engine.World.GetLocation("bush")
+			assert.True(t, ok)
+			assert.Equal(t, tc.expectedAmount, loc.Inventory.GetAmount(berries))
+			assert.Equal(t, 5, bush.Inventory.GetAmount(berries), "the location should be copied, not changed")
+			if tc.expectChanged {
+				assert.NotSame(t, before, engine.World)
+			} else {
+				assert.Same(t, before, engine.World)
+			}
+		})
+	}
+}
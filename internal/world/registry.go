@@ -3,6 +3,7 @@ package world
 import (
 	"Neolithic/internal/core"
 	"errors"
+	"slices"
 )
 
 var (
@@ -64,7 +65,7 @@ func (r *Registry) RegisterResource(resource *core.Resource) error {
 
 	for _, loc := range r.Locations {
 		for _, locAttr := range loc.Attributes().List() {
-			if locAttr.NeedsResource() {
+			if locAttr.NeedsResource() && !isResourceList(locAttr) {
 				switch {
 				case locAttr.NeedsLocation():
 					for _, secondLoc := range r.Locations {
@@ -88,7 +89,7 @@ func (r *Registry) RegisterResource(resource *core.Resource) error {
 
 	for _, res := range r.Resources {
 		for _, resAttr := range res.Attributes().List() {
-			if resAttr.NeedsResource() {
+			if resAttr.NeedsResource() && !isResourceList(resAttr) {
 				switch {
 				case resAttr.NeedsLocation():
 					for _, loc := range r.Locations {
@@ -110,6 +111,10 @@ func (r *Registry) RegisterResource(resource *core.Resource) error {
 		}
 	}
 
+	if err := r.createResourceListActions(resource); err != nil {
+		return err
+	}
+
 	r.Resources = append(r.Resources, resource)
 
 	return nil
@@ -132,7 +137,7 @@ func (r *Registry) RegisterLocation(location *core.Location) error {
 
 	for _, loc := range r.Locations {
 		for _, locAttr := range loc.Attributes().List() {
-			if locAttr.NeedsLocation() {
+			if locAttr.NeedsLocation() && !isResourceList(locAttr) {
 				switch {
 				case locAttr.NeedsResource():
 					for _, res := range r.Resources {
@@ -156,7 +161,7 @@ func (r *Registry) RegisterLocation(location *core.Location) error {
 
 	for _, res := range r.Resources {
 		for _, resAttr := range res.Attributes().List() {
-			if resAttr.NeedsLocation() {
+			if resAttr.NeedsLocation() && !isResourceList(resAttr) {
 				switch {
 				case resAttr.NeedsResource():
 					for _, res2 := range r.Resources {
@@ -179,12 +184,44 @@ func (r *Registry) RegisterLocation(location *core.Location) error {
 		}
 	}
 
+	for _, holder := range r.holders() {
+		for _, attr := range holder.Attributes().List() {
+			listAttr, ok := attr.(core.ResourceListAttribute)
+			if !ok || !listAttr.NeedsLocation() {
+				continue
+			}
+			if err := r.createResourceListAction(holder, listAttr, nil, []*core.Location{location}); err != nil {
+				return err
+			}
+		}
+	}
+
 	r.Locations = append(r.Locations, location)
 	return nil
 }
 
+// Resource returns the registered resource with the given name, or false if there is none.
+func (r *Registry) Resource(name string) (*core.Resource, bool) {
+	idx := slices.IndexFunc(r.Resources, func(res *core.Resource) bool { return res.Name == name })
+	if idx < 0 {
+		return nil, false
+	}
+	return r.Resources[idx], true
+}
+
 // createActionsForAttribute creates every action that an attribute requires, based on the needs of that attribute.
 func (r *Registry) createActionsForAttribute(holder core.AttributeHolder, attr core.Attribute) error {
+	if listAttr, ok := attr.(core.ResourceListAttribute); ok {
+		// the holder is being registered, so it isn't in the registry yet; a resource may name itself, and a location
+		// is one of the locations to create the action at
+		extra, _ := holder.(*core.Resource)
+		locations := r.Locations
+		if loc, ok := holder.(*core.Location); ok {
+			locations = append(slices.Clip(r.Locations), loc)
+		}
+		return r.createResourceListAction(holder, listAttr, extra, locations)
+	}
+
 	switch {
 	case attr.NeedsResource() && attr.NeedsLocation():
 		for _, loc := range r.Locations {
@@ -219,6 +256,69 @@ func (r *Registry) createActionsForAttribute(holder core.AttributeHolder, attr c
 	return nil
 }
 
+// createResourceListActions creates the actions of the ResourceListAttributes that were waiting on the newly
+// registered resource, now that it completes the resources they name.
+func (r *Registry) createResourceListActions(resource *core.Resource) error {
+	for _, holder := range r.holders() {
+		for _, attr := range holder.Attributes().List() {
+			listAttr, ok := attr.(core.ResourceListAttribute)
+			if !ok || !slices.Contains(listAttr.ResourceNames(), resource.Name) {
+				continue
+			}
+			if err := r.createResourceListAction(holder, listAttr, resource, r.Locations); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createResourceListAction creates the action of a ResourceListAttribute if every resource it names is registered,
+// at each of the given locations if the attribute needs a location. extra is a resource that is being registered but
+// isn't in the registry yet, or nil.
+func (r *Registry) createResourceListAction(holder core.AttributeHolder, attr core.ResourceListAttribute, extra *core.Resource, locations []*core.Location) error {
+	resources := map[string]*core.Resource{}
+	for _, name := range attr.ResourceNames() {
+		if extra != nil && extra.Name == name {
+			resources[name] = extra
+			continue
+		}
+		res, ok := r.Resource(name)
+		if !ok {
+			return nil // created once the missing resource is registered
+		}
+		resources[name] = res
+	}
+
+	if !attr.NeedsLocation() {
+		return r.createAndAddAction(holder, attr, core.CreateActionParams{Resources: resources})
+	}
+	for _, loc := range locations {
+		if err := r.createAndAddAction(holder, attr, core.CreateActionParams{Location: loc, Resources: resources}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// holders returns every registered location and resource, in that order.
+func (r *Registry) holders() []core.AttributeHolder {
+	holders := make([]core.AttributeHolder, 0, len(r.Locations)+len(r.Resources))
+	for _, loc := range r.Locations {
+		holders = append(holders, loc)
+	}
+	for _, res := range r.Resources {
+		holders = append(holders, res)
+	}
+	return holders
+}
+
+// isResourceList reports whether the attribute is a core.ResourceListAttribute, whose actions are created separately.
+func isResourceList(attr core.Attribute) bool {
+	_, ok := attr.(core.ResourceListAttribute)
+	return ok
+}
+
 // createAndAddAction creates a specific action and adds it to the registry
 func (r *Registry) createAndAddAction(holder core.AttributeHolder, attribute core.Attribute, params core.CreateActionParams) error {
 	action, err := attribute.CreateAction(holder, params)
@@ -366,3 +366,135 @@ func TestRegistry_RegisterLocation(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistry_ResourceListAttribute(t *testing.T) {
+	listAttr := &mockResourceListAttribute{mockAttribute: mockAttribute{attrType: "list"}, names: []string{"a", "b"}}
+
+	type testCase struct {
+		register        []any
+		expectedCreated bool
+	}
+
+	tests := map[string]testCase{
+		"resources before location": {
+			register: []any{
+				core.NewResource("a"),
+				core.NewResource("b"),
+				core.NewLocation("workshop", core.Coord{}, core.WithAttributes(listAttr)),
+			},
+			expectedCreated: true,
+		},
+		"location before resources": {
+			register: []any{
+				core.NewResource("a"),
+				core.NewLocation("workshop", core.Coord{}, core.WithAttributes(listAttr)),
+				core.NewResource("b"),
+			},
+			expectedCreated: true,
+		},
+		"missing resource": {
+			register: []any{
+				core.NewLocation("workshop", core.Coord{}, core.WithAttributes(listAttr)),
+				core.NewResource("a"),
+				core.NewResource("c"),
+			},
+		},
+		"resource names itself": {
+			register: []any{
+				core.NewResource("a"),
+				core.NewResource("b", core.WithResourceAttributes(listAttr)),
+			},
+			expectedCreated: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &Registry{}
+			for _, item := range tc.register {
+				switch v := item.(type) {
+				case *core.Resource:
+					require.NoError(t, r.RegisterResource(v))
+				case *core.Location:
+					require.NoError(t, r.RegisterLocation(v))
+				}
+			}
+
+			if !tc.expectedCreated {
+				require.Empty(t, r.Actions)
+				return
+			}
+			require.Len(t, r.Actions, 1, "action should be created exactly once")
+			action, ok := r.Actions[0].(*mockResourceListAction)
+			require.True(t, ok)
+			require.Len(t, action.resources, 2)
+			for _, res := range r.Resources {
+				if expected, ok := action.resources[res.Name]; ok {
+					require.Same(t, res, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistry_ResourceListAttributeNeedsLocation(t *testing.T) {
+	listAttr := &mockResourceListAttribute{
+		mockAttribute: mockAttribute{attrType: "list", requiresLocation: true},
+		names:         []string{"tool"},
+	}
+
+	type testCase struct {
+		register          []any
+		expectedLocations []string
+	}
+
+	tests := map[string]testCase{
+		"locations before resources": {
+			register: []any{
+				core.NewLocation("forest", core.Coord{}),
+				core.NewLocation("grove", core.Coord{}),
+				core.NewResource("tool"),
+				core.NewResource("wood", core.WithResourceAttributes(listAttr)),
+			},
+			expectedLocations: []string{"forest", "grove"},
+		},
+		"locations after resources": {
+			register: []any{
+				core.NewResource("wood", core.WithResourceAttributes(listAttr)),
+				core.NewLocation("forest", core.Coord{}),
+				core.NewResource("tool"),
+				core.NewLocation("grove", core.Coord{}),
+			},
+			expectedLocations: []string{"forest", "grove"},
+		},
+		"missing resource": {
+			register: []any{
+				core.NewResource("wood", core.WithResourceAttributes(listAttr)),
+				core.NewLocation("forest", core.Coord{}),
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &Registry{}
+			for _, item := range tc.register {
+				switch v := item.(type) {
+				case *core.Resource:
+					require.NoError(t, r.RegisterResource(v))
+				case *core.Location:
+					require.NoError(t, r.RegisterLocation(v))
+				}
+			}
+
+			locations := make([]string, 0, len(r.Actions))
+			for _, action := range r.Actions {
+				listAction, ok := action.(*mockResourceListAction)
+				require.True(t, ok)
+				require.NotNil(t, listAction.location)
+				locations = append(locations, listAction.location.Name)
+			}
+			require.ElementsMatch(t, tc.expectedLocations, locations, "action should be created once per location")
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+
+	"Neolithic/internal/core"
+	"Neolithic/internal/grid"
+)
+
+// Terrain is the kind of ground a Tile is made of. It decides how costly the tile is to move onto, and whether it can
+// be moved onto at all.
+type Terrain string
+
+const (
+	// Grass is open ground, the cheapest terrain to cross
+	Grass Terrain = "grass"
+	// Forest is wooded ground, slower to cross than grass
+	Forest Terrain = "forest"
+	// Marsh is wet ground, slow to cross
+	Marsh Terrain = "marsh"
+	// Rock is rocky ground, the slowest terrain that can still be crossed
+	Rock Terrain = "rock"
+	// Water is open water, which can't be crossed
+	Water Terrain = "water"
+)
+
+// ErrUnknownTerrain is returned when parsing the name of a terrain that doesn't exist.
+var ErrUnknownTerrain = errors.New("unknown terrain")
+
+// TerrainProperties are the properties a Terrain gives the tiles made of it.
+type TerrainProperties struct {
+	// MoveCost scales the cost of moving onto a tile. It is never less than 1, so that the distance between two tiles
+	// stays an admissible heuristic for the cost of moving between them.
+	MoveCost float64
+	// Passable indicates whether agents can move onto a tile
+	Passable bool
+	// Symbol is the character that stands for the terrain in a compact map of the terrain of a grid
+	Symbol byte
+}
+
+// terrains holds the properties of every Terrain, in the order they are listed by Terrains.
+var terrains = []struct {
+	terrain    Terrain
+	properties TerrainProperties
+}{
+	{Grass, TerrainProperties{MoveCost: 1, Passable: true, Symbol: 'g'}},
+	{Forest, TerrainProperties{MoveCost: 1.5, Passable: true, Symbol: 'f'}},
+	{Marsh, TerrainProperties{MoveCost: 2.5, Passable: true, Symbol: 'm'}},
+	{Rock, TerrainProperties{MoveCost: 3, Passable: true, Symbol: 'r'}},
+	{Water, TerrainProperties{MoveCost: 1, Passable: false, Symbol: 'w'}},
+}
+
+// Terrains returns every Terrain, starting with Grass.
+func Terrains() []Terrain {
+	list := make([]Terrain, len(terrains))
+	for i, entry := range terrains {
+		list[i] = entry.terrain
+	}
+	return list
+}
+
+// Properties returns the properties of the Terrain. Unknown terrains, including the zero Terrain, have the properties
+// of Grass.
+func (t Terrain) Properties() TerrainProperties {
+	for _, entry := range terrains {
+		if entry.terrain == t {
+			return entry.properties
+		}
+	}
+	return terrains[0].properties
+}
+
+// ParseTerrain returns the Terrain with the given name, or ErrUnknownTerrain if there is none.
+func ParseTerrain(name string) (Terrain, error) {
+	for _, entry := range terrains {
+		if string(entry.terrain) == name {
+			return entry.terrain, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownTerrain, name)
+}
+
+// TerrainBySymbol returns the Terrain that the symbol stands for, or false if there is none.
+func TerrainBySymbol(symbol byte) (Terrain, bool) {
+	for _, entry := range terrains {
+		if entry.properties.Symbol == symbol {
+			return entry.terrain, true
+		}
+	}
+	return "", false
+}
+
+// MakeTerrainTile returns a function that makes tiles for grid.Grid.Initialize, with the Terrain given by terrainAt for
+// each coordinate.
+func MakeTerrainTile(terrainAt func(x, y int) Terrain) func(X, Y int, grid *grid.Grid) (grid.Tile, error) {
+	return func(X, Y int, grid *grid.Grid) (grid.Tile, error) {
+		return &Tile{
+			X:       X,
+			Y:       Y,
+			Terrain: terrainAt(X, Y),
+			grid:    grid,
+		}, nil
+	}
+}
+
+// TerrainAt returns the Terrain of the tile at the given coordinate of the grid, or false if the coordinate is outside
+// the grid or the tile isn't a Tile.
+func TerrainAt(g *grid.Grid, coord core.Coord) (Terrain, bool) {
+	tile, ok := g.CellAt(coord).(*Tile)
+	if !ok {
+		return "", false
+	}
+	return tile.Terrain, true
+}
@@ -0,0 +1,56 @@
+package world
+
+import (
+	"testing"
+
+	"Neolithic/internal/core"
+	"Neolithic/internal/grid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTerrain(t *testing.T) {
+	for _, terrain := range Terrains() {
+		parsed, err := ParseTerrain(string(terrain))
+		require.NoError(t, err)
+		assert.Equal(t, terrain, parsed)
+
+		bySymbol, ok := TerrainBySymbol(terrain.Properties().Symbol)
+		require.True(t, ok, "symbol of %s", terrain)
+		assert.Equal(t, terrain, bySymbol)
+
+		assert.GreaterOrEqual(t, terrain.Properties().MoveCost, 1.0, "move cost of %s", terrain)
+	}
+
+	_, err := ParseTerrain("lava")
+	assert.ErrorIs(t, err, ErrUnknownTerrain)
+	_, ok := TerrainBySymbol('?')
+	assert.False(t, ok)
+}
+
+func TestTerrain_Properties(t *testing.T) {
+	assert.True(t, Grass.Properties().Passable)
+	assert.False(t, Water.Properties().Passable)
+	assert.Greater(t, Forest.Properties().MoveCost, Grass.Properties().MoveCost)
+	assert.Equal(t, Grass.Properties(), Terrain("").Properties(), "the zero terrain should be grass")
+}
+
+func TestTerrainAt(t *testing.T) {
+	testGrid, err := grid.New(3, 2, 1)
+	require.NoError(t, err)
+	require.NoError(t, testGrid.Initialize(MakeTerrainTile(func(x, y int) Terrain {
+		if x == 1 && y == 1 {
+			return Rock
+		}
+		return Grass
+	})))
+
+	terrain, ok := TerrainAt(testGrid, core.Coord{X: 1, Y: 1})
+	require.True(t, ok)
+	assert.Equal(t, Rock, terrain)
+	terrain, ok = TerrainAt(testGrid, core.Coord{X: 0, Y: 1})
+	require.True(t, ok)
+	assert.Equal(t, Grass, terrain)
+	_, ok = TerrainAt(testGrid, core.Coord{X: 3, Y: 0})
+	assert.False(t, ok)
+}
@@ -33,6 +33,17 @@ func (m *mockAction) GetChanges(_ core.Agent) []core.StateChange {
 	return nil
 }
 
+// mockVanishingAction is a mockAction whose resulting world no longer has the agent performing it
+type mockVanishingAction struct {
+	mockAction
+}
+
+func (m *mockVanishingAction) Perform(world *core.WorldState, agent core.Agent) *core.WorldState {
+	end := world.DeepCopy()
+	delete(end.Agents, agent.Name())
+	return end
+}
+
 type mockActionWithLocationAndResource struct {
 	mockAction
 	resource *core.Resource
@@ -88,3 +99,50 @@ var (
 	mockAttributeNeedsRes  = createTestAttribute(false, true, &mockAction{}, "needsRes")
 	mockAttributeNeedsBoth = createTestAttribute(true, true, &mockAction{}, "needsBoth")
 )
+
+// mockResourceListAction is created by mockResourceListAttribute, and records the resources and location it was
+// created with.
+type mockResourceListAction struct {
+	mockAction
+	resources map[string]*core.Resource
+	location  *core.Location
+}
+
+// mockResourceListAttribute implements core.ResourceListAttribute, and is used for testing.
+type mockResourceListAttribute struct {
+	mockAttribute
+	names []string
+}
+
+var _ core.ResourceListAttribute = (*mockResourceListAttribute)(nil)
+
+func (m *mockResourceListAttribute) ResourceNames() []string { return m.names }
+func (m *mockResourceListAttribute) CreateAction(_ core.AttributeHolder, params core.CreateActionParams) (core.Action, error) {
+	return &mockResourceListAction{resources: params.Resources, location: params.Location}, nil
+}
+func (m *mockResourceListAttribute) Copy() core.Attribute {
+	return &mockResourceListAttribute{mockAttribute: m.mockAttribute, names: m.names}
+}
+
+// mockRegeneratingAttribute implements core.RegeneratingAttribute, and adds amount of the named resource every tick.
+type mockRegeneratingAttribute struct {
+	mockAttribute
+	resource string
+	amount   int
+}
+
+var _ core.RegeneratingAttribute = (*mockRegeneratingAttribute)(nil)
+
+func (m *mockRegeneratingAttribute) CreateAction(_ core.AttributeHolder, _ core.CreateActionParams) (core.Action, error) {
+	return nil, nil
+}
+func (m *mockRegeneratingAttribute) Regenerate(_ *core.Location, resource func(name string) (*core.Resource, bool), _, _ float64) []core.InventoryEntry {
+	res, ok := resource(m.resource)
+	if !ok {
+		return nil
+	}
+	return []core.InventoryEntry{{Resource: res, Amount: m.amount}}
+}
+func (m *mockRegeneratingAttribute) Copy() core.Attribute {
+	return &mockRegeneratingAttribute{mockAttribute: m.mockAttribute, resource: m.resource, amount: m.amount}
+}
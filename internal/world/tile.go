@@ -8,26 +8,22 @@ import (
 
 	"Neolithic/internal/astar"
 	"Neolithic/internal/grid"
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
 // Tile implements grid.Tile, and represents a single square of ground
 type Tile struct {
-	Ground *Ground
-	X, Y   int
-	grid   *grid.Grid
+	X, Y int
+	// Terrain is the kind of ground the tile is made of. The zero Terrain is treated as Grass.
+	Terrain Terrain
+	grid    *grid.Grid
+	// successors are the tile's successors as of successorsVersion of the grid's terrain, or nil if not yet found
+	successors        []astar.Node
+	successorsVersion int
 }
 
 // Ensure Tile implements grid.Tile
 var _ grid.Tile = (*Tile)(nil)
 
-// Draw draws a tile on the screen
-func (t *Tile) Draw(screen *ebiten.Image, transform *ebiten.GeoM) {
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM = *transform
-	screen.DrawImage(t.Ground.Image, op)
-}
-
 // Heuristic implements astar.Node and provides a best guess for how far the tile is from the goal tile. Calculates
 // based on distance as the crow flies.
 func (t *Tile) Heuristic(goal astar.Node) (float64, error) {
@@ -47,42 +43,93 @@ func (t *Tile) ID() (string, error) {
 	return fmt.Sprintf("%d,%d", t.X, t.Y), nil
 }
 
-// Cost implements astar.Node and returns the cost for moving onto the node. It returns 1 if the node is adjacent and
-// 1.4 if the node is diagonal.
+// Cost implements astar.Node and returns the cost for moving onto the node. It is 1 if the node is adjacent and 1.4 if
+// the node is diagonal, scaled by the MoveCost of the node's Terrain.
 func (t *Tile) Cost(prev astar.Node) float64 {
 	prevTile := prev.(*Tile)
+	moveCost := t.MoveCost()
 	if isDiagonallyAdjacent(prevTile, t) {
-		return 1.4
+		return 1.4 * moveCost
 	}
-	return 1
+	return moveCost
 }
 
-// GetSuccessors implements astar.Node and returns the nodes that are adjacent to the given node.
-func (t *Tile) GetSuccessors() ([]astar.Node, error) {
-	directions := []struct{ dx, dy int }{
-		{-1, -1}, {-1, 0}, {-1, 1}, // Top-left, Top, Top-right
-		{0, -1}, {0, 1}, // Left,        Right
-		{1, -1}, {1, 0}, {1, 1}, // Bottom-left, Bottom, Bottom-right
+// MoveCost returns how costly the tile is to move onto, as decided by its Terrain.
+func (t *Tile) MoveCost() float64 {
+	return t.Terrain.Properties().MoveCost
+}
+
+// Passable returns whether agents can move onto the tile, as decided by its Terrain.
+func (t *Tile) Passable() bool {
+	return t.Terrain.Properties().Passable
+}
+
+// SetTerrain changes the kind of ground the tile is made of, and lets the grid know so that paths and successors
+// found across the old ground are not reused. Terrain must only be changed this way once the grid is in use.
+func (t *Tile) SetTerrain(terrain Terrain) {
+	t.Terrain = terrain
+	if t.grid != nil {
+		t.grid.TerrainChanged()
 	}
+}
 
-	var adjacentTiles []astar.Node
-	rows := len(t.grid.Tiles)
-	cols := len(t.grid.Tiles[0])
+// directions are the offsets of the eight tiles around a tile
+var directions = [...]struct{ dx, dy int }{
+	{-1, -1}, {-1, 0}, {-1, 1}, // Top-left, Top, Top-right
+	{0, -1}, {0, 1}, // Left,        Right
+	{1, -1}, {1, 0}, {1, 1}, // Bottom-left, Bottom, Bottom-right
+}
+
+// GetSuccessors implements astar.Node and returns the passable nodes that are adjacent to the given node. A diagonal
+// node is only returned if both of the nodes beside it are passable too, so that paths don't cut the corners of water.
+// The successors are kept until the terrain of the grid changes, so the returned slice must not be modified.
+func (t *Tile) GetSuccessors() ([]astar.Node, error) {
+	if t.successors != nil && t.successorsVersion == t.grid.TerrainVersion() {
+		return t.successors, nil
+	}
 
+	adjacentTiles := make([]astar.Node, 0, len(directions))
 	for _, d := range directions {
-		newX, newY := t.X+d.dx, t.Y+d.dy
-		if newX >= 0 && newX < rows && newY >= 0 && newY < cols {
-			adjacentTile, ok := t.grid.Tiles[newX][newY].(*Tile)
-			if !ok {
-				return nil, errors.New("grid tile is not instance of ground tile") // todo better error
+		adjacentTile, err := t.neighbor(d.dx, d.dy)
+		if err != nil {
+			return nil, err
+		}
+		if adjacentTile == nil || !adjacentTile.Passable() {
+			continue
+		}
+		if d.dx != 0 && d.dy != 0 {
+			if !t.passableNeighbor(d.dx, 0) || !t.passableNeighbor(0, d.dy) {
+				continue // don't cut the corner
 			}
-			adjacentTiles = append(adjacentTiles, adjacentTile)
 		}
+		adjacentTiles = append(adjacentTiles, adjacentTile)
 	}
 
+	t.successors, t.successorsVersion = adjacentTiles, t.grid.TerrainVersion()
 	return adjacentTiles, nil
 }
 
+// neighbor returns the tile offset from this one by dx and dy, or nil if that is outside the grid.
+func (t *Tile) neighbor(dx, dy int) (*Tile, error) {
+	rows := len(t.grid.Tiles)
+	cols := len(t.grid.Tiles[0])
+	newX, newY := t.X+dx, t.Y+dy
+	if newX < 0 || newX >= rows || newY < 0 || newY >= cols {
+		return nil, nil
+	}
+	adjacentTile, ok := t.grid.Tiles[newX][newY].(*Tile)
+	if !ok {
+		return nil, errors.New("grid tile is not instance of ground tile") // todo better error
+	}
+	return adjacentTile, nil
+}
+
+// passableNeighbor returns whether the tile offset from this one by dx and dy is inside the grid and passable.
+func (t *Tile) passableNeighbor(dx, dy int) bool {
+	neighbor, err := t.neighbor(dx, dy)
+	return err == nil && neighbor != nil && neighbor.Passable()
+}
+
 // isDiagonallyAdjacent is a helper function that determines whether two tiles are diagonally adjacent to each other.
 func isDiagonallyAdjacent(tile1, tile2 *Tile) bool {
 	return math.Abs(float64(tile1.X-tile2.X)) == 1 && math.Abs(float64(tile1.Y-tile2.Y)) == 1
@@ -95,15 +142,10 @@ func (t *Tile) Coord() core.Coord {
 
 // MakeTile returns a new Grass tile to populate the world grid
 func MakeTile(X, Y int, grid *grid.Grid) (grid.Tile, error) {
-	ground, err := NewGrassGround()
-	if err != nil {
-		return nil, err
-	}
-
 	return &Tile{
-		Ground: ground,
-		X:      X,
-		Y:      Y,
-		grid:   grid,
+		X:       X,
+		Y:       Y,
+		Terrain: Grass,
+		grid:    grid,
 	}, nil
 }
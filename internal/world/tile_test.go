@@ -4,8 +4,10 @@ import (
 	"testing"
 
 	"Neolithic/internal/astar"
+	"Neolithic/internal/core"
 	"Neolithic/internal/grid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func testMakeTile(X, Y int, grid *grid.Grid) (grid.Tile, error) {
@@ -247,3 +249,95 @@ func TestTile_ID(t *testing.T) {
 		})
 	}
 }
+
+func TestTile_Terrain(t *testing.T) {
+	// a 5x5 grid with a wall of water down the middle, open only at the bottom
+	terrainAt := func(x, y int) Terrain {
+		if x == 2 && y < 4 {
+			return Water
+		}
+		if x == 3 && y == 4 {
+			return Marsh
+		}
+		return Grass
+	}
+	testGrid, err := grid.New(5, 5, 1)
+	require.NoError(t, err)
+	require.NoError(t, testGrid.Initialize(MakeTerrainTile(terrainAt)))
+	tileAt := func(x, y int) *Tile {
+		return testGrid.Tiles[x][y].(*Tile)
+	}
+
+	t.Run("impassable and corner-cutting successors are skipped", func(t *testing.T) {
+		successors, err := tileAt(1, 1).GetSuccessors()
+		require.NoError(t, err)
+		var ids []string
+		for _, successor := range successors {
+			id, err := successor.ID()
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+		assert.Equal(t, []string{"0,0", "0,1", "0,2", "1,0", "1,2"}, ids)
+	})
+
+	t.Run("cost is scaled by terrain", func(t *testing.T) {
+		assert.Equal(t, 1.0, tileAt(1, 4).Cost(tileAt(0, 4)))
+		assert.Equal(t, 2.5, tileAt(3, 4).Cost(tileAt(2, 4)))
+		assert.InDelta(t, 1.4*2.5, tileAt(3, 4).Cost(tileAt(2, 3)), 1e-9)
+	})
+
+	t.Run("path routes around water", func(t *testing.T) {
+		search, err := astar.NewSearch(tileAt(0, 0), tileAt(4, 0))
+		require.NoError(t, err)
+		require.NoError(t, search.RunIterations(10000))
+		require.True(t, search.FoundBest)
+
+		for _, node := range search.CurrentBestPath() {
+			assert.True(t, node.(*Tile).Passable(), "path should not cross water")
+		}
+		assert.Equal(t, Grass, tileAt(1, 4).Terrain)
+	})
+}
+
+func TestTile_SetTerrain(t *testing.T) {
+	testGrid, err := grid.New(5, 5, 1)
+	require.NoError(t, err)
+	require.NoError(t, testGrid.Initialize(MakeTile))
+	from, to := core.Coord{X: 0, Y: 2}, core.Coord{X: 4, Y: 2}
+
+	path, err := testGrid.FindPath(from, to)
+	require.NoError(t, err)
+	assert.Contains(t, path, core.Coord{X: 2, Y: 2})
+
+	// a wall of water down the middle, open only at the bottom
+	for y := range 4 {
+		testGrid.Tiles[2][y].(*Tile).SetTerrain(Water)
+	}
+
+	path, err = testGrid.FindPath(from, to)
+	require.NoError(t, err)
+	assert.Contains(t, path, core.Coord{X: 2, Y: 4}, "cached path across the old ground should not be reused")
+	for _, coord := range path {
+		assert.True(t, testGrid.Tiles[coord.X][coord.Y].(*Tile).Passable(), "path should not cross water")
+	}
+}
+
+func TestTile_GetSuccessorsKept(t *testing.T) {
+	testGrid, err := grid.New(3, 3, 1)
+	require.NoError(t, err)
+	require.NoError(t, testGrid.Initialize(MakeTile))
+	center := testGrid.Tiles[1][1].(*Tile)
+
+	first, err := center.GetSuccessors()
+	require.NoError(t, err)
+	second, err := center.GetSuccessors()
+	require.NoError(t, err)
+	require.Len(t, second, 8)
+	assert.Same(t, &first[0], &second[0], "successors should be kept between calls")
+
+	testGrid.Tiles[0][1].(*Tile).SetTerrain(Water)
+
+	third, err := center.GetSuccessors()
+	require.NoError(t, err)
+	assert.Len(t, third, 5, "the water tile and the corners beside it should no longer be successors")
+}
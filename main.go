@@ -1,23 +1,22 @@
 package main
 
 import (
-	"Neolithic/internal/attributes"
+	"flag"
 	"log"
-	"os"
-	"runtime/pprof"
 
-	"Neolithic/internal/agent"
 	"Neolithic/internal/camera"
-	"Neolithic/internal/core"
-	"Neolithic/internal/goalengine"
 	"Neolithic/internal/grid"
 	"Neolithic/internal/logging"
+	"Neolithic/internal/profiling"
+	"Neolithic/internal/render"
+	"Neolithic/internal/scenario"
 	"Neolithic/internal/world"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 type Game struct {
 	Engine   *world.Engine
+	Renderer *render.Renderer
 	Camera   *camera.Camera
 	Viewport *camera.Viewport
 }
@@ -51,7 +50,7 @@ func (g *Game) Update() error {
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	g.Engine.Draw(screen, g.Viewport, g.Camera)
+	g.Renderer.Draw(screen, g.Engine, g.Viewport, g.Camera)
 }
 
 func (g *Game) Layout(_, _ int) (screenWidth, screenHeight int) {
@@ -59,103 +58,74 @@ func (g *Game) Layout(_, _ int) (screenWidth, screenHeight int) {
 }
 
 func main() {
+	scenarioPath := flag.String("scenario", "", "path to a scenario file; the built-in default scenario is used if empty")
+	seed := flag.Int64("seed", world.DefaultSeed, "seed of the simulation, from which maps are generated; overrides the seed of the scenario if given")
+	var profileConfig profiling.Config
+	profileConfig.RegisterFlags(flag.CommandLine)
+	flag.Parse()
 
-	cpuProfileFile, err := os.Create("cpu.pprof")
+	profiler, err := profiling.Start(profileConfig)
 	if err != nil {
-		log.Fatal("could not create CPU profile: ", err)
-	}
-	defer cpuProfileFile.Close() // Make sure to close the file
-
-	if err := pprof.StartCPUProfile(cpuProfileFile); err != nil {
-		log.Fatal("could not start CPU profile: ", err)
+		log.Fatal(err)
 	}
-	defer pprof.StopCPUProfile() // Make sure to stop profiling when main exits
 
 	cam := camera.NewCamera()
 	vp := camera.NewViewport(cam, 800, 600)
-	width, height := 32, 32
 
 	logger := logging.NewLogger("info")
 
-	worldGrid, err := grid.New(width, height, 16)
-	if err != nil {
-		log.Fatal(err)
+	var scenarioOpts []scenario.Option
+	if flagGiven("seed") {
+		scenarioOpts = append(scenarioOpts, scenario.WithSeed(*seed))
 	}
-	if err = worldGrid.Initialize(world.MakeTile); err != nil {
-		log.Fatal(err)
+	var engine *world.Engine
+	if *scenarioPath == "" {
+		engine, err = scenario.Default(logger, scenarioOpts...)
+	} else {
+		engine, err = scenario.Load(*scenarioPath, logger, scenarioOpts...)
+	}
+	if err != nil {
+		stopAndFatal(profiler, err)
 	}
 
-	engine, err := world.NewEngine(worldGrid, logger)
+	renderer, err := render.NewRenderer(engine.Seed(), engine.World.Grid.(*grid.Grid).CellSize)
 	if err != nil {
-		log.Fatal(err)
+		stopAndFatal(profiler, err)
 	}
 
 	game := &Game{
 		Engine:   engine,
+		Renderer: renderer,
 		Camera:   cam,
 		Viewport: vp,
 	}
 
-	baseCapacityAttr := &attributes.Capacity{Size: 100}
-
-	loc1 := core.NewLocation("loc1", core.Coord{X: 3, Y: 14}, core.WithAttributes(baseCapacityAttr))
-	loc2 := core.NewLocation("loc2", core.Coord{X: 21, Y: 4}, core.WithAttributes(baseCapacityAttr))
-	loc3 := core.NewLocation("loc3", core.Coord{X: 27, Y: 30}, core.WithAttributes(baseCapacityAttr))
-	depo := core.NewLocation("depo", core.Coord{X: 16, Y: 16}, core.WithAttributes(baseCapacityAttr))
-
-	res1 := core.NewResource("Berries", core.WithResourceAttributes(&attributes.Weight{Amount: 1}))
-	res2 := core.NewResource("Wood", core.WithResourceAttributes(&attributes.Weight{Amount: 1}))
-	res3 := core.NewResource("Stone", core.WithResourceAttributes(&attributes.Weight{Amount: 1}))
-
-	loc1.Inventory.AdjustAmount(res1, 2000)
-	loc2.Inventory.AdjustAmount(res1, 1000)
-	loc3.Inventory.AdjustAmount(res1, 2000)
-
-	goalDepo := depo.DeepCopy()
-
-	testAgent := agent.NewAgent("agent", logger)
-	testAgent.Behavior.GoalEngine = &goalengine.GoalEngine{
-		Goal: goalengine.Goal{
-			Name: "gather berries",
-			Logic: goalengine.GoalLogic{
-				Chunker:      goalengine.AddToLocation,
-				Fallback:     goalengine.FallbackChunkFunc,
-				ShouldGiveUp: goalengine.GiveUpIfNoChange,
-			},
-			Location: goalDepo,
-			Resource: res1,
-		},
-	}
+	ebiten.SetWindowSize(800, 600)
+	ebiten.SetWindowTitle("Hello, World!")
 
-	if err = engine.AddLocation(loc1); err != nil {
-		log.Fatal(err)
-	}
-	if err = engine.AddLocation(loc2); err != nil {
-		log.Fatal(err)
-	}
-	if err = engine.AddLocation(loc3); err != nil {
-		log.Fatal(err)
-	}
-	if err = engine.AddLocation(depo); err != nil {
-		log.Fatal(err)
+	if err = ebiten.RunGame(game); err != nil {
+		stopAndFatal(profiler, err)
 	}
-	if err = engine.AddResource(res1); err != nil {
-		log.Fatal(err)
-	}
-	if err = engine.AddResource(res2); err != nil {
-		log.Fatal(err)
-	}
-	if err = engine.AddResource(res3); err != nil {
-		log.Fatal(err)
+	if err = profiler.Stop(); err != nil {
+		log.Print(err)
 	}
-	if err = engine.AddAgent(testAgent); err != nil {
-		log.Fatal(err)
-	}
-
-	ebiten.SetWindowSize(800, 600)
-	ebiten.SetWindowTitle("Hello, World!")
+}
 
-	if err := ebiten.RunGame(game); err != nil {
-		log.Fatal(err)
+// stopAndFatal stops the profiler, so that the profiles taken so far are written, then logs err and exits.
+func stopAndFatal(profiler *profiling.Session, err error) {
+	if stopErr := profiler.Stop(); stopErr != nil {
+		log.Print(stopErr)
 	}
+	log.Fatal(err)
+}
+
+// flagGiven reports whether the named flag was set on the command line.
+func flagGiven(name string) bool {
+	given := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	})
+	return given
 }